@@ -486,8 +486,16 @@ func processExpectedGitOpsDeployment(ctx context.Context, expectedGitopsDeployme
 		return nil
 	}
 
-	// GitOpsDeployment already exists, so compare it with what we expect
-	if reflect.DeepEqual(expectedGitopsDeployment.Spec, actualGitOpsDeployment.Spec) &&
+	// GitOpsDeployment already exists, so compare it with what we expect.
+	// The GitOpsDeployment webhook defaults some fields of the spec (such as the sync policy of automated
+	// GitOpsDeployments), so both are defaulted before they are compared.
+	defaultedExpectedGitOpsDeployment := expectedGitopsDeployment.DeepCopy()
+	defaultedExpectedGitOpsDeployment.Default()
+
+	defaultedActualGitOpsDeployment := actualGitOpsDeployment.DeepCopy()
+	defaultedActualGitOpsDeployment.Default()
+
+	if reflect.DeepEqual(defaultedExpectedGitOpsDeployment.Spec, defaultedActualGitOpsDeployment.Spec) &&
 		areAppStudioLabelsEqualBetweenMaps(expectedGitopsDeployment.ObjectMeta.Labels, actualGitOpsDeployment.ObjectMeta.Labels) {
 		// B) The GitOpsDeployment is exactly as expected, so return
 		return nil
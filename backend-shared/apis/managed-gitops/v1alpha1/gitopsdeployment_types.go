@@ -17,12 +17,20 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"strings"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // GitOpsDeploymentSpec defines the desired state of GitOpsDeployment
 type GitOpsDeploymentSpec struct {
-	Source ApplicationSource `json:"source"`
+	// Source is a reference to the location of the application's manifests or chart.
+	// This field is required, unless the Sources field is specified.
+	Source ApplicationSource `json:"source,omitempty"`
+
+	// Sources is a list of references to the locations of the application's manifests or charts, which are deployed together.
+	// This field may be empty, and cannot be combined with the Source field.
+	Sources ApplicationSources `json:"sources,omitempty"`
 
 	// Destination is a reference to a target namespace/cluster to deploy to.
 	// This field may be empty: if it is empty, it is assumed that the destination
@@ -32,6 +40,11 @@ type GitOpsDeploymentSpec struct {
 	// SyncPolicy controls when and how a sync will be performed.
 	SyncPolicy *SyncPolicy `json:"syncPolicy,omitempty"`
 
+	// IgnoreDifferences is a list of resources, and fields within those resources, whose differences from the GitOps
+	// repository should be ignored when determining whether the GitOpsDeployment is in sync. For example, the replica
+	// count of a Deployment that is managed by a HorizontalPodAutoscaler.
+	IgnoreDifferences []ResourceIgnoreDifferences `json:"ignoreDifferences,omitempty"`
+
 	// Two possible values:
 	// - Automated: whenever a new commit occurs in the GitOps repository, or the Argo CD Application is out of sync, Argo CD should be told to (re)synchronize.
 	// - Manual: Argo CD should never be told to resynchronize. Instead, synchronize operations will be triggered via GitOpsDeploymentSyncRun operations only.
@@ -47,11 +60,87 @@ type ApplicationSource struct {
 	// RepoURL is the URL to the repository (Git or Helm) that contains the application manifests
 	RepoURL string `json:"repoURL"`
 	// Path is a directory path within the Git repository, and is only valid for applications sourced from Git.
-	Path string `json:"path"`
+	// This field is required, unless a Helm chart is specified in the Helm field.
+	Path string `json:"path,omitempty"`
 	// TargetRevision defines the revision of the source to sync the application to.
 	// In case of Git, this can be commit, tag, or branch. If omitted, will equal to HEAD.
 	// In case of Helm, this is a semver tag for the Chart's version.
 	TargetRevision string `json:"targetRevision,omitempty"`
+
+	// Helm holds Helm specific options. This field may be empty: if it is empty, the source is not treated as a Helm chart.
+	Helm *ApplicationSourceHelm `json:"helm,omitempty"`
+
+	// Kustomize holds Kustomize specific options, which are applied on top of the kustomization.yaml of Path.
+	// This field may be empty, and cannot be combined with the Helm field.
+	Kustomize *ApplicationSourceKustomize `json:"kustomize,omitempty"`
+
+	// Ref is a reference to this source, which other sources of a multi-source GitOpsDeployment may use to refer
+	// to files within this source, for example: '$values/environments/dev/values.yaml'.
+	// This field is only valid within the Sources field.
+	Ref string `json:"ref,omitempty"`
+}
+
+// HasMultipleSources returns true if the GitOpsDeployment uses the Sources field, rather than the Source field.
+func (spec GitOpsDeploymentSpec) HasMultipleSources() bool {
+	return len(spec.Sources) > 0
+}
+
+// ApplicationSourceHelm holds Helm specific options
+type ApplicationSourceHelm struct {
+	// Chart is the name of a Helm chart, and must be specified for applications sourced from a Helm repository.
+	// When Chart is specified, RepoURL is the URL of the Helm repository, and Path is ignored.
+	Chart string `json:"chart,omitempty"`
+	// ReleaseName is the Helm release name to use. If omitted it will use the application name
+	ReleaseName string `json:"releaseName,omitempty"`
+	// ValueFiles is a list of Helm value files to use when generating a template
+	ValueFiles []string `json:"valueFiles,omitempty"`
+	// Values specifies Helm values to be passed to helm template, typically defined as a block
+	Values string `json:"values,omitempty"`
+	// Parameters is a list of Helm parameters which are passed to the helm template command upon manifest generation
+	Parameters []HelmParameter `json:"parameters,omitempty"`
+	// PassCredentials pass credentials to all domains (Helm's --pass-credentials)
+	PassCredentials bool `json:"passCredentials,omitempty"`
+}
+
+// HelmParameter is a parameter that's passed to helm template during manifest generation
+type HelmParameter struct {
+	// Name is the name of the Helm parameter
+	Name string `json:"name"`
+	// Value is the value for the Helm parameter
+	Value string `json:"value,omitempty"`
+	// ForceString determines whether to tell Helm to interpret booleans and numbers as strings
+	ForceString bool `json:"forceString,omitempty"`
+}
+
+// ApplicationSourceKustomize holds Kustomize specific options
+type ApplicationSourceKustomize struct {
+	// NamePrefix is a prefix appended to resources for Kustomize apps
+	NamePrefix string `json:"namePrefix,omitempty"`
+	// NameSuffix is a suffix appended to resources for Kustomize apps
+	NameSuffix string `json:"nameSuffix,omitempty"`
+	// Images is a list of Kustomize image override specifications, for example 'quay.io/my-org/my-image:v1.0.0'
+	Images []string `json:"images,omitempty"`
+	// CommonLabels is a list of additional labels to add to rendered manifests
+	CommonLabels map[string]string `json:"commonLabels,omitempty"`
+	// CommonAnnotations is a list of additional annotations to add to rendered manifests
+	CommonAnnotations map[string]string `json:"commonAnnotations,omitempty"`
+	// Namespace sets the namespace that Kustomize adds to all resources
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ResourceIgnoreDifferences identifies the resources, and the fields within those resources, whose differences should be ignored during comparison.
+// Resources are matched by kind, and optionally by group, name and namespace.
+type ResourceIgnoreDifferences struct {
+	Group     string `json:"group,omitempty"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	// JSONPointers is a list of JSON pointers (RFC 6901) to the fields that should be ignored, e.g. '/spec/replicas'
+	JSONPointers []string `json:"jsonPointers,omitempty"`
+	// JQPathExpressions is a list of jq path expressions to the fields that should be ignored, e.g. '.spec.template.spec.initContainers[] | select(.name == "istio-init")'
+	JQPathExpressions []string `json:"jqPathExpressions,omitempty"`
+	// ManagedFieldsManagers is a list of field managers (e.g. 'kube-controller-manager'), whose changes to a resource should be ignored
+	ManagedFieldsManagers []string `json:"managedFieldsManagers,omitempty"`
 }
 
 // ApplicationDestination holds information about the application's destination
@@ -66,21 +155,123 @@ const (
 	// DeletionFinalizer will indicate the GitOpsDeployment to wait until all its dependencies are removed.
 	// In the absence of this finalizer, GitOpsDeployment will be deleted first and its dependencies will be removed in the background.
 	DeletionFinalizer string = "resources-finalizer.managed-gitops.redhat.com"
+
+	// DefaultedRetryAnnotation is set by the defaulting webhook when it sets .spec.syncPolicy.retry of an automated
+	// GitOpsDeployment to the default retry strategy, so that only a defaulted retry strategy is removed when the
+	// GitOpsDeployment is changed to manual.
+	DefaultedRetryAnnotation string = "managed-gitops.redhat.com/defaulted-retry"
 )
 
 type SyncOption string
 
 // Supported values for SyncOptions
 const (
-	SyncOptions_CreateNamespace_true  SyncOption = "CreateNamespace=true"
-	SyncOptions_CreateNamespace_false SyncOption = "CreateNamespace=false"
+	SyncOptions_CreateNamespace_true              SyncOption = "CreateNamespace=true"
+	SyncOptions_CreateNamespace_false             SyncOption = "CreateNamespace=false"
+	SyncOptions_ServerSideApply_true              SyncOption = "ServerSideApply=true"
+	SyncOptions_PruneLast_true                    SyncOption = "PruneLast=true"
+	SyncOptions_ApplyOutOfSyncOnly_true           SyncOption = "ApplyOutOfSyncOnly=true"
+	SyncOptions_Replace_true                      SyncOption = "Replace=true"
+	SyncOptions_Validate_false                    SyncOption = "Validate=false"
+	SyncOptions_PrunePropagationPolicy_foreground SyncOption = "PrunePropagationPolicy=foreground"
+	SyncOptions_PrunePropagationPolicy_background SyncOption = "PrunePropagationPolicy=background"
+	SyncOptions_PrunePropagationPolicy_orphan     SyncOption = "PrunePropagationPolicy=orphan"
+	SyncOptions_RespectIgnoreDifferences_true     SyncOption = "RespectIgnoreDifferences=true"
+	SyncOptions_FailOnSharedResource_true         SyncOption = "FailOnSharedResource=true"
 )
 
+// Keys of the supported SyncOptions: a SyncOption is of the form '<key>=<value>'
+const (
+	SyncOptionKey_CreateNamespace          = "CreateNamespace"
+	SyncOptionKey_ServerSideApply          = "ServerSideApply"
+	SyncOptionKey_PruneLast                = "PruneLast"
+	SyncOptionKey_ApplyOutOfSyncOnly       = "ApplyOutOfSyncOnly"
+	SyncOptionKey_Replace                  = "Replace"
+	SyncOptionKey_Validate                 = "Validate"
+	SyncOptionKey_PrunePropagationPolicy   = "PrunePropagationPolicy"
+	SyncOptionKey_RespectIgnoreDifferences = "RespectIgnoreDifferences"
+	SyncOptionKey_FailOnSharedResource     = "FailOnSharedResource"
+)
+
+// Key returns the key of the SyncOption, for example 'CreateNamespace' for 'CreateNamespace=true'.
+func (syncOption SyncOption) Key() string {
+	key, _, _ := strings.Cut(string(syncOption), "=")
+	return key
+}
+
+// Value returns the value of the SyncOption, for example 'true' for 'CreateNamespace=true'.
+func (syncOption SyncOption) Value() string {
+	_, value, _ := strings.Cut(string(syncOption), "=")
+	return value
+}
+
 type SyncPolicy struct {
 	// Options allow you to specify whole app sync-options.
 	// This option may be empty, if and when it is empty it is considered that there are no SyncOptions present.
 	SyncOptions SyncOptions `json:"syncOptions,omitempty"`
+
+	// Automated controls the behaviour of automated sync, and is ignored unless .spec.type is 'automated'.
+	// If it is not specified, it defaults to enabling prune, selfHeal and allowEmpty. It is removed when .spec.type is 'manual'.
+	Automated *SyncPolicyAutomated `json:"automated,omitempty"`
+
+	// Retry controls failed sync retry behavior.
+	// If it is not specified for an automated GitOpsDeployment, it defaults to retrying indefinitely, with a backoff
+	// starting at 5 seconds, doubling on each retry, up to 3 minutes. This default is removed when .spec.type is changed to
+	// 'manual' (the GitOpsDeployment is annotated with 'managed-gitops.redhat.com/defaulted-retry' while it is applied).
+	Retry *RetryStrategy `json:"retry,omitempty"`
+}
+
+// SyncPolicyAutomated controls the behavior of an automated sync
+type SyncPolicyAutomated struct {
+	// Prune specifies whether to delete resources from the cluster that are not found in the sources anymore as part of automated sync (default: true)
+	Prune *bool `json:"prune,omitempty"`
+	// SelfHeal specifies whether to revert resources back to their desired state upon modification in the cluster (default: true)
+	SelfHeal *bool `json:"selfHeal,omitempty"`
+	// AllowEmpty allows apps have zero live resources (default: true)
+	AllowEmpty *bool `json:"allowEmpty,omitempty"`
+}
+
+// Default values of the automated sync policy, and retry strategy, of automated GitOpsDeployments
+const (
+	DefaultSyncPolicyAutomatedPrune      = true
+	DefaultSyncPolicyAutomatedSelfHeal   = true
+	DefaultSyncPolicyAutomatedAllowEmpty = true
+
+	DefaultRetryLimit              int64 = -1
+	DefaultRetryBackoffDuration          = "5s"
+	DefaultRetryBackoffFactor      int64 = 2
+	DefaultRetryBackoffMaxDuration       = "3m"
+)
+
+// SetDefaults sets any unspecified automated sync policy fields to their default values.
+func (automated *SyncPolicyAutomated) SetDefaults() {
+	if automated.Prune == nil {
+		prune := DefaultSyncPolicyAutomatedPrune
+		automated.Prune = &prune
+	}
+	if automated.SelfHeal == nil {
+		selfHeal := DefaultSyncPolicyAutomatedSelfHeal
+		automated.SelfHeal = &selfHeal
+	}
+	if automated.AllowEmpty == nil {
+		allowEmpty := DefaultSyncPolicyAutomatedAllowEmpty
+		automated.AllowEmpty = &allowEmpty
+	}
+}
+
+// DefaultRetryStrategy returns the retry strategy that is used by automated GitOpsDeployments which do not specify one.
+func DefaultRetryStrategy() *RetryStrategy {
+	factor := DefaultRetryBackoffFactor
+	return &RetryStrategy{
+		Limit: DefaultRetryLimit,
+		Backoff: &Backoff{
+			Duration:    DefaultRetryBackoffDuration,
+			Factor:      &factor,
+			MaxDuration: DefaultRetryBackoffMaxDuration,
+		},
+	}
 }
+
 type SyncOptions []SyncOption
 
 const (
@@ -116,7 +307,9 @@ type ResourceStatus struct {
 
 // ReconciledState contains the last version of the GitOpsDeployment resource that the ArgoCD Controller reconciled
 type ReconciledState struct {
-	Source      GitOpsDeploymentSource      `json:"source"`
+	Source GitOpsDeploymentSource `json:"source"`
+	// Sources contains the sources that were reconciled, for GitOpsDeployments with multiple sources
+	Sources     []GitOpsDeploymentSource    `json:"sources,omitempty"`
 	Destination GitOpsDeploymentDestination `json:"destination"`
 }
 
@@ -149,6 +342,31 @@ type GitOpsDeploymentStatus struct {
 
 	// OperationState contains information about any ongoing operations, such as a sync
 	OperationState *OperationState `json:"operationState,omitempty"`
+
+	// History contains the most recent deployments of the GitOpsDeployment (up to GitOpsDeploymentHistoryLimit), from oldest to newest.
+	// A GitOpsDeploymentSyncRun may be used to roll back to one of these deployments.
+	History []RevisionHistory `json:"history,omitempty"`
+}
+
+// GitOpsDeploymentHistoryLimit is the maximum number of deployments that are listed in the .status.history field of a GitOpsDeployment
+const GitOpsDeploymentHistoryLimit = 10
+
+// RevisionHistory contains information about a previous deployment of the GitOpsDeployment
+type RevisionHistory struct {
+	// ID is the identifier of the deployment, which may be referenced by a GitOpsDeploymentSyncRun to roll back to it
+	ID int64 `json:"id"`
+	// Revision is the revision (e.g. the git commit SHA) that was deployed
+	Revision string `json:"revision,omitempty"`
+	// Revisions contains the revision that was deployed of each source, for GitOpsDeployments with multiple sources
+	Revisions []string `json:"revisions,omitempty"`
+	// DeployedAt is the time at which the deployment completed
+	DeployedAt metav1.Time `json:"deployedAt"`
+	// Source is the source that was deployed
+	Source GitOpsDeploymentSource `json:"source,omitempty"`
+	// Sources contains the sources that were deployed, for GitOpsDeployments with multiple sources
+	Sources []GitOpsDeploymentSource `json:"sources,omitempty"`
+	// InitiatedBy contains information about who initiated the deployment, if it is known
+	InitiatedBy *OperationInitiator `json:"initiatedBy,omitempty"`
 }
 
 // OperationState contains information about state of a running operation
@@ -435,6 +653,18 @@ type GitOpsDeploymentConditionType string
 const (
 	GitOpsDeploymentConditionSyncError     GitOpsDeploymentConditionType = "SyncError"
 	GitOpsDeploymentConditionErrorOccurred GitOpsDeploymentConditionType = "ErrorOccurred"
+
+	// The following condition types are reported by the Argo CD Application of the GitOpsDeployment, and correspond
+	// to the Argo CD condition type of the same name (see ApplicationConditionType, below).
+	// - SyncError (above) is also reported by Argo CD.
+	GitOpsDeploymentConditionComparisonError         GitOpsDeploymentConditionType = "ComparisonError"
+	GitOpsDeploymentConditionInvalidSpecError        GitOpsDeploymentConditionType = "InvalidSpecError"
+	GitOpsDeploymentConditionDeletionError           GitOpsDeploymentConditionType = "DeletionError"
+	GitOpsDeploymentConditionUnknownError            GitOpsDeploymentConditionType = "UnknownError"
+	GitOpsDeploymentConditionSharedResourceWarning   GitOpsDeploymentConditionType = "SharedResourceWarning"
+	GitOpsDeploymentConditionRepeatedResourceWarning GitOpsDeploymentConditionType = "RepeatedResourceWarning"
+	GitOpsDeploymentConditionExcludedResourceWarning GitOpsDeploymentConditionType = "ExcludedResourceWarning"
+	GitOpsDeploymentConditionOrphanedResourceWarning GitOpsDeploymentConditionType = "OrphanedResourceWarning"
 )
 
 // GitOpsConditionStatus is a type which represents possible comparison results
@@ -491,11 +721,24 @@ type GitOpsDeploymentReasonType string
 const (
 	GitopsDeploymentReasonSyncError     GitOpsDeploymentReasonType = "SyncError"
 	GitopsDeploymentReasonErrorOccurred GitOpsDeploymentReasonType = "ErrorOccurred"
+
+	GitopsDeploymentReasonComparisonError         GitOpsDeploymentReasonType = "ComparisonError"
+	GitopsDeploymentReasonInvalidSpecError        GitOpsDeploymentReasonType = "InvalidSpecError"
+	GitopsDeploymentReasonDeletionError           GitOpsDeploymentReasonType = "DeletionError"
+	GitopsDeploymentReasonUnknownError            GitOpsDeploymentReasonType = "UnknownError"
+	GitopsDeploymentReasonSharedResourceWarning   GitOpsDeploymentReasonType = "SharedResourceWarning"
+	GitopsDeploymentReasonRepeatedResourceWarning GitOpsDeploymentReasonType = "RepeatedResourceWarning"
+	GitopsDeploymentReasonExcludedResourceWarning GitOpsDeploymentReasonType = "ExcludedResourceWarning"
+	GitopsDeploymentReasonOrphanedResourceWarning GitOpsDeploymentReasonType = "OrphanedResourceWarning"
 )
 
 const (
-	GitOpsDeploymentUserError_InvalidPathSlash = "spec.source.path cannot be '/'"
-	GitOpsDeploymentUserError_PathIsRequired   = "spec.source.path is a required field and it cannot be empty"
+	GitOpsDeploymentUserError_InvalidPathSlash         = "spec.source.path cannot be '/'"
+	GitOpsDeploymentUserError_PathIsRequired           = "spec.source.path is a required field and it cannot be empty"
+	GitOpsDeploymentUserError_InvalidHelmValues        = "spec.source.helm.values must be a valid YAML document"
+	GitOpsDeploymentUserError_HelmAndKustomize         = "spec.source.helm and spec.source.kustomize cannot both be specified"
+	GitOpsDeploymentUserError_SourceAndSources         = "spec.source and spec.sources cannot both be specified"
+	GitOpsDeploymentUserError_InvalidIgnoreDifferences = "every entry in spec.ignoreDifferences must specify a kind, and only contain valid JSON pointers and jq path expressions"
 )
 
 // +kubebuilder:object:root=true
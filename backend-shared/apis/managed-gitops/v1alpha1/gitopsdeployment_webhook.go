@@ -18,9 +18,17 @@ package v1alpha1
 
 import (
 	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -29,9 +37,60 @@ import (
 const (
 	error_nonempty_namespace_empty_environment = "the environment field should not be empty when the namespace is non-empty"
 	error_invalid_sync_option                  = "the specified sync option in .spec.syncPolicy.syncOptions is either mispelled or is not supported by GitOpsDeployment"
+	error_duplicate_sync_option                = "each sync option in .spec.syncPolicy.syncOptions may only be specified once"
+	error_conflicting_sync_options             = "the ServerSideApply=true and Replace=true sync options in .spec.syncPolicy.syncOptions cannot both be specified"
 	error_invalid_spec_type                    = "spec type must be manual or automated"
+	error_invalid_helm_values                  = "the .spec.source.helm.values field must be a valid YAML document"
+	error_invalid_helm_release_name            = "the .spec.source.helm.releaseName field must be a valid Helm release name"
+	error_invalid_helm_parameter               = "every parameter in .spec.source.helm.parameters must have a non-empty name"
+	error_invalid_helm_value_file              = "every entry in .spec.source.helm.valueFiles must be a non-empty path"
+	error_helm_and_kustomize                   = "the .spec.source.helm and .spec.source.kustomize fields cannot both be specified"
+	error_invalid_kustomize_image              = "every entry in .spec.source.kustomize.images must be a non-empty image"
+	error_invalid_kustomize_namespace          = "the .spec.source.kustomize.namespace field must be a valid namespace name"
+	error_invalid_kustomize_common_labels      = "the .spec.source.kustomize.commonLabels field must only contain valid label keys and values"
+	error_invalid_kustomize_common_annotations = "the .spec.source.kustomize.commonAnnotations field must only contain valid annotation keys"
+	error_source_and_sources                   = "the .spec.source and .spec.sources fields cannot both be specified"
+	error_ref_without_sources                  = "the ref field may only be specified for sources within the .spec.sources field"
+	error_invalid_source_ref                   = "the ref field of a source within .spec.sources must only contain alphanumeric characters, '-' and '_'"
+	error_duplicate_source_ref                 = "the ref field of every source within .spec.sources must be unique"
+	error_invalid_ignore_differences_kind      = "every entry in .spec.ignoreDifferences must specify a kind"
+	error_invalid_json_pointer                 = "every entry in .spec.ignoreDifferences[].jsonPointers must be a valid JSON pointer (e.g. '/spec/replicas')"
+	error_invalid_jq_path_expression           = "every entry in .spec.ignoreDifferences[].jqPathExpressions must be a valid jq path expression, which does not contain single quotes, backticks, ampersands, semicolons, percent signs or newlines"
+	error_invalid_managed_fields_manager       = "every entry in .spec.ignoreDifferences[].managedFieldsManagers must be a non-empty field manager name"
+	error_invalid_retry_limit                  = "the .spec.syncPolicy.retry.limit field must be -1 (retry indefinitely), or greater than or equal to 0"
+	error_invalid_retry_backoff_duration       = "the .spec.syncPolicy.retry.backoff duration fields must be a number of seconds, or a valid duration (e.g. '2m', '1h')"
+	error_invalid_retry_backoff_factor         = "the .spec.syncPolicy.retry.backoff.factor field must be greater than or equal to 1"
+
+	// jqPathExpressionBlockedCharacters are the characters that may not be used within a jq path expression. These are
+	// the characters that are stripped from user input before it is added to an Argo CD Application, with the exception
+	// of '"', which jq requires for string literals.
+	jqPathExpressionBlockedCharacters = "'`\r\n&;%"
+
+	// helmReleaseNameMaxLength is the maximum length of a Helm release name, as enforced by Helm itself.
+	helmReleaseNameMaxLength = 53
 )
 
+// syncOptionValueValidators is the allow-list of sync options supported by GitOpsDeployment: it maps the key of each
+// supported sync option to the function used to validate its value.
+var syncOptionValueValidators = map[string]func(value string) bool{
+	SyncOptionKey_CreateNamespace:          isBooleanSyncOptionValue,
+	SyncOptionKey_ServerSideApply:          isBooleanSyncOptionValue,
+	SyncOptionKey_PruneLast:                isBooleanSyncOptionValue,
+	SyncOptionKey_ApplyOutOfSyncOnly:       isBooleanSyncOptionValue,
+	SyncOptionKey_Replace:                  isBooleanSyncOptionValue,
+	SyncOptionKey_Validate:                 isBooleanSyncOptionValue,
+	SyncOptionKey_RespectIgnoreDifferences: isBooleanSyncOptionValue,
+	SyncOptionKey_FailOnSharedResource:     isBooleanSyncOptionValue,
+	SyncOptionKey_PrunePropagationPolicy: func(value string) bool {
+		return value == SyncOptions_PrunePropagationPolicy_foreground.Value() ||
+			value == SyncOptions_PrunePropagationPolicy_background.Value() ||
+			value == SyncOptions_PrunePropagationPolicy_orphan.Value()
+	},
+}
+
+// sourceRefRegex matches the valid values of the ref field of a source, which is referenced by other sources as '$<ref>'
+var sourceRefRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
 // log is for logging in this package.
 var gitopsdeploymentlog = logf.Log.WithName(logutil.LogLogger_managed_gitops)
 
@@ -50,6 +109,48 @@ func (r *GitOpsDeployment) Default() {
 	log := gitopsdeploymentlog.WithValues(logutil.Log_K8s_Request_Name, r.Name, logutil.Log_K8s_Request_Namespace, r.Namespace, "kind", "GitOpsDeployment")
 
 	log.V(logutil.LogLevel_Debug).Info("default")
+
+	// Automated GitOpsDeployments default to prune/selfHeal/allowEmpty, with an unlimited retry
+	if r.Spec.Type == GitOpsDeploymentSpecType_Automated {
+		if r.Spec.SyncPolicy == nil {
+			r.Spec.SyncPolicy = &SyncPolicy{}
+		}
+
+		if r.Spec.SyncPolicy.Automated == nil {
+			r.Spec.SyncPolicy.Automated = &SyncPolicyAutomated{}
+		}
+		r.Spec.SyncPolicy.Automated.SetDefaults()
+
+		if r.Spec.SyncPolicy.Retry == nil {
+			r.Spec.SyncPolicy.Retry = DefaultRetryStrategy()
+			metav1.SetMetaDataAnnotation(&r.ObjectMeta, DefaultedRetryAnnotation, "true")
+
+		} else if !reflect.DeepEqual(r.Spec.SyncPolicy.Retry, DefaultRetryStrategy()) {
+			// The user has replaced the defaulted retry strategy with their own
+			delete(r.Annotations, DefaultedRetryAnnotation)
+		}
+
+	} else if r.Spec.Type == GitOpsDeploymentSpecType_Manual {
+		_, retryDefaulted := r.Annotations[DefaultedRetryAnnotation]
+		delete(r.Annotations, DefaultedRetryAnnotation)
+
+		if r.Spec.SyncPolicy == nil {
+			return
+		}
+
+		// Manual GitOpsDeployments remove the defaults that were added while the GitOpsDeployment was automated: the automated
+		// sync policy is ignored for manual GitOpsDeployments, and the default retry strategy only applies to automated ones.
+		// A retry strategy that was specified by the user is kept, even if it is equal to the default one.
+		r.Spec.SyncPolicy.Automated = nil
+
+		if retryDefaulted && reflect.DeepEqual(r.Spec.SyncPolicy.Retry, DefaultRetryStrategy()) {
+			r.Spec.SyncPolicy.Retry = nil
+		}
+
+		if len(r.Spec.SyncPolicy.SyncOptions) == 0 && r.Spec.SyncPolicy.Retry == nil {
+			r.Spec.SyncPolicy = nil
+		}
+	}
 }
 
 //+kubebuilder:webhook:path=/validate-managed-gitops-redhat-com-v1alpha1-gitopsdeployment,mutating=false,failurePolicy=fail,sideEffects=None,groups=managed-gitops.redhat.com,resources=gitopsdeployments,verbs=create;update,versions=v1alpha1,name=vgitopsdeployment.kb.io,admissionReviewVersions=v1
@@ -105,19 +206,324 @@ func (r *GitOpsDeployment) validateGitOpsDeployment() error {
 
 	// Check whether sync options are valid
 	if r.Spec.SyncPolicy != nil {
-		for _, syncOptionString := range r.Spec.SyncPolicy.SyncOptions {
-
-			if !(syncOptionString == SyncOptions_CreateNamespace_true ||
-				syncOptionString == SyncOptions_CreateNamespace_false) {
-				return fmt.Errorf(error_invalid_sync_option)
-			}
+		if err := ValidateSyncOptions(r.Spec.SyncPolicy.SyncOptions); err != nil {
+			return err
+		}
+	}
 
+	if r.Spec.SyncPolicy != nil && r.Spec.SyncPolicy.Retry != nil {
+		if err := validateRetryStrategy(*r.Spec.SyncPolicy.Retry); err != nil {
+			return err
 		}
 	}
 
+	if err := ValidateIgnoreDifferences(r.Spec.IgnoreDifferences); err != nil {
+		return err
+	}
+
 	if r.Spec.Destination.Environment == "" && r.Spec.Destination.Namespace != "" {
 		return fmt.Errorf(error_nonempty_namespace_empty_environment)
 	}
 
+	if r.Spec.HasMultipleSources() {
+		if !reflect.DeepEqual(r.Spec.Source, ApplicationSource{}) {
+			return fmt.Errorf(error_source_and_sources)
+		}
+
+		refs := map[string]bool{}
+		for _, source := range r.Spec.Sources {
+			if source.Ref != "" {
+				if !sourceRefRegex.MatchString(source.Ref) {
+					return fmt.Errorf(error_invalid_source_ref)
+				}
+				if refs[source.Ref] {
+					return fmt.Errorf(error_duplicate_source_ref)
+				}
+				refs[source.Ref] = true
+			}
+
+			if err := validateApplicationSource(source); err != nil {
+				return err
+			}
+		}
+
+	} else {
+		if r.Spec.Source.Ref != "" {
+			return fmt.Errorf(error_ref_without_sources)
+		}
+
+		if err := validateApplicationSource(r.Spec.Source); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidateSyncOptions checks that every sync option is in the allow-list of sync options supported by GitOpsDeployment,
+// with a supported value, and that no sync option is specified more than once, or conflicts with another.
+func ValidateSyncOptions(syncOptions SyncOptions) error {
+
+	keys := map[string]bool{}
+
+	for _, syncOption := range syncOptions {
+
+		if !strings.Contains(string(syncOption), "=") {
+			return fmt.Errorf(error_invalid_sync_option)
+		}
+
+		isValidValue, exists := syncOptionValueValidators[syncOption.Key()]
+		if !exists {
+			return fmt.Errorf(error_invalid_sync_option)
+		}
+
+		if !isValidValue(syncOption.Value()) {
+			return fmt.Errorf(error_invalid_sync_option)
+		}
+
+		if keys[syncOption.Key()] {
+			return fmt.Errorf(error_duplicate_sync_option)
+		}
+		keys[syncOption.Key()] = true
+	}
+
+	// Argo CD is unable to both server-side apply and replace a resource
+	serverSideApply, replace := false, false
+	for _, syncOption := range syncOptions {
+		if syncOption == SyncOptions_ServerSideApply_true {
+			serverSideApply = true
+		} else if syncOption == SyncOptions_Replace_true {
+			replace = true
+		}
+	}
+	if serverSideApply && replace {
+		return fmt.Errorf(error_conflicting_sync_options)
+	}
+
+	return nil
+}
+
+// ValidateIgnoreDifferences checks that every entry of .spec.ignoreDifferences specifies a kind, and only contains valid
+// JSON pointers, jq path expressions and field managers.
+func ValidateIgnoreDifferences(ignoreDifferences []ResourceIgnoreDifferences) error {
+
+	for _, ignoreDifference := range ignoreDifferences {
+
+		if strings.TrimSpace(ignoreDifference.Kind) == "" {
+			return fmt.Errorf(error_invalid_ignore_differences_kind)
+		}
+
+		for _, jsonPointer := range ignoreDifference.JSONPointers {
+			if !isValidJSONPointer(jsonPointer) {
+				return fmt.Errorf(error_invalid_json_pointer)
+			}
+		}
+
+		for _, jqPathExpression := range ignoreDifference.JQPathExpressions {
+			if !isValidJQPathExpression(jqPathExpression) {
+				return fmt.Errorf(error_invalid_jq_path_expression)
+			}
+		}
+
+		for _, manager := range ignoreDifference.ManagedFieldsManagers {
+			if strings.TrimSpace(manager) == "" {
+				return fmt.Errorf(error_invalid_managed_fields_manager)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isValidJSONPointer returns true if the JSON pointer is a non-empty RFC 6901 JSON pointer: it must begin with '/',
+// and '~' may only be used in the escape sequences '~0' and '~1'.
+func isValidJSONPointer(jsonPointer string) bool {
+
+	if !strings.HasPrefix(jsonPointer, "/") {
+		return false
+	}
+
+	for idx := 0; idx < len(jsonPointer); idx++ {
+		if jsonPointer[idx] != '~' {
+			continue
+		}
+		if idx+1 >= len(jsonPointer) || (jsonPointer[idx+1] != '0' && jsonPointer[idx+1] != '1') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isValidJQPathExpression performs a syntax check of a jq path expression: it must be non-empty, must not contain
+// any of the blocked characters, must terminate every string literal, and must balance every bracket outside of string literals.
+func isValidJQPathExpression(jqPathExpression string) bool {
+
+	if strings.TrimSpace(jqPathExpression) == "" || strings.ContainsAny(jqPathExpression, jqPathExpressionBlockedCharacters) {
+		return false
+	}
+
+	openingBrackets := map[rune]rune{')': '(', ']': '[', '}': '{'}
+
+	brackets := []rune{}
+	inString, escaped := false, false
+
+	for _, char := range jqPathExpression {
+
+		if inString {
+			if escaped {
+				escaped = false
+			} else if char == '\\' {
+				escaped = true
+			} else if char == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		switch char {
+		case '"':
+			inString = true
+		case '(', '[', '{':
+			brackets = append(brackets, char)
+		case ')', ']', '}':
+			if len(brackets) == 0 || brackets[len(brackets)-1] != openingBrackets[char] {
+				return false
+			}
+			brackets = brackets[:len(brackets)-1]
+		}
+	}
+
+	return !inString && len(brackets) == 0
+}
+
+func isBooleanSyncOptionValue(value string) bool {
+	return value == "true" || value == "false"
+}
+
+// validateRetryStrategy checks the retry strategy of a GitOpsDeployment sync policy.
+func validateRetryStrategy(retry RetryStrategy) error {
+
+	// A limit of -1 means retry indefinitely
+	if retry.Limit < -1 {
+		return fmt.Errorf(error_invalid_retry_limit)
+	}
+
+	if retry.Backoff == nil {
+		return nil
+	}
+
+	if !isValidBackoffDuration(retry.Backoff.Duration) || !isValidBackoffDuration(retry.Backoff.MaxDuration) {
+		return fmt.Errorf(error_invalid_retry_backoff_duration)
+	}
+
+	if retry.Backoff.Factor != nil && *retry.Backoff.Factor < 1 {
+		return fmt.Errorf(error_invalid_retry_backoff_factor)
+	}
+
+	return nil
+}
+
+// isValidBackoffDuration returns true if the duration is empty, or is in a format supported by Argo CD: either a
+// number of seconds, or a duration string (e.g. "2m", "1h").
+func isValidBackoffDuration(duration string) bool {
+	if duration == "" {
+		return true
+	}
+
+	if seconds, err := strconv.ParseInt(duration, 10, 64); err == nil {
+		return seconds >= 0
+	}
+
+	parsedDuration, err := time.ParseDuration(duration)
+	return err == nil && parsedDuration >= 0
+}
+
+// validateApplicationSource checks the tool specific (Helm/Kustomize) options of a single GitOpsDeployment source.
+func validateApplicationSource(source ApplicationSource) error {
+
+	if source.Helm != nil && source.Kustomize != nil {
+		return fmt.Errorf(error_helm_and_kustomize)
+	}
+
+	if source.Helm != nil {
+		if err := validateHelmSource(*source.Helm); err != nil {
+			return err
+		}
+	}
+
+	if source.Kustomize != nil {
+		if err := validateKustomizeSource(*source.Kustomize); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateHelmSource checks the Helm specific options of a GitOpsDeployment source.
+func validateHelmSource(helm ApplicationSourceHelm) error {
+
+	if helm.ReleaseName != "" {
+		if len(helm.ReleaseName) > helmReleaseNameMaxLength || len(validation.IsDNS1123Subdomain(helm.ReleaseName)) != 0 {
+			return fmt.Errorf(error_invalid_helm_release_name)
+		}
+	}
+
+	for _, valueFile := range helm.ValueFiles {
+		if strings.TrimSpace(valueFile) == "" {
+			return fmt.Errorf(error_invalid_helm_value_file)
+		}
+	}
+
+	for _, param := range helm.Parameters {
+		if strings.TrimSpace(param.Name) == "" {
+			return fmt.Errorf(error_invalid_helm_parameter)
+		}
+	}
+
+	if !IsValidHelmValues(helm.Values) {
+		return fmt.Errorf(error_invalid_helm_values)
+	}
+
+	return nil
+}
+
+// IsValidHelmValues returns true if the given string can be used as the inline values of a Helm chart: it must either
+// be empty, or be a YAML document containing a map.
+func IsValidHelmValues(values string) bool {
+	if strings.TrimSpace(values) == "" {
+		return true
+	}
+
+	parsedValues := map[string]interface{}{}
+	return yaml.Unmarshal([]byte(values), &parsedValues) == nil
+}
+
+// validateKustomizeSource checks the Kustomize specific options of a GitOpsDeployment source.
+func validateKustomizeSource(kustomize ApplicationSourceKustomize) error {
+
+	for _, image := range kustomize.Images {
+		if strings.TrimSpace(image) == "" {
+			return fmt.Errorf(error_invalid_kustomize_image)
+		}
+	}
+
+	if kustomize.Namespace != "" && len(validation.IsDNS1123Label(kustomize.Namespace)) != 0 {
+		return fmt.Errorf(error_invalid_kustomize_namespace)
+	}
+
+	for key, value := range kustomize.CommonLabels {
+		if len(validation.IsQualifiedName(key)) != 0 || len(validation.IsValidLabelValue(value)) != 0 {
+			return fmt.Errorf(error_invalid_kustomize_common_labels)
+		}
+	}
+
+	for key := range kustomize.CommonAnnotations {
+		if len(validation.IsQualifiedName(key)) != 0 {
+			return fmt.Errorf(error_invalid_kustomize_common_annotations)
+		}
+	}
+
 	return nil
 }
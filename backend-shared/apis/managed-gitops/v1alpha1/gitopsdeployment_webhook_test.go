@@ -149,4 +149,375 @@ var _ = Describe("GitOpsDeployment validation webhook", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 	})
+
+	Context("Create GitOpsDeployment CR with invalid .spec.source.helm.values field", func() {
+		It("Should fail with error saying the helm values must be a valid YAML document", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.Source.Helm = &ApplicationSourceHelm{
+				Chart:  "my-chart",
+				Values: "- not\n- a map",
+			}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_invalid_helm_values))
+
+		})
+
+	})
+
+	Context("Create GitOpsDeployment CR with invalid .spec.source.helm.releaseName field", func() {
+		It("Should fail with error saying the helm release name must be valid", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.Source.Helm = &ApplicationSourceHelm{
+				Chart:       "my-chart",
+				ReleaseName: "Invalid_Release_Name",
+			}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_invalid_helm_release_name))
+
+		})
+
+	})
+
+	Context("Create GitOpsDeployment CR with both .spec.source.helm and .spec.source.kustomize fields", func() {
+		It("Should fail with error saying helm and kustomize cannot both be specified", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.Source.Helm = &ApplicationSourceHelm{
+				Chart: "my-chart",
+			}
+			gitopsDepl.Spec.Source.Kustomize = &ApplicationSourceKustomize{
+				NamePrefix: "dev-",
+			}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_helm_and_kustomize))
+
+		})
+
+	})
+
+	Context("Create GitOpsDeployment CR with invalid .spec.source.kustomize.commonLabels field", func() {
+		It("Should fail with error saying the common labels must be valid", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.Source.Kustomize = &ApplicationSourceKustomize{
+				CommonLabels: map[string]string{"invalid label key!": "dev"},
+			}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_invalid_kustomize_common_labels))
+
+		})
+
+	})
+
+	Context("Create GitOpsDeployment CR with both .spec.source and .spec.sources fields", func() {
+		It("Should fail with error saying source and sources cannot both be specified", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.Source = ApplicationSource{
+				RepoURL: "https://github.com/redhat-appstudio/managed-gitops",
+				Path:    "resources/test-data/sample-gitops-repository/environments/overlays/dev",
+			}
+			gitopsDepl.Spec.Sources = ApplicationSources{
+				{
+					RepoURL: "https://github.com/redhat-appstudio/managed-gitops",
+					Path:    "resources/test-data/sample-gitops-repository/environments/overlays/staging",
+				},
+			}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_source_and_sources))
+
+		})
+
+	})
+
+	Context("Create GitOpsDeployment CR with duplicate .spec.sources ref fields", func() {
+		It("Should fail with error saying the ref of every source must be unique", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.Sources = ApplicationSources{
+				{
+					RepoURL: "https://github.com/redhat-appstudio/managed-gitops",
+					Ref:     "values",
+				},
+				{
+					RepoURL: "https://github.com/redhat-appstudio/managed-gitops",
+					Ref:     "values",
+				},
+			}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_duplicate_source_ref))
+
+		})
+
+	})
+
+	Context("Create GitOpsDeployment CR with a ref in the .spec.source field", func() {
+		It("Should fail with error saying the ref field may only be specified within .spec.sources", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.Source.Ref = "values"
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_ref_without_sources))
+
+		})
+
+	})
+
+	Context("Create GitOpsDeployment CR of automated type without a .spec.syncPolicy field", func() {
+		It("Should default the automated sync policy and retry strategy", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Succeed())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(gitopsDepl), gitopsDepl)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(gitopsDepl.Spec.SyncPolicy).ToNot(BeNil())
+			Expect(gitopsDepl.Spec.SyncPolicy.Automated).ToNot(BeNil())
+			Expect(*gitopsDepl.Spec.SyncPolicy.Automated.Prune).To(BeTrue())
+			Expect(*gitopsDepl.Spec.SyncPolicy.Automated.SelfHeal).To(BeTrue())
+			Expect(*gitopsDepl.Spec.SyncPolicy.Automated.AllowEmpty).To(BeTrue())
+			Expect(gitopsDepl.Spec.SyncPolicy.Retry).To(Equal(DefaultRetryStrategy()))
+
+			err = k8sClient.Delete(context.Background(), gitopsDepl)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("Update GitOpsDeployment CR from automated type to manual type", func() {
+		It("Should remove the defaulted automated sync policy and retry strategy", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Succeed())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(gitopsDepl), gitopsDepl)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gitopsDepl.Spec.SyncPolicy).ToNot(BeNil())
+			Expect(gitopsDepl.Spec.SyncPolicy.Automated).ToNot(BeNil())
+			Expect(gitopsDepl.Spec.SyncPolicy.Retry).To(Equal(DefaultRetryStrategy()))
+			Expect(gitopsDepl.Annotations).To(HaveKeyWithValue(DefaultedRetryAnnotation, "true"))
+
+			By("switching the GitOpsDeployment to manual")
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Manual
+			err = k8sClient.Update(ctx, gitopsDepl)
+			Expect(err).Should(Succeed())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(gitopsDepl), gitopsDepl)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gitopsDepl.Spec.SyncPolicy).To(BeNil())
+			Expect(gitopsDepl.Annotations).ToNot(HaveKey(DefaultedRetryAnnotation))
+
+			By("switching the GitOpsDeployment back to automated, with a non-default retry strategy and a sync option")
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.SyncPolicy = &SyncPolicy{
+				SyncOptions: SyncOptions{SyncOptions_CreateNamespace_true},
+				Retry:       &RetryStrategy{Limit: 5},
+			}
+			err = k8sClient.Update(ctx, gitopsDepl)
+			Expect(err).Should(Succeed())
+
+			By("switching the GitOpsDeployment to manual again, to ensure only the automated sync policy is removed")
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Manual
+			err = k8sClient.Update(ctx, gitopsDepl)
+			Expect(err).Should(Succeed())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(gitopsDepl), gitopsDepl)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gitopsDepl.Spec.SyncPolicy).ToNot(BeNil())
+			Expect(gitopsDepl.Spec.SyncPolicy.Automated).To(BeNil())
+			Expect(gitopsDepl.Spec.SyncPolicy.Retry).To(Equal(&RetryStrategy{Limit: 5}))
+			Expect(gitopsDepl.Spec.SyncPolicy.SyncOptions).To(Equal(SyncOptions{SyncOptions_CreateNamespace_true}))
+
+			err = k8sClient.Delete(context.Background(), gitopsDepl)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("Update GitOpsDeployment CR with a retry strategy equal to the default one, from automated type to manual type", func() {
+		It("Should keep the retry strategy, as it was specified by the user", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.SyncPolicy = &SyncPolicy{Retry: DefaultRetryStrategy()}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Succeed())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(gitopsDepl), gitopsDepl)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gitopsDepl.Annotations).ToNot(HaveKey(DefaultedRetryAnnotation))
+
+			By("switching the GitOpsDeployment to manual")
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Manual
+			err = k8sClient.Update(ctx, gitopsDepl)
+			Expect(err).Should(Succeed())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(gitopsDepl), gitopsDepl)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gitopsDepl.Spec.SyncPolicy).ToNot(BeNil())
+			Expect(gitopsDepl.Spec.SyncPolicy.Automated).To(BeNil())
+			Expect(gitopsDepl.Spec.SyncPolicy.Retry).To(Equal(DefaultRetryStrategy()))
+
+			err = k8sClient.Delete(context.Background(), gitopsDepl)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("Create GitOpsDeployment CR of automated type with prune disabled", func() {
+		It("Should only default the unspecified automated sync policy fields", func() {
+			prune := false
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.SyncPolicy = &SyncPolicy{
+				Automated: &SyncPolicyAutomated{
+					Prune: &prune,
+				},
+			}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Succeed())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(gitopsDepl), gitopsDepl)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(*gitopsDepl.Spec.SyncPolicy.Automated.Prune).To(BeFalse())
+			Expect(*gitopsDepl.Spec.SyncPolicy.Automated.SelfHeal).To(BeTrue())
+			Expect(*gitopsDepl.Spec.SyncPolicy.Automated.AllowEmpty).To(BeTrue())
+
+			err = k8sClient.Delete(context.Background(), gitopsDepl)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("Create GitOpsDeployment CR with invalid .spec.syncPolicy.retry field", func() {
+		It("Should fail with error saying the retry backoff duration is invalid", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Manual
+			gitopsDepl.Spec.SyncPolicy = &SyncPolicy{
+				Retry: &RetryStrategy{
+					Limit: 3,
+					Backoff: &Backoff{
+						Duration: "five seconds",
+					},
+				},
+			}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_invalid_retry_backoff_duration))
+
+		})
+
+	})
+
+	Context("Create GitOpsDeployment CR with duplicate .spec.syncPolicy.syncOptions", func() {
+		It("Should fail with error saying each sync option may only be specified once", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.SyncPolicy = &SyncPolicy{
+				SyncOptions: SyncOptions{
+					SyncOptions_PrunePropagationPolicy_foreground,
+					SyncOptions_PrunePropagationPolicy_orphan,
+				},
+			}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_duplicate_sync_option))
+
+		})
+
+	})
+
+	Context("Create GitOpsDeployment CR with conflicting .spec.syncPolicy.syncOptions", func() {
+		It("Should fail with error saying ServerSideApply and Replace cannot both be specified", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Manual
+			gitopsDepl.Spec.SyncPolicy = &SyncPolicy{
+				SyncOptions: SyncOptions{
+					SyncOptions_ServerSideApply_true,
+					SyncOptions_Replace_true,
+				},
+			}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_conflicting_sync_options))
+
+		})
+
+	})
+
+	Context("Create GitOpsDeployment CR with invalid .spec.ignoreDifferences jsonPointers field", func() {
+		It("Should fail with error saying the JSON pointer is invalid", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.IgnoreDifferences = []ResourceIgnoreDifferences{
+				{
+					Group:        "apps",
+					Kind:         "Deployment",
+					JSONPointers: []string{"spec/replicas"},
+				},
+			}
+
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_invalid_json_pointer))
+
+		})
+
+	})
+
+	Context("Update GitOpsDeployment CR with invalid .spec.ignoreDifferences jqPathExpressions field", func() {
+		It("Should accept a valid jq path expression, and fail with error saying an unbalanced jq path expression is invalid", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			gitopsDepl.Spec.IgnoreDifferences = []ResourceIgnoreDifferences{
+				{
+					Kind:              "Deployment",
+					JQPathExpressions: []string{`.spec.template.spec.initContainers[] | select(.name == "istio-init")`},
+				},
+			}
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Succeed())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(gitopsDepl), gitopsDepl)
+			Expect(err).ToNot(HaveOccurred())
+
+			gitopsDepl.Spec.IgnoreDifferences[0].JQPathExpressions = []string{`.spec.template.spec.initContainers[] | select(.name == "istio-init"`}
+			err = k8sClient.Update(ctx, gitopsDepl)
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).Should(ContainSubstring(error_invalid_jq_path_expression))
+
+			err = k8sClient.Delete(context.Background(), gitopsDepl)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("Update GitOpsDeployment CR with invalid .spec.source.helm.parameters field", func() {
+		It("Should fail with error saying every helm parameter must have a name", func() {
+			gitopsDepl.Spec.Type = GitOpsDeploymentSpecType_Automated
+			err := k8sClient.Create(ctx, gitopsDepl)
+			Expect(err).Should(Succeed())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(gitopsDepl), gitopsDepl)
+			Expect(err).ToNot(HaveOccurred())
+
+			gitopsDepl.Spec.Source.Helm = &ApplicationSourceHelm{
+				Chart: "my-chart",
+				Parameters: []HelmParameter{
+					{Name: "", Value: "some-value"},
+				},
+			}
+			err = k8sClient.Update(ctx, gitopsDepl)
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).Should(ContainSubstring(error_invalid_helm_parameter))
+
+			err = k8sClient.Delete(context.Background(), gitopsDepl)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
 })
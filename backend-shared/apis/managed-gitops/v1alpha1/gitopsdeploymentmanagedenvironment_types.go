@@ -17,11 +17,20 @@ limitations under the License.
 package v1alpha1
 
 import (
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
 	ManagedEnvironmentStatusConnectionInitializationSucceeded = "ConnectionInitializationSucceeded"
+
+	// ManagedEnvironmentStatusReachable is periodically updated by the GitOps Service, to indicate whether the API
+	// server of the managed environment could be contacted using the cluster credentials.
+	ManagedEnvironmentStatusReachable = "Reachable"
+
+	// ManagedEnvironmentStatusServiceAccountRemovalFailed is set to True by the GitOps Service when it is unable to remove
+	// a ServiceAccount that it installed into the cluster of the managed environment, and which is no longer used.
+	ManagedEnvironmentStatusServiceAccountRemovalFailed = "ServiceAccountRemovalFailed"
 )
 
 // The GitOpsDeploymentManagedEnvironment CR describes a remote cluster which the GitOps Service will deploy to, via Argo CD.
@@ -41,13 +50,28 @@ type GitOpsDeploymentManagedEnvironmentSpec struct {
 	// Defaults to false.
 	AllowInsecureSkipTLSVerify bool `json:"allowInsecureSkipTLSVerify"`
 
+	// CertificateAuthorityData is a PEM-encoded CA bundle that is used to verify the TLS certificate of the Kubernetes API URL.
+	// Optional: If not specified, the 'certificate-authority-data' of the matching cluster in the kubeconfig Secret is used (if present).
+	// - This field may not be combined with .spec.allowInsecureSkipTLSVerify.
+	// - If you are familiar with Argo CD: this field is equivalent to the 'caData' field of the Argo CD Cluster Secret config.
+	CertificateAuthorityData string `json:"certificateAuthorityData,omitempty"`
+
+	// ProxyURL is the URL of an HTTP(S) proxy that is used to reach the Kubernetes API URL, for clusters that are only
+	// reachable through an egress proxy.
+	// Optional: If not specified, the cluster is contacted directly.
+	// - The URL must use the 'http' or 'https' scheme, for example: 'http://proxy.example.com:3128'
+	// - This field is not yet supported, and is rejected: the Argo CD version used by the GitOps Service does not support it.
+	// - If you are familiar with Argo CD: this field is equivalent to the 'proxyUrl' field of the Argo CD Cluster Secret config.
+	ProxyURL string `json:"proxyURL,omitempty"`
+
 	// CreateNewServiceAccount controls whether Argo CD will use the ServiceAccount provided by the user in the Secret, or if a new ServiceAccount
 	// should be created.
 	//
 	// Optional, default to false.
 	//
 	// - If true, the GitOps Service will automatically create a ServiceAccount/ClusterRole/ClusterRoleBinding on the target cluster,
-	//   using the credentials provided by the user in the secret.
+	//   using the credentials provided by the user in the secret. If .spec.namespaces is non-empty, a Role/RoleBinding
+	//   is created in each of those Namespaces instead (see .spec.serviceAccountRules).
 	//   - Argo CD will then be configured to deploy with that new ServiceAccount.
 	//
 	// - Default: If false, it is assumed that the credentials provided by the user in the Secret are for a ServiceAccount on the cluster, and
@@ -64,14 +88,45 @@ type GitOpsDeploymentManagedEnvironmentSpec struct {
 	// - If you are familiar with Argo CD: this field is equivalent to the field of the same name in the Argo CD Cluster Secret.
 	Namespaces []string `json:"namespaces,omitempty"`
 
+	// NamespaceSelector selects the Namespaces of the target cluster that the Secret's ServiceAccount has access to, by label.
+	//
+	// Optional. If specified, the Namespaces of the target cluster that match the selector are added to those of .spec.namespaces,
+	// and the resulting list is kept up to date as matching Namespaces are created and deleted. The resolved list is
+	// reported in .status.resolvedNamespaces.
+	// - The credentials in the Secret must be able to list the Namespaces of the target cluster.
+	// - At least one Namespace must match: an empty list of Namespaces would otherwise grant access to all Namespaces.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
 	// ClusterResources is used in conjuction with the Namespace field.
-	// If the .spec.namespaces field is non-empty, this field will be used to determine whether Argo CD should
+	// If the .spec.namespaces field (or .spec.namespaceSelector) is non-empty, this field will be used to determine whether Argo CD should
 	// attempt to manage cluster-scoped resources.
-	// - If .spec.namespaces field is empty, this field is ignored.
+	// - If .spec.namespaces field is empty, and .spec.namespaceSelector is not specified, this field is ignored.
 	// - If you are familiar with Argo CD: this field is equivalent to the field of the same name in the Argo CD Cluster Secret.
 	//
 	// Optional, default to false.
 	ClusterResources bool `json:"clusterResources,omitempty"`
+
+	// ServiceAccountRules are the RBAC policy rules that are granted to the ServiceAccount created by the GitOps Service,
+	// when .spec.createNewServiceAccount is true.
+	//
+	// Optional, defaults to full access (*/*/*).
+	//
+	// - If .spec.namespaces is non-empty, the rules are granted in each of those Namespaces, via a Role/RoleBinding.
+	//   The rules are only granted at cluster scope (via a ClusterRole/ClusterRoleBinding) if .spec.clusterResources is true.
+	//   The same applies to the Namespaces that match .spec.namespaceSelector.
+	// - If .spec.namespaces is empty (and .spec.namespaceSelector is not specified), the rules are granted at cluster scope.
+	ServiceAccountRules []rbacv1.PolicyRule `json:"serviceAccountRules,omitempty"`
+
+	// ClusterLabels are labels that are added to the Argo CD cluster secret of the managed environment, for example to allow
+	// Argo CD ApplicationSets to target the cluster by label.
+	//
+	// Optional. The labels that identify the Secret as an Argo CD cluster secret may not be specified.
+	ClusterLabels map[string]string `json:"clusterLabels,omitempty"`
+
+	// ClusterAnnotations are annotations that are added to the Argo CD cluster secret of the managed environment.
+	//
+	// Optional.
+	ClusterAnnotations map[string]string `json:"clusterAnnotations,omitempty"`
 }
 
 type AllowInsecureSkipTLSVerify bool
@@ -87,8 +142,49 @@ const (
 // GitOpsDeploymentManagedEnvironmentStatus defines the observed state of GitOpsDeploymentManagedEnvironment
 type GitOpsDeploymentManagedEnvironmentStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ServerVersion is the Kubernetes version reported by the API server of the managed environment, the last time
+	// that it was successfully contacted.
+	ServerVersion string `json:"serverVersion,omitempty"`
+
+	// ServerLatency is the time taken by the API server of the managed environment to respond, the last time that it was
+	// successfully contacted.
+	ServerLatency *metav1.Duration `json:"serverLatency,omitempty"`
+
+	// LastSuccessfulContactTime is the last time that the API server of the managed environment was successfully
+	// contacted by the GitOps Service. As with ServerLatency, if nothing else in the status changed, it is only updated
+	// every 15 minutes.
+	LastSuccessfulContactTime *metav1.Time `json:"lastSuccessfulContactTime,omitempty"`
+
+	// ResolvedNamespaces is the list of Namespaces that Argo CD is able to deploy to: the Namespaces of .spec.namespaces,
+	// plus those that match .spec.namespaceSelector. Empty if Argo CD is able to deploy to all Namespaces.
+	ResolvedNamespaces []string `json:"resolvedNamespaces,omitempty"`
+
+	// Platform is the Kubernetes distribution of the cluster of the managed environment: 'OpenShift' or 'Kubernetes'.
+	Platform ManagedEnvironmentPlatform `json:"platform,omitempty"`
+
+	// NodeCount is the number of Nodes of the cluster of the managed environment. Only reported if the cluster
+	// credentials are able to list Nodes.
+	NodeCount *int32 `json:"nodeCount,omitempty"`
+
+	// ReachableNamespaces is the list of Namespaces of .status.resolvedNamespaces in which the cluster credentials have
+	// been granted permissions. Any Namespace of .status.resolvedNamespaces that is missing from this list is not
+	// reachable by Argo CD. Only reported if .status.resolvedNamespaces is non-empty.
+	ReachableNamespaces []string `json:"reachableNamespaces,omitempty"`
+
+	// ClusterScopedAccess is true if the cluster credentials are able to list all resources at cluster scope, as is
+	// required if .spec.namespaces is empty, or .spec.clusterResources is true.
+	ClusterScopedAccess *bool `json:"clusterScopedAccess,omitempty"`
 }
 
+// ManagedEnvironmentPlatform is the Kubernetes distribution of the cluster of a managed environment.
+type ManagedEnvironmentPlatform string
+
+const (
+	ManagedEnvironmentPlatformOpenShift  ManagedEnvironmentPlatform = "OpenShift"
+	ManagedEnvironmentPlatformKubernetes ManagedEnvironmentPlatform = "Kubernetes"
+)
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 
@@ -113,12 +209,24 @@ const (
 	ConditionReasonUnableToCreateClient               ManagedEnvironmentConditionReason = "UnableToCreateClient"
 	ConditionReasonUnableToCreateClusterCredentials   ManagedEnvironmentConditionReason = "UnableToCreateClusterCredentials"
 	ConditionReasonUnableToInstallServiceAccount      ManagedEnvironmentConditionReason = "UnableToInstallServiceAccount"
+	ConditionReasonUnableToRemoveServiceAccount       ManagedEnvironmentConditionReason = "UnableToRemoveServiceAccount"
 	ConditionReasonUnableToValidateClusterCredentials ManagedEnvironmentConditionReason = "UnableToValidateClusterCredentials"
 	ConditionReasonUnableToLocateContext              ManagedEnvironmentConditionReason = "UnableToLocateContext"
 	ConditionReasonUnableToParseKubeconfigData        ManagedEnvironmentConditionReason = "UnableToParseKubeconfigData"
 	ConditionReasonInvalidNamespaceList               ManagedEnvironmentConditionReason = "InvalidNamespaceList"
+	ConditionReasonInvalidNamespaceSelector           ManagedEnvironmentConditionReason = "InvalidNamespaceSelector"
+	ConditionReasonNoMatchingNamespaces               ManagedEnvironmentConditionReason = "NoMatchingNamespaces"
+	ConditionReasonNamespaceNotFound                  ManagedEnvironmentConditionReason = "NamespaceNotFound"
 	ConditionReasonUnableToRetrieveRestConfig         ManagedEnvironmentConditionReason = "UnableToRetrieveRestConfig"
+	ConditionReasonInvalidCertificateAuthorityData    ManagedEnvironmentConditionReason = "InvalidCertificateAuthorityData"
+	ConditionReasonInvalidProxyURL                    ManagedEnvironmentConditionReason = "InvalidProxyURL"
+	ConditionReasonInvalidClusterSecretMetadata       ManagedEnvironmentConditionReason = "InvalidClusterSecretMetadata"
+	ConditionReasonUnsupportedAuthInfo                ManagedEnvironmentConditionReason = "UnsupportedAuthInfo"
+	ConditionReasonInvalidAuthInfo                    ManagedEnvironmentConditionReason = "InvalidAuthInfo"
+	ConditionReasonInvalidServiceAccountRules         ManagedEnvironmentConditionReason = "InvalidServiceAccountRules"
 	ConditionReasonUnknownError                       ManagedEnvironmentConditionReason = "UnknownError"
+	ConditionReasonUnreachable                        ManagedEnvironmentConditionReason = "Unreachable"
+	ConditionReasonUnauthorized                       ManagedEnvironmentConditionReason = "Unauthorized"
 )
 
 //+kubebuilder:object:root=true
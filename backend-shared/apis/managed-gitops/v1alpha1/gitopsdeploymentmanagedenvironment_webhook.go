@@ -17,17 +17,38 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"crypto/x509"
 	"fmt"
 	"net/url"
 
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
-const error_invalid_cluster_api_url = "cluster api url must start with https://"
+// reservedClusterLabels are the labels of the Argo CD cluster secret that are set by the GitOps Service itself: the label
+// that identifies the Secret as an Argo CD cluster secret, and the label that references the managed environment in the database.
+var reservedClusterLabels = []string{"argocd.argoproj.io/secret-type", "databaseID"}
+
+const (
+	error_invalid_cluster_api_url                 = "cluster api url must start with https://"
+	error_invalid_certificate_authority_data      = "certificateAuthorityData must contain at least one PEM-encoded certificate"
+	error_certificate_authority_data_and_insecure = "certificateAuthorityData may not be specified when allowInsecureSkipTLSVerify is true"
+	error_service_account_rules_without_new_sa    = "serviceAccountRules may only be specified when createNewServiceAccount is true"
+	error_service_account_rule_missing_verbs      = "each rule in serviceAccountRules must specify at least one verb"
+	error_service_account_rule_missing_resources  = "each rule in serviceAccountRules must specify apiGroups and resources, or nonResourceURLs"
+	error_service_account_rule_non_resource_urls  = "nonResourceURLs in serviceAccountRules may only be used at cluster scope: either namespaces and namespaceSelector must be empty, or clusterResources must be true"
+	error_invalid_namespace_selector              = "namespaceSelector is not a valid label selector"
+	error_invalid_proxy_url                       = "proxyURL must be an absolute URL with an http:// or https:// scheme"
+	error_proxy_url_not_supported                 = "proxyURL is not yet supported, as the Argo CD version used by the GitOps Service does not support connecting to clusters through a proxy"
+	error_invalid_cluster_labels                  = "clusterLabels must only contain valid label keys and values"
+	error_reserved_cluster_label                  = "clusterLabels may not contain the labels that identify the Argo CD cluster secret"
+	error_invalid_cluster_annotations             = "clusterAnnotations must only contain valid annotation keys"
+)
 
 // log is for logging in this package.
 var gitopsdeploymentmanagedenvironmentlog = logf.Log.WithName(logutil.LogLogger_managed_gitops)
@@ -107,5 +128,137 @@ func (r *GitOpsDeploymentManagedEnvironment) ValidateGitOpsDeploymentManagedEnv(
 		}
 	}
 
+	if err := ValidateCertificateAuthorityData(r.Spec); err != nil {
+		return err
+	}
+
+	if err := ValidateServiceAccountRules(r.Spec); err != nil {
+		return err
+	}
+
+	if err := ValidateNamespaceSelector(r.Spec); err != nil {
+		return err
+	}
+
+	if err := ValidateProxyURL(r.Spec); err != nil {
+		return err
+	}
+
+	if err := ValidateClusterSecretMetadata(r.Spec); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ValidateCertificateAuthorityData verifies that the .spec.certificateAuthorityData field, if specified, contains
+// at least one valid PEM-encoded certificate, and is not combined with .spec.allowInsecureSkipTLSVerify.
+func ValidateCertificateAuthorityData(spec GitOpsDeploymentManagedEnvironmentSpec) error {
+	if spec.CertificateAuthorityData == "" {
+		return nil
+	}
+
+	if spec.AllowInsecureSkipTLSVerify {
+		return fmt.Errorf(error_certificate_authority_data_and_insecure)
+	}
+
+	if !x509.NewCertPool().AppendCertsFromPEM([]byte(spec.CertificateAuthorityData)) {
+		return fmt.Errorf(error_invalid_certificate_authority_data)
+	}
+
+	return nil
+}
+
+// ValidateServiceAccountRules verifies that the .spec.serviceAccountRules field, if specified, contains valid policy rules
+// that can be granted to the ServiceAccount created by the GitOps Service.
+func ValidateServiceAccountRules(spec GitOpsDeploymentManagedEnvironmentSpec) error {
+	if len(spec.ServiceAccountRules) == 0 {
+		return nil
+	}
+
+	if !spec.CreateNewServiceAccount {
+		return fmt.Errorf(error_service_account_rules_without_new_sa)
+	}
+
+	for _, rule := range spec.ServiceAccountRules {
+
+		if len(rule.Verbs) == 0 {
+			return fmt.Errorf(error_service_account_rule_missing_verbs)
+		}
+
+		if len(rule.NonResourceURLs) > 0 {
+			if (len(spec.Namespaces) > 0 || spec.NamespaceSelector != nil) && !spec.ClusterResources {
+				return fmt.Errorf(error_service_account_rule_non_resource_urls)
+			}
+		} else if len(rule.APIGroups) == 0 || len(rule.Resources) == 0 {
+			return fmt.Errorf(error_service_account_rule_missing_resources)
+		}
+	}
+
+	return nil
+}
+
+// ValidateNamespaceSelector verifies that the .spec.namespaceSelector field, if specified, is a valid label selector.
+func ValidateNamespaceSelector(spec GitOpsDeploymentManagedEnvironmentSpec) error {
+	if spec.NamespaceSelector == nil {
+		return nil
+	}
+
+	if _, err := metav1.LabelSelectorAsSelector(spec.NamespaceSelector); err != nil {
+		return fmt.Errorf("%s: %v", error_invalid_namespace_selector, err)
+	}
+
+	return nil
+}
+
+// proxyURLSupported is whether the .spec.proxyURL field may be specified. The Argo CD version that is used by the GitOps
+// Service does not read the 'proxyUrl' field of the Argo CD cluster secret config, and so would connect to the cluster
+// directly, rather than through the proxy: the field is thus rejected until Argo CD is upgraded.
+const proxyURLSupported = false
+
+// ValidateProxyURL verifies that the .spec.proxyURL field, if specified, is supported, and is an absolute http(s) URL.
+func ValidateProxyURL(spec GitOpsDeploymentManagedEnvironmentSpec) error {
+	if spec.ProxyURL == "" {
+		return nil
+	}
+
+	if !proxyURLSupported {
+		return fmt.Errorf(error_proxy_url_not_supported)
+	}
+
+	proxyURL, err := url.Parse(spec.ProxyURL)
+	if err != nil {
+		return fmt.Errorf("%s: %v", error_invalid_proxy_url, err)
+	}
+
+	if (proxyURL.Scheme != "http" && proxyURL.Scheme != "https") || proxyURL.Host == "" {
+		return fmt.Errorf(error_invalid_proxy_url)
+	}
+
+	return nil
+}
+
+// ValidateClusterSecretMetadata verifies that the .spec.clusterLabels and .spec.clusterAnnotations fields, if specified, are
+// valid labels and annotations, and do not override the labels that are set by the GitOps Service.
+func ValidateClusterSecretMetadata(spec GitOpsDeploymentManagedEnvironmentSpec) error {
+
+	for key, value := range spec.ClusterLabels {
+		if len(validation.IsQualifiedName(key)) != 0 || len(validation.IsValidLabelValue(value)) != 0 {
+			return fmt.Errorf(error_invalid_cluster_labels)
+		}
+
+		for _, reservedLabel := range reservedClusterLabels {
+			if key == reservedLabel {
+				return fmt.Errorf(error_reserved_cluster_label)
+			}
+		}
+	}
+
+	for key := range spec.ClusterAnnotations {
+		if len(validation.IsQualifiedName(key)) != 0 {
+			return fmt.Errorf(error_invalid_cluster_annotations)
+		}
+	}
+
 	return nil
 }
@@ -6,6 +6,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -78,4 +79,200 @@ var _ = Describe("GitOpsDeploymentManagedEnvironment validation webhook", func()
 		})
 	})
 
+	Context("Create GitOpsDeploymentManagedEnvironment CR with invalid certificateAuthorityData", func() {
+		It("Should fail with error saying certificateAuthorityData must contain a PEM-encoded certificate", func() {
+
+			managedEnv.Spec.APIURL = "https://api.fake-unit-test-data.origin-ci-int-gce.dev.rhcloud.com:6443"
+			managedEnv.Spec.CertificateAuthorityData = "not-a-certificate"
+
+			err := k8sClient.Create(ctx, managedEnv)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_invalid_certificate_authority_data))
+		})
+
+		It("Should fail with error if certificateAuthorityData is combined with allowInsecureSkipTLSVerify", func() {
+
+			managedEnv.Spec.APIURL = "https://api.fake-unit-test-data.origin-ci-int-gce.dev.rhcloud.com:6443"
+			managedEnv.Spec.CertificateAuthorityData = fakeCertificateAuthorityData
+			managedEnv.Spec.AllowInsecureSkipTLSVerify = true
+
+			err := k8sClient.Create(ctx, managedEnv)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_certificate_authority_data_and_insecure))
+		})
+
+		It("Should succeed if certificateAuthorityData contains a valid PEM-encoded certificate", func() {
+
+			managedEnv.Spec.APIURL = "https://api.fake-unit-test-data.origin-ci-int-gce.dev.rhcloud.com:6443"
+			managedEnv.Spec.CertificateAuthorityData = fakeCertificateAuthorityData
+
+			err := k8sClient.Create(ctx, managedEnv)
+			Expect(err).Should(Succeed())
+
+			err = k8sClient.Delete(context.Background(), managedEnv)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("Create GitOpsDeploymentManagedEnvironment CR with invalid serviceAccountRules", func() {
+
+		BeforeEach(func() {
+			managedEnv.Spec.APIURL = "https://api.fake-unit-test-data.origin-ci-int-gce.dev.rhcloud.com:6443"
+			managedEnv.Spec.CreateNewServiceAccount = true
+		})
+
+		It("Should fail with error if serviceAccountRules is specified without createNewServiceAccount", func() {
+			managedEnv.Spec.CreateNewServiceAccount = false
+			managedEnv.Spec.ServiceAccountRules = []rbacv1.PolicyRule{{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"*"}}}
+
+			err := k8sClient.Create(ctx, managedEnv)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_service_account_rules_without_new_sa))
+		})
+
+		It("Should fail with error if a rule doesn't specify any verbs", func() {
+			managedEnv.Spec.ServiceAccountRules = []rbacv1.PolicyRule{{APIGroups: []string{"apps"}, Resources: []string{"deployments"}}}
+
+			err := k8sClient.Create(ctx, managedEnv)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_service_account_rule_missing_verbs))
+		})
+
+		It("Should fail with error if a rule doesn't specify any resources", func() {
+			managedEnv.Spec.ServiceAccountRules = []rbacv1.PolicyRule{{APIGroups: []string{"apps"}, Verbs: []string{"get"}}}
+
+			err := k8sClient.Create(ctx, managedEnv)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_service_account_rule_missing_resources))
+		})
+
+		It("Should fail with error if a rule specifies nonResourceURLs, but is restricted to namespaces", func() {
+			managedEnv.Spec.Namespaces = []string{"my-namespace"}
+			managedEnv.Spec.ServiceAccountRules = []rbacv1.PolicyRule{{NonResourceURLs: []string{"/version"}, Verbs: []string{"get"}}}
+
+			err := k8sClient.Create(ctx, managedEnv)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_service_account_rule_non_resource_urls))
+		})
+
+		It("Should succeed if the rules are valid", func() {
+			managedEnv.Spec.Namespaces = []string{"my-namespace"}
+			managedEnv.Spec.ServiceAccountRules = []rbacv1.PolicyRule{{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"*"}}}
+
+			err := k8sClient.Create(ctx, managedEnv)
+			Expect(err).Should(Succeed())
+
+			err = k8sClient.Delete(context.Background(), managedEnv)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Should fail with error if a rule specifies nonResourceURLs, but is restricted to the namespaces of namespaceSelector", func() {
+			managedEnv.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "my-tenant"}}
+			managedEnv.Spec.ServiceAccountRules = []rbacv1.PolicyRule{{NonResourceURLs: []string{"/version"}, Verbs: []string{"get"}}}
+
+			err := k8sClient.Create(ctx, managedEnv)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_service_account_rule_non_resource_urls))
+		})
+	})
+
+	Context("Create GitOpsDeploymentManagedEnvironment CR with namespaceSelector", func() {
+
+		BeforeEach(func() {
+			managedEnv.Spec.APIURL = "https://api.fake-unit-test-data.origin-ci-int-gce.dev.rhcloud.com:6443"
+		})
+
+		It("Should fail with error if namespaceSelector is not a valid label selector", func() {
+			managedEnv.Spec.NamespaceSelector = &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tenant", Operator: "NotAnOperator"}},
+			}
+
+			err := k8sClient.Create(ctx, managedEnv)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_invalid_namespace_selector))
+		})
+
+		It("Should succeed if namespaceSelector is a valid label selector", func() {
+			managedEnv.Spec.NamespaceSelector = &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tenant", Operator: metav1.LabelSelectorOpIn, Values: []string{"my-tenant"}}},
+			}
+
+			err := k8sClient.Create(ctx, managedEnv)
+			Expect(err).Should(Succeed())
+
+			err = k8sClient.Delete(context.Background(), managedEnv)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("Create GitOpsDeploymentManagedEnvironment CR with proxyURL", func() {
+
+		BeforeEach(func() {
+			managedEnv.Spec.APIURL = "https://api.fake-unit-test-data.origin-ci-int-gce.dev.rhcloud.com:6443"
+		})
+
+		It("Should fail with error if proxyURL is specified, as it is not yet supported by Argo CD", func() {
+			managedEnv.Spec.ProxyURL = "http://proxy.example.com:3128"
+
+			err := k8sClient.Create(ctx, managedEnv)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_proxy_url_not_supported))
+		})
+	})
+
+	Context("Create GitOpsDeploymentManagedEnvironment CR with clusterLabels and clusterAnnotations", func() {
+
+		BeforeEach(func() {
+			managedEnv.Spec.APIURL = "https://api.fake-unit-test-data.origin-ci-int-gce.dev.rhcloud.com:6443"
+		})
+
+		It("Should fail with error if clusterLabels contains an invalid label value", func() {
+			managedEnv.Spec.ClusterLabels = map[string]string{"region": "not a valid value"}
+
+			err := k8sClient.Create(ctx, managedEnv)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_invalid_cluster_labels))
+		})
+
+		It("Should fail with error if clusterLabels contains a label that identifies the Argo CD cluster secret", func() {
+			managedEnv.Spec.ClusterLabels = map[string]string{"argocd.argoproj.io/secret-type": "repository"}
+
+			err := k8sClient.Create(ctx, managedEnv)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_reserved_cluster_label))
+		})
+
+		It("Should fail with error if clusterAnnotations contains an invalid annotation key", func() {
+			managedEnv.Spec.ClusterAnnotations = map[string]string{"not a valid key": "value"}
+
+			err := k8sClient.Create(ctx, managedEnv)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_invalid_cluster_annotations))
+		})
+
+		It("Should succeed if clusterLabels and clusterAnnotations are valid", func() {
+			managedEnv.Spec.ClusterLabels = map[string]string{"region": "eu", "example.com/tier": "prod"}
+			managedEnv.Spec.ClusterAnnotations = map[string]string{"example.com/owner": "team a"}
+
+			err := k8sClient.Create(ctx, managedEnv)
+			Expect(err).Should(Succeed())
+
+			err = k8sClient.Delete(context.Background(), managedEnv)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
 })
+
+// fakeCertificateAuthorityData is a self-signed CA certificate that is only used by unit tests.
+const fakeCertificateAuthorityData = `-----BEGIN CERTIFICATE-----
+MIIBkDCCATWgAwIBAgIUD9pFGwMP2FkvjLhb6fYoD5lEtWIwCgYIKoZIzj0EAwIw
+HDEaMBgGA1UEAwwRZmFrZS11bml0LXRlc3QtY2EwIBcNMjYxMDE3MDIwNTA0WhgP
+MjEyNjA5MjMwMjA1MDRaMBwxGjAYBgNVBAMMEWZha2UtdW5pdC10ZXN0LWNhMFkw
+EwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEl31+6OpmfWtNZDkd321EShFmzZ5m33ic
+OonfWwOoRw1cjPr1xiBqHDKfDX0dsP1cWPonnd777yHjGJfrTDT4T6NTMFEwHQYD
+VR0OBBYEFOlReddJFnowAk8mFHsPNMG5PjGcMB8GA1UdIwQYMBaAFOlReddJFnow
+Ak8mFHsPNMG5PjGcMA8GA1UdEwEB/wQFMAMBAf8wCgYIKoZIzj0EAwIDSQAwRgIh
+ALlZO2pH/c17u1/PCAX6AJ3qj/nrBQhaQ6OUMDe+OoQ3AiEA/SRwBTpOy7m+AqkI
+Lb18o8ujSr+CYuJpVrE7+MGIIX8=
+-----END CERTIFICATE-----
+`
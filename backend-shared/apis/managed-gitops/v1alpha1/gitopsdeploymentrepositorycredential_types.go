@@ -26,7 +26,8 @@ import (
 // GitOpsDeploymentRepositoryCredentialSpec defines the desired state of GitOpsDeploymentRepositoryCredential
 type GitOpsDeploymentRepositoryCredentialSpec struct {
 
-	// Repository (HTTPS url, or SSH string) for accessing the Git repo
+	// Repository (HTTPS url, or SSH string) for accessing the Git repo, Helm chart repository, or OCI registry.
+	// If .spec.template is true, this is a URL prefix.
 	// Required field
 	// As of this writing (Mar 2022), we only support HTTPS URL
 	Repository string `json:"repository"`
@@ -34,8 +35,34 @@ type GitOpsDeploymentRepositoryCredentialSpec struct {
 	// Reference to a K8s Secret in the namespace that contains repository credentials (Git username/password, as of this writing)
 	// Required field
 	Secret string `json:"secret"`
+
+	// Template indicates that the credentials are a template: .spec.repository is a URL prefix, and the credentials are
+	// used for every repository whose URL begins with that prefix (for example, every repository of a GitLab group).
+	// - If you are familiar with Argo CD: a template corresponds to an Argo CD 'repo-creds' Secret.
+	//
+	// Optional, defaults to false.
+	Template bool `json:"template,omitempty"`
+
+	// Optional: The type of the repository.
+	// - git: (default) a Git repository, with an HTTPS or SSH URL
+	// - helm: a Helm chart repository, with an HTTPS URL (for example: https://charts.example.com/stable)
+	// - oci: an OCI registry containing Helm charts, with a URL that does not specify a scheme (for example: registry.example.com/charts)
+	Type RepositoryCredentialType `json:"type,omitempty"`
+
+	// Optional: SSH known hosts entries (in the OpenSSH 'known_hosts' format, one per line) of the servers of SSH
+	// repository URLs. These are combined with the 'knownHosts' entries of the Secret, used for strict host key
+	// checking when validating the credentials, and added to the SSH known hosts of Argo CD.
+	KnownHosts string `json:"knownHosts,omitempty"`
 }
 
+type RepositoryCredentialType string
+
+const (
+	RepositoryCredentialTypeGit  RepositoryCredentialType = "git"
+	RepositoryCredentialTypeHelm RepositoryCredentialType = "helm"
+	RepositoryCredentialTypeOCI  RepositoryCredentialType = "oci"
+)
+
 // ErrorOccurred / ValidRepositoryURL / ValidRepositoryCredential
 const (
 	GitOpsDeploymentRepositoryCredentialConditionErrorOccurred             = "ErrorOccurred"
@@ -84,6 +111,9 @@ const (
 	RepositoryCredentialReasonInvalidCredentials   = "InvalidCredentials"
 	RepositoryCredentialReasonInValidRepositoryUrl = "InvalidRepositoryUrl"
 	RepositoryCredentialReasonValidRepositoryUrl   = "ValidRepositoryUrl"
+	RepositoryCredentialReasonTemplateNotValidated = "TemplateNotValidated"
+	RepositoryCredentialReasonHostKeyMismatch      = "HostKeyMismatch"
+	RepositoryCredentialReasonInvalidKnownHosts    = "InvalidKnownHosts"
 )
 
 // SetConditions updates the GitOpsDeploymentRepositoryCredential status conditions for a subset of evaluated types.
@@ -19,15 +19,23 @@ package v1alpha1
 import (
 	"fmt"
 	"net/url"
+	"strings"
 
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	"golang.org/x/crypto/ssh"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
-const error_invalid_repository = "repository must begin with ssh:// or https://"
+const (
+	error_invalid_repository      = "repository must begin with ssh:// or https://"
+	error_invalid_helm_repository = "repository of a Helm chart repository must begin with https://"
+	error_invalid_oci_repository  = "repository of an OCI registry must be a registry host and path, without a scheme (for example: registry.example.com/charts)"
+	error_invalid_repository_type = "type must be one of: git, helm, oci"
+	error_invalid_known_hosts     = "knownHosts requires a repository with a host"
+)
 
 // log is for logging in this package.
 var gitopsdeploymentrepositorycredentiallog = logf.Log.WithName(logutil.LogLogger_managed_gitops)
@@ -95,14 +103,102 @@ func (r *GitOpsDeploymentRepositoryCredential) ValidateDelete() error {
 }
 func (r *GitOpsDeploymentRepositoryCredential) ValidateGitOpsDeploymentRepoCred() error {
 
+	switch r.Spec.Type {
+	case "", RepositoryCredentialTypeGit, RepositoryCredentialTypeHelm, RepositoryCredentialTypeOCI:
+	default:
+		return fmt.Errorf(error_invalid_repository_type)
+	}
+
 	if r.Spec.Repository != "" {
-		apiURL, err := url.ParseRequestURI(r.Spec.Repository)
+
+		if r.Spec.Type == RepositoryCredentialTypeOCI {
+			// As with Argo CD, an OCI registry is referenced by its host and path, without a scheme
+			if strings.Contains(r.Spec.Repository, "://") {
+				return fmt.Errorf(error_invalid_oci_repository)
+			}
+			if registryURL, err := url.Parse("oci://" + r.Spec.Repository); err != nil || registryURL.Host == "" {
+				return fmt.Errorf(error_invalid_oci_repository)
+			}
+
+		} else {
+
+			apiURL, err := url.ParseRequestURI(r.Spec.Repository)
+			if err != nil {
+				return fmt.Errorf(err.Error())
+			}
+
+			if r.Spec.Type == RepositoryCredentialTypeHelm && apiURL.Scheme != "https" {
+				return fmt.Errorf(error_invalid_helm_repository)
+			}
+
+			if !(apiURL.Scheme == "https" || apiURL.Scheme == "ssh") {
+				return fmt.Errorf(error_invalid_repository)
+			}
+		}
+	}
+
+	if r.Spec.KnownHosts != "" {
+		if err := ValidateKnownHosts(r.Spec.KnownHosts, r.Spec.Repository); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidateKnownHosts returns an error if the SSH known hosts entries may not be used for the repository. The entries are
+// shared by all the users of an Argo CD instance, so each (non-comment) line must be a valid SSH known hosts entry:
+// - without a marker (@cert-authority or @revoked)
+// - without wildcard, negated, or hashed host patterns
+// - for the host of the repository only
+func ValidateKnownHosts(knownHosts string, repository string) error {
+
+	repositoryHost := ""
+
+	// OCI registries are referenced without a scheme
+	if !strings.Contains(repository, "://") {
+		repository = "https://" + repository
+	}
+	if repositoryURL, err := url.Parse(repository); err == nil {
+		repositoryHost = repositoryURL.Hostname()
+	}
+
+	for idx, line := range strings.Split(knownHosts, "\n") {
+
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if repositoryHost == "" {
+			return fmt.Errorf(error_invalid_known_hosts)
+		}
+
+		marker, hosts, _, _, _, err := ssh.ParseKnownHosts([]byte(line))
 		if err != nil {
-			return fmt.Errorf(err.Error())
+			return fmt.Errorf("invalid SSH known hosts entry on line %d: %v", idx+1, err)
+		}
+
+		if marker != "" {
+			return fmt.Errorf("SSH known hosts entry on line %d must not have the @%s marker", idx+1, marker)
 		}
 
-		if !(apiURL.Scheme == "https" || apiURL.Scheme == "ssh") {
-			return fmt.Errorf(error_invalid_repository)
+		for _, host := range hosts {
+
+			if strings.ContainsAny(host, "*?!|") {
+				return fmt.Errorf("SSH known hosts entry on line %d must not have wildcard, negated, or hashed host patterns", idx+1)
+			}
+
+			// A host with a non-default port is of the form '[host]:port'
+			hostname := host
+			if strings.HasPrefix(hostname, "[") && strings.Contains(hostname, "]") {
+				hostname = hostname[1:strings.Index(hostname, "]")]
+			}
+
+			if !strings.EqualFold(hostname, repositoryHost) {
+				return fmt.Errorf("SSH known hosts entry on line %d is for host '%s', but only entries for the host of the repository ('%s') may be provided",
+					idx+1, hostname, repositoryHost)
+			}
 		}
 	}
 
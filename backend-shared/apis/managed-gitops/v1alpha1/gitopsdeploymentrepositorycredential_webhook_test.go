@@ -79,4 +79,74 @@ var _ = Describe("GitOpsDeploymentRepositoryCredential validation webhook", func
 		})
 	})
 
+	Context("Create GitOpsDeploymentRepositoryCredential CR with a repository URL that does not match its type", func() {
+		It("Should fail with an error describing the URL that is expected for the type", func() {
+
+			repoCredentialCr.Spec.Type = RepositoryCredentialTypeHelm
+			repoCredentialCr.Spec.Repository = "ssh://git@github.com/my-org/my-charts"
+			err := k8sClient.Create(ctx, repoCredentialCr)
+
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_invalid_helm_repository))
+
+			repoCredentialCr.Spec.Type = RepositoryCredentialTypeOCI
+			repoCredentialCr.Spec.Repository = "https://registry.example.com/charts"
+			err = k8sClient.Create(ctx, repoCredentialCr)
+
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_invalid_oci_repository))
+
+			By("creating an OCI registry credential with a URL without a scheme, which should succeed")
+			repoCredentialCr.Spec.Repository = "registry.example.com/charts"
+			err = k8sClient.Create(ctx, repoCredentialCr)
+			Expect(err).Should(Succeed())
+
+			err = k8sClient.Delete(context.Background(), repoCredentialCr)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("Create GitOpsDeploymentRepositoryCredential CR with SSH known hosts entries", func() {
+		It("Should only accept valid entries for the host of the repository", func() {
+
+			repoCredentialCr.Spec.Repository = "ssh://git@git.example.com/my-org/my-repo"
+
+			repoCredentialCr.Spec.KnownHosts = "git.example.com,other.example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEXYJchXQqL1l3AwO9/hhZ2BMfTNfATULHpmVpHKsJQ3"
+			err := k8sClient.Create(ctx, repoCredentialCr)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring("only entries for the host of the repository"))
+
+			By("creating a credential with an entry for the host of the repository, which should succeed")
+			repoCredentialCr.Spec.KnownHosts = "# comment\ngit.example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEXYJchXQqL1l3AwO9/hhZ2BMfTNfATULHpmVpHKsJQ3\n"
+			err = k8sClient.Create(ctx, repoCredentialCr)
+			Expect(err).Should(Succeed())
+
+			err = k8sClient.Delete(context.Background(), repoCredentialCr)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	DescribeTable("ValidateKnownHosts should reject SSH known hosts entries that may not be used for the repository",
+		func(knownHosts string, repository string, expectedError string) {
+			err := ValidateKnownHosts(knownHosts, repository)
+			if expectedError == "" {
+				Expect(err).ToNot(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(expectedError))
+			}
+		},
+		Entry("entry for the host of the repository", "git.example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEXYJchXQqL1l3AwO9/hhZ2BMfTNfATULHpmVpHKsJQ3", "ssh://git@git.example.com/org/repo", ""),
+		Entry("entry for the host and port of the repository", "[git.example.com]:2222 ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEXYJchXQqL1l3AwO9/hhZ2BMfTNfATULHpmVpHKsJQ3", "ssh://git@git.example.com:2222/org/repo", ""),
+		Entry("entry for the host of an OCI registry", "registry.example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEXYJchXQqL1l3AwO9/hhZ2BMfTNfATULHpmVpHKsJQ3", "registry.example.com/charts", ""),
+		Entry("invalid entry", "git.example.com ssh-ed25519 not-a-key", "ssh://git@git.example.com/org/repo", "invalid SSH known hosts entry on line 1"),
+		Entry("@cert-authority marker", "@cert-authority git.example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEXYJchXQqL1l3AwO9/hhZ2BMfTNfATULHpmVpHKsJQ3", "ssh://git@git.example.com/org/repo", "@cert-authority marker"),
+		Entry("@revoked marker", "@revoked git.example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEXYJchXQqL1l3AwO9/hhZ2BMfTNfATULHpmVpHKsJQ3", "ssh://git@git.example.com/org/repo", "@revoked marker"),
+		Entry("wildcard host pattern", "*.example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEXYJchXQqL1l3AwO9/hhZ2BMfTNfATULHpmVpHKsJQ3", "ssh://git@git.example.com/org/repo", "wildcard, negated, or hashed"),
+		Entry("negated host pattern", "git.example.com,!other.example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEXYJchXQqL1l3AwO9/hhZ2BMfTNfATULHpmVpHKsJQ3", "ssh://git@git.example.com/org/repo", "wildcard, negated, or hashed"),
+		Entry("hashed host", "|1|F1E1KeoE/eEWhi10WpGv4OdiO6Y=|3988QV0VE8wmZL7suNrYQLITLCg= ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEXYJchXQqL1l3AwO9/hhZ2BMfTNfATULHpmVpHKsJQ3", "ssh://git@git.example.com/org/repo", "wildcard, negated, or hashed"),
+		Entry("entry for another host", "git.example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEXYJchXQqL1l3AwO9/hhZ2BMfTNfATULHpmVpHKsJQ3\nother.example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEXYJchXQqL1l3AwO9/hhZ2BMfTNfATULHpmVpHKsJQ3", "ssh://git@git.example.com/org/repo", "line 2 is for host 'other.example.com'"),
+		Entry("repository without a host", "git.example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEXYJchXQqL1l3AwO9/hhZ2BMfTNfATULHpmVpHKsJQ3", "", error_invalid_known_hosts),
+	)
+
 })
@@ -27,15 +27,107 @@ type GitOpsDeploymentSyncRunSpec struct {
 
 	// Optional: If specified, tells the GitOps Service to deploy a particular git commit SHA
 	RevisionID string `json:"revisionID,omitempty"`
+
+	// Optional: If specified, tells the GitOps Service to roll back the GitOpsDeployment to one of its previous deployments,
+	// as listed in the .status.history field of the GitOpsDeployment.
+	// This field cannot be combined with the RevisionID field.
+	Rollback *GitOpsDeploymentSyncRunRollback `json:"rollback,omitempty"`
+
+	// Optional: If true, resources that are no longer defined in the GitOps repository are deleted from the target cluster
+	Prune bool `json:"prune,omitempty"`
+
+	// Optional: If true, the sync operation is performed as a dry run: no changes are made to the target cluster
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// Optional: If true, resources that cannot be updated in place are deleted and recreated
+	Force bool `json:"force,omitempty"`
+
+	// Optional: Describes how the resources are synchronized.
+	// - apply: resources are applied using 'kubectl apply', and sync hooks are skipped
+	// - hook: (default) resources are applied using 'kubectl apply', and sync hooks are run
+	SyncStrategy SyncRunStrategyType `json:"syncStrategy,omitempty"`
+
+	// Optional: If specified, only the listed resources of the GitOpsDeployment are synchronized
+	Resources []SyncRunResource `json:"resources,omitempty"`
+}
+
+type SyncRunStrategyType string
+
+const (
+	SyncRunStrategy_Apply SyncRunStrategyType = "apply"
+	SyncRunStrategy_Hook  SyncRunStrategyType = "hook"
+)
+
+// SyncRunResource identifies a resource of the GitOpsDeployment to synchronize
+type SyncRunResource struct {
+	// Group is the API group of the resource, which is empty for the core API group
+	Group string `json:"group,omitempty"`
+	// Kind is the kind of the resource, for example: ConfigMap
+	Kind string `json:"kind"`
+	// Name is the name of the resource
+	Name string `json:"name"`
+	// Namespace is the namespace of the resource, which is empty for cluster-scoped resources
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// GitOpsDeploymentSyncRunRollback identifies the previous deployment of a GitOpsDeployment to roll back to.
+// Exactly one of HistoryID and Revision must be specified.
+type GitOpsDeploymentSyncRunRollback struct {
+	// HistoryID is the ID of a deployment within the .status.history field of the GitOpsDeployment
+	HistoryID *int64 `json:"historyID,omitempty"`
+
+	// Revision is a previously deployed revision (e.g. a git commit SHA): the GitOpsDeployment is rolled back to the
+	// most recent deployment of this revision within its .status.history field.
+	Revision string `json:"revision,omitempty"`
 }
 
 // GitOpsDeploymentSyncRunStatus defines the observed state of GitOpsDeploymentSyncRun
 type GitOpsDeploymentSyncRunStatus struct {
 	Conditions []GitOpsDeploymentSyncRunCondition `json:"conditions,omitempty"`
+
+	// Phase is the current phase of the sync operation: Pending, Running, Succeeded, Failed or Terminated
+	Phase SyncRunPhase `json:"phase,omitempty"`
+
+	// Message contains any pertinent message from Argo CD on the sync operation (typically errors)
+	Message string `json:"message,omitempty"`
+
+	// StartedAt is the time at which the sync operation started
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// FinishedAt is the time at which the sync operation completed
+	FinishedAt *metav1.Time `json:"finishedAt,omitempty"`
+
+	// Revision is the revision (e.g. the git commit SHA) that was synced
+	Revision string `json:"revision,omitempty"`
+
+	// Resources contains the result of the sync operation for each individual resource
+	Resources ResourceResults `json:"resources,omitempty"`
+}
+
+// SyncRunPhase is the phase of the sync operation of a GitOpsDeploymentSyncRun
+type SyncRunPhase string
+
+const (
+	// SyncRunPhase_Pending indicates the sync operation has not yet been started by the GitOps Service
+	SyncRunPhase_Pending SyncRunPhase = "Pending"
+	// SyncRunPhase_Running indicates the sync operation is in progress
+	SyncRunPhase_Running SyncRunPhase = "Running"
+	// SyncRunPhase_Succeeded indicates the sync operation completed successfully
+	SyncRunPhase_Succeeded SyncRunPhase = "Succeeded"
+	// SyncRunPhase_Failed indicates the sync operation failed
+	SyncRunPhase_Failed SyncRunPhase = "Failed"
+	// SyncRunPhase_Terminated indicates the sync operation was terminated before it completed
+	SyncRunPhase_Terminated SyncRunPhase = "Terminated"
+)
+
+// IsFinished returns true if the sync operation will not progress further from the phase
+func (phase SyncRunPhase) IsFinished() bool {
+	return phase == SyncRunPhase_Succeeded || phase == SyncRunPhase_Failed || phase == SyncRunPhase_Terminated
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
 
 // GitOpsDeploymentSyncRun is the Schema for the gitopsdeploymentsyncruns API
 type GitOpsDeploymentSyncRun struct {
@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	"fmt"
+	"strings"
 
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -29,8 +30,20 @@ import (
 const (
 	error_invalid_name = "name should not be zyxwvutsrqponmlkjihgfedcba-abcdefghijklmnoqrstuvwxyz"
 	invalid_name       = "zyxwvutsrqponmlkjihgfedcba-abcdefghijklmnoqrstuvwxyz"
+
+	error_invalid_rollback              = "spec.rollback field must specify exactly one of historyID and revision"
+	error_invalid_rollback_history_id   = "spec.rollback.historyID field must not be negative"
+	error_rollback_and_revision_id_both = "spec.rollback field cannot be combined with the spec.revisionID field"
+
+	error_invalid_sync_strategy         = "spec.syncStrategy field must be either apply or hook"
+	error_invalid_sync_run_resource     = "spec.resources field must specify the kind and name of each resource"
+	error_invalid_sync_run_resource_ref = "spec.resources field contains an invalid character: ',', ':' and '/' are not supported"
+	error_rollback_and_sync_parameters  = "spec.rollback field cannot be combined with the spec.force, spec.syncStrategy or spec.resources fields"
 )
 
+// syncRunResourceBlockedCharacters are the characters which may not appear in the fields of the resources of a GitOpsDeploymentSyncRun
+const syncRunResourceBlockedCharacters = ",:/"
+
 // log is for logging in this package.
 var gitopsdeploymentsyncrunlog = logf.Log.WithName(logutil.LogLogger_managed_gitops)
 
@@ -71,6 +84,16 @@ func (r *GitOpsDeploymentSyncRun) ValidateCreate() error {
 		return err
 	}
 
+	if err := ValidateSyncRunRollback(r.Spec); err != nil {
+		log.Info("webhook rejected invalid create", "error", fmt.Sprintf("%v", err))
+		return err
+	}
+
+	if err := ValidateSyncRunSyncParameters(r.Spec); err != nil {
+		log.Info("webhook rejected invalid create", "error", fmt.Sprintf("%v", err))
+		return err
+	}
+
 	return nil
 }
 
@@ -81,6 +104,16 @@ func (r *GitOpsDeploymentSyncRun) ValidateUpdate(old runtime.Object) error {
 
 	log.V(logutil.LogLevel_Debug).Info("validate update")
 
+	if err := ValidateSyncRunRollback(r.Spec); err != nil {
+		log.Info("webhook rejected invalid update", "error", fmt.Sprintf("%v", err))
+		return err
+	}
+
+	if err := ValidateSyncRunSyncParameters(r.Spec); err != nil {
+		log.Info("webhook rejected invalid update", "error", fmt.Sprintf("%v", err))
+		return err
+	}
+
 	return nil
 }
 
@@ -93,3 +126,54 @@ func (r *GitOpsDeploymentSyncRun) ValidateDelete() error {
 
 	return nil
 }
+
+// ValidateSyncRunRollback validates the rollback field of a GitOpsDeploymentSyncRun: exactly one of historyID and revision
+// must be specified, and the field cannot be combined with the revisionID field.
+func ValidateSyncRunRollback(spec GitOpsDeploymentSyncRunSpec) error {
+
+	if spec.Rollback == nil {
+		return nil
+	}
+
+	if spec.RevisionID != "" {
+		return fmt.Errorf(error_rollback_and_revision_id_both)
+	}
+
+	if (spec.Rollback.HistoryID == nil) == (spec.Rollback.Revision == "") {
+		return fmt.Errorf(error_invalid_rollback)
+	}
+
+	if spec.Rollback.HistoryID != nil && *spec.Rollback.HistoryID < 0 {
+		return fmt.Errorf(error_invalid_rollback_history_id)
+	}
+
+	return nil
+}
+
+// ValidateSyncRunSyncParameters validates the fields of a GitOpsDeploymentSyncRun that describe how the sync operation is performed:
+// the sync strategy, and the resources to sync. Of these, a rollback only supports the prune and dryRun fields.
+func ValidateSyncRunSyncParameters(spec GitOpsDeploymentSyncRunSpec) error {
+
+	if spec.SyncStrategy != "" && spec.SyncStrategy != SyncRunStrategy_Apply && spec.SyncStrategy != SyncRunStrategy_Hook {
+		return fmt.Errorf(error_invalid_sync_strategy)
+	}
+
+	for _, resource := range spec.Resources {
+
+		if resource.Kind == "" || resource.Name == "" {
+			return fmt.Errorf(error_invalid_sync_run_resource)
+		}
+
+		for _, field := range []string{resource.Group, resource.Kind, resource.Name, resource.Namespace} {
+			if strings.ContainsAny(field, syncRunResourceBlockedCharacters) {
+				return fmt.Errorf(error_invalid_sync_run_resource_ref)
+			}
+		}
+	}
+
+	if spec.Rollback != nil && (spec.Force || spec.SyncStrategy != "" || len(spec.Resources) > 0) {
+		return fmt.Errorf(error_rollback_and_sync_parameters)
+	}
+
+	return nil
+}
@@ -55,4 +55,119 @@ var _ = Describe("GitOpsDeploymentSyncRun validation webhook", func() {
 		})
 	})
 
+	Context("Create GitOpsDeploymentSyncRun CR with both .spec.rollback and .spec.revisionID fields", func() {
+		It("Should fail with error saying the rollback field cannot be combined with the revisionID field", func() {
+
+			gitopsDeplSyncRunCr.Name = "rollback-and-revision"
+			historyID := int64(1)
+			gitopsDeplSyncRunCr.Spec.Rollback = &GitOpsDeploymentSyncRunRollback{
+				HistoryID: &historyID,
+			}
+
+			err := k8sClient.Create(ctx, gitopsDeplSyncRunCr)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_rollback_and_revision_id_both))
+
+		})
+	})
+
+	Context("Create GitOpsDeploymentSyncRun CR with an invalid .spec.rollback field", func() {
+		It("Should fail with error saying exactly one of historyID and revision must be specified", func() {
+
+			gitopsDeplSyncRunCr.Name = "rollback-empty"
+			gitopsDeplSyncRunCr.Spec.RevisionID = ""
+			gitopsDeplSyncRunCr.Spec.Rollback = &GitOpsDeploymentSyncRunRollback{}
+
+			err := k8sClient.Create(ctx, gitopsDeplSyncRunCr)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_invalid_rollback))
+
+			historyID := int64(1)
+			gitopsDeplSyncRunCr.Spec.Rollback = &GitOpsDeploymentSyncRunRollback{
+				HistoryID: &historyID,
+				Revision:  "HEAD",
+			}
+
+			err = k8sClient.Create(ctx, gitopsDeplSyncRunCr)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_invalid_rollback))
+
+		})
+
+		It("Should fail with error saying the historyID must not be negative", func() {
+
+			gitopsDeplSyncRunCr.Name = "rollback-negative-id"
+			gitopsDeplSyncRunCr.Spec.RevisionID = ""
+			historyID := int64(-1)
+			gitopsDeplSyncRunCr.Spec.Rollback = &GitOpsDeploymentSyncRunRollback{
+				HistoryID: &historyID,
+			}
+
+			err := k8sClient.Create(ctx, gitopsDeplSyncRunCr)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_invalid_rollback_history_id))
+
+		})
+	})
+
+	Context("Create GitOpsDeploymentSyncRun CR with an invalid .spec.syncStrategy field", func() {
+		It("Should fail with error saying the sync strategy must be either apply or hook", func() {
+
+			gitopsDeplSyncRunCr.Name = "invalid-sync-strategy"
+			gitopsDeplSyncRunCr.Spec.SyncStrategy = "replace"
+
+			err := k8sClient.Create(ctx, gitopsDeplSyncRunCr)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_invalid_sync_strategy))
+
+		})
+	})
+
+	Context("Create GitOpsDeploymentSyncRun CR with an invalid .spec.resources field", func() {
+		It("Should fail with error saying the kind and name of each resource must be specified", func() {
+
+			gitopsDeplSyncRunCr.Name = "invalid-sync-run-resource"
+			gitopsDeplSyncRunCr.Spec.Resources = []SyncRunResource{
+				{Kind: "ConfigMap"},
+			}
+
+			err := k8sClient.Create(ctx, gitopsDeplSyncRunCr)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_invalid_sync_run_resource))
+
+		})
+
+		It("Should fail with error saying the resource contains an invalid character", func() {
+
+			gitopsDeplSyncRunCr.Name = "invalid-sync-run-resource-ref"
+			gitopsDeplSyncRunCr.Spec.Resources = []SyncRunResource{
+				{Kind: "ConfigMap", Name: "my-config-map", Namespace: "jane/test"},
+			}
+
+			err := k8sClient.Create(ctx, gitopsDeplSyncRunCr)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_invalid_sync_run_resource_ref))
+
+		})
+	})
+
+	Context("Create GitOpsDeploymentSyncRun CR with both .spec.rollback and .spec.resources fields", func() {
+		It("Should fail with error saying the rollback field cannot be combined with the resources field", func() {
+
+			gitopsDeplSyncRunCr.Name = "rollback-and-resources"
+			gitopsDeplSyncRunCr.Spec.RevisionID = ""
+			gitopsDeplSyncRunCr.Spec.Rollback = &GitOpsDeploymentSyncRunRollback{
+				Revision: "HEAD",
+			}
+			gitopsDeplSyncRunCr.Spec.Resources = []SyncRunResource{
+				{Kind: "ConfigMap", Name: "my-config-map"},
+			}
+
+			err := k8sClient.Create(ctx, gitopsDeplSyncRunCr)
+			Expect(err).Should(Not(Succeed()))
+			Expect(err.Error()).Should(ContainSubstring(error_rollback_and_sync_parameters))
+
+		})
+	})
+
 })
@@ -22,7 +22,7 @@ func (dbq *PostgreSQLDatabaseQueries) UnsafeListAllClusterCredentials(ctx contex
 		return err
 	}
 
-	return nil
+	return decryptClusterCredentialsColumns(*clusterCredentials)
 }
 
 func (dbq *PostgreSQLDatabaseQueries) CreateClusterCredentials(ctx context.Context, obj *ClusterCredentials) error {
@@ -50,6 +50,12 @@ func (dbq *PostgreSQLDatabaseQueries) CreateClusterCredentials(ctx context.Conte
 		return err
 	}
 
+	restoreColumns, err := obj.encryptColumns()
+	if err != nil {
+		return fmt.Errorf("error on encrypting cluster credentials: %v", err)
+	}
+	defer restoreColumns()
+
 	result, err := dbq.dbConnection.Model(obj).Context(ctx).Insert()
 	if err != nil {
 		return fmt.Errorf("error on inserting cluster credentials: %v", err)
@@ -86,7 +92,7 @@ func (dbq *PostgreSQLDatabaseQueries) GetClusterCredentialsById(ctx context.Cont
 
 	*clusterCreds = dbResults[0]
 
-	return nil
+	return clusterCreds.decryptColumns()
 }
 
 func (dbq *PostgreSQLDatabaseQueries) CheckedGetClusterCredentialsById(ctx context.Context, clusterCredentials *ClusterCredentials, ownerId string) error {
@@ -131,7 +137,7 @@ func (dbq *PostgreSQLDatabaseQueries) CheckedGetClusterCredentialsById(ctx conte
 
 	*clusterCredentials = dbResults[0]
 
-	return nil
+	return clusterCredentials.decryptColumns()
 
 }
 
@@ -178,20 +184,24 @@ func (dbq *PostgreSQLDatabaseQueries) CheckedListClusterCredentialsByHost(ctx co
 
 	*clusterCredentials = matchingClusterCreds
 
-	return nil
+	return decryptClusterCredentialsColumns(*clusterCredentials)
 
 }
 
 // Get ClusterCredentials in a batch. Batch size defined by 'limit' and starting point of batch is defined by 'offSet'.
 // For example if you want ClusterCredentials starting from 51-150 then set the limit to 100 and offset to 50.
 func (dbq *PostgreSQLDatabaseQueries) GetClusterCredentialsBatch(ctx context.Context, clusterCredentials *[]ClusterCredentials, limit, offSet int) error {
-	return dbq.dbConnection.
+	if err := dbq.dbConnection.
 		Model(clusterCredentials).
 		Order("seq_id ASC").
 		Limit(limit).   // Batch size
 		Offset(offSet). // offset+1 is starting point of batch
 		Context(ctx).
-		Select()
+		Select(); err != nil {
+		return err
+	}
+
+	return decryptClusterCredentialsColumns(*clusterCredentials)
 }
 
 // A user should only be able to get cluster credentials if:
@@ -261,6 +271,40 @@ func (dbq *PostgreSQLDatabaseQueries) isAccessibleByUser(ctx context.Context, cl
 
 }
 
+func (dbq *PostgreSQLDatabaseQueries) UpdateClusterCredentials(ctx context.Context, obj *ClusterCredentials) error {
+
+	if err := validateQueryParamsEntity(obj, dbq); err != nil {
+		return err
+	}
+
+	if err := isEmptyValues("UpdateClusterCredentials",
+		"clustercredentials_cred_id", obj.Clustercredentials_cred_id,
+		"host", obj.Host); err != nil {
+		return err
+	}
+
+	if err := validateFieldLength(obj); err != nil {
+		return err
+	}
+
+	restoreColumns, err := obj.encryptColumns()
+	if err != nil {
+		return fmt.Errorf("error on encrypting cluster credentials: %v, %v", err, obj.Clustercredentials_cred_id)
+	}
+	defer restoreColumns()
+
+	result, err := dbq.dbConnection.Model(obj).WherePK().Context(ctx).Update()
+	if err != nil {
+		return fmt.Errorf("error on updating cluster credentials: %v, %v", err, obj.Clustercredentials_cred_id)
+	}
+
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("unexpected number of rows affected: %d, %v", result.RowsAffected(), obj.Clustercredentials_cred_id)
+	}
+
+	return nil
+}
+
 func (dbq *PostgreSQLDatabaseQueries) DeleteClusterCredentialsById(ctx context.Context, id string) (int, error) {
 
 	if dbq.dbConnection == nil {
@@ -299,9 +343,48 @@ func (obj *ClusterCredentials) GetAsLogKeyValues() []interface{} {
 		return []interface{}{}
 	}
 
-	// We avoid logging the bearer_token or kube_config, as these contain sensitive user data.
+	// We avoid logging the bearer_token, kube_config or client key, as these contain sensitive user data.
 	return []interface{}{"host", obj.Host, "kube-config-length", len(obj.Kube_config),
 		"kube-config-context", len(obj.Kube_config_context), "serviceaccount_ns", obj.Serviceaccount_ns,
 		"serviceaccount-bearer-token-length", len(obj.Serviceaccount_bearer_token), "cluster_resources", obj.ClusterResources,
-		"cluster_namespaces", obj.Namespaces}
+		"cluster_namespaces", obj.Namespaces, "ca-data-length", len(obj.Ca_data), "cert-data-length", len(obj.Cert_data),
+		"exec-provider-config-length", len(obj.Exec_provider_config)}
+}
+
+// encryptColumns encrypts the sensitive columns (the client certificate key) of the ClusterCredentials, before they are
+// written to the database. The returned function restores the unencrypted values.
+func (obj *ClusterCredentials) encryptColumns() (func(), error) {
+
+	keyData := obj.Key_data
+	restore := func() {
+		obj.Key_data = keyData
+	}
+
+	var err error
+	if obj.Key_data, err = encryptColumnWithMaxLength("Key_data", keyData, ClusterCredentialsKeyDataLength); err != nil {
+		restore()
+		return nil, err
+	}
+
+	return restore, nil
+}
+
+// decryptColumns decrypts the sensitive columns of the ClusterCredentials, after they are read from the database.
+func (obj *ClusterCredentials) decryptColumns() error {
+
+	var err error
+	if obj.Key_data, err = decryptColumn(obj.Key_data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func decryptClusterCredentialsColumns(clusterCredentials []ClusterCredentials) error {
+	for idx := range clusterCredentials {
+		if err := clusterCredentials[idx].decryptColumns(); err != nil {
+			return fmt.Errorf("unable to decrypt cluster credentials %s: %v", clusterCredentials[idx].Clustercredentials_cred_id, err)
+		}
+	}
+	return nil
 }
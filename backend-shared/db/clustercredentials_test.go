@@ -2,6 +2,8 @@ package db_test
 
 import (
 	"context"
+	"encoding/base64"
+	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -107,6 +109,75 @@ var _ = Describe("ClusterCredentials Tests", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(listOfClusterCredFromDB).To(HaveLen(3))
 		})
+
+		It("Should update the ServiceAccount bearer token and expiration of ClusterCredentials", func() {
+
+			fetchedCluster := db.ClusterCredentials{
+				Clustercredentials_cred_id: clusterCreds.Clustercredentials_cred_id,
+			}
+			err := dbq.GetClusterCredentialsById(ctx, &fetchedCluster)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fetchedCluster.Serviceaccount_token_expiration.IsZero()).To(BeTrue())
+
+			expiration := time.Now().Add(time.Hour)
+			fetchedCluster.Serviceaccount_bearer_token = "test-renewed-serviceaccount_bearer_token"
+			fetchedCluster.Serviceaccount_token_expiration = expiration
+
+			err = dbq.UpdateClusterCredentials(ctx, &fetchedCluster)
+			Expect(err).ToNot(HaveOccurred())
+
+			updatedCluster := db.ClusterCredentials{
+				Clustercredentials_cred_id: clusterCreds.Clustercredentials_cred_id,
+			}
+			err = dbq.GetClusterCredentialsById(ctx, &updatedCluster)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(updatedCluster.Serviceaccount_bearer_token).To(Equal("test-renewed-serviceaccount_bearer_token"))
+			Expect(updatedCluster.Serviceaccount_token_expiration).To(BeTemporally("~", expiration, time.Second))
+			Expect(updatedCluster.Host).To(Equal(clusterCreds.Host))
+
+			By("verifying that an update to a row that doesn't exist returns an error")
+			nonExistentCluster := updatedCluster
+			nonExistentCluster.Clustercredentials_cred_id = "test-" + uuid.NewString()
+			err = dbq.UpdateClusterCredentials(ctx, &nonExistentCluster)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should encrypt the client certificate key of ClusterCredentials in the database", func() {
+
+			os.Setenv(db.ColumnEncryptionKeyEnvVar, base64.StdEncoding.EncodeToString(make([]byte, 32)))
+			DeferCleanup(os.Unsetenv, db.ColumnEncryptionKeyEnvVar)
+
+			clusterCredsWithKey := db.ClusterCredentials{
+				Host:              "test-host",
+				Serviceaccount_ns: "test-serviceaccount_ns",
+				Cert_data:         "test-cert-data",
+				Key_data:          "test-key-data",
+			}
+			err := dbq.CreateClusterCredentials(ctx, &clusterCredsWithKey)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(clusterCredsWithKey.Key_data).To(Equal("test-key-data"), "the unencrypted value should be restored")
+
+			fetchedCluster := db.ClusterCredentials{
+				Clustercredentials_cred_id: clusterCredsWithKey.Clustercredentials_cred_id,
+			}
+			err = dbq.GetClusterCredentialsById(ctx, &fetchedCluster)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fetchedCluster.Cert_data).To(Equal("test-cert-data"))
+			Expect(fetchedCluster.Key_data).To(Equal("test-key-data"))
+
+			By("verifying that the value cannot be read without the key, as it is encrypted")
+			os.Unsetenv(db.ColumnEncryptionKeyEnvVar)
+			err = dbq.GetClusterCredentialsById(ctx, &fetchedCluster)
+			Expect(err).To(HaveOccurred())
+
+			By("verifying that the value cannot be written without the key")
+			err = dbq.UpdateClusterCredentials(ctx, &clusterCredsWithKey)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(db.ColumnEncryptionKeyEnvVar))
+
+			_, err = dbq.DeleteClusterCredentialsById(ctx, clusterCredsWithKey.Clustercredentials_cred_id)
+			Expect(err).ToNot(HaveOccurred())
+		})
 	})
 
 	Context("Test Dispose function for clusterCredentials", func() {
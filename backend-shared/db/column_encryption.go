@@ -0,0 +1,115 @@
+package db
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// ColumnEncryptionKeyEnvVar is the environment variable that contains the (base64 encoded, 32 byte) AES-256 key that
+	// is used to encrypt the sensitive columns of the database. It must be the same for all the components of GitOps
+	// Service that read or write those columns.
+	ColumnEncryptionKeyEnvVar = "DB_COLUMN_ENCRYPTION_KEY"
+
+	// encryptedColumnPrefix is the prefix of the values of encrypted columns. Values without it were stored before the
+	// column was encrypted, and are returned as is.
+	encryptedColumnPrefix = "enc:v1:"
+)
+
+// ValidateColumnEncryptionKey returns an error if the column encryption key is not set, or is not valid. Components that
+// read or write encrypted columns call it at startup, rather than failing when an encrypted column is first used.
+func ValidateColumnEncryptionKey() error {
+	_, err := columnEncryptionAEAD()
+	return err
+}
+
+// columnEncryptionAEAD returns the AES-GCM cipher of the column encryption key.
+func columnEncryptionAEAD() (cipher.AEAD, error) {
+
+	encodedKey := strings.TrimSpace(os.Getenv(ColumnEncryptionKeyEnvVar))
+	if encodedKey == "" {
+		return nil, fmt.Errorf("%s must be set, in order to encrypt or decrypt sensitive database columns", ColumnEncryptionKeyEnvVar)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not base64 encoded: %v", ColumnEncryptionKeyEnvVar, err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("%s must be a 32 byte key, but is %d bytes", ColumnEncryptionKeyEnvVar, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// encryptColumn returns the encrypted value of a sensitive column. An empty value is not encrypted.
+func encryptColumn(value string) (string, error) {
+
+	if value == "" {
+		return "", nil
+	}
+
+	aead, err := columnEncryptionAEAD()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("unable to generate nonce: %v", err)
+	}
+
+	return encryptedColumnPrefix + base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(value), nil)), nil
+}
+
+// encryptColumnWithMaxLength returns the encrypted value of a sensitive column, or an error if the encrypted value exceeds
+// the maximum length of the column. The encrypted value is base64 encoded, and so is about 4/3 the length of the value:
+// the length constants of encrypted columns (in db_field_constants.go) are thus sized for the encrypted value.
+func encryptColumnWithMaxLength(fieldName string, value string, maxLength int) (string, error) {
+
+	encrypted, err := encryptColumn(value)
+	if err != nil {
+		return "", err
+	}
+
+	if len(encrypted) > maxLength {
+		return "", fmt.Errorf("%v value exceeds maximum size: max: %d, actual: %d (encrypted)", fieldName, maxLength, len(encrypted))
+	}
+
+	return encrypted, nil
+}
+
+// decryptColumn returns the decrypted value of a sensitive column.
+func decryptColumn(value string) (string, error) {
+
+	if !strings.HasPrefix(value, encryptedColumnPrefix) {
+		return value, nil
+	}
+
+	aead, err := columnEncryptionAEAD()
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedColumnPrefix))
+	if err != nil || len(ciphertext) < aead.NonceSize() {
+		return "", fmt.Errorf("encrypted column is not valid")
+	}
+
+	plaintext, err := aead.Open(nil, ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("unable to decrypt column: %v", err)
+	}
+
+	return string(plaintext), nil
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"encoding/base64"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumnEncryption(t *testing.T) {
+
+	t.Setenv(ColumnEncryptionKeyEnvVar, base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32))))
+
+	encrypted, err := encryptColumn("test-client-key")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encrypted, encryptedColumnPrefix))
+	assert.NotContains(t, encrypted, "test-client-key")
+
+	decrypted, err := decryptColumn(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "test-client-key", decrypted)
+
+	// Empty values, and values stored before the column was encrypted, are not modified
+	encrypted, err = encryptColumn("")
+	assert.NoError(t, err)
+	assert.Empty(t, encrypted)
+
+	decrypted, err = decryptColumn("unencrypted-value")
+	assert.NoError(t, err)
+	assert.Equal(t, "unencrypted-value", decrypted)
+
+	// Values that were not encrypted with the key cannot be decrypted
+	os.Setenv(ColumnEncryptionKeyEnvVar, base64.StdEncoding.EncodeToString([]byte(strings.Repeat("x", 32))))
+	encryptedWithOtherKey, err := encryptColumn("test-client-key")
+	assert.NoError(t, err)
+
+	os.Setenv(ColumnEncryptionKeyEnvVar, base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32))))
+	_, err = decryptColumn(encryptedWithOtherKey)
+	assert.Error(t, err)
+}
+
+func TestColumnEncryptionKey(t *testing.T) {
+
+	for _, c := range []struct {
+		name string
+		key  string
+	}{
+		{name: "no key", key: ""},
+		{name: "key is not base64 encoded", key: "not base64!"},
+		{name: "key is not 32 bytes", key: base64.StdEncoding.EncodeToString([]byte("short"))},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			t.Setenv(ColumnEncryptionKeyEnvVar, c.key)
+
+			_, err := encryptColumn("test-client-key")
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), ColumnEncryptionKeyEnvVar)
+
+			err = ValidateColumnEncryptionKey()
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), ColumnEncryptionKeyEnvVar)
+		})
+	}
+
+	t.Setenv(ColumnEncryptionKeyEnvVar, base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32))))
+	assert.NoError(t, ValidateColumnEncryptionKey())
+}
+
+func TestColumnEncryptionMaxLength(t *testing.T) {
+
+	t.Setenv(ColumnEncryptionKeyEnvVar, base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32))))
+
+	// A value that fits in the column unencrypted, but not once it is encrypted
+	value := strings.Repeat("a", RepositoryCredentialsRepoCredTlsClientCertKeyLength-1024)
+
+	_, err := encryptColumnWithMaxLength("TLSClientCertKey", value, RepositoryCredentialsRepoCredTlsClientCertKeyLength)
+	assert.Error(t, err)
+	assert.True(t, IsMaxLengthError(err))
+
+	// A PEM encoded certificate chain of a realistic size fits in the column once it is encrypted
+	value = strings.Repeat("a", 8192)
+
+	encrypted, err := encryptColumnWithMaxLength("TLSClientCertKey", value, RepositoryCredentialsRepoCredTlsClientCertKeyLength)
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, len(encrypted), RepositoryCredentialsRepoCredTlsClientCertKeyLength)
+}
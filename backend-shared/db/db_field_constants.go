@@ -13,7 +13,16 @@ const (
 	ClusterCredentialsKubeConfigContextLength                               = 64
 	ClusterCredentialsServiceaccountBearerTokenLength                       = 2048
 	ClusterCredentialsServiceaccountNsLength                                = 128
-	ClusterCredentialsNamespacesLength                                      = 4096
+	ClusterCredentialsNamespacesLength                                      = 65536
+	ClusterCredentialsCaDataLength                                          = 16384
+	ClusterCredentialsCertDataLength                                        = 16384
+	ClusterCredentialsKeyDataLength                                         = 16384
+	ClusterCredentialsExecProviderConfigLength                              = 4096
+	ClusterCredentialsServiceaccountRulesLength                             = 16384
+	ClusterCredentialsServiceaccountInstallationUIDLength                   = 48
+	ClusterCredentialsProxyURLLength                                        = 512
+	ClusterCredentialsClusterLabelsLength                                   = 16384
+	ClusterCredentialsClusterAnnotationsLength                              = 16384
 	GitopsEngineClusterGitopsengineclusterIDLength                          = 48
 	GitopsEngineInstanceGitopsengineinstanceIDLength                        = 48
 	GitopsEngineInstanceNamespaceNameLength                                 = 48
@@ -63,12 +72,23 @@ const (
 	SyncOperationDeploymentNameLength                                       = 256
 	SyncOperationRevisionLength                                             = 256
 	SyncOperationDesiredStateLength                                         = 16
+	SyncOperationSyncStrategyLength                                         = 16
+	SyncOperationResourcesLength                                            = 4096
 	RepositoryCredentialsRepositorycredentialsIDLength                      = 48
 	RepositoryCredentialsRepoCredUserIDLength                               = 48
 	RepositoryCredentialsRepoCredURLLength                                  = 512
 	RepositoryCredentialsRepoCredUserLength                                 = 256
 	RepositoryCredentialsRepoCredPassLength                                 = 1024
 	RepositoryCredentialsRepoCredSshLength                                  = 1024
+	RepositoryCredentialsRepoCredGithubAppIDLength                          = 64
+	RepositoryCredentialsRepoCredGithubAppInstallationIDLength              = 64
+	RepositoryCredentialsRepoCredGithubAppPrivateKeyLength                  = 8192
+	RepositoryCredentialsRepoCredGithubAppEnterpriseBaseURLLength           = 512
+	RepositoryCredentialsRepoCredTypeLength                                 = 16
+	RepositoryCredentialsRepoCredTlsClientCertDataLength                    = 16384
+	RepositoryCredentialsRepoCredTlsClientCertKeyLength                     = 16384
+	RepositoryCredentialsRepoCredTlsCaCertDataLength                        = 16384
+	RepositoryCredentialsRepoCredKnownHostsLength                           = 16384
 	RepositoryCredentialsRepoCredSecretLength                               = 48
 	RepositoryCredentialsRepoCredEngineIDLength                             = 48
 	AppProjectRepositoryAppprojectRepositoryIDLength                        = 48
@@ -118,6 +138,15 @@ var DbFieldMap = map[string]int{
 	"ClusterCredentialsServiceaccountBearerTokenLength":                       ClusterCredentialsServiceaccountBearerTokenLength,
 	"ClusterCredentialsServiceaccountNsLength":                                ClusterCredentialsServiceaccountNsLength,
 	"ClusterCredentialsNamespacesLength":                                      ClusterCredentialsNamespacesLength,
+	"ClusterCredentialsCaDataLength":                                          ClusterCredentialsCaDataLength,
+	"ClusterCredentialsCertDataLength":                                        ClusterCredentialsCertDataLength,
+	"ClusterCredentialsKeyDataLength":                                         ClusterCredentialsKeyDataLength,
+	"ClusterCredentialsExecProviderConfigLength":                              ClusterCredentialsExecProviderConfigLength,
+	"ClusterCredentialsServiceaccountRulesLength":                             ClusterCredentialsServiceaccountRulesLength,
+	"ClusterCredentialsServiceaccountInstallationUIDLength":                   ClusterCredentialsServiceaccountInstallationUIDLength,
+	"ClusterCredentialsProxyURLLength":                                        ClusterCredentialsProxyURLLength,
+	"ClusterCredentialsClusterLabelsLength":                                   ClusterCredentialsClusterLabelsLength,
+	"ClusterCredentialsClusterAnnotationsLength":                              ClusterCredentialsClusterAnnotationsLength,
 	"GitopsEngineClusterGitopsengineclusterIDLength":                          GitopsEngineClusterGitopsengineclusterIDLength,
 	"GitopsEngineInstanceGitopsengineinstanceIDLength":                        GitopsEngineInstanceGitopsengineinstanceIDLength,
 	"GitopsEngineInstanceNamespaceNameLength":                                 GitopsEngineInstanceNamespaceNameLength,
@@ -182,12 +211,23 @@ var DbFieldMap = map[string]int{
 	"SyncOperationDeploymentNameFieldLength":                                  SyncOperationDeploymentNameLength,
 	"SyncOperationRevisionLength":                                             SyncOperationRevisionLength,
 	"SyncOperationDesiredStateLength":                                         SyncOperationDesiredStateLength,
+	"SyncOperationSyncStrategyLength":                                         SyncOperationSyncStrategyLength,
+	"SyncOperationResourcesLength":                                            SyncOperationResourcesLength,
 	"RepositoryCredentialsRepositorycredentialsIDLength":                      RepositoryCredentialsRepositorycredentialsIDLength,
 	"RepositoryCredentialsRepoCredUserIDLength":                               RepositoryCredentialsRepoCredUserIDLength,
 	"RepositoryCredentialsRepoCredURLLength":                                  RepositoryCredentialsRepoCredURLLength,
 	"RepositoryCredentialsRepoCredUserLength":                                 RepositoryCredentialsRepoCredUserLength,
 	"RepositoryCredentialsRepoCredPassLength":                                 RepositoryCredentialsRepoCredPassLength,
 	"RepositoryCredentialsRepoCredSshLength":                                  RepositoryCredentialsRepoCredSshLength,
+	"RepositoryCredentialsRepoCredGithubAppIDLength":                          RepositoryCredentialsRepoCredGithubAppIDLength,
+	"RepositoryCredentialsRepoCredGithubAppInstallationIDLength":              RepositoryCredentialsRepoCredGithubAppInstallationIDLength,
+	"RepositoryCredentialsRepoCredGithubAppPrivateKeyLength":                  RepositoryCredentialsRepoCredGithubAppPrivateKeyLength,
+	"RepositoryCredentialsRepoCredGithubAppEnterpriseBaseURLLength":           RepositoryCredentialsRepoCredGithubAppEnterpriseBaseURLLength,
+	"RepositoryCredentialsRepoCredTypeLength":                                 RepositoryCredentialsRepoCredTypeLength,
+	"RepositoryCredentialsRepoCredTlsClientCertDataLength":                    RepositoryCredentialsRepoCredTlsClientCertDataLength,
+	"RepositoryCredentialsRepoCredTlsClientCertKeyLength":                     RepositoryCredentialsRepoCredTlsClientCertKeyLength,
+	"RepositoryCredentialsRepoCredTlsCaCertDataLength":                        RepositoryCredentialsRepoCredTlsCaCertDataLength,
+	"RepositoryCredentialsRepoCredKnownHostsLength":                           RepositoryCredentialsRepoCredKnownHostsLength,
 	"RepositoryCredentialsRepoCredSecretLength":                               RepositoryCredentialsRepoCredSecretLength,
 	"RepositoryCredentialsRepoCredEngineIDLength":                             RepositoryCredentialsRepoCredEngineIDLength,
 	"AppProjectRepositoryAppprojectRepositoryIDLength":                        AppProjectRepositoryAppprojectRepositoryIDLength,
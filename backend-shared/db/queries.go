@@ -155,6 +155,10 @@ type DatabaseQueries interface {
 	GetDeploymentToApplicationMappingBatch(ctx context.Context, deploymentToApplicationMappings *[]DeploymentToApplicationMapping, limit, offSet int) error
 
 	UpdateManagedEnvironment(ctx context.Context, obj *ManagedEnvironment) error
+
+	// UpdateClusterCredentials updates the ClusterCredentials row, for example, when the ServiceAccount bearer token is renewed
+	UpdateClusterCredentials(ctx context.Context, obj *ClusterCredentials) error
+
 	DeleteGitopsEngineInstanceById(ctx context.Context, id string) (int, error)
 
 	// Delete ManagedEnvironment row by ID
@@ -34,6 +34,12 @@ func (dbq *PostgreSQLDatabaseQueries) CreateRepositoryCredentials(ctx context.Co
 
 	obj.Created_on = time.Now()
 
+	restoreColumns, err := obj.encryptColumns()
+	if err != nil {
+		return fmt.Errorf("%v: %w", errCreateRepositoryCredentials, err)
+	}
+	defer restoreColumns()
+
 	result, err := dbq.dbConnection.Model(obj).Context(ctx).Insert()
 	if err != nil {
 		return fmt.Errorf("%v: %w", errCreateRepositoryCredentials, err)
@@ -75,6 +81,10 @@ func (dbq *PostgreSQLDatabaseQueries) GetRepositoryCredentialsByID(ctx context.C
 		return obj, fmt.Errorf("%v: %w", errGetRepositoryCredentials, err)
 	}
 
+	if err := obj.decryptColumns(); err != nil {
+		return obj, fmt.Errorf("%v: %w", errGetRepositoryCredentials, err)
+	}
+
 	return obj, nil
 }
 
@@ -86,6 +96,12 @@ func (dbq *PostgreSQLDatabaseQueries) UpdateRepositoryCredentials(ctx context.Co
 		return err
 	}
 
+	restoreColumns, err := obj.encryptColumns()
+	if err != nil {
+		return fmt.Errorf("%v: %w", errUpdateRepositoryCredentials, err)
+	}
+	defer restoreColumns()
+
 	result, err := dbq.dbConnection.Model(obj).WherePK().Context(ctx).Update()
 	if err != nil {
 		return fmt.Errorf("%v: %w", errUpdateRepositoryCredentials, err)
@@ -110,7 +126,7 @@ func (dbq *PostgreSQLDatabaseQueries) UnsafeListAllRepositoryCredentials(ctx con
 		return err
 	}
 
-	return nil
+	return decryptRepositoryCredentialsColumns(*repositoryCredentials)
 }
 
 func (obj *RepositoryCredentials) Dispose(ctx context.Context, dbq DatabaseQueries) error {
@@ -125,11 +141,71 @@ func (obj *RepositoryCredentials) Dispose(ctx context.Context, dbq DatabaseQueri
 // Get RepositoryCredentials in a batch. Batch size defined by 'limit' and starting point of batch is defined by 'offSet'.
 // For example if you want RepositoryCredentials starting from 51-150 then set the limit to 100 and offset to 50.
 func (dbq *PostgreSQLDatabaseQueries) GetRepositoryCredentialsBatch(ctx context.Context, repositoryCredentials *[]RepositoryCredentials, limit, offSet int) error {
-	return dbq.dbConnection.
+	err := dbq.dbConnection.
 		Model(repositoryCredentials).
 		Order("seq_id ASC").
 		Limit(limit).   // Batch size
 		Offset(offSet). // offset+1 is starting point of batch
 		Context(ctx).
 		Select()
+	if err != nil {
+		return err
+	}
+
+	return decryptRepositoryCredentialsColumns(*repositoryCredentials)
+}
+
+// encryptColumns encrypts the sensitive columns (the TLS client certificate and key, and the GitHub App private key) of
+// the RepositoryCredentials, before they are written to the database. The returned function restores the unencrypted values.
+func (obj *RepositoryCredentials) encryptColumns() (func(), error) {
+
+	tlsClientCertData, tlsClientCertKey, githubAppPrivateKey := obj.TLSClientCertData, obj.TLSClientCertKey, obj.GithubAppPrivateKey
+	restore := func() {
+		obj.TLSClientCertData, obj.TLSClientCertKey, obj.GithubAppPrivateKey = tlsClientCertData, tlsClientCertKey, githubAppPrivateKey
+	}
+
+	var err error
+	if obj.TLSClientCertData, err = encryptColumnWithMaxLength("TLSClientCertData", tlsClientCertData,
+		RepositoryCredentialsRepoCredTlsClientCertDataLength); err != nil {
+		restore()
+		return nil, err
+	}
+	if obj.TLSClientCertKey, err = encryptColumnWithMaxLength("TLSClientCertKey", tlsClientCertKey,
+		RepositoryCredentialsRepoCredTlsClientCertKeyLength); err != nil {
+		restore()
+		return nil, err
+	}
+	if obj.GithubAppPrivateKey, err = encryptColumnWithMaxLength("GithubAppPrivateKey", githubAppPrivateKey,
+		RepositoryCredentialsRepoCredGithubAppPrivateKeyLength); err != nil {
+		restore()
+		return nil, err
+	}
+
+	return restore, nil
+}
+
+// decryptColumns decrypts the sensitive columns of the RepositoryCredentials, after they are read from the database.
+func (obj *RepositoryCredentials) decryptColumns() error {
+
+	var err error
+	if obj.TLSClientCertData, err = decryptColumn(obj.TLSClientCertData); err != nil {
+		return err
+	}
+	if obj.TLSClientCertKey, err = decryptColumn(obj.TLSClientCertKey); err != nil {
+		return err
+	}
+	if obj.GithubAppPrivateKey, err = decryptColumn(obj.GithubAppPrivateKey); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func decryptRepositoryCredentialsColumns(repositoryCredentials []RepositoryCredentials) error {
+	for idx := range repositoryCredentials {
+		if err := repositoryCredentials[idx].decryptColumns(); err != nil {
+			return fmt.Errorf("unable to decrypt repository credentials %s: %w", repositoryCredentials[idx].RepositoryCredentialsID, err)
+		}
+	}
+	return nil
 }
@@ -4,6 +4,8 @@ package db_test
 
 import (
 	"context"
+	"encoding/base64"
+	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -167,6 +169,49 @@ var _ = Describe("RepositoryCredentials Tests", func() {
 			updatedCR.EngineClusterID = gitopsEngineInstance.Gitopsengineinstance_id // reset the EngineClusterID to the original value
 		})
 
+		It("should encrypt the TLS client certificate and key, and the GitHub App private key, of RepositoryCredentials in the database", func() {
+
+			os.Setenv(db.ColumnEncryptionKeyEnvVar, base64.StdEncoding.EncodeToString(make([]byte, 32)))
+			DeferCleanup(os.Unsetenv, db.ColumnEncryptionKeyEnvVar)
+
+			gitopsRepositoryCredentials := db.RepositoryCredentials{
+				RepositoryCredentialsID: "test-repo-cred-tls-id",
+				UserID:                  clusterUser.Clusteruser_id,
+				PrivateURL:              "https://test-private-url",
+				SecretObj:               "test-secret-obj",
+				EngineClusterID:         gitopsEngineInstance.Gitopsengineinstance_id,
+				TLSClientCertData:       "test-client-cert",
+				TLSClientCertKey:        "test-client-key",
+				TLSCACertData:           "test-ca-cert",
+				GithubAppID:             "1",
+				GithubAppInstallationID: "2",
+				GithubAppPrivateKey:     "test-github-app-private-key",
+			}
+
+			err = dbq.CreateRepositoryCredentials(ctx, &gitopsRepositoryCredentials)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gitopsRepositoryCredentials.TLSClientCertKey).To(Equal("test-client-key"), "the unencrypted values should be restored")
+
+			fetch, err := dbq.GetRepositoryCredentialsByID(ctx, gitopsRepositoryCredentials.RepositoryCredentialsID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fetch.TLSClientCertData).To(Equal("test-client-cert"))
+			Expect(fetch.TLSClientCertKey).To(Equal("test-client-key"))
+			Expect(fetch.GithubAppPrivateKey).To(Equal("test-github-app-private-key"))
+
+			By("verifying that the values cannot be read without the key, as they are encrypted")
+			os.Unsetenv(db.ColumnEncryptionKeyEnvVar)
+			_, err = dbq.GetRepositoryCredentialsByID(ctx, gitopsRepositoryCredentials.RepositoryCredentialsID)
+			Expect(err).To(HaveOccurred())
+
+			By("verifying that the values cannot be written without the key")
+			err = dbq.UpdateRepositoryCredentials(ctx, &gitopsRepositoryCredentials)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(db.ColumnEncryptionKeyEnvVar))
+
+			_, err = dbq.DeleteRepositoryCredentialsByID(ctx, gitopsRepositoryCredentials.RepositoryCredentialsID)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
 		It("Should Get RepositoryCredentials in batch.", func() {
 
 			By("Create multiple RepositoryCredentials entries.")
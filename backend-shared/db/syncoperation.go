@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -11,6 +12,69 @@ const (
 	SyncOperation_DesiredState_Terminated = "Terminated"
 )
 
+// SyncOperationResource identifies one of the resources within the 'resources' field of a SyncOperation
+type SyncOperationResource struct {
+	Group     string
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// EncodeSyncOperationResources converts a list of resources into the value of the 'resources' field of a SyncOperation.
+// The value is a comma-separated list of resources, each of the form 'GROUP:KIND:NAMESPACE/NAME' (as used by the
+// Argo CD CLI), with the namespace and its '/' omitted for cluster-scoped resources.
+func EncodeSyncOperationResources(resources []SyncOperationResource) string {
+
+	var res []string
+
+	for _, resource := range resources {
+		name := resource.Name
+		if resource.Namespace != "" {
+			name = resource.Namespace + "/" + resource.Name
+		}
+		res = append(res, resource.Group+":"+resource.Kind+":"+name)
+	}
+
+	return strings.Join(res, ",")
+}
+
+// DecodeSyncOperationResources converts the value of the 'resources' field of a SyncOperation into a list of resources.
+// See EncodeSyncOperationResources for the format of the field.
+func DecodeSyncOperationResources(value string) ([]SyncOperationResource, error) {
+
+	if value == "" {
+		return nil, nil
+	}
+
+	var res []SyncOperationResource
+
+	for _, resourceStr := range strings.Split(value, ",") {
+
+		fields := strings.Split(resourceStr, ":")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid resource in SyncOperation resources field: '%s'", resourceStr)
+		}
+
+		resource := SyncOperationResource{
+			Group: fields[0],
+			Kind:  fields[1],
+			Name:  fields[2],
+		}
+		if namespace, name, found := strings.Cut(fields[2], "/"); found {
+			resource.Namespace = namespace
+			resource.Name = name
+		}
+
+		if resource.Kind == "" || resource.Name == "" {
+			return nil, fmt.Errorf("invalid resource in SyncOperation resources field: '%s'", resourceStr)
+		}
+
+		res = append(res, resource)
+	}
+
+	return res, nil
+}
+
 func (dbq *PostgreSQLDatabaseQueries) GetSyncOperationById(ctx context.Context, syncOperation *SyncOperation) error {
 
 	if err := validateQueryParamsEntity(syncOperation, dbq); err != nil {
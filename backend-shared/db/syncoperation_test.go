@@ -247,3 +247,37 @@ var _ = Describe("SyncOperation Tests", func() {
 		})
 	})
 })
+
+var _ = Describe("SyncOperation resources field", func() {
+
+	It("should encode and decode the resources to sync", func() {
+
+		resources := []db.SyncOperationResource{
+			{Kind: "ConfigMap", Namespace: "jane", Name: "my-config-map"},
+			{Group: "apps", Kind: "Deployment", Namespace: "jane", Name: "my-deployment"},
+			{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "my-cluster-role"},
+		}
+
+		value := db.EncodeSyncOperationResources(resources)
+		Expect(value).To(Equal(":ConfigMap:jane/my-config-map,apps:Deployment:jane/my-deployment,rbac.authorization.k8s.io:ClusterRole:my-cluster-role"))
+
+		decoded, err := db.DecodeSyncOperationResources(value)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decoded).To(Equal(resources))
+	})
+
+	It("should treat an empty field as all resources", func() {
+		Expect(db.EncodeSyncOperationResources(nil)).To(BeEmpty())
+
+		decoded, err := db.DecodeSyncOperationResources("")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decoded).To(BeNil())
+	})
+
+	It("should return an error for an invalid field", func() {
+		for _, value := range []string{"ConfigMap", ":ConfigMap:", "apps:Deployment:jane/my-deployment:extra", ":ConfigMap:jane/"} {
+			_, err := db.DecodeSyncOperationResources(value)
+			Expect(err).To(HaveOccurred(), "value '%s' should be rejected", value)
+		}
+	})
+})
@@ -122,6 +122,42 @@ type ClusterCredentials struct {
 	// -- - This corresponds to the Argo CD cluster secret field of the same name.
 	ClusterResources bool `pg:"cluster_resources"`
 
+	// -- PEM-encoded CA bundle that is used to verify the TLS certificate of the cluster API URL.
+	// -- - This corresponds to the 'caData' field of the Argo CD cluster secret config.
+	Ca_data string `pg:"ca_data"`
+
+	// -- State 2) PEM-encoded client certificate and key, used to authenticate to the cluster (as an alternative to the bearer token)
+	// -- - These correspond to the 'certData'/'keyData' fields of the Argo CD cluster secret config.
+	// -- - The key is encrypted: the encrypted value is about 4/3 the length of the key, so keys of up to ~12000 characters fit.
+	Cert_data string `pg:"cert_data"`
+	Key_data  string `pg:"key_data"`
+
+	// -- State 2) JSON-encoded exec plugin configuration, used to authenticate to the cluster (as an alternative to the bearer token)
+	// -- - This corresponds to the 'execProviderConfig' field of the Argo CD cluster secret config.
+	Exec_provider_config string `pg:"exec_provider_config"`
+
+	// -- JSON-encoded RBAC policy rules that were granted to the ServiceAccount created by the GitOps Service (if any)
+	// -- - This corresponds to the '.spec.serviceAccountRules' field of the GitOpsDeploymentManagedEnvironment.
+	Serviceaccount_rules string `pg:"serviceaccount_rules"`
+
+	// -- The UID that was used to name the ServiceAccount/ClusterRole/ClusterRoleBinding/Roles/RoleBindings that were installed
+	// -- into the managed cluster by the GitOps Service (if any). These resources are removed when the credentials are no longer used.
+	// -- - This corresponds to the UID of the GitOpsDeploymentManagedEnvironment that requested the ServiceAccount.
+	Serviceaccount_installation_uid string `pg:"serviceaccount_installation_uid"`
+
+	// -- The time at which Serviceaccount_bearer_token expires, if it was issued by the Kubernetes TokenRequest API.
+	// -- - Zero (NULL) if the token does not expire (for example, it was read from a ServiceAccount token Secret, or provided by the user).
+	Serviceaccount_token_expiration time.Time `pg:"serviceaccount_token_expiration"`
+
+	// -- URL of the HTTP(S) proxy that is used to reach the cluster API URL (if any)
+	// -- - This corresponds to the 'proxyUrl' field of the Argo CD cluster secret config.
+	Proxy_url string `pg:"proxy_url"`
+
+	// -- JSON-encoded labels and annotations that are added to the Argo CD cluster secret (if any)
+	// -- - These correspond to the '.spec.clusterLabels'/'.spec.clusterAnnotations' fields of the GitOpsDeploymentManagedEnvironment.
+	Cluster_labels      string `pg:"cluster_labels"`
+	Cluster_annotations string `pg:"cluster_annotations"`
+
 	// -- Created_on field will tell us how old resources are
 	Created_on time.Time `pg:"created_on"`
 }
@@ -410,6 +446,21 @@ type SyncOperation struct {
 
 	DesiredState string `pg:"desired_state"`
 
+	// Rollback_history_id is the ID of the Argo CD Application history entry to roll back to, or nil if the SyncOperation is a regular sync
+	Rollback_history_id *int64 `pg:"rollback_history_id"`
+
+	// Prune, Dry_run and Force correspond to the fields of the same name in the GitOpsDeploymentSyncRun
+	Prune   bool `pg:"prune"`
+	Dry_run bool `pg:"dry_run"`
+	Force   bool `pg:"force"`
+
+	// Sync_strategy is the sync strategy to use: apply, hook, or empty for the default
+	Sync_strategy string `pg:"sync_strategy"`
+
+	// Resources is the list of resources to sync, or empty if all the resources of the Application should be synced.
+	// See EncodeSyncOperationResources for the format of this field.
+	Resources string `pg:"resources"`
+
 	Created_on time.Time `pg:"created_on"`
 }
 
@@ -444,7 +495,7 @@ type RepositoryCredentials struct {
 	// -- Foreign key to: ClusterUser.Clusteruser_id
 	UserID string `pg:"repo_cred_user_id,notnull"`
 
-	// PrivateURL is the address of the private Git repository.
+	// PrivateURL is the address of the private Git repository (or, if Template is true, the URL prefix of the private Git repositories).
 	PrivateURL string `pg:"repo_cred_url,notnull"`
 
 	// AuthUsername is the authorized username login for accessing the private Git repo.
@@ -457,8 +508,48 @@ type RepositoryCredentials struct {
 	// that provides access to the private Git repo. It can also be used for decrypting Sealed secrets.
 	AuthSSHKey string `pg:"repo_cred_ssh"`
 
+	// GithubAppID (alternative authentication method) is the ID of the GitHub App that provides access to the private Git repo.
+	GithubAppID string `pg:"repo_cred_github_app_id"`
+
+	// GithubAppInstallationID is the ID of the installation of the GitHub App, in the organization (or user account)
+	// that owns the private Git repo.
+	GithubAppInstallationID string `pg:"repo_cred_github_app_installation_id"`
+
+	// GithubAppPrivateKey is the private key of the GitHub App (PEM encoded), used to mint installation access tokens.
+	// It is encrypted in the database (see ColumnEncryptionKeyEnvVar).
+	GithubAppPrivateKey string `pg:"repo_cred_github_app_private_key"`
+
+	// GithubAppEnterpriseBaseURL is the base URL of the GitHub Enterprise API. Empty for GitHub.com.
+	GithubAppEnterpriseBaseURL string `pg:"repo_cred_github_app_enterprise_base_url"`
+
+	// Template is true if the credentials are a template, in which case PrivateURL is a URL prefix, and the credentials
+	// are used for every repository whose URL begins with it.
+	Template bool `pg:"repo_cred_template"`
+
+	// Type is the type of the repository: 'git', 'helm' (a Helm chart repository), or 'oci' (an OCI registry).
+	// Empty is equivalent to 'git'.
+	Type string `pg:"repo_cred_type"`
+
+	// TLSClientCertData is the TLS client certificate (PEM encoded) that is presented to the repository server.
+	// It is encrypted in the database (see ColumnEncryptionKeyEnvVar).
+	TLSClientCertData string `pg:"repo_cred_tls_client_cert_data"`
+
+	// TLSClientCertKey is the private key (PEM encoded) of the TLS client certificate.
+	// It is encrypted in the database (see ColumnEncryptionKeyEnvVar).
+	TLSClientCertKey string `pg:"repo_cred_tls_client_cert_key"`
+
+	// TLSCACertData is the bundle of CA certificates (PEM encoded) that are trusted to sign the certificate of the
+	// repository server, instead of the system CAs.
+	TLSCACertData string `pg:"repo_cred_tls_ca_cert_data"`
+
+	// Insecure is true if the certificate of the repository server should not be verified.
+	Insecure bool `pg:"repo_cred_insecure"`
+
+	// KnownHosts are the SSH known hosts entries (in the OpenSSH 'known_hosts' format) of the servers of SSH repository URLs.
+	KnownHosts string `pg:"repo_cred_known_hosts"`
+
 	// SecretObj is the name of the (insecure and unencrypted) Kubernetes secret object that provides
-	// the credentials (AuthUsername & AuthPassword, OR the AuthSSHKey) to the GitOps Engine (e.g. ArgoCD)
+	// the credentials (AuthUsername & AuthPassword, OR the AuthSSHKey, OR the GitHub App) to the GitOps Engine (e.g. ArgoCD)
 	// to gain access into the PrivateURL repo.
 	SecretObj string `pg:"repo_cred_secret,notnull"`
 
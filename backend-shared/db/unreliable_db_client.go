@@ -819,6 +819,16 @@ func (cdb *ChaosDBClient) UpdateManagedEnvironment(ctx context.Context, obj *Man
 
 }
 
+func (cdb *ChaosDBClient) UpdateClusterCredentials(ctx context.Context, obj *ClusterCredentials) error {
+
+	if err := shouldSimulateFailure("UpdateClusterCredentials", obj); err != nil {
+		return err
+	}
+
+	return cdb.InnerClient.UpdateClusterCredentials(ctx, obj)
+
+}
+
 func (cdb *ChaosDBClient) DeleteGitopsEngineInstanceById(ctx context.Context, id string) (int, error) {
 
 	if err := shouldSimulateFailure("DeleteGitopsEngineInstanceById", id); err != nil {
@@ -69,11 +69,26 @@ func ConvertArgoCDClusterSecretNameToManagedIdDatabaseRowId(argoCDClusterSecretN
 }
 
 type ClusterSecretTLSClientConfigJSON struct {
-	Insecure bool `json:"insecure"`
+	Insecure bool   `json:"insecure"`
+	CAData   []byte `json:"caData,omitempty"`
+	CertData []byte `json:"certData,omitempty"`
+	KeyData  []byte `json:"keyData,omitempty"`
 }
+
+// ClusterSecretExecProviderConfigJSON is the exec plugin configuration that Argo CD uses to authenticate to a cluster.
+type ClusterSecretExecProviderConfigJSON struct {
+	Command     string            `json:"command,omitempty"`
+	Args        []string          `json:"args,omitempty"`
+	Env         map[string]string `json:"env,omitempty"`
+	APIVersion  string            `json:"apiVersion,omitempty"`
+	InstallHint string            `json:"installHint,omitempty"`
+}
+
 type ClusterSecretConfigJSON struct {
-	BearerToken     string                           `json:"bearerToken"`
-	TLSClientConfig ClusterSecretTLSClientConfigJSON `json:"tlsClientConfig"`
+	BearerToken        string                               `json:"bearerToken"`
+	TLSClientConfig    ClusterSecretTLSClientConfigJSON     `json:"tlsClientConfig"`
+	ExecProviderConfig *ClusterSecretExecProviderConfigJSON `json:"execProviderConfig,omitempty"`
+	ProxyURL           string                               `json:"proxyUrl,omitempty"`
 }
 
 func GetArgoCDApplicationName(labels map[string]string) string {
@@ -30,8 +30,9 @@ type FauxTypeMeta struct {
 
 // ApplicationSpec represents desired application state. Contains link to repository with application definition and additional parameters link definition revision.
 type FauxApplicationSpec struct {
-	// Source is a reference to the location of the application's manifests or chart
-	Source ApplicationSource `json:"source" protobuf:"bytes,1,opt,name=source"`
+	// Source is a reference to the location of the application's manifests or chart.
+	// The source is omitted (via the yaml tag) when it is empty, which is the case for multi-source Applications.
+	Source ApplicationSource `json:"source" yaml:"source,omitempty" protobuf:"bytes,1,opt,name=source"`
 	// Destination is a reference to the target Kubernetes server and namespace
 	Destination ApplicationDestination `json:"destination" protobuf:"bytes,2,name=destination"`
 	// Project is a reference to the project this application belongs to.
@@ -39,6 +40,25 @@ type FauxApplicationSpec struct {
 	Project string `json:"project" protobuf:"bytes,3,name=project"`
 	// SyncPolicy controls when and how a sync will be performed
 	SyncPolicy *SyncPolicy `json:"syncPolicy,omitempty" protobuf:"bytes,4,name=syncPolicy"`
+	// Sources is a reference to the location of the application's manifests or chart, for multi-source Applications.
+	// Sources are omitted (via the yaml tag) when empty, so that the spec of single-source Applications is unchanged.
+	Sources []ApplicationSource `json:"sources,omitempty" yaml:"sources,omitempty" protobuf:"bytes,8,opt,name=sources"`
+	// IgnoreDifferences is a list of resources and their fields which should be ignored during comparison.
+	// It is omitted (via the yaml tag) when empty, so that the spec of existing Applications is unchanged.
+	IgnoreDifferences []ResourceIgnoreDifferences `json:"ignoreDifferences,omitempty" yaml:"ignoredifferences,omitempty" protobuf:"bytes,5,name=ignoreDifferences"`
+}
+
+// ResourceIgnoreDifferences contains resource filter and list of json paths which should be ignored during comparison with live state.
+type ResourceIgnoreDifferences struct {
+	Group             string   `json:"group,omitempty" protobuf:"bytes,1,opt,name=group"`
+	Kind              string   `json:"kind" protobuf:"bytes,2,opt,name=kind"`
+	Name              string   `json:"name,omitempty" protobuf:"bytes,3,opt,name=name"`
+	Namespace         string   `json:"namespace,omitempty" protobuf:"bytes,4,opt,name=namespace"`
+	JSONPointers      []string `json:"jsonPointers,omitempty" protobuf:"bytes,5,opt,name=jsonPointers"`
+	JQPathExpressions []string `json:"jqPathExpressions,omitempty" protobuf:"bytes,6,opt,name=jqPathExpressions"`
+	// ManagedFieldsManagers is a list of trusted managers. Fields mutated by those managers will take precedence over the
+	// desired state defined in the SCM and won't be displayed in diffs
+	ManagedFieldsManagers []string `json:"managedFieldsManagers,omitempty" protobuf:"bytes,7,opt,name=managedFieldsManagers"`
 }
 
 // ApplicationSource contains all required information about the source of an application
@@ -54,6 +74,62 @@ type ApplicationSource struct {
 	// In case of Git, this can be commit, tag, or branch. If omitted, will equal to HEAD.
 	// In case of Helm, this is a semver tag for the Chart's version.
 	TargetRevision string `json:"targetRevision,omitempty" protobuf:"bytes,4,opt,name=targetRevision"`
+
+	// Helm holds helm specific options
+	// It is omitted (via the yaml tag) when nil, so that the spec of existing Applications is unchanged.
+	Helm *ApplicationSourceHelm `json:"helm,omitempty" yaml:"helm,omitempty" protobuf:"bytes,7,opt,name=helm"`
+
+	// Kustomize holds kustomize specific options
+	// It is omitted (via the yaml tag) when nil, so that the spec of existing Applications is unchanged.
+	Kustomize *ApplicationSourceKustomize `json:"kustomize,omitempty" yaml:"kustomize,omitempty" protobuf:"bytes,8,opt,name=kustomize"`
+
+	// Chart is a Helm chart name, and must be specified for applications sourced from a Helm repo.
+	// It is omitted (via the yaml tag) when empty, so that the spec of existing Applications is unchanged.
+	Chart string `json:"chart,omitempty" yaml:"chart,omitempty" protobuf:"bytes,12,opt,name=chart"`
+
+	// Ref is reference to another source within sources field. This field will not be used if used with a `source` tag.
+	// It is omitted (via the yaml tag) when empty, so that the spec of existing Applications is unchanged.
+	Ref string `json:"ref,omitempty" yaml:"ref,omitempty" protobuf:"bytes,13,opt,name=ref"`
+}
+
+// ApplicationSourceHelm holds helm specific options
+type ApplicationSourceHelm struct {
+	// ValuesFiles is a list of Helm value files to use when generating a template
+	ValueFiles []string `json:"valueFiles,omitempty" protobuf:"bytes,1,opt,name=valueFiles"`
+	// Parameters is a list of Helm parameters which are passed to the helm template command upon manifest generation
+	Parameters []HelmParameter `json:"parameters,omitempty" protobuf:"bytes,2,opt,name=parameters"`
+	// ReleaseName is the Helm release name to use. If omitted it will use the application name
+	ReleaseName string `json:"releaseName,omitempty" protobuf:"bytes,3,opt,name=releaseName"`
+	// Values specifies Helm values to be passed to helm template, typically defined as a block
+	Values string `json:"values,omitempty" protobuf:"bytes,4,opt,name=values"`
+	// PassCredentials pass credentials to all domains (Helm's --pass-credentials)
+	PassCredentials bool `json:"passCredentials,omitempty" protobuf:"bytes,7,opt,name=passCredentials"`
+}
+
+// HelmParameter is a parameter that's passed to helm template during manifest generation
+type HelmParameter struct {
+	// Name is the name of the Helm parameter
+	Name string `json:"name,omitempty" protobuf:"bytes,1,opt,name=name"`
+	// Value is the value for the Helm parameter
+	Value string `json:"value,omitempty" protobuf:"bytes,2,opt,name=value"`
+	// ForceString determines whether to tell Helm to interpret booleans and numbers as strings
+	ForceString bool `json:"forceString,omitempty" protobuf:"bytes,3,opt,name=forceString"`
+}
+
+// ApplicationSourceKustomize holds kustomize specific options
+type ApplicationSourceKustomize struct {
+	// NamePrefix is a prefix appended to resources for Kustomize apps
+	NamePrefix string `json:"namePrefix,omitempty" protobuf:"bytes,1,opt,name=namePrefix"`
+	// NameSuffix is a suffix appended to resources for Kustomize apps
+	NameSuffix string `json:"nameSuffix,omitempty" protobuf:"bytes,2,opt,name=nameSuffix"`
+	// Images is a list of Kustomize image override specifications
+	Images []string `json:"images,omitempty" protobuf:"bytes,3,opt,name=images"`
+	// CommonLabels is a list of additional labels to add to rendered manifests
+	CommonLabels map[string]string `json:"commonLabels,omitempty" protobuf:"bytes,4,opt,name=commonLabels"`
+	// CommonAnnotations is a list of additional annotations to add to rendered manifests
+	CommonAnnotations map[string]string `json:"commonAnnotations,omitempty" protobuf:"bytes,6,opt,name=commonAnnotations"`
+	// Namespace sets the namespace that Kustomize adds to all resources
+	Namespace string `json:"namespace,omitempty" protobuf:"bytes,9,opt,name=namespace"`
 }
 
 // ApplicationDestination holds information about the application's destination
@@ -73,6 +149,8 @@ type ApplicationDestination struct {
 type FauxComparedTo struct {
 	// Source is a reference to the location of the application's manifests or chart
 	Source ApplicationSource `json:"source"`
+	// Sources is a reference to the application's multiple sources used for comparison
+	Sources []ApplicationSource `json:"sources,omitempty" yaml:"sources,omitempty"`
 	// Destination is a reference to the target Kubernetes server and namespace
 	Destination ApplicationDestination `json:"destination"`
 }
@@ -128,6 +206,30 @@ type FauxApplicationStatus struct {
 	Conditions []ApplicationCondition `json:"conditions,omitempty" protobuf:"bytes,5,opt,name=conditions"`
 	// OperationState contains information about any ongoing operations, such as a sync
 	OperationState *OperationState `json:"operationState,omitempty" protobuf:"bytes,7,opt,name=operationState"`
+	// History contains information about the application's sync history
+	// It is omitted (via the yaml tag) when empty, so that the spec field of existing Applications is unchanged.
+	History RevisionHistories `json:"history,omitempty" yaml:"history,omitempty" protobuf:"bytes,11,opt,name=history"`
+}
+
+// RevisionHistories is a array of history, oldest first and newest last
+type RevisionHistories []RevisionHistory
+
+// RevisionHistory contains history information about a previous sync
+type RevisionHistory struct {
+	// Revision holds the revision the sync was performed against
+	Revision string `json:"revision,omitempty" protobuf:"bytes,2,opt,name=revision"`
+	// DeployedAt holds the time the sync operation completed
+	DeployedAt metav1.Time `json:"deployedAt" protobuf:"bytes,4,opt,name=deployedAt"`
+	// ID is an auto incrementing identifier of the RevisionHistory
+	ID int64 `json:"id" protobuf:"bytes,5,opt,name=id"`
+	// Source is a reference to the application source used for the sync operation
+	Source ApplicationSource `json:"source,omitempty" protobuf:"bytes,6,opt,name=source"`
+	// DeployStartedAt holds the time the sync operation started
+	DeployStartedAt *metav1.Time `json:"deployStartedAt,omitempty" protobuf:"bytes,7,opt,name=deployStartedAt"`
+	// Sources is a reference to the application sources used for the sync operation
+	Sources ApplicationSources `json:"sources,omitempty" protobuf:"bytes,8,opt,name=sources"`
+	// Revisions holds the revision of each source in sources field the sync was performed against
+	Revisions []string `json:"revisions,omitempty" protobuf:"bytes,9,opt,name=revisions"`
 }
 
 // ResourceStatus holds the current sync and health status of a resource
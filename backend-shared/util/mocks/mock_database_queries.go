@@ -1576,6 +1576,20 @@ func (mr *MockDatabaseQueriesMockRecorder) UpdateKubernetesResourceUIDForKuberne
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateKubernetesResourceUIDForKubernetesToDBResourceMapping", reflect.TypeOf((*MockDatabaseQueries)(nil).UpdateKubernetesResourceUIDForKubernetesToDBResourceMapping), arg0, arg1)
 }
 
+// UpdateClusterCredentials mocks base method.
+func (m *MockDatabaseQueries) UpdateClusterCredentials(arg0 context.Context, arg1 *db.ClusterCredentials) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateClusterCredentials", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateClusterCredentials indicates an expected call of UpdateClusterCredentials.
+func (mr *MockDatabaseQueriesMockRecorder) UpdateClusterCredentials(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateClusterCredentials", reflect.TypeOf((*MockDatabaseQueries)(nil).UpdateClusterCredentials), arg0, arg1)
+}
+
 // UpdateManagedEnvironment mocks base method.
 func (m *MockDatabaseQueries) UpdateManagedEnvironment(arg0 context.Context, arg1 *db.ManagedEnvironment) error {
 	m.ctrl.T.Helper()
@@ -3,16 +3,19 @@ package util
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apierr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -22,6 +25,16 @@ const (
 	ArgoCDManagerServiceAccountPrefix         = "argocd-manager-"
 	ArgoCDManagerClusterRoleNamePrefix        = "argocd-manager-cluster-role-"
 	ArgoCDManagerClusterRoleBindingNamePrefix = "argocd-manager-cluster-role-binding-"
+	ArgoCDManagerRoleNamePrefix               = "argocd-manager-role-"
+	ArgoCDManagerRoleBindingNamePrefix        = "argocd-manager-role-binding-"
+
+	// argoCDManagerTokenSecretNameSuffix is appended to the name of the ServiceAccount, to form the name of the token
+	// Secret that is created by InstallServiceAccount. Token Secrets created by earlier versions have a generated name.
+	argoCDManagerTokenSecretNameSuffix = "-token"
+
+	// ArgoCDManagerUIDLabel is set on the Roles/RoleBindings that are created by InstallServiceAccount, so that they
+	// can be located (and deleted) when they are no longer needed. The value is the uuid passed to InstallServiceAccount.
+	ArgoCDManagerUIDLabel = "managed-gitops.redhat.com/argocd-manager-uid"
 )
 
 var (
@@ -34,6 +47,78 @@ var (
 	}
 )
 
+// NamespacesNotFoundError is returned by InstallServiceAccount and UpdateServiceAccountRBAC when the ServiceAccount would
+// be granted access to Namespaces that do not exist on the cluster. In this case, the permissions of the ServiceAccount
+// are not changed.
+type NamespacesNotFoundError struct {
+	Namespaces []string
+}
+
+func (e NamespacesNotFoundError) Error() string {
+	return fmt.Sprintf("the following namespaces do not exist on the cluster: %s", strings.Join(e.Namespaces, ", "))
+}
+
+// ServiceAccountRBACOptions controls the permissions that InstallServiceAccount grants to the ServiceAccount.
+type ServiceAccountRBACOptions struct {
+	// Namespaces, if non-empty, restricts the Rules to the given Namespaces, via a Role/RoleBinding in each Namespace.
+	// If empty, the Rules are granted at cluster scope, via a ClusterRole/ClusterRoleBinding.
+	Namespaces []string
+
+	// ClusterResources controls whether the Rules are also granted at cluster scope, when Namespaces is non-empty.
+	// This is required for Argo CD to deploy cluster-scoped resources. Ignored if Namespaces is empty.
+	ClusterResources bool
+
+	// Rules are the policy rules to grant to the ServiceAccount. Defaults to ArgoCDManagerNamespacePolicyRules, if empty.
+	Rules []rbacv1.PolicyRule
+
+	// ListNamespaces allows the ServiceAccount to list all Namespaces at cluster scope, in addition to Rules. This is
+	// required to resolve a namespace selector using the credentials of the ServiceAccount.
+	ListNamespaces bool
+}
+
+// policyRules returns the rules to grant to the ServiceAccount
+func (options ServiceAccountRBACOptions) policyRules() []rbacv1.PolicyRule {
+	if len(options.Rules) == 0 {
+		return ArgoCDManagerNamespacePolicyRules
+	}
+	return options.Rules
+}
+
+// clusterRoleRules returns the rules of the ClusterRole that is bound to the ServiceAccount.
+func (options ServiceAccountRBACOptions) clusterRoleRules() []rbacv1.PolicyRule {
+
+	var rules []rbacv1.PolicyRule
+
+	if len(options.Namespaces) == 0 || options.ClusterResources {
+		rules = append(rules, options.policyRules()...)
+	} else {
+		// When restricted to a set of Namespaces, the ServiceAccount is only able to 'get' those Namespaces at cluster scope:
+		// this is required to verify the credentials of the ServiceAccount.
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups:     []string{""},
+			Resources:     []string{"namespaces"},
+			ResourceNames: append([]string{}, options.Namespaces...),
+			Verbs:         []string{"get"},
+		})
+	}
+
+	if options.ListNamespaces {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{""},
+			Resources: []string{"namespaces"},
+			Verbs:     []string{"list"},
+		})
+	}
+
+	return rules
+}
+
+// installedClusterRoleRules returns the rules of the ClusterRole of the ServiceAccount with the given uuid, which also
+// allow it to manage itself.
+func (options ServiceAccountRBACOptions) installedClusterRoleRules(uuid string) []rbacv1.PolicyRule {
+	return append(append([]rbacv1.PolicyRule{}, options.clusterRoleRules()...), selfManagementPolicyRules(uuid)...)
+}
+
 func getOrCreateServiceAccount(ctx context.Context, k8sClient client.Client, serviceAccountName string, serviceAccountNS string,
 	log logr.Logger) (*corev1.ServiceAccount, error) {
 
@@ -71,20 +156,51 @@ func GenerateServiceAccountName(uuid string) string {
 	return ArgoCDManagerServiceAccountPrefix + uuid
 }
 
-func InstallServiceAccount(ctx context.Context, k8sClient client.Client, uuid string, serviceAccountNS string, log logr.Logger) (string, *corev1.ServiceAccount, error) {
+// InstallServiceAccount creates (or updates) a ServiceAccount on the cluster, grants it the permissions described by
+// rbacOptions, and returns a bearer token for it. Calling it again with different rbacOptions will tighten or widen the
+// permissions of the existing ServiceAccount.
+//
+// All the installed resources are owned by the ClusterRole, which the ServiceAccount is allowed to delete: see
+// UninstallServiceAccount. If the ServiceAccount was installed by an earlier version of the GitOps Service (with a
+// ClusterRole that grants all permissions), its permissions are replaced, and its resources are made owned by the ClusterRole.
+func InstallServiceAccount(ctx context.Context, k8sClient client.Client, uuid string, serviceAccountNS string,
+	rbacOptions ServiceAccountRBACOptions, log logr.Logger) (string, *corev1.ServiceAccount, error) {
 
 	serviceAccountName := GenerateServiceAccountName(uuid)
 
+	if err := verifyNamespacesExist(ctx, k8sClient, rbacOptions.Namespaces); err != nil {
+		return "", nil, err
+	}
+
+	clusterRole, err := createOrUpdateClusterRoleAndRoleBinding(ctx, uuid, k8sClient, serviceAccountName, serviceAccountNS,
+		rbacOptions.installedClusterRoleRules(uuid), log)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to create or update role and cluster role binding: %w", err)
+	}
+
+	if err := adoptLegacyServiceAccountResources(ctx, k8sClient, clusterRole, uuid, serviceAccountNS, log); err != nil {
+		return "", nil, err
+	}
+
+	ownerRefs := []metav1.OwnerReference{generateClusterRoleOwnerReference(clusterRole)}
+
 	sa, err := getOrCreateServiceAccount(ctx, k8sClient, serviceAccountName, serviceAccountNS, log)
 	if err != nil {
 		return "", nil, fmt.Errorf("unable to create or update service account: %v, error: %w", serviceAccountName, err)
 	}
 
-	if err := createOrUpdateClusterRoleAndRoleBinding(ctx, uuid, k8sClient, serviceAccountName, serviceAccountNS, log); err != nil {
-		return "", nil, fmt.Errorf("unable to create or update role and cluster role binding: %w", err)
+	if err := createOrUpdateObject(ctx, k8sClient, sa, func() {
+		sa.OwnerReferences = ownerRefs
+	}, log); err != nil {
+		return "", nil, fmt.Errorf("unable to set owner of service account: %v, error: %w", serviceAccountName, err)
+	}
+
+	if err := createOrUpdateRolesAndRoleBindings(ctx, uuid, k8sClient, serviceAccountName, serviceAccountNS,
+		rbacOptions.Namespaces, rbacOptions.policyRules(), ownerRefs, log); err != nil {
+		return "", nil, fmt.Errorf("unable to create or update roles and role bindings: %w", err)
 	}
 
-	token, err := getOrCreateServiceAccountBearerToken(ctx, k8sClient, serviceAccountName, serviceAccountNS, log)
+	token, err := getOrCreateServiceAccountBearerToken(ctx, k8sClient, serviceAccountName, serviceAccountNS, ownerRefs, log)
 	if err != nil {
 		return "", nil, err
 	}
@@ -92,12 +208,253 @@ func InstallServiceAccount(ctx context.Context, k8sClient client.Client, uuid st
 	return token, sa, nil
 }
 
+// UpdateServiceAccountRBAC updates the permissions of a ServiceAccount that was installed by InstallServiceAccount to
+// those described by rbacOptions, without issuing a new token for it.
+func UpdateServiceAccountRBAC(ctx context.Context, k8sClient client.Client, uuid string, serviceAccountNS string,
+	rbacOptions ServiceAccountRBACOptions, log logr.Logger) error {
+
+	serviceAccountName := GenerateServiceAccountName(uuid)
+
+	if err := verifyNamespacesExist(ctx, k8sClient, rbacOptions.Namespaces); err != nil {
+		return err
+	}
+
+	clusterRole, err := createOrUpdateClusterRoleAndRoleBinding(ctx, uuid, k8sClient, serviceAccountName, serviceAccountNS,
+		rbacOptions.installedClusterRoleRules(uuid), log)
+	if err != nil {
+		return fmt.Errorf("unable to create or update role and cluster role binding: %w", err)
+	}
+
+	ownerRefs := []metav1.OwnerReference{generateClusterRoleOwnerReference(clusterRole)}
+
+	if err := createOrUpdateRolesAndRoleBindings(ctx, uuid, k8sClient, serviceAccountName, serviceAccountNS,
+		rbacOptions.Namespaces, rbacOptions.policyRules(), ownerRefs, log); err != nil {
+		return fmt.Errorf("unable to create or update roles and role bindings: %w", err)
+	}
+
+	return nil
+}
+
+// UninstallServiceAccount removes the ServiceAccount, token Secrets, ClusterRole, ClusterRoleBinding, Roles and RoleBindings
+// that were installed by InstallServiceAccount, for the given uuid. k8sClient may be authenticated as the ServiceAccount itself.
+//
+// Only the ClusterRole is deleted directly: deleting any of the other resources first would revoke the permissions of the
+// ServiceAccount. The remaining resources are owned by the ClusterRole, and so are deleted by the K8s garbage collector.
+func UninstallServiceAccount(ctx context.Context, k8sClient client.Client, uuid string, serviceAccountNS string, log logr.Logger) error {
+
+	clusterRole := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: ArgoCDManagerClusterRoleNamePrefix + uuid,
+		},
+	}
+
+	// The ServiceAccounts installed by this version are only permitted to delete the ClusterRole: but all their resources
+	// are already owned by it.
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(clusterRole), clusterRole); err != nil {
+		if apierr.IsNotFound(err) {
+			return nil
+		}
+		if !apierr.IsForbidden(err) {
+			return fmt.Errorf("unable to get cluster role '%s': %w", clusterRole.Name, err)
+		}
+	} else if err := adoptLegacyServiceAccountResources(ctx, k8sClient, clusterRole, uuid, serviceAccountNS, log); err != nil {
+		return err
+	}
+
+	if err := k8sClient.Delete(ctx, clusterRole, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
+		if apierr.IsNotFound(err) {
+			return nil
+		}
+		log.Error(err, "Unable to delete ClusterRole", "clusterRoleName", clusterRole.Name)
+		return fmt.Errorf("unable to delete cluster role '%s': %w", clusterRole.Name, err)
+	}
+	logutil.LogAPIResourceChangeEvent(clusterRole.Namespace, clusterRole.Name, clusterRole, logutil.ResourceDeleted, log)
+
+	return nil
+}
+
+// adoptLegacyServiceAccountResources makes the ClusterRole the owner of the ServiceAccount, ClusterRoleBinding and token
+// Secrets, for ServiceAccounts that were installed by earlier versions of the GitOps Service, which did not set an owner
+// on them. When uninstalling, the ClusterRole of these ServiceAccounts still grants all permissions, and so k8sClient may
+// be authenticated as the ServiceAccount itself.
+func adoptLegacyServiceAccountResources(ctx context.Context, k8sClient client.Client, clusterRole *rbacv1.ClusterRole,
+	uuid string, serviceAccountNS string, log logr.Logger) error {
+
+	serviceAccountName := GenerateServiceAccountName(uuid)
+
+	objects := []client.Object{
+		&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: ArgoCDManagerClusterRoleBindingNamePrefix + uuid}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: serviceAccountName, Namespace: serviceAccountNS}},
+	}
+
+	var secretList corev1.SecretList
+	if err := k8sClient.List(ctx, &secretList, client.InNamespace(serviceAccountNS)); err != nil {
+		if !apierr.IsForbidden(err) {
+			return fmt.Errorf("unable to list secrets in namespace '%s': %w", serviceAccountNS, err)
+		}
+	}
+	for i := range secretList.Items {
+		secret := secretList.Items[i]
+		if secret.Type == corev1.SecretTypeServiceAccountToken && secret.Annotations[corev1.ServiceAccountNameKey] == serviceAccountName {
+			objects = append(objects, &secret)
+		}
+	}
+
+	ownerRef := generateClusterRoleOwnerReference(clusterRole)
+
+	for _, obj := range objects {
+
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			if apierr.IsNotFound(err) || apierr.IsForbidden(err) {
+				continue
+			}
+			return fmt.Errorf("unable to get '%s' of service account '%s': %w", obj.GetName(), serviceAccountName, err)
+		}
+
+		isOwned := false
+		for _, existingOwnerRef := range obj.GetOwnerReferences() {
+			if existingOwnerRef.UID == clusterRole.UID {
+				isOwned = true
+				break
+			}
+		}
+		if isOwned {
+			continue
+		}
+
+		obj.SetOwnerReferences(append(obj.GetOwnerReferences(), ownerRef))
+		if err := k8sClient.Update(ctx, obj); err != nil {
+			log.Error(err, "Unable to set owner of resource installed by an earlier version", "name", obj.GetName(), "namespace", obj.GetNamespace())
+			return fmt.Errorf("unable to set owner of '%s' of service account '%s': %w", obj.GetName(), serviceAccountName, err)
+		}
+		logutil.LogAPIResourceChangeEvent(obj.GetNamespace(), obj.GetName(), obj, logutil.ResourceModified, log)
+	}
+
+	return nil
+}
+
+// DeleteServiceAccountTokenSecrets deletes the token Secrets of the ServiceAccount that was installed by
+// InstallServiceAccount, for the given uuid. The tokens in these Secrets do not expire: they are no longer needed once
+// the ServiceAccount has a token from RequestServiceAccountToken. k8sClient may be authenticated as the ServiceAccount
+// itself.
+func DeleteServiceAccountTokenSecrets(ctx context.Context, k8sClient client.Client, uuid string, serviceAccountNS string,
+	log logr.Logger) error {
+
+	serviceAccountName := GenerateServiceAccountName(uuid)
+
+	tokenSecrets := []corev1.Secret{{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      serviceAccountName + argoCDManagerTokenSecretNameSuffix,
+			Namespace: serviceAccountNS,
+		},
+	}}
+
+	// Token Secrets that were created by earlier versions have a generated name, and so can only be located if the
+	// ServiceAccount is permitted to list Secrets. Otherwise, they are removed when the ServiceAccount is uninstalled.
+	var secretList corev1.SecretList
+	if err := k8sClient.List(ctx, &secretList, client.InNamespace(serviceAccountNS)); err != nil {
+		if !apierr.IsForbidden(err) {
+			return fmt.Errorf("unable to list secrets in namespace '%s': %w", serviceAccountNS, err)
+		}
+	}
+	for _, secret := range secretList.Items {
+		if secret.Type == corev1.SecretTypeServiceAccountToken && secret.Name != tokenSecrets[0].Name &&
+			secret.Annotations[corev1.ServiceAccountNameKey] == serviceAccountName {
+			tokenSecrets = append(tokenSecrets, secret)
+		}
+	}
+
+	for i := range tokenSecrets {
+		tokenSecret := tokenSecrets[i]
+
+		if err := k8sClient.Delete(ctx, &tokenSecret); err != nil {
+			if apierr.IsNotFound(err) {
+				continue
+			}
+			log.Error(err, "Unable to delete ServiceAccountToken Secret", "tokenSecretName", tokenSecret.Name, "tokenSecretNamespace", tokenSecret.Namespace)
+			return fmt.Errorf("unable to delete token secret '%s' of service account '%s': %w", tokenSecret.Name, serviceAccountName, err)
+		}
+		logutil.LogAPIResourceChangeEvent(tokenSecret.Namespace, tokenSecret.Name, tokenSecret, logutil.ResourceDeleted, log)
+	}
+
+	return nil
+}
+
+// selfManagementPolicyRules returns the rules that allow the ServiceAccount to call UninstallServiceAccount,
+// RequestServiceAccountToken and DeleteServiceAccountTokenSecrets on itself.
+func selfManagementPolicyRules(uuid string) []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{
+			APIGroups:     []string{rbacv1.GroupName},
+			Resources:     []string{"clusterroles"},
+			ResourceNames: []string{ArgoCDManagerClusterRoleNamePrefix + uuid},
+			Verbs:         []string{"delete"},
+		},
+		{
+			APIGroups:     []string{corev1.GroupName},
+			Resources:     []string{"serviceaccounts/token"},
+			ResourceNames: []string{GenerateServiceAccountName(uuid)},
+			Verbs:         []string{"create"},
+		},
+		{
+			APIGroups:     []string{corev1.GroupName},
+			Resources:     []string{"secrets"},
+			ResourceNames: []string{GenerateServiceAccountName(uuid) + argoCDManagerTokenSecretNameSuffix},
+			Verbs:         []string{"delete"},
+		},
+	}
+}
+
+// RequestServiceAccountToken uses the TokenRequest API to issue a new bearer token for the ServiceAccount, which expires
+// after (approximately) the given duration. restConfig may be authenticated as the ServiceAccount itself: see
+// selfManagementPolicyRules.
+//
+// Returns the token, and the time at which it expires.
+func RequestServiceAccountToken(ctx context.Context, restConfig *rest.Config, serviceAccountName string, serviceAccountNS string,
+	expiration time.Duration) (string, time.Time, error) {
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("unable to create clientset: %w", err)
+	}
+
+	expirationSeconds := int64(expiration.Seconds())
+
+	tokenRequest := &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			ExpirationSeconds: &expirationSeconds,
+		},
+	}
+
+	res, err := clientset.CoreV1().ServiceAccounts(serviceAccountNS).CreateToken(ctx, serviceAccountName, tokenRequest, metav1.CreateOptions{})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("unable to request token for service account '%s' in '%s': %w", serviceAccountName, serviceAccountNS, err)
+	}
+
+	if res.Status.Token == "" {
+		return "", time.Time{}, fmt.Errorf("token request for service account '%s' in '%s' returned an empty token", serviceAccountName, serviceAccountNS)
+	}
+
+	return res.Status.Token, res.Status.ExpirationTimestamp.Time, nil
+}
+
+// generateClusterRoleOwnerReference returns an OwnerReference which, when set on another resource, causes that resource
+// to be garbage collected when the ClusterRole is deleted.
+func generateClusterRoleOwnerReference(clusterRole *rbacv1.ClusterRole) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: rbacv1.SchemeGroupVersion.String(),
+		Kind:       "ClusterRole",
+		Name:       clusterRole.Name,
+		UID:        clusterRole.UID,
+	}
+}
+
 // getOrCreateServiceAccountBearerToken returns a token if there is an existing token secret for a service account.
 // If the token secret is missing, it creates a new secret and attach it to the service account
 func getOrCreateServiceAccountBearerToken(ctx context.Context, k8sClient client.Client, serviceAccountName string,
-	serviceAccountNS string, log logr.Logger) (string, error) {
+	serviceAccountNS string, ownerRefs []metav1.OwnerReference, log logr.Logger) (string, error) {
 
-	tokenSecret, err := createServiceAccountTokenSecret(ctx, k8sClient, serviceAccountName, serviceAccountNS, log)
+	tokenSecret, err := createServiceAccountTokenSecret(ctx, k8sClient, serviceAccountName, serviceAccountNS, ownerRefs, log)
 	if err != nil {
 		return "", fmt.Errorf("failed to create a token secret for service account %s: %w", serviceAccountName, err)
 	}
@@ -154,15 +511,16 @@ func getServiceAccountTokenSecret(ctx context.Context, k8sClient client.Client,
 }
 
 func createServiceAccountTokenSecret(ctx context.Context, k8sClient client.Client, serviceAccountName, serviceAccountNS string,
-	log logr.Logger) (*corev1.Secret, error) {
+	ownerRefs []metav1.OwnerReference, log logr.Logger) (*corev1.Secret, error) {
 
 	tokenSecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: serviceAccountName,
-			Namespace:    serviceAccountNS,
+			Name:      serviceAccountName + argoCDManagerTokenSecretNameSuffix,
+			Namespace: serviceAccountNS,
 			Annotations: map[string]string{
 				corev1.ServiceAccountNameKey: serviceAccountName,
 			},
+			OwnerReferences: ownerRefs,
 		},
 		Type: corev1.SecretTypeServiceAccountToken,
 	}
@@ -170,6 +528,10 @@ func createServiceAccountTokenSecret(ctx context.Context, k8sClient client.Clien
 	log = log.WithValues("tokenSecretName", tokenSecret.Name, "tokenSecretNamespace", tokenSecret.Namespace)
 
 	if err := k8sClient.Create(ctx, tokenSecret); err != nil {
+		if apierr.IsAlreadyExists(err) {
+			// The Secret was created by a previous install, so reuse it
+			return tokenSecret, nil
+		}
 		log.Error(err, "Unable to create ServiceAccountToken Secret")
 		return nil, err
 	}
@@ -178,8 +540,10 @@ func createServiceAccountTokenSecret(ctx context.Context, k8sClient client.Clien
 	return tokenSecret, nil
 }
 
+// createOrUpdateClusterRoleAndRoleBinding ensures that a ClusterRole with the given rules exists, and that it is bound to the
+// ServiceAccount. The ClusterRoleBinding is owned by the ClusterRole.
 func createOrUpdateClusterRoleAndRoleBinding(ctx context.Context, uuid string, k8sClient client.Client,
-	serviceAccountName string, serviceAccountNamespace string, log logr.Logger) error {
+	serviceAccountName string, serviceAccountNamespace string, rules []rbacv1.PolicyRule, log logr.Logger) (*rbacv1.ClusterRole, error) {
 
 	clusterRole := &rbacv1.ClusterRole{
 		ObjectMeta: metav1.ObjectMeta{
@@ -189,25 +553,25 @@ func createOrUpdateClusterRoleAndRoleBinding(ctx context.Context, uuid string, k
 	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(clusterRole), clusterRole); err != nil {
 
 		if !apierr.IsNotFound(err) {
-			return fmt.Errorf("unable to get cluster role: %w", err)
+			return nil, fmt.Errorf("unable to get cluster role: %w", err)
 		}
 
 		log := log.WithValues("clusterRoleName", clusterRole.Name)
 
-		clusterRole.Rules = ArgoCDManagerNamespacePolicyRules
+		clusterRole.Rules = rules
 		if err := k8sClient.Create(ctx, clusterRole); err != nil {
 			log.Error(err, "Unable to create ClusterRole")
-			return fmt.Errorf("unable to create clusterrole: %w", err)
+			return nil, fmt.Errorf("unable to create clusterrole: %w", err)
 		}
 		logutil.LogAPIResourceChangeEvent(clusterRole.Namespace, clusterRole.Name, clusterRole, logutil.ResourceCreated, log)
 
 	} else {
 		log := log.WithValues("clusterRoleName", clusterRole.Name)
 
-		clusterRole.Rules = ArgoCDManagerNamespacePolicyRules
+		clusterRole.Rules = rules
 		if err := k8sClient.Update(ctx, clusterRole); err != nil {
 			log.Error(err, "Unable to update ClusterRole")
-			return fmt.Errorf("unable to update cluster role: %w", err)
+			return nil, fmt.Errorf("unable to update cluster role: %w", err)
 		}
 		logutil.LogAPIResourceChangeEvent(clusterRole.Namespace, clusterRole.Name, clusterRole, logutil.ResourceModified, log)
 	}
@@ -220,11 +584,13 @@ func createOrUpdateClusterRoleAndRoleBinding(ctx context.Context, uuid string, k
 	update := true
 	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(clusterRoleBinding), clusterRoleBinding); err != nil {
 		if !apierr.IsNotFound(err) {
-			return fmt.Errorf("unable to get cluster role binding: %w", err)
+			return nil, fmt.Errorf("unable to get cluster role binding: %w", err)
 		}
 		update = false
 	}
 
+	clusterRoleBinding.OwnerReferences = []metav1.OwnerReference{generateClusterRoleOwnerReference(clusterRole)}
+
 	clusterRoleBinding.RoleRef = rbacv1.RoleRef{
 		APIGroup: "rbac.authorization.k8s.io",
 		Kind:     "ClusterRole",
@@ -242,17 +608,170 @@ func createOrUpdateClusterRoleAndRoleBinding(ctx context.Context, uuid string, k
 	if update {
 		if err := k8sClient.Update(ctx, clusterRoleBinding); err != nil {
 			log.Error(err, "Unable to update ClusterRoleBinding")
-			return fmt.Errorf("unable to create clusterrole: %w", err)
+			return nil, fmt.Errorf("unable to create clusterrole: %w", err)
 		}
 		logutil.LogAPIResourceChangeEvent(clusterRoleBinding.Namespace, clusterRoleBinding.Name, clusterRoleBinding, logutil.ResourceModified, log)
 	} else {
 		if err := k8sClient.Create(ctx, clusterRoleBinding); err != nil {
 			log.Error(err, "Unable to create ClusterRoleBinding")
-			return fmt.Errorf("unable to create clusterrole: %w", err)
+			return nil, fmt.Errorf("unable to create clusterrole: %w", err)
 		}
 		logutil.LogAPIResourceChangeEvent(clusterRoleBinding.Namespace, clusterRoleBinding.Name, clusterRoleBinding, logutil.ResourceCreated, log)
 	}
 
+	return clusterRole, nil
+}
+
+// verifyNamespacesExist returns a NamespacesNotFoundError if any of the given Namespaces do not exist: a Role/RoleBinding
+// cannot be created in them.
+func verifyNamespacesExist(ctx context.Context, k8sClient client.Client, namespaces []string) error {
+
+	var missingNamespaces []string
+
+	for _, namespaceName := range namespaces {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespaceName}}
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(namespace), namespace); err != nil {
+			if !apierr.IsNotFound(err) {
+				return fmt.Errorf("unable to get namespace '%s': %w", namespaceName, err)
+			}
+			missingNamespaces = append(missingNamespaces, namespaceName)
+		}
+	}
+
+	if len(missingNamespaces) > 0 {
+		return NamespacesNotFoundError{Namespaces: missingNamespaces}
+	}
+
+	return nil
+}
+
+// createOrUpdateRolesAndRoleBindings ensures that a Role/RoleBinding, granting the given rules to the ServiceAccount, exists in
+// each of the given namespaces. Roles/RoleBindings that were previously created for the ServiceAccount, in namespaces that are
+// no longer in the list, are deleted.
+func createOrUpdateRolesAndRoleBindings(ctx context.Context, uuid string, k8sClient client.Client,
+	serviceAccountName string, serviceAccountNamespace string, namespaces []string, rules []rbacv1.PolicyRule,
+	ownerRefs []metav1.OwnerReference, log logr.Logger) error {
+
+	expectedNamespaces := map[string]bool{}
+
+	for _, namespace := range namespaces {
+
+		expectedNamespaces[namespace] = true
+
+		role := &rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      ArgoCDManagerRoleNamePrefix + uuid,
+				Namespace: namespace,
+			},
+		}
+		if err := createOrUpdateObject(ctx, k8sClient, role, func() {
+			role.Labels = map[string]string{ArgoCDManagerUIDLabel: uuid}
+			role.OwnerReferences = ownerRefs
+			role.Rules = rules
+		}, log); err != nil {
+			return fmt.Errorf("unable to create or update role in namespace '%s': %w", namespace, err)
+		}
+
+		roleBinding := &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      ArgoCDManagerRoleBindingNamePrefix + uuid,
+				Namespace: namespace,
+			},
+		}
+		if err := createOrUpdateObject(ctx, k8sClient, roleBinding, func() {
+			roleBinding.Labels = map[string]string{ArgoCDManagerUIDLabel: uuid}
+			roleBinding.OwnerReferences = ownerRefs
+			roleBinding.RoleRef = rbacv1.RoleRef{
+				APIGroup: "rbac.authorization.k8s.io",
+				Kind:     "Role",
+				Name:     role.Name,
+			}
+			roleBinding.Subjects = []rbacv1.Subject{{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      serviceAccountName,
+				Namespace: serviceAccountNamespace,
+			}}
+		}, log); err != nil {
+			return fmt.Errorf("unable to create or update role binding in namespace '%s': %w", namespace, err)
+		}
+	}
+
+	// Delete the Roles/RoleBindings of namespaces that are no longer in the list
+	var roleBindingList rbacv1.RoleBindingList
+	if err := k8sClient.List(ctx, &roleBindingList, client.MatchingLabels{ArgoCDManagerUIDLabel: uuid}); err != nil {
+		return fmt.Errorf("unable to list role bindings: %w", err)
+	}
+	for idx := range roleBindingList.Items {
+		roleBinding := roleBindingList.Items[idx]
+		if expectedNamespaces[roleBinding.Namespace] {
+			continue
+		}
+		if err := deleteObjectIfExists(ctx, k8sClient, &roleBinding, log); err != nil {
+			return fmt.Errorf("unable to delete role binding in namespace '%s': %w", roleBinding.Namespace, err)
+		}
+	}
+
+	var roleList rbacv1.RoleList
+	if err := k8sClient.List(ctx, &roleList, client.MatchingLabels{ArgoCDManagerUIDLabel: uuid}); err != nil {
+		return fmt.Errorf("unable to list roles: %w", err)
+	}
+	for idx := range roleList.Items {
+		role := roleList.Items[idx]
+		if expectedNamespaces[role.Namespace] {
+			continue
+		}
+		if err := deleteObjectIfExists(ctx, k8sClient, &role, log); err != nil {
+			return fmt.Errorf("unable to delete role in namespace '%s': %w", role.Namespace, err)
+		}
+	}
+
+	return nil
+}
+
+// createOrUpdateObject retrieves the given object, calls mutate to set the expected values, and then creates or updates it.
+func createOrUpdateObject(ctx context.Context, k8sClient client.Client, obj client.Object, mutate func(), log logr.Logger) error {
+
+	log = log.WithValues("name", obj.GetName(), "namespace", obj.GetNamespace())
+
+	exists := true
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+		if !apierr.IsNotFound(err) {
+			return err
+		}
+		exists = false
+	}
+
+	mutate()
+
+	if exists {
+		if err := k8sClient.Update(ctx, obj); err != nil {
+			log.Error(err, "Unable to update resource")
+			return err
+		}
+		logutil.LogAPIResourceChangeEvent(obj.GetNamespace(), obj.GetName(), obj, logutil.ResourceModified, log)
+	} else {
+		if err := k8sClient.Create(ctx, obj); err != nil {
+			log.Error(err, "Unable to create resource")
+			return err
+		}
+		logutil.LogAPIResourceChangeEvent(obj.GetNamespace(), obj.GetName(), obj, logutil.ResourceCreated, log)
+	}
+
+	return nil
+}
+
+// deleteObjectIfExists deletes the given object, ignoring the error if it no longer exists.
+func deleteObjectIfExists(ctx context.Context, k8sClient client.Client, obj client.Object, log logr.Logger) error {
+
+	if err := k8sClient.Delete(ctx, obj); err != nil {
+		if apierr.IsNotFound(err) {
+			return nil
+		}
+		log.Error(err, "Unable to delete resource", "name", obj.GetName(), "namespace", obj.GetNamespace())
+		return err
+	}
+	logutil.LogAPIResourceChangeEvent(obj.GetNamespace(), obj.GetName(), obj, logutil.ResourceDeleted, log)
+
 	return nil
 }
 
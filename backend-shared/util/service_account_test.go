@@ -2,16 +2,25 @@ package util
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -122,7 +131,7 @@ var _ = Describe("Service Account Tests", func() {
 			It("Should pass.", func() {
 				// namespaces := []string{}
 				uuid := "my-uuid"
-				token, sa, err := InstallServiceAccount(ctx, k8sClient, uuid, "kube-system" /*namespaces,*/, log)
+				token, sa, err := InstallServiceAccount(ctx, k8sClient, uuid, "kube-system", ServiceAccountRBACOptions{}, log)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(token).ToNot(BeEmpty())
 				Expect(sa).ToNot(BeNil())
@@ -152,7 +161,7 @@ var _ = Describe("Service Account Tests", func() {
 
 				By("check if a new token secret is created")
 				if secret == nil {
-					token, sa, err := InstallServiceAccount(ctx, k8sClient, uuid, serviceAccountNS, log)
+					token, sa, err := InstallServiceAccount(ctx, k8sClient, uuid, serviceAccountNS, ServiceAccountRBACOptions{}, log)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(token).ToNot(BeEmpty())
 					Expect(sa).ToNot(BeNil())
@@ -178,4 +187,358 @@ var _ = Describe("Service Account Tests", func() {
 			})
 		})
 	})
+
+	Context("Service account RBAC tests", func() {
+
+		ctx := context.Background()
+		log := log.FromContext(ctx)
+
+		const (
+			uuid               = "my-uuid"
+			serviceAccountName = ArgoCDManagerServiceAccountPrefix + uuid
+			serviceAccountNS   = "kube-system"
+		)
+
+		var k8sClient client.Client
+
+		BeforeEach(func() {
+			k8sClient = fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		})
+
+		DescribeTable("ServiceAccountRBACOptions should only grant the rules at cluster scope if namespaces is empty, or clusterResources is true",
+			func(options ServiceAccountRBACOptions, expectedClusterRoleRules []rbacv1.PolicyRule) {
+				Expect(options.clusterRoleRules()).To(Equal(expectedClusterRoleRules))
+			},
+			Entry("default rules, at cluster scope", ServiceAccountRBACOptions{}, ArgoCDManagerNamespacePolicyRules),
+			Entry("user-supplied rules, at cluster scope",
+				ServiceAccountRBACOptions{Rules: []rbacv1.PolicyRule{{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"*"}}}},
+				[]rbacv1.PolicyRule{{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"*"}}}),
+			Entry("namespaces with cluster resources", ServiceAccountRBACOptions{Namespaces: []string{"a"}, ClusterResources: true}, ArgoCDManagerNamespacePolicyRules),
+			Entry("namespaces without cluster resources", ServiceAccountRBACOptions{Namespaces: []string{"a", "b"}},
+				[]rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"namespaces"}, ResourceNames: []string{"a", "b"}, Verbs: []string{"get"}}}),
+			Entry("namespaces without cluster resources, resolved from a namespace selector", ServiceAccountRBACOptions{Namespaces: []string{"a"}, ListNamespaces: true},
+				[]rbacv1.PolicyRule{
+					{APIGroups: []string{""}, Resources: []string{"namespaces"}, ResourceNames: []string{"a"}, Verbs: []string{"get"}},
+					{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"list"}},
+				}),
+		)
+
+		It("should create a Role/RoleBinding in each namespace, and delete those of namespaces that are no longer in the list", func() {
+
+			rules := []rbacv1.PolicyRule{{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"*"}}}
+
+			By("creating roles in namespaces 'a' and 'b'")
+			err := createOrUpdateRolesAndRoleBindings(ctx, uuid, k8sClient, serviceAccountName, serviceAccountNS, []string{"a", "b"}, rules, nil, log)
+			Expect(err).ToNot(HaveOccurred())
+
+			for _, namespace := range []string{"a", "b"} {
+				role := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: ArgoCDManagerRoleNamePrefix + uuid, Namespace: namespace}}
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(role), role)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(role.Rules).To(Equal(rules))
+				Expect(role.Labels[ArgoCDManagerUIDLabel]).To(Equal(uuid))
+
+				roleBinding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: ArgoCDManagerRoleBindingNamePrefix + uuid, Namespace: namespace}}
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(roleBinding), roleBinding)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(roleBinding.RoleRef.Kind).To(Equal("Role"))
+				Expect(roleBinding.RoleRef.Name).To(Equal(role.Name))
+				Expect(roleBinding.Subjects).To(Equal([]rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: serviceAccountName, Namespace: serviceAccountNS}}))
+			}
+
+			By("updating the rules, and removing namespace 'a' from the list")
+			rules = []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}}}
+			err = createOrUpdateRolesAndRoleBindings(ctx, uuid, k8sClient, serviceAccountName, serviceAccountNS, []string{"b"}, rules, nil, log)
+			Expect(err).ToNot(HaveOccurred())
+
+			role := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: ArgoCDManagerRoleNamePrefix + uuid, Namespace: "b"}}
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(role), role)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(role.Rules).To(Equal(rules))
+
+			var roleList rbacv1.RoleList
+			err = k8sClient.List(ctx, &roleList, client.MatchingLabels{ArgoCDManagerUIDLabel: uuid})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(roleList.Items).To(HaveLen(1))
+			Expect(roleList.Items[0].Namespace).To(Equal("b"))
+
+			var roleBindingList rbacv1.RoleBindingList
+			err = k8sClient.List(ctx, &roleBindingList, client.MatchingLabels{ArgoCDManagerUIDLabel: uuid})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(roleBindingList.Items).To(HaveLen(1))
+			Expect(roleBindingList.Items[0].Namespace).To(Equal("b"))
+
+			By("removing all namespaces from the list")
+			err = createOrUpdateRolesAndRoleBindings(ctx, uuid, k8sClient, serviceAccountName, serviceAccountNS, nil, rules, nil, log)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = k8sClient.List(ctx, &roleList, client.MatchingLabels{ArgoCDManagerUIDLabel: uuid})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(roleList.Items).To(BeEmpty())
+		})
+
+		It("should update the ClusterRole and Roles in UpdateServiceAccountRBAC, without creating a token Secret", func() {
+
+			for _, namespace := range []string{"a", "b"} {
+				err := k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			err := UpdateServiceAccountRBAC(ctx, k8sClient, uuid, serviceAccountNS, ServiceAccountRBACOptions{Namespaces: []string{"a"}}, log)
+			Expect(err).ToNot(HaveOccurred())
+
+			options := ServiceAccountRBACOptions{Namespaces: []string{"b"}, ListNamespaces: true}
+			err = UpdateServiceAccountRBAC(ctx, k8sClient, uuid, serviceAccountNS, options, log)
+			Expect(err).ToNot(HaveOccurred())
+
+			clusterRole := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: ArgoCDManagerClusterRoleNamePrefix + uuid}}
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(clusterRole), clusterRole)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(clusterRole.Rules).To(Equal(options.installedClusterRoleRules(uuid)))
+
+			var roleList rbacv1.RoleList
+			err = k8sClient.List(ctx, &roleList, client.MatchingLabels{ArgoCDManagerUIDLabel: uuid})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(roleList.Items).To(HaveLen(1))
+			Expect(roleList.Items[0].Namespace).To(Equal("b"))
+			Expect(roleList.Items[0].OwnerReferences).To(Equal([]metav1.OwnerReference{generateClusterRoleOwnerReference(clusterRole)}))
+
+			var secretList corev1.SecretList
+			err = k8sClient.List(ctx, &secretList)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(secretList.Items).To(BeEmpty())
+		})
+
+		It("should not change the permissions in UpdateServiceAccountRBAC, if a namespace does not exist", func() {
+
+			err := k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "a"}})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = UpdateServiceAccountRBAC(ctx, k8sClient, uuid, serviceAccountNS, ServiceAccountRBACOptions{Namespaces: []string{"a"}}, log)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = UpdateServiceAccountRBAC(ctx, k8sClient, uuid, serviceAccountNS, ServiceAccountRBACOptions{Namespaces: []string{"a", "b", "c"}}, log)
+			Expect(err).To(Equal(NamespacesNotFoundError{Namespaces: []string{"b", "c"}}))
+
+			clusterRole := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: ArgoCDManagerClusterRoleNamePrefix + uuid}}
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(clusterRole), clusterRole)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(clusterRole.Rules).To(Equal(ServiceAccountRBACOptions{Namespaces: []string{"a"}}.installedClusterRoleRules(uuid)))
+
+			var roleList rbacv1.RoleList
+			err = k8sClient.List(ctx, &roleList, client.MatchingLabels{ArgoCDManagerUIDLabel: uuid})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(roleList.Items).To(HaveLen(1))
+			Expect(roleList.Items[0].Namespace).To(Equal("a"))
+		})
+
+		It("should delete the token Secrets of the ServiceAccount in DeleteServiceAccountTokenSecrets, including those with a generated name", func() {
+
+			tokenSecret, err := createServiceAccountTokenSecret(ctx, k8sClient, serviceAccountName, serviceAccountNS, nil, log)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tokenSecret.Name).To(Equal(serviceAccountName + argoCDManagerTokenSecretNameSuffix))
+
+			By("creating the same token Secret again, which should reuse the existing Secret")
+			_, err = createServiceAccountTokenSecret(ctx, k8sClient, serviceAccountName, serviceAccountNS, nil, log)
+			Expect(err).ToNot(HaveOccurred())
+
+			legacyTokenSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        serviceAccountName + "abcde",
+					Namespace:   serviceAccountNS,
+					Annotations: map[string]string{corev1.ServiceAccountNameKey: serviceAccountName},
+				},
+				Type: corev1.SecretTypeServiceAccountToken,
+			}
+			err = k8sClient.Create(ctx, legacyTokenSecret)
+			Expect(err).ToNot(HaveOccurred())
+
+			otherTokenSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "other-token",
+					Namespace:   serviceAccountNS,
+					Annotations: map[string]string{corev1.ServiceAccountNameKey: "other"},
+				},
+				Type: corev1.SecretTypeServiceAccountToken,
+			}
+			err = k8sClient.Create(ctx, otherTokenSecret)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = DeleteServiceAccountTokenSecrets(ctx, k8sClient, uuid, serviceAccountNS, log)
+			Expect(err).ToNot(HaveOccurred())
+
+			var secretList corev1.SecretList
+			err = k8sClient.List(ctx, &secretList)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(secretList.Items).To(HaveLen(1))
+			Expect(secretList.Items[0].Name).To(Equal(otherTokenSecret.Name))
+
+			By("deleting the token Secrets a second time, which should be a no-op")
+			err = DeleteServiceAccountTokenSecrets(ctx, k8sClient, uuid, serviceAccountNS, log)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should update the rules of the ClusterRole, when they change", func() {
+
+			_, err := createOrUpdateClusterRoleAndRoleBinding(ctx, uuid, k8sClient, serviceAccountName, serviceAccountNS,
+				ArgoCDManagerNamespacePolicyRules, log)
+			Expect(err).ToNot(HaveOccurred())
+
+			options := ServiceAccountRBACOptions{Namespaces: []string{"a"}}
+			_, err = createOrUpdateClusterRoleAndRoleBinding(ctx, uuid, k8sClient, serviceAccountName, serviceAccountNS,
+				options.clusterRoleRules(), log)
+			Expect(err).ToNot(HaveOccurred())
+
+			clusterRole := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: ArgoCDManagerClusterRoleNamePrefix + uuid}}
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(clusterRole), clusterRole)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(clusterRole.Rules).To(Equal(options.clusterRoleRules()))
+		})
+
+		It("should make the ClusterRole the owner of the other resources, so that UninstallServiceAccount can remove them", func() {
+
+			clusterRole, err := createOrUpdateClusterRoleAndRoleBinding(ctx, uuid, k8sClient, serviceAccountName, serviceAccountNS,
+				selfManagementPolicyRules(uuid), log)
+			Expect(err).ToNot(HaveOccurred())
+
+			expectedOwnerRefs := []metav1.OwnerReference{{
+				APIVersion: "rbac.authorization.k8s.io/v1",
+				Kind:       "ClusterRole",
+				Name:       ArgoCDManagerClusterRoleNamePrefix + uuid,
+				UID:        clusterRole.UID,
+			}}
+
+			clusterRoleBinding := &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: ArgoCDManagerClusterRoleBindingNamePrefix + uuid}}
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(clusterRoleBinding), clusterRoleBinding)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(clusterRoleBinding.OwnerReferences).To(Equal(expectedOwnerRefs))
+
+			err = createOrUpdateRolesAndRoleBindings(ctx, uuid, k8sClient, serviceAccountName, serviceAccountNS, []string{"a"},
+				ArgoCDManagerNamespacePolicyRules, expectedOwnerRefs, log)
+			Expect(err).ToNot(HaveOccurred())
+
+			role := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: ArgoCDManagerRoleNamePrefix + uuid, Namespace: "a"}}
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(role), role)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(role.OwnerReferences).To(Equal(expectedOwnerRefs))
+
+			roleBinding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: ArgoCDManagerRoleBindingNamePrefix + uuid, Namespace: "a"}}
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(roleBinding), roleBinding)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(roleBinding.OwnerReferences).To(Equal(expectedOwnerRefs))
+
+			tokenSecret, err := createServiceAccountTokenSecret(ctx, k8sClient, serviceAccountName, serviceAccountNS, expectedOwnerRefs, log)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tokenSecret.OwnerReferences).To(Equal(expectedOwnerRefs))
+
+			By("uninstalling the service account, which should delete the ClusterRole")
+			err = UninstallServiceAccount(ctx, k8sClient, uuid, serviceAccountNS, log)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(clusterRole), clusterRole)
+			Expect(apierr.IsNotFound(err)).To(BeTrue())
+
+			By("uninstalling the service account a second time, which should be a no-op")
+			err = UninstallServiceAccount(ctx, k8sClient, uuid, serviceAccountNS, log)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should make the ClusterRole the owner of the resources of a ServiceAccount installed by an earlier version, when uninstalling it", func() {
+
+			By("creating the resources of a ServiceAccount as installed by an earlier version, without owners")
+			clusterRole := &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{Name: ArgoCDManagerClusterRoleNamePrefix + uuid, UID: "cluster-role-uid"},
+				Rules:      ArgoCDManagerNamespacePolicyRules,
+			}
+			clusterRoleBinding := &rbacv1.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: ArgoCDManagerClusterRoleBindingNamePrefix + uuid},
+			}
+			serviceAccount := &corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{Name: serviceAccountName, Namespace: serviceAccountNS},
+			}
+			tokenSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        serviceAccountName + "abcde",
+					Namespace:   serviceAccountNS,
+					Annotations: map[string]string{corev1.ServiceAccountNameKey: serviceAccountName},
+				},
+				Type: corev1.SecretTypeServiceAccountToken,
+			}
+			for _, obj := range []client.Object{clusterRole, clusterRoleBinding, serviceAccount, tokenSecret} {
+				err := k8sClient.Create(ctx, obj)
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			err := adoptLegacyServiceAccountResources(ctx, k8sClient, clusterRole, uuid, serviceAccountNS, log)
+			Expect(err).ToNot(HaveOccurred())
+
+			for _, obj := range []client.Object{clusterRoleBinding, serviceAccount, tokenSecret} {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(obj.GetOwnerReferences()).To(Equal([]metav1.OwnerReference{generateClusterRoleOwnerReference(clusterRole)}))
+			}
+
+			By("uninstalling the service account, which should delete the ClusterRole")
+			err = UninstallServiceAccount(ctx, k8sClient, uuid, serviceAccountNS, log)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(clusterRole), clusterRole)
+			Expect(apierr.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("Test RequestServiceAccountToken", func() {
+
+		ctx := context.Background()
+
+		serviceAccountName := GenerateServiceAccountName("test-uid")
+		serviceAccountNS := "kube-system"
+
+		It("should issue a token for the ServiceAccount, using the TokenRequest API", func() {
+
+			expirationTimestamp := metav1.NewTime(time.Now().Add(time.Hour).Truncate(time.Second))
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer GinkgoRecover()
+
+				Expect(r.Method).To(Equal(http.MethodPost))
+				Expect(r.URL.Path).To(Equal("/api/v1/namespaces/" + serviceAccountNS + "/serviceaccounts/" + serviceAccountName + "/token"))
+				Expect(r.Header.Get("Authorization")).To(Equal("Bearer current-token"))
+
+				tokenRequest := authenticationv1.TokenRequest{}
+				Expect(json.NewDecoder(r.Body).Decode(&tokenRequest)).To(Succeed())
+				Expect(tokenRequest.Spec.ExpirationSeconds).ToNot(BeNil())
+				Expect(*tokenRequest.Spec.ExpirationSeconds).To(Equal(int64(3600)))
+
+				tokenRequest.Status = authenticationv1.TokenRequestStatus{
+					Token:               "renewed-token",
+					ExpirationTimestamp: expirationTimestamp,
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				Expect(json.NewEncoder(w).Encode(tokenRequest)).To(Succeed())
+			}))
+			defer server.Close()
+
+			token, expiration, err := RequestServiceAccountToken(ctx, &rest.Config{Host: server.URL, BearerToken: "current-token"},
+				serviceAccountName, serviceAccountNS, time.Hour)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(token).To(Equal("renewed-token"))
+			Expect(expiration.Equal(expirationTimestamp.Time)).To(BeTrue())
+		})
+
+		It("should return an error if the token could not be issued", func() {
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			}))
+			defer server.Close()
+
+			token, _, err := RequestServiceAccountToken(ctx, &rest.Config{Host: server.URL, BearerToken: "expired-token"},
+				serviceAccountName, serviceAccountNS, time.Hour)
+			Expect(err).To(HaveOccurred())
+			Expect(apierr.IsUnauthorized(err)).To(BeTrue())
+			Expect(token).To(BeEmpty())
+		})
+	})
 })
@@ -104,7 +104,9 @@ func (dppelp *DefaultPreProcessEventLoopProcessor) callPreprocessEventLoopForMan
 // SetupWithManager sets up the controller with the Manager.
 func (r *GitOpsDeploymentManagedEnvironmentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment{}).
+		For(&managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment{},
+			// Ignore updates to the status (for example, by the periodic ManagedEnvironment reconcile), which do not change the generation
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Watches(
 			&source.Kind{Type: &corev1.Secret{}},
 			handler.EnqueueRequestsFromMapFunc(r.findSecretsForManagedEnvironment),
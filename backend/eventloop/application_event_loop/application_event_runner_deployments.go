@@ -42,7 +42,7 @@ const (
 	deploymentModifiedResult_Updated  deploymentModifiedResult = "updatedApp"
 	deploymentModifiedResult_NoChange deploymentModifiedResult = "noChangeInApp"
 
-	prunePropagationPolicy = "PrunePropagationPolicy=background"
+	prunePropagationPolicy = string(managedgitopsv1alpha1.SyncOptions_PrunePropagationPolicy_background)
 	appProjectPrefix       = "app-project-"
 )
 
@@ -111,17 +111,15 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleDeploym
 
 	if !isGitOpsDeploymentDeleted(gitopsDeployment) {
 		// Perform basic validation of GitOpsDeployment values
-
-		if gitopsDeployment.Spec.Source.Path == "" {
-			userError := managedgitopsv1alpha1.GitOpsDeploymentUserError_PathIsRequired
+		if userError := validateGitOpsDeploymentSources(gitopsDeployment.Spec); userError != "" {
 			return signalledShutdown_false, nil, nil, deploymentModifiedResult_Failed,
 				gitopserrors.NewUserDevError(userError, fmt.Errorf(userError))
+		}
 
-		} else if gitopsDeployment.Spec.Source.Path == "/" {
-			userError := managedgitopsv1alpha1.GitOpsDeploymentUserError_InvalidPathSlash
+		if err := managedgitopsv1alpha1.ValidateIgnoreDifferences(gitopsDeployment.Spec.IgnoreDifferences); err != nil {
+			userError := managedgitopsv1alpha1.GitOpsDeploymentUserError_InvalidIgnoreDifferences
 			return signalledShutdown_false, nil, nil, deploymentModifiedResult_Failed,
-				gitopserrors.NewUserDevError(userError, fmt.Errorf(userError))
-
+				gitopserrors.NewUserDevError(userError, err)
 		}
 	}
 
@@ -286,6 +284,10 @@ func (a applicationEventLoopRunner_Action) handleNewGitOpsDeplEvent(ctx context.
 		sourceRepoURL:        gitopsDeployment.Spec.Source.RepoURL,
 		sourcePath:           gitopsDeployment.Spec.Source.Path,
 		sourceTargetRevision: gitopsDeployment.Spec.Source.TargetRevision,
+		sourceHelm:           gitopsDeployment.Spec.Source.Helm,
+		sourceKustomize:      gitopsDeployment.Spec.Source.Kustomize,
+		sources:              gitopsDeployment.Spec.Sources,
+		ignoreDifferences:    gitopsDeployment.Spec.IgnoreDifferences,
 		// syncOptions:       if non-empty, it gets updated below.
 		automated: strings.EqualFold(gitopsDeployment.Spec.Type, managedgitopsv1alpha1.GitOpsDeploymentSpecType_Automated),
 		project:   appProjectPrefix + clusterUser.Clusteruser_id,
@@ -307,6 +309,11 @@ func (a applicationEventLoopRunner_Action) handleNewGitOpsDeplEvent(ctx context.
 
 	}
 
+	if gitopsDeployment.Spec.SyncPolicy != nil {
+		specFieldInput.syncPolicyAutomated = gitopsDeployment.Spec.SyncPolicy.Automated
+		specFieldInput.retry = gitopsDeployment.Spec.SyncPolicy.Retry
+	}
+
 	specFieldText, err := createSpecField(specFieldInput)
 	if err != nil {
 		a.log.Error(err, "SEVERE: unable to marshal generated YAML")
@@ -653,6 +660,10 @@ func (a applicationEventLoopRunner_Action) handleUpdatedGitOpsDeplEvent(ctx cont
 		sourceRepoURL:        gitopsDeployment.Spec.Source.RepoURL,
 		sourcePath:           gitopsDeployment.Spec.Source.Path,
 		sourceTargetRevision: gitopsDeployment.Spec.Source.TargetRevision,
+		sourceHelm:           gitopsDeployment.Spec.Source.Helm,
+		sourceKustomize:      gitopsDeployment.Spec.Source.Kustomize,
+		sources:              gitopsDeployment.Spec.Sources,
+		ignoreDifferences:    gitopsDeployment.Spec.IgnoreDifferences,
 		// syncOptions:       if non-empty, it gets updated below.
 		automated: strings.EqualFold(gitopsDeployment.Spec.Type, managedgitopsv1alpha1.GitOpsDeploymentSpecType_Automated),
 		project:   appProjectPrefix + clusterUser.Clusteruser_id,
@@ -671,6 +682,11 @@ func (a applicationEventLoopRunner_Action) handleUpdatedGitOpsDeplEvent(ctx cont
 		specFieldInput.syncOptions = managedgitopsv1alpha1.SyncOptionToStringSlice(gitopsDeployment.Spec.SyncPolicy.SyncOptions)
 	}
 
+	if gitopsDeployment.Spec.SyncPolicy != nil {
+		specFieldInput.syncPolicyAutomated = gitopsDeployment.Spec.SyncPolicy.Automated
+		specFieldInput.retry = gitopsDeployment.Spec.SyncPolicy.Retry
+	}
+
 	shouldUpdateApplication := false
 
 	if appProjectDBRowsUpdated {
@@ -1016,30 +1032,7 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleUpdateD
 	gitopsDeployment.Status.Sync.Revision = appStatus.Sync.Revision
 
 	// We update the GitopsDeployment .status.conditions with the conditions from the Argo CD Application, if the conditions column of ApplicationState row is non empty.
-	newGitopsDeplConditions := []managedgitopsv1alpha1.GitOpsDeploymentCondition{}
-	if len(appStatus.Conditions) != 0 {
-		for _, cond := range appStatus.Conditions {
-			newGitopsDeplConditions = append(newGitopsDeplConditions, managedgitopsv1alpha1.GitOpsDeploymentCondition{
-				Type:    managedgitopsv1alpha1.GitOpsDeploymentConditionType(cond.Type),
-				Message: cond.Message,
-				Reason:  managedgitopsv1alpha1.GitOpsDeploymentReasonType(cond.Type),
-			})
-		}
-	}
-
-	conditionManager := condition.NewConditionManager()
-	for _, c := range newGitopsDeplConditions {
-		// If the new condition already exists, then update it with the latest values.
-		conditionManager.SetCondition(&gitopsDeployment.Status.Conditions, c.Type, managedgitopsv1alpha1.GitOpsConditionStatusTrue, managedgitopsv1alpha1.GitOpsDeploymentReasonType(c.Type), c.Message)
-	}
-
-	// Go through the existing conditions and check if they are present in the list of new conditions. If they are absent then it can marked as resolved.
-	for _, c := range gitopsDeployment.Status.Conditions {
-		reason := c.Type + "Resolved"
-		if !conditionManager.HasCondition(&newGitopsDeplConditions, c.Type) && c.Reason != managedgitopsv1alpha1.GitOpsDeploymentReasonType(reason) {
-			conditionManager.SetCondition(&gitopsDeployment.Status.Conditions, c.Type, managedgitopsv1alpha1.GitOpsConditionStatusFalse, managedgitopsv1alpha1.GitOpsDeploymentReasonType(reason), "")
-		}
-	}
+	updateConditionsFromArgoCDConditions(&gitopsDeployment.Status.Conditions, appStatus.Conditions, condition.NewConditionManager())
 
 	gitopsDeployment.Status.Resources = extractResourceStatus(appStatus.Resources)
 
@@ -1049,6 +1042,8 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleUpdateD
 		return crUpdated_false, err
 	}
 
+	gitopsDeployment.Status.History = extractRevisionHistory(appStatus.History, appStatus.OperationState, gitopsDeployment.Status.History)
+
 	comparedTo := appStatus.Sync.ComparedTo
 
 	// If the `comparedTo` value from Argo CD has a non-empty destination name field, then retrieve the corresponding `GitOpsDeploymentManagedEnvironment` resource that has that name,
@@ -1073,6 +1068,15 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleUpdateD
 	gitopsDeployment.Status.ReconciledState.Source.Path = comparedTo.Source.Path
 	gitopsDeployment.Status.ReconciledState.Source.RepoURL = comparedTo.Source.RepoURL
 	gitopsDeployment.Status.ReconciledState.Source.Branch = comparedTo.Source.TargetRevision
+	gitopsDeployment.Status.ReconciledState.Sources = nil
+	for _, source := range comparedTo.Sources {
+		gitopsDeployment.Status.ReconciledState.Sources = append(gitopsDeployment.Status.ReconciledState.Sources,
+			managedgitopsv1alpha1.GitOpsDeploymentSource{
+				Path:    source.Path,
+				RepoURL: source.RepoURL,
+				Branch:  source.TargetRevision,
+			})
+	}
 	gitopsDeployment.Status.ReconciledState.Destination.Name = comparedTo.Destination.Name
 	gitopsDeployment.Status.ReconciledState.Destination.Namespace = comparedTo.Destination.Namespace
 
@@ -1094,6 +1098,87 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleUpdateD
 
 }
 
+// argoCDApplicationConditions maps the condition types of an Argo CD Application to the condition types, and reasons,
+// of the GitOpsDeployment.
+var argoCDApplicationConditions = map[fauxargocd.ApplicationConditionType]struct {
+	conditionType managedgitopsv1alpha1.GitOpsDeploymentConditionType
+	reason        managedgitopsv1alpha1.GitOpsDeploymentReasonType
+}{
+	fauxargocd.ApplicationConditionComparisonError:         {managedgitopsv1alpha1.GitOpsDeploymentConditionComparisonError, managedgitopsv1alpha1.GitopsDeploymentReasonComparisonError},
+	fauxargocd.ApplicationConditionInvalidSpecError:        {managedgitopsv1alpha1.GitOpsDeploymentConditionInvalidSpecError, managedgitopsv1alpha1.GitopsDeploymentReasonInvalidSpecError},
+	fauxargocd.ApplicationConditionSyncError:               {managedgitopsv1alpha1.GitOpsDeploymentConditionSyncError, managedgitopsv1alpha1.GitopsDeploymentReasonSyncError},
+	fauxargocd.ApplicationConditionDeletionError:           {managedgitopsv1alpha1.GitOpsDeploymentConditionDeletionError, managedgitopsv1alpha1.GitopsDeploymentReasonDeletionError},
+	fauxargocd.ApplicationConditionUnknownError:            {managedgitopsv1alpha1.GitOpsDeploymentConditionUnknownError, managedgitopsv1alpha1.GitopsDeploymentReasonUnknownError},
+	fauxargocd.ApplicationConditionSharedResourceWarning:   {managedgitopsv1alpha1.GitOpsDeploymentConditionSharedResourceWarning, managedgitopsv1alpha1.GitopsDeploymentReasonSharedResourceWarning},
+	fauxargocd.ApplicationConditionRepeatedResourceWarning: {managedgitopsv1alpha1.GitOpsDeploymentConditionRepeatedResourceWarning, managedgitopsv1alpha1.GitopsDeploymentReasonRepeatedResourceWarning},
+	fauxargocd.ApplicationConditionExcludedResourceWarning: {managedgitopsv1alpha1.GitOpsDeploymentConditionExcludedResourceWarning, managedgitopsv1alpha1.GitopsDeploymentReasonExcludedResourceWarning},
+	fauxargocd.ApplicationConditionOrphanedResourceWarning: {managedgitopsv1alpha1.GitOpsDeploymentConditionOrphanedResourceWarning, managedgitopsv1alpha1.GitopsDeploymentReasonOrphanedResourceWarning},
+}
+
+// updateConditionsFromArgoCDConditions updates the GitOpsDeployment conditions to reflect the conditions of the Argo CD Application:
+// - Argo CD conditions are set to True, with the message of the Argo CD condition. If Argo CD reports more than one condition of the same type, the messages are combined.
+// - Conditions that Argo CD no longer reports are set to False, with a '(type)Resolved' reason.
+// - Conditions that are set by the GitOps Service itself (ErrorOccurred) are left unchanged.
+func updateConditionsFromArgoCDConditions(conditions *[]managedgitopsv1alpha1.GitOpsDeploymentCondition,
+	argoCDConditions []fauxargocd.ApplicationCondition, conditionManager condition.Conditions) {
+
+	newGitopsDeplConditions := []managedgitopsv1alpha1.GitOpsDeploymentCondition{}
+	for _, argoCDCondition := range argoCDConditions {
+
+		conditionType := managedgitopsv1alpha1.GitOpsDeploymentConditionType(argoCDCondition.Type)
+		reason := managedgitopsv1alpha1.GitOpsDeploymentReasonType(argoCDCondition.Type)
+		if mapping, exists := argoCDApplicationConditions[argoCDCondition.Type]; exists {
+			conditionType = mapping.conditionType
+			reason = mapping.reason
+		}
+
+		if existing, exists := conditionManager.FindCondition(&newGitopsDeplConditions, conditionType); exists {
+			existing.Message = existing.Message + "; " + argoCDCondition.Message
+			if argoCDCondition.LastTransitionTime != nil && (existing.LastTransitionTime == nil || existing.LastTransitionTime.Before(argoCDCondition.LastTransitionTime)) {
+				existing.LastTransitionTime = argoCDCondition.LastTransitionTime
+			}
+		} else {
+			existing.Message = argoCDCondition.Message
+			existing.Reason = reason
+			existing.LastTransitionTime = argoCDCondition.LastTransitionTime
+		}
+	}
+
+	for _, c := range newGitopsDeplConditions {
+
+		// If the condition is already up to date, there is nothing to do: this avoids updating the GitOpsDeployment on every tick.
+		if conditionManager.HasCondition(conditions, c.Type) {
+			if existing, _ := conditionManager.FindCondition(conditions, c.Type); existing.Status == managedgitopsv1alpha1.GitOpsConditionStatusTrue &&
+				existing.Reason == c.Reason && existing.Message == c.Message {
+				continue
+			}
+		}
+
+		// If the new condition already exists, then update it with the latest values.
+		conditionManager.SetCondition(conditions, c.Type, managedgitopsv1alpha1.GitOpsConditionStatusTrue, c.Reason, c.Message)
+
+		// Prefer the time at which Argo CD observed the condition, if available
+		if c.LastTransitionTime != nil {
+			if updated, _ := conditionManager.FindCondition(conditions, c.Type); updated != nil {
+				updated.LastTransitionTime = c.LastTransitionTime.DeepCopy()
+			}
+		}
+	}
+
+	// Go through the existing conditions and check if they are present in the list of new conditions. If they are absent then it can marked as resolved.
+	for _, c := range *conditions {
+
+		if c.Type == managedgitopsv1alpha1.GitOpsDeploymentConditionErrorOccurred {
+			continue
+		}
+
+		reason := c.Type + "Resolved"
+		if !conditionManager.HasCondition(&newGitopsDeplConditions, c.Type) && c.Reason != managedgitopsv1alpha1.GitOpsDeploymentReasonType(reason) {
+			conditionManager.SetCondition(conditions, c.Type, managedgitopsv1alpha1.GitOpsConditionStatusFalse, managedgitopsv1alpha1.GitOpsDeploymentReasonType(reason), "")
+		}
+	}
+}
+
 // gitOpsDeploymentAdapter is an "adapter" for GitOpsDeployment allowing you to easily plug any other related
 // API component (i.e. for adding Conditions, look at setGitOpsDeploymentCondition() method)
 // Same principle can be used for others, e.g. Finalizers, or any other field which is part of the GitOpsDeployment CRD
@@ -1172,27 +1257,90 @@ func (g *gitOpsDeploymentAdapter) setGitOpsDeploymentCondition(conditionType man
 	return nil
 }
 
+// validateGitOpsDeploymentSources performs basic validation of the source(s) of a GitOpsDeployment, returning a user
+// error if a source is invalid, or "" otherwise.
+func validateGitOpsDeploymentSources(spec managedgitopsv1alpha1.GitOpsDeploymentSpec) string {
+
+	sources := []managedgitopsv1alpha1.ApplicationSource{spec.Source}
+
+	if spec.HasMultipleSources() {
+		if !reflect.DeepEqual(spec.Source, managedgitopsv1alpha1.ApplicationSource{}) {
+			return managedgitopsv1alpha1.GitOpsDeploymentUserError_SourceAndSources
+		}
+		sources = spec.Sources
+	}
+
+	for _, source := range sources {
+
+		// A Helm chart sourced from a Helm repository has no path, so the path is only required for non-chart sources.
+		// Likewise, a source of a multi-source GitOpsDeployment may only be referenced by other sources (via ref).
+		isHelmChartSource := source.Helm != nil && source.Helm.Chart != ""
+		isRefOnlySource := spec.HasMultipleSources() && source.Ref != ""
+
+		if source.Path == "" && !isHelmChartSource && !isRefOnlySource {
+			return managedgitopsv1alpha1.GitOpsDeploymentUserError_PathIsRequired
+
+		} else if source.Path == "/" {
+			return managedgitopsv1alpha1.GitOpsDeploymentUserError_InvalidPathSlash
+
+		} else if source.Helm != nil && !managedgitopsv1alpha1.IsValidHelmValues(source.Helm.Values) {
+			return managedgitopsv1alpha1.GitOpsDeploymentUserError_InvalidHelmValues
+
+		} else if source.Helm != nil && source.Kustomize != nil {
+			return managedgitopsv1alpha1.GitOpsDeploymentUserError_HelmAndKustomize
+
+		}
+	}
+
+	return ""
+}
+
 func checkValidSyncOption(syncOptions []managedgitopsv1alpha1.SyncOption) gitopserrors.UserError {
 
-	for _, syncOptionString := range syncOptions {
+	if err := managedgitopsv1alpha1.ValidateSyncOptions(syncOptions); err != nil {
+		devError := fmt.Errorf("invalid SyncOptions %v: %v", syncOptions, err)
+
+		return gitopserrors.NewUserDevError(err.Error(), devError)
+	}
+
+	return nil
+}
 
-		match := true
+// mergeSyncOptions returns the sync options of the Argo CD Application: the sync options that GitOpsDeployment sets by
+// default, overridden by any user-specified sync option with the same key, followed by the remaining user-specified sync options.
+// - An error is returned if a user-specified sync option is specified more than once.
+func mergeSyncOptions(defaultSyncOptions []string, userSyncOptions []string) ([]string, error) {
 
-		// Check for SyncOption string
-		if !(syncOptionString == managedgitopsv1alpha1.SyncOptions_CreateNamespace_true ||
-			syncOptionString == managedgitopsv1alpha1.SyncOptions_CreateNamespace_false) {
-			match = false
+	userSyncOptionsByKey := map[string]string{}
+	for _, syncOption := range userSyncOptions {
+		key := managedgitopsv1alpha1.SyncOption(syncOption).Key()
+		if _, exists := userSyncOptionsByKey[key]; exists {
+			return nil, fmt.Errorf("sync option '%s' is specified more than once", key)
 		}
+		userSyncOptionsByKey[key] = syncOption
+	}
+
+	res := []string{}
+	defaultKeys := map[string]bool{}
 
-		// If at least one of the options don't match, return a user  error
-		if !match {
-			userError := "the specified sync option in .spec.syncPolicy.syncOptions is either mispelled or is not supported by GitOpsDeployment"
-			devError := fmt.Errorf("invalid SyncOption : %s", syncOptionString)
+	for _, syncOption := range defaultSyncOptions {
+		key := managedgitopsv1alpha1.SyncOption(syncOption).Key()
+		defaultKeys[key] = true
 
-			return gitopserrors.NewUserDevError(userError, devError)
+		if userSyncOption, exists := userSyncOptionsByKey[key]; exists {
+			res = append(res, userSyncOption)
+		} else {
+			res = append(res, syncOption)
 		}
 	}
-	return nil
+
+	for _, syncOption := range userSyncOptions {
+		if !defaultKeys[managedgitopsv1alpha1.SyncOption(syncOption).Key()] {
+			res = append(res, syncOption)
+		}
+	}
+
+	return res, nil
 }
 
 type argoCDSpecInput struct {
@@ -1206,9 +1354,19 @@ type argoCDSpecInput struct {
 	sourceRepoURL        string
 	sourcePath           string
 	sourceTargetRevision string
-	syncOptions          []string
+	sourceHelm           *managedgitopsv1alpha1.ApplicationSourceHelm
+	sourceKustomize      *managedgitopsv1alpha1.ApplicationSourceKustomize
+	// MAKE SURE YOU SANITIZE ANY NEW FIELDS THAT ARE ADDED!!!!
+	// sources is only set for multi-source GitOpsDeployments, in which case the single source fields above are empty.
+	sources     []managedgitopsv1alpha1.ApplicationSource
+	syncOptions []string
+	// MAKE SURE YOU SANITIZE ANY NEW FIELDS THAT ARE ADDED!!!!
+	ignoreDifferences []managedgitopsv1alpha1.ResourceIgnoreDifferences
 	// MAKE SURE YOU SANITIZE ANY NEW FIELDS THAT ARE ADDED!!!!
 	automated bool
+	// syncPolicyAutomated and retry are optional: if nil, the defaults of the GitOpsDeployment API are used.
+	syncPolicyAutomated *managedgitopsv1alpha1.SyncPolicyAutomated
+	retry               *managedgitopsv1alpha1.RetryStrategy
 	// MAKE SURE YOU SANITIZE ANY NEW FIELDS THAT ARE ADDED!!!!
 	project string
 
@@ -1238,6 +1396,189 @@ func createSpecField(fieldsParam argoCDSpecInput) (string, error) {
 		return res
 	}
 
+	// jq path expressions require double quotes for string literals, so they are kept (they are safely quoted when
+	// the Application is marshalled to YAML), but the other characters stripped by 'sanitize' are still removed.
+	sanitizeJQPathExpressions := func(input []string) []string {
+		res := []string{}
+		for _, jqPathExpression := range input {
+			for _, char := range []string{"'", "`", "\r", "\n", "&", ";", "%"} {
+				jqPathExpression = strings.ReplaceAll(jqPathExpression, char, "")
+			}
+			res = append(res, jqPathExpression)
+		}
+		return res
+	}
+
+	// sanitizeHelmValue removes the characters stripped by 'sanitize' from the keys and string values of a parsed
+	// Helm values document.
+	var sanitizeHelmValue func(input interface{}) interface{}
+	sanitizeHelmValue = func(input interface{}) interface{} {
+		switch value := input.(type) {
+		case string:
+			return sanitize(value)
+		case map[interface{}]interface{}:
+			res := map[interface{}]interface{}{}
+			for key, item := range value {
+				res[sanitizeHelmValue(key)] = sanitizeHelmValue(item)
+			}
+			return res
+		case []interface{}:
+			res := []interface{}{}
+			for _, item := range value {
+				res = append(res, sanitizeHelmValue(item))
+			}
+			return res
+		}
+		return input
+	}
+
+	// Helm values are a YAML document, and thus can't be sanitized as a whole, as the YAML syntax relies on some of the
+	// characters stripped by 'sanitize' (such as newlines): instead, we parse them, sanitize each key and value, and
+	// re-marshal them, which ensures the result only ever contains YAML data.
+	sanitizeHelmValues := func(input string) (string, error) {
+		if strings.TrimSpace(input) == "" {
+			return "", nil
+		}
+
+		values := map[interface{}]interface{}{}
+		if err := goyaml.Unmarshal([]byte(input), &values); err != nil {
+			return "", fmt.Errorf("unable to parse helm values: %v", err)
+		}
+
+		valuesBytes, err := goyaml.Marshal(sanitizeHelmValue(values))
+		if err != nil {
+			return "", fmt.Errorf("unable to marshal helm values: %v", err)
+		}
+
+		return string(valuesBytes), nil
+	}
+
+	sanitizeHelm := func(input *managedgitopsv1alpha1.ApplicationSourceHelm) (*managedgitopsv1alpha1.ApplicationSourceHelm, error) {
+		if input == nil {
+			return nil, nil
+		}
+
+		values, err := sanitizeHelmValues(input.Values)
+		if err != nil {
+			return nil, err
+		}
+
+		res := &managedgitopsv1alpha1.ApplicationSourceHelm{
+			Chart:           sanitize(input.Chart),
+			ReleaseName:     sanitize(input.ReleaseName),
+			Values:          values,
+			PassCredentials: input.PassCredentials,
+		}
+
+		if len(input.ValueFiles) > 0 {
+			res.ValueFiles = sanitizeArray(input.ValueFiles)
+		}
+
+		for _, param := range input.Parameters {
+			res.Parameters = append(res.Parameters, managedgitopsv1alpha1.HelmParameter{
+				Name:        sanitize(param.Name),
+				Value:       sanitize(param.Value),
+				ForceString: param.ForceString,
+			})
+		}
+
+		return res, nil
+	}
+
+	sourceHelm, err := sanitizeHelm(fieldsParam.sourceHelm)
+	if err != nil {
+		return "", err
+	}
+
+	sanitizeMap := func(input map[string]string) map[string]string {
+		if len(input) == 0 {
+			return nil
+		}
+		res := map[string]string{}
+		for key, value := range input {
+			res[sanitize(key)] = sanitize(value)
+		}
+		return res
+	}
+
+	sanitizeKustomize := func(input *managedgitopsv1alpha1.ApplicationSourceKustomize) *managedgitopsv1alpha1.ApplicationSourceKustomize {
+		if input == nil {
+			return nil
+		}
+
+		res := &managedgitopsv1alpha1.ApplicationSourceKustomize{
+			NamePrefix:        sanitize(input.NamePrefix),
+			NameSuffix:        sanitize(input.NameSuffix),
+			CommonLabels:      sanitizeMap(input.CommonLabels),
+			CommonAnnotations: sanitizeMap(input.CommonAnnotations),
+			Namespace:         sanitize(input.Namespace),
+		}
+
+		if len(input.Images) > 0 {
+			res.Images = sanitizeArray(input.Images)
+		}
+
+		return res
+	}
+
+	sourceKustomize := sanitizeKustomize(fieldsParam.sourceKustomize)
+
+	// Apply the defaults to any unspecified automated sync policy fields, which matches the behaviour of the webhook.
+	syncPolicyAutomated := &managedgitopsv1alpha1.SyncPolicyAutomated{}
+	if fieldsParam.syncPolicyAutomated != nil {
+		syncPolicyAutomated = fieldsParam.syncPolicyAutomated.DeepCopy()
+	}
+	syncPolicyAutomated.SetDefaults()
+
+	var retry *managedgitopsv1alpha1.RetryStrategy
+	if fieldsParam.retry != nil {
+		retry = &managedgitopsv1alpha1.RetryStrategy{
+			Limit: fieldsParam.retry.Limit,
+		}
+		if fieldsParam.retry.Backoff != nil {
+			retry.Backoff = &managedgitopsv1alpha1.Backoff{
+				Duration:    sanitize(fieldsParam.retry.Backoff.Duration),
+				Factor:      fieldsParam.retry.Backoff.Factor,
+				MaxDuration: sanitize(fieldsParam.retry.Backoff.MaxDuration),
+			}
+		}
+	} else if fieldsParam.automated {
+		retry = managedgitopsv1alpha1.DefaultRetryStrategy()
+	}
+
+	var ignoreDifferences []managedgitopsv1alpha1.ResourceIgnoreDifferences
+	for _, ignoreDifference := range fieldsParam.ignoreDifferences {
+		ignoreDifferences = append(ignoreDifferences, managedgitopsv1alpha1.ResourceIgnoreDifferences{
+			Group:                 sanitize(ignoreDifference.Group),
+			Kind:                  sanitize(ignoreDifference.Kind),
+			Name:                  sanitize(ignoreDifference.Name),
+			Namespace:             sanitize(ignoreDifference.Namespace),
+			JSONPointers:          sanitizeArray(ignoreDifference.JSONPointers),
+			JQPathExpressions:     sanitizeJQPathExpressions(ignoreDifference.JQPathExpressions),
+			ManagedFieldsManagers: sanitizeArray(ignoreDifference.ManagedFieldsManagers),
+		})
+	}
+
+	var sources []managedgitopsv1alpha1.ApplicationSource
+	for _, source := range fieldsParam.sources {
+
+		helm, err := sanitizeHelm(source.Helm)
+		if err != nil {
+			return "", err
+		}
+
+		kustomize := sanitizeKustomize(source.Kustomize)
+
+		sources = append(sources, managedgitopsv1alpha1.ApplicationSource{
+			RepoURL:        sanitize(source.RepoURL),
+			Path:           sanitize(source.Path),
+			TargetRevision: sanitize(source.TargetRevision),
+			Ref:            sanitize(source.Ref),
+			Helm:           helm,
+			Kustomize:      kustomize,
+		})
+	}
+
 	fields := argoCDSpecInput{
 		// MAKE SURE YOU SANITIZE ANY NEW FIELDS THAT ARE ADDED!!!!
 		crName:               sanitize(fieldsParam.crName),
@@ -1248,8 +1589,14 @@ func createSpecField(fieldsParam argoCDSpecInput) (string, error) {
 		sourceRepoURL:        sanitize(fieldsParam.sourceRepoURL),
 		sourcePath:           sanitize(fieldsParam.sourcePath),
 		sourceTargetRevision: sanitize(fieldsParam.sourceTargetRevision),
+		sourceHelm:           sourceHelm,
+		sourceKustomize:      sourceKustomize,
+		sources:              sources,
 		syncOptions:          sanitizeArray(fieldsParam.syncOptions),
+		ignoreDifferences:    ignoreDifferences,
 		automated:            fieldsParam.automated,
+		syncPolicyAutomated:  syncPolicyAutomated,
+		retry:                retry,
 		project:              sanitize(fieldsParam.project),
 		// MAKE SURE YOU SANITIZE ANY NEW FIELDS THAT ARE ADDED!!!!
 		// Hopefully you are getting the message, here :)
@@ -1278,24 +1625,41 @@ func createSpecField(fieldsParam argoCDSpecInput) (string, error) {
 		},
 	}
 
+	if fields.sourceHelm != nil {
+		application.Spec.Source.Chart = fields.sourceHelm.Chart
+		application.Spec.Source.Helm = convertHelmToFauxHelm(*fields.sourceHelm)
+	}
+
+	if fields.sourceKustomize != nil {
+		application.Spec.Source.Kustomize = convertKustomizeToFauxKustomize(*fields.sourceKustomize)
+	}
+
+	for _, source := range fields.sources {
+		application.Spec.Sources = append(application.Spec.Sources, convertSourceToFauxSource(source))
+	}
+
+	for _, ignoreDifference := range fields.ignoreDifferences {
+		application.Spec.IgnoreDifferences = append(application.Spec.IgnoreDifferences, fauxargocd.ResourceIgnoreDifferences{
+			Group:                 ignoreDifference.Group,
+			Kind:                  ignoreDifference.Kind,
+			Name:                  ignoreDifference.Name,
+			Namespace:             ignoreDifference.Namespace,
+			JSONPointers:          ignoreDifference.JSONPointers,
+			JQPathExpressions:     ignoreDifference.JQPathExpressions,
+			ManagedFieldsManagers: ignoreDifference.ManagedFieldsManagers,
+		})
+	}
+
 	if fields.automated {
 		application.Spec.SyncPolicy = &fauxargocd.SyncPolicy{
 			Automated: &fauxargocd.SyncPolicyAutomated{
-				Prune:      true,
-				SelfHeal:   true,
-				AllowEmpty: true,
+				Prune:      *fields.syncPolicyAutomated.Prune,
+				SelfHeal:   *fields.syncPolicyAutomated.SelfHeal,
+				AllowEmpty: *fields.syncPolicyAutomated.AllowEmpty,
 			},
 			SyncOptions: fauxargocd.SyncOptions{
 				prunePropagationPolicy,
 			},
-			Retry: &fauxargocd.RetryStrategy{
-				Limit: -1,
-				Backoff: &fauxargocd.Backoff{
-					Duration:    "5s",
-					Factor:      getInt64Pointer(2),
-					MaxDuration: "3m",
-				},
-			},
 		}
 
 	} else {
@@ -1303,20 +1667,25 @@ func createSpecField(fieldsParam argoCDSpecInput) (string, error) {
 		application.Spec.SyncPolicy = nil
 	}
 
-	if len(fields.syncOptions) > 0 {
-
+	if fields.retry != nil {
 		if application.Spec.SyncPolicy == nil {
 			application.Spec.SyncPolicy = &fauxargocd.SyncPolicy{}
 		}
+		application.Spec.SyncPolicy.Retry = convertRetryToFauxRetry(*fields.retry)
+	}
 
-		if application.Spec.SyncPolicy.SyncOptions == nil {
-			application.Spec.SyncPolicy.SyncOptions = fauxargocd.SyncOptions{}
+	if len(fields.syncOptions) > 0 {
+
+		if application.Spec.SyncPolicy == nil {
+			application.Spec.SyncPolicy = &fauxargocd.SyncPolicy{}
 		}
 
-		for _, syncOptionString := range fields.syncOptions {
-			application.Spec.SyncPolicy.SyncOptions = append(application.Spec.SyncPolicy.SyncOptions,
-				syncOptionString)
+		// User-specified sync options override the sync options that are set by default (e.g. PrunePropagationPolicy)
+		syncOptions, err := mergeSyncOptions(application.Spec.SyncPolicy.SyncOptions, fields.syncOptions)
+		if err != nil {
+			return "", err
 		}
+		application.Spec.SyncPolicy.SyncOptions = syncOptions
 	}
 
 	resBytes, err := goyaml.Marshal(application)
@@ -1327,6 +1696,79 @@ func createSpecField(fieldsParam argoCDSpecInput) (string, error) {
 	return string(resBytes), nil
 }
 
+// convertRetryToFauxRetry converts the retry strategy of a GitOpsDeployment sync policy into the equivalent Argo CD Application field.
+func convertRetryToFauxRetry(retry managedgitopsv1alpha1.RetryStrategy) *fauxargocd.RetryStrategy {
+	res := &fauxargocd.RetryStrategy{
+		Limit: retry.Limit,
+	}
+
+	if retry.Backoff != nil {
+		res.Backoff = &fauxargocd.Backoff{
+			Duration:    retry.Backoff.Duration,
+			MaxDuration: retry.Backoff.MaxDuration,
+		}
+		if retry.Backoff.Factor != nil {
+			res.Backoff.Factor = getInt64Pointer(int(*retry.Backoff.Factor))
+		}
+	}
+
+	return res
+}
+
+// convertSourceToFauxSource converts a single source of a multi-source GitOpsDeployment into the equivalent Argo CD Application source.
+func convertSourceToFauxSource(source managedgitopsv1alpha1.ApplicationSource) fauxargocd.ApplicationSource {
+	res := fauxargocd.ApplicationSource{
+		RepoURL:        source.RepoURL,
+		Path:           source.Path,
+		TargetRevision: source.TargetRevision,
+		Ref:            source.Ref,
+	}
+
+	if source.Helm != nil {
+		res.Chart = source.Helm.Chart
+		res.Helm = convertHelmToFauxHelm(*source.Helm)
+	}
+
+	if source.Kustomize != nil {
+		res.Kustomize = convertKustomizeToFauxKustomize(*source.Kustomize)
+	}
+
+	return res
+}
+
+// convertHelmToFauxHelm converts the Helm options of a GitOpsDeployment source into the equivalent Argo CD Application source field.
+// The chart name is not part of the Argo CD Helm options, and so is not converted here.
+func convertHelmToFauxHelm(helm managedgitopsv1alpha1.ApplicationSourceHelm) *fauxargocd.ApplicationSourceHelm {
+	res := &fauxargocd.ApplicationSourceHelm{
+		ValueFiles:      helm.ValueFiles,
+		ReleaseName:     helm.ReleaseName,
+		Values:          helm.Values,
+		PassCredentials: helm.PassCredentials,
+	}
+
+	for _, param := range helm.Parameters {
+		res.Parameters = append(res.Parameters, fauxargocd.HelmParameter{
+			Name:        param.Name,
+			Value:       param.Value,
+			ForceString: param.ForceString,
+		})
+	}
+
+	return res
+}
+
+// convertKustomizeToFauxKustomize converts the Kustomize options of a GitOpsDeployment source into the equivalent Argo CD Application source field.
+func convertKustomizeToFauxKustomize(kustomize managedgitopsv1alpha1.ApplicationSourceKustomize) *fauxargocd.ApplicationSourceKustomize {
+	return &fauxargocd.ApplicationSourceKustomize{
+		NamePrefix:        kustomize.NamePrefix,
+		NameSuffix:        kustomize.NameSuffix,
+		Images:            kustomize.Images,
+		CommonLabels:      kustomize.CommonLabels,
+		CommonAnnotations: kustomize.CommonAnnotations,
+		Namespace:         kustomize.Namespace,
+	}
+}
+
 // Decompress byte array received from ApplicationState table and convert it into Application status.
 func decompressApplicationStatus(statusBytes []byte) (*fauxargocd.FauxApplicationStatus, error) {
 	appStatus := &fauxargocd.FauxApplicationStatus{}
@@ -1385,6 +1827,63 @@ func extractOperationState(fauxOpState *fauxargocd.OperationState) (*managedgito
 	return opState, nil
 }
 
+// extractRevisionHistory converts the history of the Argo CD Application into the .status.history field of the GitOpsDeployment,
+// keeping only the most recent GitOpsDeploymentHistoryLimit entries.
+//
+// Argo CD does not record who initiated each deployment, so the initiator is derived from the operation state of the
+// Application when the operation produced the history entry, and otherwise preserved from the previous status.
+func extractRevisionHistory(fauxHistory fauxargocd.RevisionHistories, fauxOpState *fauxargocd.OperationState,
+	existingHistory []managedgitopsv1alpha1.RevisionHistory) []managedgitopsv1alpha1.RevisionHistory {
+
+	if len(fauxHistory) > managedgitopsv1alpha1.GitOpsDeploymentHistoryLimit {
+		fauxHistory = fauxHistory[len(fauxHistory)-managedgitopsv1alpha1.GitOpsDeploymentHistoryLimit:]
+	}
+
+	existingInitiators := map[int64]*managedgitopsv1alpha1.OperationInitiator{}
+	for _, existing := range existingHistory {
+		existingInitiators[existing.ID] = existing.InitiatedBy
+	}
+
+	convertSource := func(source fauxargocd.ApplicationSource) managedgitopsv1alpha1.GitOpsDeploymentSource {
+		return managedgitopsv1alpha1.GitOpsDeploymentSource{
+			Path:    source.Path,
+			RepoURL: source.RepoURL,
+			Branch:  source.TargetRevision,
+		}
+	}
+
+	var res []managedgitopsv1alpha1.RevisionHistory
+
+	for _, fauxEntry := range fauxHistory {
+
+		entry := managedgitopsv1alpha1.RevisionHistory{
+			ID:          fauxEntry.ID,
+			Revision:    fauxEntry.Revision,
+			Revisions:   fauxEntry.Revisions,
+			DeployedAt:  fauxEntry.DeployedAt,
+			Source:      convertSource(fauxEntry.Source),
+			InitiatedBy: existingInitiators[fauxEntry.ID],
+		}
+
+		for _, source := range fauxEntry.Sources {
+			entry.Sources = append(entry.Sources, convertSource(source))
+		}
+
+		// The history entry was produced by the operation in the operation state if the operation started at the same time
+		if fauxOpState != nil && fauxEntry.DeployStartedAt != nil && fauxEntry.DeployStartedAt.Equal(&fauxOpState.StartedAt) {
+			initiatedBy := managedgitopsv1alpha1.OperationInitiator{
+				Username:  fauxOpState.Operation.InitiatedBy.Username,
+				Automated: fauxOpState.Operation.InitiatedBy.Automated,
+			}
+			entry.InitiatedBy = &initiatedBy
+		}
+
+		res = append(res, entry)
+	}
+
+	return res
+}
+
 func getInt64Pointer(i int) *int64 {
 	i64 := int64(i)
 	return &i64
@@ -13,6 +13,7 @@ import (
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/fauxargocd"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	"github.com/redhat-appstudio/managed-gitops/backend/condition"
 	"github.com/redhat-appstudio/managed-gitops/backend/eventloop/shared_resource_loop"
 	"gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
@@ -106,6 +107,25 @@ var _ = Describe("Application Event Runner Deployments", func() {
 			Expect(application).To(Equal(getValidApplication(false)))
 		})
 
+		It("should generate the same spec field for a single-source Application as before Helm, Kustomize and multiple sources were supported", func() {
+			// The spec field is compared against the Application in the database, so any change to the bytes of an
+			// existing spec field would cause every existing Application to be updated.
+			expectedSpecField := "fauxtypemeta:\n  kind: Application\n  apiversion: argoproj.io/v1alpha1\n" +
+				"fauxobjectmeta:\n  name: sample-depl\n  namespace: workspace\n" +
+				"spec:\n  source:\n    repourl: https://github.com/test/test\n    path: environments/prod\n    targetrevision: \"\"\n" +
+				"  destination:\n    server: \"\"\n    namespace: prod\n    name: in-cluster\n" +
+				"  project: app-project-cluster-user-id\n  syncpolicy: null\n" +
+				"status:\n  resources: []\n  sync:\n    status: \"\"\n    comparedto:\n" +
+				"      source:\n        repourl: \"\"\n        path: \"\"\n        targetrevision: \"\"\n" +
+				"      destination:\n        server: \"\"\n        namespace: \"\"\n        name: \"\"\n" +
+				"    revision: \"\"\n    revisions: []\n" +
+				"  health:\n    status: \"\"\n    message: \"\"\n  conditions: []\n  operationstate: null\n"
+
+			application, err := createSpecField(getFakeArgoCDSpecInput(false, false))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(application).To(Equal(expectedSpecField))
+		})
+
 		It("Sanitize illegal characters from input", func() {
 			input := getFakeArgoCDSpecInput(false, true)
 			application, err := createSpecField(input)
@@ -119,6 +139,548 @@ var _ = Describe("Application Event Runner Deployments", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(application).To(Equal(getValidApplication(true)))
 		})
+
+		It("Input spec with Helm options should set the chart and helm fields of the Application source", func() {
+			input := getFakeArgoCDSpecInput(false, false)
+			input.sourcePath = ""
+			input.sourceHelm = &managedgitopsv1alpha1.ApplicationSourceHelm{
+				Chart:       "my-chart",
+				ReleaseName: "my-release",
+				ValueFiles:  []string{"values-prod.yaml"},
+				Values:      "replicaCount: 2\n",
+				Parameters: []managedgitopsv1alpha1.HelmParameter{
+					{Name: "image.tag", Value: "v1.0.0", ForceString: true},
+				},
+				PassCredentials: true,
+			}
+
+			applicationStr, err := createSpecField(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			application := fauxargocd.FauxApplication{}
+			Expect(yaml.Unmarshal([]byte(applicationStr), &application)).To(Succeed())
+
+			Expect(application.Spec.Source.Chart).To(Equal("my-chart"))
+			Expect(application.Spec.Source.Helm).ToNot(BeNil())
+			Expect(application.Spec.Source.Helm.ReleaseName).To(Equal("my-release"))
+			Expect(application.Spec.Source.Helm.ValueFiles).To(Equal([]string{"values-prod.yaml"}))
+			Expect(application.Spec.Source.Helm.Values).To(Equal("replicaCount: 2\n"))
+			Expect(application.Spec.Source.Helm.Parameters).To(Equal([]fauxargocd.HelmParameter{
+				{Name: "image.tag", Value: "v1.0.0", ForceString: true},
+			}))
+			Expect(application.Spec.Source.Helm.PassCredentials).To(BeTrue())
+		})
+
+		It("Sanitize illegal characters from Helm options, and re-serialize Helm values", func() {
+			input := getFakeArgoCDSpecInput(false, false)
+			input.sourceHelm = &managedgitopsv1alpha1.ApplicationSourceHelm{
+				Chart:       "my-chart;\n",
+				ReleaseName: "my-`release`",
+				Values:      "replicaCount:   2\nname: 'my-app'\nimage:\n  tags: [\"v1;\", \"v2%\"]\n  \"repo&\": \"quay.io/`my-org`\"\n",
+				Parameters: []managedgitopsv1alpha1.HelmParameter{
+					{Name: "image.tag&", Value: "\"v1.0.0\""},
+				},
+			}
+
+			applicationStr, err := createSpecField(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			application := fauxargocd.FauxApplication{}
+			Expect(yaml.Unmarshal([]byte(applicationStr), &application)).To(Succeed())
+
+			Expect(application.Spec.Source.Chart).To(Equal("my-chart"))
+			Expect(application.Spec.Source.Helm.ReleaseName).To(Equal("my-release"))
+			Expect(application.Spec.Source.Helm.Values).To(Equal("image:\n  repo: quay.io/my-org\n  tags:\n  - v1\n  - v2\nname: my-app\nreplicaCount: 2\n"))
+			Expect(application.Spec.Source.Helm.Parameters).To(Equal([]fauxargocd.HelmParameter{
+				{Name: "image.tag", Value: "v1.0.0"},
+			}))
+		})
+
+		It("Should return an error if the Helm values are not a valid YAML document", func() {
+			input := getFakeArgoCDSpecInput(false, false)
+			input.sourceHelm = &managedgitopsv1alpha1.ApplicationSourceHelm{
+				Values: "- not\n- a map",
+			}
+
+			_, err := createSpecField(input)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Input spec with Kustomize options should set the kustomize field of the Application source", func() {
+			input := getFakeArgoCDSpecInput(false, false)
+			input.sourceKustomize = &managedgitopsv1alpha1.ApplicationSourceKustomize{
+				NamePrefix:        "dev-",
+				NameSuffix:        "-v1",
+				Images:            []string{"quay.io/my-org/my-image:v1.0.0"},
+				CommonLabels:      map[string]string{"env": "dev"},
+				CommonAnnotations: map[string]string{"owner": "team-a"},
+				Namespace:         "my-namespace",
+			}
+
+			applicationStr, err := createSpecField(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			application := fauxargocd.FauxApplication{}
+			Expect(yaml.Unmarshal([]byte(applicationStr), &application)).To(Succeed())
+
+			kustomize := application.Spec.Source.Kustomize
+			Expect(kustomize).ToNot(BeNil())
+			Expect(kustomize.NamePrefix).To(Equal("dev-"))
+			Expect(kustomize.NameSuffix).To(Equal("-v1"))
+			Expect(kustomize.Images).To(Equal([]string{"quay.io/my-org/my-image:v1.0.0"}))
+			Expect(kustomize.CommonLabels).To(Equal(map[string]string{"env": "dev"}))
+			Expect(kustomize.CommonAnnotations).To(Equal(map[string]string{"owner": "team-a"}))
+			Expect(kustomize.Namespace).To(Equal("my-namespace"))
+		})
+
+		It("Sanitize illegal characters from Kustomize options", func() {
+			input := getFakeArgoCDSpecInput(false, false)
+			input.sourceKustomize = &managedgitopsv1alpha1.ApplicationSourceKustomize{
+				NamePrefix:   "dev-;",
+				Images:       []string{"quay.io/my-org/my-image:v1.0.0`"},
+				CommonLabels: map[string]string{"env\n": "'dev'"},
+			}
+
+			applicationStr, err := createSpecField(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			application := fauxargocd.FauxApplication{}
+			Expect(yaml.Unmarshal([]byte(applicationStr), &application)).To(Succeed())
+
+			kustomize := application.Spec.Source.Kustomize
+			Expect(kustomize.NamePrefix).To(Equal("dev-"))
+			Expect(kustomize.Images).To(Equal([]string{"quay.io/my-org/my-image:v1.0.0"}))
+			Expect(kustomize.CommonLabels).To(Equal(map[string]string{"env": "dev"}))
+		})
+
+		It("Changing only the Kustomize options should change the generated spec field", func() {
+			input := getFakeArgoCDSpecInput(false, false)
+			input.sourceKustomize = &managedgitopsv1alpha1.ApplicationSourceKustomize{
+				Images: []string{"quay.io/my-org/my-image:v1.0.0"},
+			}
+
+			originalSpecField, err := createSpecField(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			input.sourceKustomize = &managedgitopsv1alpha1.ApplicationSourceKustomize{
+				Images: []string{"quay.io/my-org/my-image:v2.0.0"},
+			}
+
+			updatedSpecField, err := createSpecField(input)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(updatedSpecField).ToNot(Equal(originalSpecField))
+		})
+
+		It("Input spec with multiple sources should set the sources field of the Application, and omit the single source", func() {
+			input := getFakeArgoCDSpecInput(false, false)
+			input.sourceRepoURL = ""
+			input.sourcePath = ""
+			input.sources = []managedgitopsv1alpha1.ApplicationSource{
+				{
+					RepoURL: "https://github.com/test/values",
+					Ref:     "values;",
+				},
+				{
+					RepoURL:        "https://charts.example.com",
+					TargetRevision: "1.0.0",
+					Helm: &managedgitopsv1alpha1.ApplicationSourceHelm{
+						Chart:      "my-chart",
+						ValueFiles: []string{"$values/environments/dev/values.yaml"},
+					},
+				},
+			}
+
+			applicationStr, err := createSpecField(input)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(applicationStr).ToNot(ContainSubstring("\n  source:"), "the spec should not contain a single source")
+
+			application := fauxargocd.FauxApplication{}
+			Expect(yaml.Unmarshal([]byte(applicationStr), &application)).To(Succeed())
+
+			Expect(application.Spec.Source).To(Equal(fauxargocd.ApplicationSource{}))
+			Expect(application.Spec.Sources).To(Equal([]fauxargocd.ApplicationSource{
+				{
+					RepoURL: "https://github.com/test/values",
+					Ref:     "values",
+				},
+				{
+					RepoURL:        "https://charts.example.com",
+					TargetRevision: "1.0.0",
+					Chart:          "my-chart",
+					Helm: &fauxargocd.ApplicationSourceHelm{
+						ValueFiles: []string{"$values/environments/dev/values.yaml"},
+						Parameters: []fauxargocd.HelmParameter{},
+					},
+				},
+			}))
+		})
+
+		It("Input spec with an automated sync policy should override the default prune/selfHeal/allowEmpty values", func() {
+			input := getFakeArgoCDSpecInput(true, false)
+			prune, selfHeal := false, false
+			input.syncPolicyAutomated = &managedgitopsv1alpha1.SyncPolicyAutomated{
+				Prune:    &prune,
+				SelfHeal: &selfHeal,
+			}
+
+			applicationStr, err := createSpecField(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			application := fauxargocd.FauxApplication{}
+			Expect(yaml.Unmarshal([]byte(applicationStr), &application)).To(Succeed())
+
+			Expect(application.Spec.SyncPolicy.Automated).To(Equal(&fauxargocd.SyncPolicyAutomated{
+				Prune:      false,
+				SelfHeal:   false,
+				AllowEmpty: true,
+			}))
+
+			By("verifying the input was not modified by the defaulting")
+			Expect(input.syncPolicyAutomated.AllowEmpty).To(BeNil())
+		})
+
+		It("Input spec with a retry strategy should override the default retry strategy", func() {
+			input := getFakeArgoCDSpecInput(true, false)
+			factor := int64(3)
+			input.retry = &managedgitopsv1alpha1.RetryStrategy{
+				Limit: 5,
+				Backoff: &managedgitopsv1alpha1.Backoff{
+					Duration:    "10s;",
+					Factor:      &factor,
+					MaxDuration: "10m",
+				},
+			}
+
+			applicationStr, err := createSpecField(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			application := fauxargocd.FauxApplication{}
+			Expect(yaml.Unmarshal([]byte(applicationStr), &application)).To(Succeed())
+
+			Expect(application.Spec.SyncPolicy.Retry).To(Equal(&fauxargocd.RetryStrategy{
+				Limit: 5,
+				Backoff: &fauxargocd.Backoff{
+					Duration:    "10s",
+					Factor:      getInt64Pointer(3),
+					MaxDuration: "10m",
+				},
+			}))
+		})
+
+		It("Input spec with manual sync and a retry strategy should only set the retry strategy", func() {
+			input := getFakeArgoCDSpecInput(false, false)
+			input.retry = &managedgitopsv1alpha1.RetryStrategy{
+				Limit: 2,
+			}
+
+			applicationStr, err := createSpecField(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			application := fauxargocd.FauxApplication{}
+			Expect(yaml.Unmarshal([]byte(applicationStr), &application)).To(Succeed())
+
+			Expect(application.Spec.SyncPolicy).ToNot(BeNil())
+			Expect(application.Spec.SyncPolicy.Automated).To(BeNil())
+			Expect(application.Spec.SyncPolicy.Retry).To(Equal(&fauxargocd.RetryStrategy{Limit: 2}))
+		})
+
+		It("Input spec with a single source should not set the sources field of the Application", func() {
+			applicationStr, err := createSpecField(getFakeArgoCDSpecInput(false, false))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(applicationStr).To(ContainSubstring("\n  source:"))
+			Expect(applicationStr).ToNot(ContainSubstring("\n  sources:"), "the spec should not contain multiple sources")
+		})
+		It("Input spec with an automated sync and sync options should merge them with the default sync options", func() {
+			input := getFakeArgoCDSpecInput(true, false)
+			input.syncOptions = []string{
+				string(managedgitopsv1alpha1.SyncOptions_ServerSideApply_true),
+				string(managedgitopsv1alpha1.SyncOptions_PrunePropagationPolicy_foreground),
+			}
+
+			applicationStr, err := createSpecField(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			application := fauxargocd.FauxApplication{}
+			Expect(yaml.Unmarshal([]byte(applicationStr), &application)).To(Succeed())
+
+			By("verifying the user-specified PrunePropagationPolicy replaces the default, rather than being duplicated")
+			Expect(application.Spec.SyncPolicy.SyncOptions).To(Equal(fauxargocd.SyncOptions{
+				string(managedgitopsv1alpha1.SyncOptions_PrunePropagationPolicy_foreground),
+				string(managedgitopsv1alpha1.SyncOptions_ServerSideApply_true),
+			}))
+		})
+
+		It("Input spec with ignoreDifferences should set the ignoreDifferences field, keeping the jq syntax intact", func() {
+			input := getFakeArgoCDSpecInput(true, false)
+			input.ignoreDifferences = []managedgitopsv1alpha1.ResourceIgnoreDifferences{
+				{
+					Group:                 "apps",
+					Kind:                  "Deployment",
+					Name:                  "my-deployment;",
+					JSONPointers:          []string{"/spec/replicas"},
+					JQPathExpressions:     []string{`.spec.template.spec.initContainers[] | select(.name == "istio-init")`, "'.spec`;\n"},
+					ManagedFieldsManagers: []string{"kube-controller-manager"},
+				},
+			}
+
+			applicationStr, err := createSpecField(input)
+			Expect(err).ToNot(HaveOccurred())
+
+			application := fauxargocd.FauxApplication{}
+			Expect(yaml.Unmarshal([]byte(applicationStr), &application)).To(Succeed())
+
+			Expect(application.Spec.IgnoreDifferences).To(Equal([]fauxargocd.ResourceIgnoreDifferences{
+				{
+					Group:                 "apps",
+					Kind:                  "Deployment",
+					Name:                  "my-deployment",
+					JSONPointers:          []string{"/spec/replicas"},
+					JQPathExpressions:     []string{`.spec.template.spec.initContainers[] | select(.name == "istio-init")`, ".spec"},
+					ManagedFieldsManagers: []string{"kube-controller-manager"},
+				},
+			}), "double quotes should be kept within jq path expressions, while other unsafe characters are removed")
+		})
+
+		It("Input spec without ignoreDifferences should not set the ignoreDifferences field", func() {
+			applicationStr, err := createSpecField(getFakeArgoCDSpecInput(true, false))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(applicationStr).ToNot(ContainSubstring("ignoredifferences"))
+		})
+
+		It("Input spec with a duplicate sync option should return an error", func() {
+			input := getFakeArgoCDSpecInput(false, false)
+			input.syncOptions = []string{
+				string(managedgitopsv1alpha1.SyncOptions_PruneLast_true),
+				string(managedgitopsv1alpha1.SyncOptions_PruneLast_true),
+			}
+
+			_, err := createSpecField(input)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("checkValidSyncOption should only accept supported sync options", func() {
+
+		It("should accept every supported sync option", func() {
+			Expect(checkValidSyncOption([]managedgitopsv1alpha1.SyncOption{
+				managedgitopsv1alpha1.SyncOptions_CreateNamespace_true,
+				managedgitopsv1alpha1.SyncOptions_ServerSideApply_true,
+				managedgitopsv1alpha1.SyncOptions_PruneLast_true,
+				managedgitopsv1alpha1.SyncOptions_ApplyOutOfSyncOnly_true,
+				managedgitopsv1alpha1.SyncOptions_Validate_false,
+				managedgitopsv1alpha1.SyncOptions_PrunePropagationPolicy_orphan,
+				managedgitopsv1alpha1.SyncOptions_RespectIgnoreDifferences_true,
+				managedgitopsv1alpha1.SyncOptions_FailOnSharedResource_true,
+			})).To(BeNil())
+		})
+
+		It("should reject unsupported, invalid, duplicate and conflicting sync options", func() {
+			for _, syncOptions := range [][]managedgitopsv1alpha1.SyncOption{
+				{"Unknown=true"},
+				{"CreateNamespace"},
+				{"CreateNamespace=foo"},
+				{"PrunePropagationPolicy=sometimes"},
+				{managedgitopsv1alpha1.SyncOptions_CreateNamespace_true, managedgitopsv1alpha1.SyncOptions_CreateNamespace_false},
+				{managedgitopsv1alpha1.SyncOptions_ServerSideApply_true, managedgitopsv1alpha1.SyncOptions_Replace_true},
+			} {
+				userErr := checkValidSyncOption(syncOptions)
+				Expect(userErr).ToNot(BeNil(), "sync options %v should be rejected", syncOptions)
+				Expect(userErr.UserError()).ToNot(BeEmpty())
+			}
+		})
+	})
+
+	Context("extractRevisionHistory should convert the history of the Argo CD Application into the GitOpsDeployment history", func() {
+
+		It("should keep the most recent entries, and record who initiated them", func() {
+			startTime := metav1.NewTime(time.Now().Truncate(time.Second))
+
+			var fauxHistory fauxargocd.RevisionHistories
+			for i := 1; i <= managedgitopsv1alpha1.GitOpsDeploymentHistoryLimit+2; i++ {
+				deployStartedAt := metav1.NewTime(startTime.Add(time.Duration(i) * time.Minute))
+				fauxHistory = append(fauxHistory, fauxargocd.RevisionHistory{
+					ID:              int64(i),
+					Revision:        fmt.Sprintf("revision-%d", i),
+					DeployStartedAt: &deployStartedAt,
+					DeployedAt:      metav1.NewTime(deployStartedAt.Add(time.Second)),
+					Source: fauxargocd.ApplicationSource{
+						RepoURL:        "https://github.com/test/test",
+						Path:           "environments/dev",
+						TargetRevision: "main",
+					},
+				})
+			}
+			lastEntry := fauxHistory[len(fauxHistory)-1]
+
+			opState := &fauxargocd.OperationState{
+				Operation: fauxargocd.Operation{
+					InitiatedBy: fauxargocd.OperationInitiator{Username: "gitops-service"},
+				},
+				Phase:     fauxargocd.OperationSucceeded,
+				StartedAt: *lastEntry.DeployStartedAt,
+			}
+
+			existingHistory := []managedgitopsv1alpha1.RevisionHistory{
+				{
+					ID:          lastEntry.ID - 1,
+					InitiatedBy: &managedgitopsv1alpha1.OperationInitiator{Automated: true},
+				},
+			}
+
+			history := extractRevisionHistory(fauxHistory, opState, existingHistory)
+			Expect(history).To(HaveLen(managedgitopsv1alpha1.GitOpsDeploymentHistoryLimit))
+			Expect(history[0].ID).To(Equal(int64(3)))
+
+			By("verifying the most recent entry was converted, and was initiated by the operation")
+			newest := history[len(history)-1]
+			Expect(newest.ID).To(Equal(lastEntry.ID))
+			Expect(newest.Revision).To(Equal(lastEntry.Revision))
+			Expect(newest.DeployedAt.Equal(&lastEntry.DeployedAt)).To(BeTrue())
+			Expect(newest.Source).To(Equal(managedgitopsv1alpha1.GitOpsDeploymentSource{
+				RepoURL: "https://github.com/test/test",
+				Path:    "environments/dev",
+				Branch:  "main",
+			}))
+			Expect(newest.InitiatedBy).To(Equal(&managedgitopsv1alpha1.OperationInitiator{Username: "gitops-service"}))
+
+			By("verifying the initiator of a previous entry is preserved from the existing history")
+			Expect(history[len(history)-2].InitiatedBy).To(Equal(&managedgitopsv1alpha1.OperationInitiator{Automated: true}))
+
+			By("verifying the initiator is left empty if it is not known")
+			Expect(history[0].InitiatedBy).To(BeNil())
+
+			By("verifying an empty Argo CD history results in an empty GitOpsDeployment history")
+			Expect(extractRevisionHistory(nil, opState, history)).To(BeNil())
+		})
+	})
+
+	Context("updateConditionsFromArgoCDConditions should reflect the Argo CD Application conditions on the GitOpsDeployment", func() {
+
+		It("should set, preserve and resolve conditions as Argo CD reports and clears them", func() {
+			argoCDTransitionTime := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+
+			conditions := []managedgitopsv1alpha1.GitOpsDeploymentCondition{
+				{
+					Type:    managedgitopsv1alpha1.GitOpsDeploymentConditionErrorOccurred,
+					Status:  managedgitopsv1alpha1.GitOpsConditionStatusTrue,
+					Reason:  managedgitopsv1alpha1.GitopsDeploymentReasonErrorOccurred,
+					Message: "user error",
+				},
+			}
+
+			argoCDConditions := []fauxargocd.ApplicationCondition{
+				{
+					Type:               fauxargocd.ApplicationConditionComparisonError,
+					Message:            "unable to generate manifests",
+					LastTransitionTime: &argoCDTransitionTime,
+				},
+				{
+					Type:    fauxargocd.ApplicationConditionSharedResourceWarning,
+					Message: "resource is shared",
+				},
+				{
+					Type:    fauxargocd.ApplicationConditionSharedResourceWarning,
+					Message: "another resource is shared",
+				},
+			}
+
+			updateConditionsFromArgoCDConditions(&conditions, argoCDConditions, condition.NewConditionManager())
+
+			By("verifying the Argo CD conditions are mapped to GitOpsDeployment conditions, with a reason and last transition time")
+			Expect(conditions).To(HaveLen(3))
+
+			comparisonError, _ := condition.NewConditionManager().FindCondition(&conditions, managedgitopsv1alpha1.GitOpsDeploymentConditionComparisonError)
+			Expect(comparisonError.Status).To(Equal(managedgitopsv1alpha1.GitOpsConditionStatusTrue))
+			Expect(comparisonError.Reason).To(Equal(managedgitopsv1alpha1.GitopsDeploymentReasonComparisonError))
+			Expect(comparisonError.Message).To(Equal("unable to generate manifests"))
+			Expect(comparisonError.LastTransitionTime).To(Equal(&argoCDTransitionTime))
+
+			sharedResourceWarning, _ := condition.NewConditionManager().FindCondition(&conditions, managedgitopsv1alpha1.GitOpsDeploymentConditionSharedResourceWarning)
+			Expect(sharedResourceWarning.Status).To(Equal(managedgitopsv1alpha1.GitOpsConditionStatusTrue))
+			Expect(sharedResourceWarning.Reason).To(Equal(managedgitopsv1alpha1.GitopsDeploymentReasonSharedResourceWarning))
+			Expect(sharedResourceWarning.Message).To(Equal("resource is shared; another resource is shared"))
+			Expect(sharedResourceWarning.LastTransitionTime).ToNot(BeNil())
+
+			By("verifying the conditions are unchanged if the Argo CD conditions are unchanged")
+			conditionsBefore := []managedgitopsv1alpha1.GitOpsDeploymentCondition{}
+			for _, c := range conditions {
+				conditionsBefore = append(conditionsBefore, *c.DeepCopy())
+			}
+			updateConditionsFromArgoCDConditions(&conditions, argoCDConditions, condition.NewConditionManager())
+			Expect(conditions).To(Equal(conditionsBefore))
+
+			By("verifying the conditions are resolved once Argo CD clears them, while the ErrorOccurred condition is preserved")
+			updateConditionsFromArgoCDConditions(&conditions, []fauxargocd.ApplicationCondition{}, condition.NewConditionManager())
+
+			for _, c := range conditions {
+				if c.Type == managedgitopsv1alpha1.GitOpsDeploymentConditionErrorOccurred {
+					Expect(c.Status).To(Equal(managedgitopsv1alpha1.GitOpsConditionStatusTrue))
+					Expect(c.Message).To(Equal("user error"))
+					continue
+				}
+				Expect(c.Status).To(Equal(managedgitopsv1alpha1.GitOpsConditionStatusFalse))
+				Expect(c.Reason).To(Equal(managedgitopsv1alpha1.GitOpsDeploymentReasonType(c.Type + "Resolved")))
+				Expect(c.Message).To(BeEmpty())
+			}
+		})
+	})
+
+	Context("validateGitOpsDeploymentSources should perform basic validation of the GitOpsDeployment source(s)", func() {
+
+		It("should accept a valid single source", func() {
+			Expect(validateGitOpsDeploymentSources(managedgitopsv1alpha1.GitOpsDeploymentSpec{
+				Source: managedgitopsv1alpha1.ApplicationSource{
+					RepoURL: "https://github.com/test/test",
+					Path:    "environments/prod",
+				},
+			})).To(BeEmpty())
+		})
+
+		It("should reject a single source without a path, unless it is a Helm chart", func() {
+			spec := managedgitopsv1alpha1.GitOpsDeploymentSpec{
+				Source: managedgitopsv1alpha1.ApplicationSource{
+					RepoURL: "https://charts.example.com",
+				},
+			}
+			Expect(validateGitOpsDeploymentSources(spec)).To(Equal(managedgitopsv1alpha1.GitOpsDeploymentUserError_PathIsRequired))
+
+			spec.Source.Helm = &managedgitopsv1alpha1.ApplicationSourceHelm{Chart: "my-chart"}
+			Expect(validateGitOpsDeploymentSources(spec)).To(BeEmpty())
+		})
+
+		It("should reject a spec that sets both source and sources", func() {
+			Expect(validateGitOpsDeploymentSources(managedgitopsv1alpha1.GitOpsDeploymentSpec{
+				Source: managedgitopsv1alpha1.ApplicationSource{
+					RepoURL: "https://github.com/test/test",
+					Path:    "environments/prod",
+				},
+				Sources: managedgitopsv1alpha1.ApplicationSources{
+					{
+						RepoURL: "https://github.com/test/test",
+						Path:    "environments/prod",
+					},
+				},
+			})).To(Equal(managedgitopsv1alpha1.GitOpsDeploymentUserError_SourceAndSources))
+		})
+
+		It("should validate every source of a multi-source spec, allowing ref-only sources without a path", func() {
+			spec := managedgitopsv1alpha1.GitOpsDeploymentSpec{
+				Sources: managedgitopsv1alpha1.ApplicationSources{
+					{
+						RepoURL: "https://github.com/test/values",
+						Ref:     "values",
+					},
+					{
+						RepoURL: "https://github.com/test/test",
+						Path:    "environments/prod",
+					},
+				},
+			}
+			Expect(validateGitOpsDeploymentSources(spec)).To(BeEmpty())
+
+			spec.Sources[1].Path = "/"
+			Expect(validateGitOpsDeploymentSources(spec)).To(Equal(managedgitopsv1alpha1.GitOpsDeploymentUserError_InvalidPathSlash))
+		})
 	})
 })
 
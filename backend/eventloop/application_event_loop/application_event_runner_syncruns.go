@@ -3,12 +3,15 @@ package application_event_loop
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"strings"
 	"time"
 
 	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
 	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
 	dbutil "github.com/redhat-appstudio/managed-gitops/backend-shared/db/util"
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/fauxargocd"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/gitopserrors"
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/operations"
@@ -24,6 +27,10 @@ const (
 	ErrDeploymentNameIsImmutable = "deployment name field is immutable: changing it from its initial value is not supported"
 
 	ErrRevisionIsImmutable = "revision change is not supported: changing it from its initial value is not supported"
+
+	ErrRollbackIsImmutable = "rollback change is not supported: changing it from its initial value is not supported"
+
+	ErrSyncParametersAreImmutable = "prune, dryRun, force, syncStrategy and resources fields are immutable: changing them from their initial values is not supported"
 )
 
 // This file is responsible for processing events related to GitOpsDeploymentSyncRun CR.
@@ -295,6 +302,12 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleSyncRun
 			return gitopserrors.NewUserDevError(userErr, devErr)
 		}
 
+		if err := validateSyncRunSpec(syncRunCR.Spec); err != nil {
+			userErr := fmt.Sprintf("invalid GitOpsDeploymentSyncRun '%s': %v", syncRunCR.Name, err)
+			log.Error(err, "failed to process GitOpsDeploymentSyncRun")
+			return gitopserrors.NewUserDevError(userErr, err)
+		}
+
 		// The GitopsDepl CR exists, so use the UID of the CR to retrieve the database entry, if possible
 		deplToAppMapping := &db.DeploymentToApplicationMapping{Deploymenttoapplicationmapping_uid_id: string(gitopsDepl.UID)}
 
@@ -327,7 +340,19 @@ func (a *applicationEventLoopRunner_Action) applicationEventRunner_handleSyncRun
 			// have seen the GitOpsDeplSyncRun CR.
 			// Create it in the DB and create the operation.
 
-			return a.handleNewGitOpsDeplSyncRunEvent(ctx, syncRunCR, dbQueries, application, gitopsEngineInstance, namespace, *clusterUser)
+			// If the SyncRun is a rollback, locate the previous deployment to roll back to, in the history of the GitOpsDeployment
+			var rollbackTo *managedgitopsv1alpha1.RevisionHistory
+			if syncRunCR.Spec.Rollback != nil {
+				if rollbackTo = findRevisionHistoryForRollback(gitopsDepl.Status.History, *syncRunCR.Spec.Rollback); rollbackTo == nil {
+					userErr := fmt.Sprintf("invalid GitOpsDeploymentSyncRun '%s'. Unable to locate the deployment to roll back to, in the .status.history field of GitOpsDeployment '%s'",
+						syncRunCR.Name, gitopsDepl.Name)
+					devErr := fmt.Errorf(userErr)
+					log.Error(devErr, "failed to process GitOpsDeploymentSyncRun")
+					return gitopserrors.NewUserDevError(userErr, devErr)
+				}
+			}
+
+			return a.handleNewGitOpsDeplSyncRunEvent(ctx, syncRunCR, dbQueries, application, gitopsEngineInstance, namespace, *clusterUser, rollbackTo)
 		}
 
 	}
@@ -480,10 +505,11 @@ func (a *applicationEventLoopRunner_Action) handleDeletedGitOpsDeplSyncRunEvent(
 // In this case, we need to create SyncOperation and APICRToDBMapping rows in the database.
 //
 // Finally, we need to inform the cluster-agent component (via Operation), so that it can sync the Argo CD Application.
+// If 'rollbackTo' is non-nil, the cluster-agent will instead roll back the Argo CD Application to that deployment.
 //
 // Returns:
 // - error is non-nil, if an error occurred
-func (a *applicationEventLoopRunner_Action) handleNewGitOpsDeplSyncRunEvent(ctx context.Context, syncRunCRParam *managedgitopsv1alpha1.GitOpsDeploymentSyncRun, dbQueries db.ApplicationScopedQueries, application *db.Application, gitopsEngineInstance *db.GitopsEngineInstance, namespace corev1.Namespace, clusterUser db.ClusterUser) gitopserrors.UserError {
+func (a *applicationEventLoopRunner_Action) handleNewGitOpsDeplSyncRunEvent(ctx context.Context, syncRunCRParam *managedgitopsv1alpha1.GitOpsDeploymentSyncRun, dbQueries db.ApplicationScopedQueries, application *db.Application, gitopsEngineInstance *db.GitopsEngineInstance, namespace corev1.Namespace, clusterUser db.ClusterUser, rollbackTo *managedgitopsv1alpha1.RevisionHistory) gitopserrors.UserError {
 
 	log := a.log
 	log.Info("Received GitOpsDeploymentSyncRun event for a new GitOpsDeploymentSyncRun resource")
@@ -508,10 +534,24 @@ func (a *applicationEventLoopRunner_Action) handleNewGitOpsDeplSyncRunEvent(ctx
 		DeploymentNameField: syncRunCRParam.Spec.GitopsDeploymentName,
 		Revision:            syncRunCRParam.Spec.RevisionID,
 		DesiredState:        db.SyncOperation_DesiredState_Running,
+		Prune:               syncRunCRParam.Spec.Prune,
+		Dry_run:             syncRunCRParam.Spec.DryRun,
+		Force:               syncRunCRParam.Spec.Force,
+		Sync_strategy:       string(syncRunCRParam.Spec.SyncStrategy),
+		Resources:           db.EncodeSyncOperationResources(convertSyncRunResources(syncRunCRParam.Spec.Resources)),
+	}
+	if rollbackTo != nil {
+		syncOperation.Revision = db.TruncateVarchar(rollbackRevision(*rollbackTo), db.SyncOperationRevisionLength)
+		syncOperation.Rollback_history_id = &rollbackTo.ID
 	}
 	if err := dbQueries.CreateSyncOperation(ctx, syncOperation); err != nil {
 		log.Error(err, "unable to create sync operation in database")
 
+		if db.IsMaxLengthError(err) {
+			userErr := fmt.Sprintf("invalid GitOpsDeploymentSyncRun '%s': too many resources are specified in the resources field", syncRunCRParam.Name)
+			return gitopserrors.NewUserDevError(userErr, err)
+		}
+
 		return gitopserrors.NewDevOnlyError(err)
 	}
 	createdResources = append(createdResources, syncOperation)
@@ -591,6 +631,11 @@ outer_for:
 			break outer_for
 		}
 
+		// Report the progress of the operation in the status of the SyncRun CR
+		if err := a.updateSyncRunStatus(ctx, syncRunCRParam, *dbOperation, *syncOperation, dbQueries); err != nil {
+			log.Error(err, "unable to update the status of the GitOpsDeploymentSyncRun")
+		}
+
 		currentSyncRunCR := syncRunCRParam.DeepCopy()
 		if err := a.workspaceClient.Get(ctx, client.ObjectKeyFromObject(currentSyncRunCR), currentSyncRunCR); err != nil {
 
@@ -618,6 +663,17 @@ outer_for:
 
 	}
 
+	// The operation may have completed since its state was last read in the loop above (for example, if the loop exited
+	// on an error), so read it again before reporting it as the final state
+	if err := dbQueries.GetOperationById(ctx, dbOperation); err != nil {
+		log.Error(err, "unable to retrieve the final state of the operation")
+	}
+
+	// Report the final state of the operation in the status of the SyncRun CR
+	if err := a.updateSyncRunStatus(ctx, syncRunCRParam, *dbOperation, *syncOperation, dbQueries); err != nil {
+		log.Error(err, "unable to update the status of the GitOpsDeploymentSyncRun")
+	}
+
 	if err := operations.CleanupOperation(ctx, *dbOperation, *k8sOperation, dbQueries, operationClient, !a.testOnlySkipCreateOperation, log); err != nil {
 		return gitopserrors.NewDevOnlyError(err)
 	}
@@ -625,6 +681,124 @@ outer_for:
 	return nil
 }
 
+// updateSyncRunStatus updates the phase, timing and per-resource results in the status of the GitOpsDeploymentSyncRun,
+// based on the state of the Operation of its SyncOperation, and on the Argo CD operation state of the Application.
+func (a *applicationEventLoopRunner_Action) updateSyncRunStatus(ctx context.Context, syncRunCRParam *managedgitopsv1alpha1.GitOpsDeploymentSyncRun,
+	dbOperation db.Operation, syncOperation db.SyncOperation, dbQueries db.ApplicationScopedQueries) error {
+
+	syncRunCR, err := getGitOpsDeploymentSyncRun(ctx, a.workspaceClient, syncRunCRParam.Name, syncRunCRParam.Namespace)
+	if err != nil {
+		if apierr.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if syncRunCR.UID != syncRunCRParam.UID {
+		// The SyncRun CR was deleted and recreated, so the operation no longer belongs to it
+		return nil
+	}
+
+	// The SyncOperation may have been terminated while the operation was running, so use its current state
+	if err := dbQueries.GetSyncOperationById(ctx, &syncOperation); err != nil && !db.IsResultNotFoundError(err) {
+		return err
+	}
+
+	var appOpState *fauxargocd.OperationState
+
+	applicationState := db.ApplicationState{Applicationstate_application_id: syncOperation.Application_id}
+	if err := dbQueries.GetApplicationStateById(ctx, &applicationState); err != nil {
+		if !db.IsResultNotFoundError(err) {
+			return err
+		}
+	} else {
+		appStatus, err := decompressApplicationStatus(applicationState.ArgoCD_Application_Status)
+		if err != nil {
+			return err
+		}
+		appOpState = appStatus.OperationState
+	}
+
+	newStatus, err := computeSyncRunStatus(syncRunCR.Status, dbOperation.State, syncOperation, appOpState, metav1.Now())
+	if err != nil {
+		return err
+	}
+
+	if reflect.DeepEqual(newStatus, syncRunCR.Status) {
+		return nil
+	}
+
+	syncRunCR.Status = newStatus
+
+	return a.workspaceClient.Status().Update(ctx, syncRunCR)
+}
+
+// computeSyncRunStatus returns the status of a GitOpsDeploymentSyncRun, based on:
+// - the state of the Operation that was created for its SyncOperation
+// - the Argo CD operation state of the Application, if that operation was started by the SyncOperation
+//
+// Times that are not reported by Argo CD are set to 'now', when the phase of the SyncRun first changes.
+func computeSyncRunStatus(status managedgitopsv1alpha1.GitOpsDeploymentSyncRunStatus, operationState db.OperationState,
+	syncOperation db.SyncOperation, appOpState *fauxargocd.OperationState, now metav1.Time) (managedgitopsv1alpha1.GitOpsDeploymentSyncRunStatus, error) {
+
+	// Once terminated, a SyncRun remains terminated: Argo CD reports a terminated operation as failed
+	terminated := status.Phase == managedgitopsv1alpha1.SyncRunPhase_Terminated ||
+		syncOperation.DesiredState == db.SyncOperation_DesiredState_Terminated
+
+	switch operationState {
+	case db.OperationState_Waiting:
+		status.Phase = managedgitopsv1alpha1.SyncRunPhase_Pending
+	case db.OperationState_In_Progress:
+		status.Phase = managedgitopsv1alpha1.SyncRunPhase_Running
+	case db.OperationState_Completed:
+		status.Phase = managedgitopsv1alpha1.SyncRunPhase_Succeeded
+	case db.OperationState_Failed:
+		status.Phase = managedgitopsv1alpha1.SyncRunPhase_Failed
+	}
+
+	if terminated {
+		status.Phase = managedgitopsv1alpha1.SyncRunPhase_Terminated
+	}
+
+	// The Argo CD operation belongs to the SyncOperation only if it started after the SyncOperation was created
+	// (Argo CD times have a granularity of seconds)
+	if appOpState != nil && !appOpState.StartedAt.Time.Before(syncOperation.Created_on.Truncate(time.Second)) {
+
+		startedAt := appOpState.StartedAt
+		status.StartedAt = &startedAt
+		status.FinishedAt = appOpState.FinishedAt
+		status.Message = appOpState.Message
+
+		opState, err := extractOperationState(appOpState)
+		if err != nil {
+			return status, err
+		}
+		if opState.SyncResult != nil {
+			status.Revision = opState.SyncResult.Revision
+			status.Resources = opState.SyncResult.Resources
+		}
+
+		switch appOpState.Phase {
+		case fauxargocd.OperationTerminating:
+			status.Phase = managedgitopsv1alpha1.SyncRunPhase_Terminated
+		case fauxargocd.OperationFailed, fauxargocd.OperationError:
+			if status.Phase.IsFinished() && !terminated {
+				status.Phase = managedgitopsv1alpha1.SyncRunPhase_Failed
+			}
+		}
+	}
+
+	if status.Phase != managedgitopsv1alpha1.SyncRunPhase_Pending && status.StartedAt == nil {
+		status.StartedAt = &now
+	}
+
+	if status.Phase.IsFinished() && status.FinishedAt == nil {
+		status.FinishedAt = &now
+	}
+
+	return status, nil
+}
+
 // handleUpdatedGitOpsDeplSyncRunEvent handles GitOpsDeploymentSyncRun events where the user has just updated an existing GitOpsDeploymentSyncRun resource.
 // In this case, we need to ensure that the immutable fields GitOpsDeploymentName and RevisionID are not updated.
 //
@@ -640,6 +814,29 @@ func (a *applicationEventLoopRunner_Action) handleUpdatedGitOpsDeplSyncRunEvent(
 		return gitopserrors.NewUserDevError(ErrDeploymentNameIsImmutable, err)
 	}
 
+	if !isSameSyncParameters(syncOperation, syncRunCR.Spec) {
+		err := fmt.Errorf(ErrSyncParametersAreImmutable)
+		log.Error(err, ErrSyncParametersAreImmutable)
+		return gitopserrors.NewUserDevError(ErrSyncParametersAreImmutable, err)
+	}
+
+	if syncOperation.Rollback_history_id != nil {
+
+		if !isSameRollback(syncOperation, syncRunCR.Spec.Rollback) {
+			err := fmt.Errorf(ErrRollbackIsImmutable)
+			log.Error(err, ErrRollbackIsImmutable)
+			return gitopserrors.NewUserDevError(ErrRollbackIsImmutable, err)
+		}
+
+		return nil
+	}
+
+	if syncRunCR.Spec.Rollback != nil {
+		err := fmt.Errorf(ErrRollbackIsImmutable)
+		log.Error(err, ErrRollbackIsImmutable)
+		return gitopserrors.NewUserDevError(ErrRollbackIsImmutable, err)
+	}
+
 	if syncOperation.Revision != syncRunCR.Spec.RevisionID {
 		err := fmt.Errorf(ErrRevisionIsImmutable)
 		log.Error(err, ErrRevisionIsImmutable)
@@ -649,6 +846,102 @@ func (a *applicationEventLoopRunner_Action) handleUpdatedGitOpsDeplSyncRunEvent(
 	return nil
 }
 
+// findRevisionHistoryForRollback returns the entry of the GitOpsDeployment's .status.history field that is referenced by the
+// rollback field of a GitOpsDeploymentSyncRun, or nil if there is no such entry.
+//
+// When the rollback field references a revision, the most recent deployment of that revision is returned.
+func findRevisionHistoryForRollback(history []managedgitopsv1alpha1.RevisionHistory,
+	rollback managedgitopsv1alpha1.GitOpsDeploymentSyncRunRollback) *managedgitopsv1alpha1.RevisionHistory {
+
+	for i := len(history) - 1; i >= 0; i-- {
+		entry := history[i]
+
+		if rollback.HistoryID != nil {
+			if entry.ID == *rollback.HistoryID {
+				return &entry
+			}
+			continue
+		}
+
+		if entry.Revision == rollback.Revision {
+			return &entry
+		}
+		for _, revision := range entry.Revisions {
+			if revision == rollback.Revision {
+				return &entry
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateSyncRunSpec returns an error if the fields of the GitOpsDeploymentSyncRun are invalid.
+// These fields are also validated by the webhook, if it is enabled.
+func validateSyncRunSpec(spec managedgitopsv1alpha1.GitOpsDeploymentSyncRunSpec) error {
+	if err := managedgitopsv1alpha1.ValidateSyncRunRollback(spec); err != nil {
+		return err
+	}
+	return managedgitopsv1alpha1.ValidateSyncRunSyncParameters(spec)
+}
+
+// convertSyncRunResources converts the resources field of a GitOpsDeploymentSyncRun into the resources of a SyncOperation
+func convertSyncRunResources(resources []managedgitopsv1alpha1.SyncRunResource) []db.SyncOperationResource {
+
+	var res []db.SyncOperationResource
+
+	for _, resource := range resources {
+		res = append(res, db.SyncOperationResource{
+			Group:     resource.Group,
+			Kind:      resource.Kind,
+			Namespace: resource.Namespace,
+			Name:      resource.Name,
+		})
+	}
+
+	return res
+}
+
+// isSameSyncParameters returns true if the prune, dryRun, force, syncStrategy and resources fields of a
+// GitOpsDeploymentSyncRun are unchanged from the SyncOperation that was created for it.
+func isSameSyncParameters(syncOperation db.SyncOperation, spec managedgitopsv1alpha1.GitOpsDeploymentSyncRunSpec) bool {
+	return syncOperation.Prune == spec.Prune &&
+		syncOperation.Dry_run == spec.DryRun &&
+		syncOperation.Force == spec.Force &&
+		syncOperation.Sync_strategy == string(spec.SyncStrategy) &&
+		syncOperation.Resources == db.EncodeSyncOperationResources(convertSyncRunResources(spec.Resources))
+}
+
+// rollbackRevision returns the value of the 'revision' field of the SyncOperation that rolls back to the given deployment.
+// For deployments of multiple sources, this is the comma-separated list of the revisions of each source.
+func rollbackRevision(rollbackTo managedgitopsv1alpha1.RevisionHistory) string {
+	if rollbackTo.Revision != "" {
+		return rollbackTo.Revision
+	}
+	return strings.Join(rollbackTo.Revisions, ",")
+}
+
+// isSameRollback returns true if the rollback field of a GitOpsDeploymentSyncRun still references the deployment that
+// the SyncOperation was created to roll back to.
+func isSameRollback(syncOperation db.SyncOperation, rollback *managedgitopsv1alpha1.GitOpsDeploymentSyncRunRollback) bool {
+
+	if rollback == nil || syncOperation.Rollback_history_id == nil {
+		return false
+	}
+
+	if rollback.HistoryID != nil {
+		return *rollback.HistoryID == *syncOperation.Rollback_history_id
+	}
+
+	for _, revision := range strings.Split(syncOperation.Revision, ",") {
+		if revision == rollback.Revision {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (a *applicationEventLoopRunner_Action) cleanupOldSyncDBEntry(ctx context.Context, apiCRToDB *db.APICRToDatabaseMapping,
 	clusterUser db.ClusterUser, dbQueries db.ApplicationScopedQueries) error {
 
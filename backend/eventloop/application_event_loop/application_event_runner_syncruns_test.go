@@ -3,6 +3,7 @@ package application_event_loop
 import (
 	"context"
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -10,6 +11,7 @@ import (
 	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
 	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/fauxargocd"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
 	"github.com/redhat-appstudio/managed-gitops/backend/eventloop/shared_resource_loop"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -183,6 +185,69 @@ var _ = Describe("Application Event Runner SyncRuns", func() {
 			Expect(userDevErr.UserError()).Should(Equal(ErrRevisionIsImmutable))
 		})
 
+		It("should persist the sync parameters of the GitOpsDeploymentSyncRun in the SyncOperation, and treat them as immutable", func() {
+			By("create a GitOpsDeploymentSyncRun that syncs a single resource")
+			paramsSyncRun := &managedgitopsv1alpha1.GitOpsDeploymentSyncRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "gitops-syncrun-params",
+					Namespace: gitopsDepl.Namespace,
+					UID:       uuid.NewUUID(),
+				},
+				Spec: managedgitopsv1alpha1.GitOpsDeploymentSyncRunSpec{
+					GitopsDeploymentName: gitopsDepl.Name,
+					RevisionID:           "HEAD",
+					Prune:                true,
+					DryRun:               true,
+					Force:                true,
+					SyncStrategy:         managedgitopsv1alpha1.SyncRunStrategy_Apply,
+					Resources: []managedgitopsv1alpha1.SyncRunResource{
+						{Kind: "ConfigMap", Namespace: gitopsDepl.Namespace, Name: "my-config-map"},
+					},
+				},
+			}
+			err := k8sClient.Create(ctx, paramsSyncRun)
+			Expect(err).ToNot(HaveOccurred())
+
+			paramsAppAction := applicationAction
+			paramsAppAction.eventResourceName = paramsSyncRun.Name
+			userDevErr := paramsAppAction.applicationEventRunner_handleSyncRunModifiedInternal(ctx, dbQueries)
+			Expect(userDevErr).To(BeNil())
+
+			By("check if the SyncOperation entry contains the sync parameters")
+			mapping := db.APICRToDatabaseMapping{
+				APIResourceType: db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentSyncRun,
+				APIResourceUID:  string(paramsSyncRun.UID),
+				DBRelationType:  db.APICRToDatabaseMapping_DBRelationType_SyncOperation,
+			}
+			err = dbQueries.GetDatabaseMappingForAPICR(ctx, &mapping)
+			Expect(err).ToNot(HaveOccurred())
+
+			syncOperation := db.SyncOperation{SyncOperation_id: mapping.DBRelationKey}
+			err = dbQueries.GetSyncOperationById(ctx, &syncOperation)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(syncOperation.Prune).To(BeTrue())
+			Expect(syncOperation.Dry_run).To(BeTrue())
+			Expect(syncOperation.Force).To(BeTrue())
+			Expect(syncOperation.Sync_strategy).To(Equal(string(managedgitopsv1alpha1.SyncRunStrategy_Apply)))
+
+			resources, err := db.DecodeSyncOperationResources(syncOperation.Resources)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resources).To(Equal([]db.SyncOperationResource{
+				{Kind: "ConfigMap", Namespace: gitopsDepl.Namespace, Name: "my-config-map"},
+			}))
+
+			By("verify if the sync parameters of the GitOpsDeploymentSyncRun are immutable")
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(paramsSyncRun), paramsSyncRun)
+			Expect(err).ToNot(HaveOccurred())
+
+			paramsSyncRun.Spec.Prune = false
+			err = k8sClient.Update(ctx, paramsSyncRun)
+			Expect(err).ToNot(HaveOccurred())
+			userDevErr = paramsAppAction.applicationEventRunner_handleSyncRunModifiedInternal(ctx, dbQueries)
+			Expect(userDevErr).ToNot(BeNil())
+			Expect(userDevErr.UserError()).Should(Equal(ErrSyncParametersAreImmutable))
+		})
+
 		It("should terminate the SyncOperation and create an Operation when the SyncRun CR is deleted", func() {
 			mapping := db.APICRToDatabaseMapping{
 				APIResourceType:      db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentSyncRun,
@@ -506,4 +571,185 @@ var _ = Describe("Application Event Runner SyncRuns", func() {
 			Expect(syncRunCR).Should(SatisfyAll(haveErrOccurredConditionSet(expectedSyncRunStatus)))
 		})
 	})
+
+	Context("Resolve the rollback field of a GitOpsDeploymentSyncRun", func() {
+
+		historyID := func(id int64) *int64 {
+			return &id
+		}
+
+		history := []managedgitopsv1alpha1.RevisionHistory{
+			{ID: 1, Revision: "abc"},
+			{ID: 2, Revision: "def"},
+			{ID: 3, Revision: "abc"},
+			{ID: 4, Revisions: []string{"ghi", "jkl"}},
+		}
+
+		It("findRevisionHistoryForRollback should locate the entry referenced by history ID or revision", func() {
+
+			Expect(findRevisionHistoryForRollback(history, managedgitopsv1alpha1.GitOpsDeploymentSyncRunRollback{HistoryID: historyID(2)})).
+				To(Equal(&history[1]))
+
+			By("verifying the most recent deployment of a revision is returned")
+			Expect(findRevisionHistoryForRollback(history, managedgitopsv1alpha1.GitOpsDeploymentSyncRunRollback{Revision: "abc"})).
+				To(Equal(&history[2]))
+
+			By("verifying the revisions of deployments of multiple sources are matched")
+			Expect(findRevisionHistoryForRollback(history, managedgitopsv1alpha1.GitOpsDeploymentSyncRunRollback{Revision: "jkl"})).
+				To(Equal(&history[3]))
+
+			Expect(findRevisionHistoryForRollback(history, managedgitopsv1alpha1.GitOpsDeploymentSyncRunRollback{HistoryID: historyID(5)})).To(BeNil())
+			Expect(findRevisionHistoryForRollback(history, managedgitopsv1alpha1.GitOpsDeploymentSyncRunRollback{Revision: "xyz"})).To(BeNil())
+			Expect(findRevisionHistoryForRollback(nil, managedgitopsv1alpha1.GitOpsDeploymentSyncRunRollback{HistoryID: historyID(1)})).To(BeNil())
+		})
+
+		It("isSameRollback should detect changes to the rollback field of an existing GitOpsDeploymentSyncRun", func() {
+
+			syncOperation := db.SyncOperation{
+				Revision:            rollbackRevision(history[3]),
+				Rollback_history_id: historyID(history[3].ID),
+			}
+			Expect(syncOperation.Revision).To(Equal("ghi,jkl"))
+
+			Expect(isSameRollback(syncOperation, &managedgitopsv1alpha1.GitOpsDeploymentSyncRunRollback{HistoryID: historyID(4)})).To(BeTrue())
+			Expect(isSameRollback(syncOperation, &managedgitopsv1alpha1.GitOpsDeploymentSyncRunRollback{Revision: "ghi"})).To(BeTrue())
+
+			Expect(isSameRollback(syncOperation, &managedgitopsv1alpha1.GitOpsDeploymentSyncRunRollback{HistoryID: historyID(1)})).To(BeFalse())
+			Expect(isSameRollback(syncOperation, &managedgitopsv1alpha1.GitOpsDeploymentSyncRunRollback{Revision: "abc"})).To(BeFalse())
+			Expect(isSameRollback(syncOperation, nil)).To(BeFalse())
+			Expect(isSameRollback(db.SyncOperation{Revision: "abc"}, &managedgitopsv1alpha1.GitOpsDeploymentSyncRunRollback{Revision: "abc"})).To(BeFalse())
+		})
+	})
+
+	Context("computeSyncRunStatus should report the progress of the sync operation", func() {
+
+		createdOn := time.Now().Add(-time.Minute)
+		now := metav1.NewTime(time.Now().Truncate(time.Second))
+
+		syncOperation := db.SyncOperation{
+			Revision:     "main",
+			DesiredState: db.SyncOperation_DesiredState_Running,
+			Created_on:   createdOn,
+		}
+
+		It("should report the phase from the state of the Operation, when Argo CD has not yet started the sync", func() {
+
+			status, err := computeSyncRunStatus(managedgitopsv1alpha1.GitOpsDeploymentSyncRunStatus{}, db.OperationState_Waiting, syncOperation, nil, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status.Phase).To(Equal(managedgitopsv1alpha1.SyncRunPhase_Pending))
+			Expect(status.StartedAt).To(BeNil())
+			Expect(status.FinishedAt).To(BeNil())
+
+			status, err = computeSyncRunStatus(status, db.OperationState_In_Progress, syncOperation, nil, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status.Phase).To(Equal(managedgitopsv1alpha1.SyncRunPhase_Running))
+			Expect(status.StartedAt).To(Equal(&now))
+			Expect(status.FinishedAt).To(BeNil())
+
+			By("verifying the start time is preserved")
+			later := metav1.NewTime(now.Add(time.Minute))
+			status, err = computeSyncRunStatus(status, db.OperationState_Failed, syncOperation, nil, later)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status.Phase).To(Equal(managedgitopsv1alpha1.SyncRunPhase_Failed))
+			Expect(status.StartedAt).To(Equal(&now))
+			Expect(status.FinishedAt).To(Equal(&later))
+		})
+
+		It("should report the times, revision and resource results from the Argo CD operation state", func() {
+
+			startedAt := metav1.NewTime(createdOn.Add(time.Second).Truncate(time.Second))
+			finishedAt := metav1.NewTime(startedAt.Add(10 * time.Second))
+
+			appOpState := &fauxargocd.OperationState{
+				Phase:      fauxargocd.OperationSucceeded,
+				Message:    "successfully synced (all tasks run)",
+				StartedAt:  startedAt,
+				FinishedAt: &finishedAt,
+				SyncResult: &fauxargocd.SyncOperationResult{
+					Revision: "abc123",
+					Resources: fauxargocd.ResourceResults{
+						{Kind: "ConfigMap", Namespace: "jane", Name: "my-config-map", Status: fauxargocd.ResultCodeSynced, Message: "configmap/my-config-map created"},
+					},
+				},
+			}
+
+			status, err := computeSyncRunStatus(managedgitopsv1alpha1.GitOpsDeploymentSyncRunStatus{}, db.OperationState_Completed, syncOperation, appOpState, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status.Phase).To(Equal(managedgitopsv1alpha1.SyncRunPhase_Succeeded))
+			Expect(status.Message).To(Equal(appOpState.Message))
+			Expect(status.StartedAt.Equal(&startedAt)).To(BeTrue())
+			Expect(status.FinishedAt.Equal(&finishedAt)).To(BeTrue())
+			Expect(status.Revision).To(Equal("abc123"))
+			Expect(status.Resources).To(HaveLen(1))
+			Expect(status.Resources[0].Name).To(Equal("my-config-map"))
+			Expect(status.Resources[0].Status).To(Equal(managedgitopsv1alpha1.ResultCodeSynced))
+
+			By("verifying a failed Argo CD operation is reported as failed")
+			appOpState.Phase = fauxargocd.OperationFailed
+			status, err = computeSyncRunStatus(status, db.OperationState_Completed, syncOperation, appOpState, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status.Phase).To(Equal(managedgitopsv1alpha1.SyncRunPhase_Failed))
+		})
+
+		It("should ignore an Argo CD operation that started before the SyncOperation was created", func() {
+
+			appOpState := &fauxargocd.OperationState{
+				Phase:     fauxargocd.OperationSucceeded,
+				StartedAt: metav1.NewTime(createdOn.Add(-time.Hour)),
+				SyncResult: &fauxargocd.SyncOperationResult{
+					Revision: "old-revision",
+				},
+			}
+
+			status, err := computeSyncRunStatus(managedgitopsv1alpha1.GitOpsDeploymentSyncRunStatus{}, db.OperationState_In_Progress, syncOperation, appOpState, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status.Phase).To(Equal(managedgitopsv1alpha1.SyncRunPhase_Running))
+			Expect(status.Revision).To(BeEmpty())
+			Expect(status.StartedAt).To(Equal(&now))
+		})
+
+		It("should report a terminated sync operation", func() {
+
+			terminatedSyncOperation := syncOperation
+			terminatedSyncOperation.DesiredState = db.SyncOperation_DesiredState_Terminated
+
+			status, err := computeSyncRunStatus(managedgitopsv1alpha1.GitOpsDeploymentSyncRunStatus{}, db.OperationState_Completed, terminatedSyncOperation, nil, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status.Phase).To(Equal(managedgitopsv1alpha1.SyncRunPhase_Terminated))
+			Expect(status.FinishedAt).To(Equal(&now))
+
+			appOpState := &fauxargocd.OperationState{
+				Phase:     fauxargocd.OperationTerminating,
+				StartedAt: metav1.NewTime(createdOn.Add(time.Second)),
+			}
+			status, err = computeSyncRunStatus(managedgitopsv1alpha1.GitOpsDeploymentSyncRunStatus{}, db.OperationState_In_Progress, syncOperation, appOpState, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status.Phase).To(Equal(managedgitopsv1alpha1.SyncRunPhase_Terminated))
+		})
+
+		It("should keep reporting a terminated sync operation as terminated, once Argo CD reports it as failed", func() {
+
+			terminatedSyncOperation := syncOperation
+			terminatedSyncOperation.DesiredState = db.SyncOperation_DesiredState_Terminated
+
+			finishedAt := metav1.NewTime(createdOn.Add(10 * time.Second).Truncate(time.Second))
+			appOpState := &fauxargocd.OperationState{
+				Phase:      fauxargocd.OperationFailed,
+				Message:    "Operation terminated",
+				StartedAt:  metav1.NewTime(createdOn.Add(time.Second).Truncate(time.Second)),
+				FinishedAt: &finishedAt,
+			}
+
+			status, err := computeSyncRunStatus(managedgitopsv1alpha1.GitOpsDeploymentSyncRunStatus{}, db.OperationState_Completed, terminatedSyncOperation, appOpState, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status.Phase).To(Equal(managedgitopsv1alpha1.SyncRunPhase_Terminated))
+			Expect(status.Message).To(Equal("Operation terminated"))
+			Expect(status.FinishedAt.Equal(&finishedAt)).To(BeTrue())
+
+			By("verifying the phase is preserved, even if the SyncOperation is no longer reported as terminated")
+			status, err = computeSyncRunStatus(status, db.OperationState_Completed, syncOperation, appOpState, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status.Phase).To(Equal(managedgitopsv1alpha1.SyncRunPhase_Terminated))
+		})
+	})
 })
@@ -93,7 +93,7 @@ func (r *DatabaseReconciler) startTimerForNextCycle(ctx context.Context, databas
 			cleanOrphanedEntriesfromTable_ClusterUser(ctx, r.DB, r.Client, false, log)
 
 			// Clean orphaned entries from ClusterCredential table.
-			cleanOrphanedEntriesfromTable_ClusterCredential(ctx, r.DB, r.Client, false, log)
+			cleanOrphanedEntriesfromTable_ClusterCredential(ctx, r.DB, r.Client, r.K8sClientFactory, false, log)
 
 			return nil
 		})
@@ -997,7 +997,7 @@ func cleanOrphanedEntriesfromTable_ClusterUser(ctx context.Context, dbQueries db
 }
 
 // cleanOrphanedEntriesfromTable_ClusterCredential loops through ClusterCredentials in database and verifies they are still in use. If not, the credentials are deleted.
-func cleanOrphanedEntriesfromTable_ClusterCredential(ctx context.Context, dbQueries db.DatabaseQueries, client client.Client, skipDelay bool, l logr.Logger) {
+func cleanOrphanedEntriesfromTable_ClusterCredential(ctx context.Context, dbQueries db.DatabaseQueries, client client.Client, k8sClientFactory sharedresourceloop.SRLK8sClientFactory, skipDelay bool, l logr.Logger) {
 	log := l.WithValues(sharedutil.Log_JobKey, "cleanOrphanedEntriesfromTable_ClusterCredential")
 
 	// Retrieve a list of cluster credentials from the other tables that reference ClusterCredential table
@@ -1037,6 +1037,14 @@ func cleanOrphanedEntriesfromTable_ClusterCredential(ctx context.Context, dbQuer
 				slices.Contains(listOfClusterCredsFromGitOpsEngine[dbType_GitopsEngineCluster], clusterCred.Clustercredentials_cred_id)) &&
 				time.Since(clusterCred.Created_on) > waitTimeforRowDelete {
 
+				// If the GitOps Service installed a ServiceAccount using these credentials, it must be removed from the managed
+				// cluster before the ClusterCredentials are removed: the background task will remove both.
+				if clusterCred.Serviceaccount_installation_uid != "" {
+					log.Info("Orphaned ClusterCredentials has an installed ServiceAccount, queueing removal", "clusterCredentialsId", clusterCred.Clustercredentials_cred_id)
+					sharedresourceloop.QueueServiceAccountCleanup(clusterCred.Clustercredentials_cred_id, nil, k8sClientFactory, dbQueries, log)
+					continue
+				}
+
 				// 1) Remove the ClusterCredentials from the database
 				if err := deleteDbEntry(ctx, clusterCred.Clustercredentials_cred_id, dbType_ClusterCredentials, nil, dbQueries, log); err != nil {
 					log.Error(err, "Error occurred in cleanOrphanedEntriesfromTable_ClusterCredential while deleting ClusterCredentials entry : "+clusterCred.Clustercredentials_cred_id+" from DB.")
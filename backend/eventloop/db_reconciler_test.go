@@ -19,8 +19,11 @@ import (
 
 	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
 	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
 	sharedoperations "github.com/redhat-appstudio/managed-gitops/backend-shared/util/operations"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	logger "sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -2334,7 +2337,7 @@ var _ = Describe("DB Clean-up Function Tests", func() {
 
 			By("Call clean-up function.")
 
-			cleanOrphanedEntriesfromTable_ClusterCredential(ctx, dbq, k8sClient, true, log)
+			cleanOrphanedEntriesfromTable_ClusterCredential(ctx, dbq, k8sClient, MockSRLK8sClientFactory{fakeClient: k8sClient}, true, log)
 
 			By("Verify that Cluster Credential entry is deleted from DB.")
 
@@ -2343,6 +2346,42 @@ var _ = Describe("DB Clean-up Function Tests", func() {
 			Expect(db.IsResultNotFoundError(err)).To(BeTrue())
 		})
 
+		It("Should remove the installed ServiceAccount, before deleting ClusterCredentials that are not used in any other table.", func() {
+
+			defer dbq.CloseDatabase()
+
+			// Set "Created_on" field to > waitTimeForRowDelete
+			clusterCreds.Created_on = time.Now().Add(-1 * (waitTimeforRowDelete + 1*time.Second))
+			clusterCreds.Serviceaccount_installation_uid = "test-" + string(uuid.NewUUID())
+
+			By("Create Cluster Credential, and the ClusterRole of the ServiceAccount that was installed using it.")
+
+			err := dbq.CreateClusterCredentials(ctx, &clusterCreds)
+			Expect(err).ToNot(HaveOccurred())
+
+			clusterRole := &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: sharedutil.ArgoCDManagerClusterRoleNamePrefix + clusterCreds.Serviceaccount_installation_uid,
+				},
+			}
+			err = k8sClient.Create(ctx, clusterRole)
+			Expect(err).ToNot(HaveOccurred())
+
+			By("Call clean-up function.")
+
+			cleanOrphanedEntriesfromTable_ClusterCredential(ctx, dbq, k8sClient, MockSRLK8sClientFactory{fakeClient: k8sClient}, true, log)
+
+			By("Verify that the ClusterRole is removed, and the Cluster Credential entry is deleted from DB.")
+
+			Eventually(func() bool {
+				err := dbq.GetClusterCredentialsById(ctx, &clusterCreds)
+				return db.IsResultNotFoundError(err)
+			}, "30s", "100ms").Should(BeTrue())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(clusterRole), clusterRole)
+			Expect(apierr.IsNotFound(err)).To(BeTrue())
+		})
+
 		It("Should not delete ClusterCredentials if it is not used in any other table but it's created time is less than 'waitTimeforRowDelete'.", func() {
 
 			defer dbq.CloseDatabase()
@@ -2354,7 +2393,7 @@ var _ = Describe("DB Clean-up Function Tests", func() {
 
 			By("Call clean-up function.")
 
-			cleanOrphanedEntriesfromTable_ClusterCredential(ctx, dbq, k8sClient, true, log)
+			cleanOrphanedEntriesfromTable_ClusterCredential(ctx, dbq, k8sClient, MockSRLK8sClientFactory{fakeClient: k8sClient}, true, log)
 
 			By("Verify that Cluster Credential entry is not deleted from DB.")
 
@@ -2387,7 +2426,7 @@ var _ = Describe("DB Clean-up Function Tests", func() {
 
 			By("Call clean-up function.")
 
-			cleanOrphanedEntriesfromTable_ClusterCredential(ctx, dbq, k8sClient, true, log)
+			cleanOrphanedEntriesfromTable_ClusterCredential(ctx, dbq, k8sClient, MockSRLK8sClientFactory{fakeClient: k8sClient}, true, log)
 
 			By("Verify that Cluster Credential entry is not deleted from DB.")
 
@@ -2419,7 +2458,7 @@ var _ = Describe("DB Clean-up Function Tests", func() {
 
 			By("Call clean-up function.")
 
-			cleanOrphanedEntriesfromTable_ClusterCredential(ctx, dbq, k8sClient, true, log)
+			cleanOrphanedEntriesfromTable_ClusterCredential(ctx, dbq, k8sClient, MockSRLK8sClientFactory{fakeClient: k8sClient}, true, log)
 
 			By("Verify that Cluster Credential entry is not deleted from DB.")
 
@@ -21,6 +21,10 @@ const (
 	ManagedEnvironmentModified   EventLoopEventType = "ManagedEnvironmentModified"
 	SyncRunModified              EventLoopEventType = "SyncRunModified"
 	UpdateDeploymentStatusTick   EventLoopEventType = "UpdateDeploymentStatusTick"
+
+	// ManagedEnvironmentPeriodicTick is sent periodically for each ManagedEnvironment, so that the credentials,
+	// connectivity, and namespaces of the managed environment are kept up to date.
+	ManagedEnvironmentPeriodicTick EventLoopEventType = "ManagedEnvironmentPeriodicTick"
 )
 
 const KubeSystemNamespace = "kube-system"
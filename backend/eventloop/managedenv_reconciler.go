@@ -0,0 +1,177 @@
+package eventloop
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/go-logr/logr"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	"github.com/redhat-appstudio/managed-gitops/backend/eventloop/eventlooptypes"
+	"github.com/redhat-appstudio/managed-gitops/backend/metrics"
+)
+
+const (
+	managedEnvRowBatchSize            = 100             // Number of rows needs to be fetched in each batch.
+	managedEnvReconcilerInterval      = 5 * time.Minute // Interval in Minutes to reconcile Managed Environments.
+	managedEnvSleepIntervalsOfBatches = 1 * time.Second // Interval in Millisecond between each batch.
+)
+
+// ManagedEnvironmentEventReceiver is informed of ManagedEnvironment CRs that should be reconciled: this is implemented
+// by the PreprocessEventLoop.
+type ManagedEnvironmentEventReceiver interface {
+	EventReceived(req ctrl.Request, reqResource eventlooptypes.GitOpsResourceType,
+		client client.Client, eventType eventlooptypes.EventLoopEventType, namespaceID string)
+}
+
+// ManagedEnvReconciler periodically sends a ManagedEnvironmentPeriodicTick event for each Managed Environment. The event
+// is processed by the shared resource loop, which:
+// - renews the tokens of the ServiceAccounts that the GitOps Service installed into the cluster of the Managed Environment
+// - contacts the API server of the Managed Environment, and records the result in the status of the ManagedEnvironment
+// CR, and in the connectivity metrics of the Managed Environment
+// - resolves the .spec.namespaceSelector of the Managed Environment against its cluster
+// and reconciles the Managed Environment if its credentials are no longer valid, or if its matching Namespaces have changed.
+type ManagedEnvReconciler struct {
+	client.Client
+	DB db.DatabaseQueries
+
+	// EventReceiver is sent the periodic events of the Managed Environments.
+	EventReceiver ManagedEnvironmentEventReceiver
+
+	// reportedManagedEnvs contains the Managed Environments that events were sent for in the previous run, so that the
+	// metrics of Managed Environments that no longer exist can be removed.
+	reportedManagedEnvs map[types.NamespacedName]bool
+}
+
+// This function iterates through each Managed Environment in the DB, and sends a periodic event for it.
+func (r *ManagedEnvReconciler) StartManagedEnvReconciler() {
+	r.startTimerForNextCycle()
+}
+
+func (r *ManagedEnvReconciler) startTimerForNextCycle() {
+	go func() {
+		// Timer to trigger Reconciler
+		timer := time.NewTimer(managedEnvReconcilerInterval)
+		<-timer.C
+
+		ctx := context.Background()
+		log := log.FromContext(ctx).
+			WithName(logutil.LogLogger_managed_gitops).
+			WithValues(logutil.Log_Component, logutil.Log_Component_Backend_ManagedEnvReconciler)
+
+		if _, err := sharedutil.CatchPanic(func() error {
+
+			// Send the periodic events here
+			r.reconcileManagedEnvironments(ctx, log)
+
+			return nil
+		}); err != nil {
+			log.Error(err, "error on managed environment reconcile")
+		}
+
+		// Kick off the timer again, once the old task runs.
+		// This ensures that at least 'managedEnvReconcilerInterval' time elapses from the end of one run to the beginning of another.
+		r.startTimerForNextCycle()
+	}()
+
+}
+
+// reconcileManagedEnvironments iterates through the ManagedEnvironment entries of the ACTDM table, and sends a periodic
+// event for each.
+func (r *ManagedEnvReconciler) reconcileManagedEnvironments(ctx context.Context, logParam logr.Logger) {
+
+	offSet := 0
+	log := logParam.WithValues(sharedutil.Log_JobKey, "reconcileManagedEnvironments")
+
+	reportedManagedEnvs := map[types.NamespacedName]bool{}
+
+	// Continuously iterate and fetch batches until all entries of ACTDM table are processed.
+	for {
+		if offSet != 0 {
+			time.Sleep(managedEnvSleepIntervalsOfBatches)
+		}
+
+		var listOfApiCrToDbMapping []db.APICRToDatabaseMapping
+
+		// Fetch ACTDMs table entries in batch size as configured above.
+		if err := r.DB.GetAPICRToDatabaseMappingBatch(ctx, &listOfApiCrToDbMapping, managedEnvRowBatchSize, offSet); err != nil {
+			log.Error(err, fmt.Sprintf("Error occurred in Managed Environment Reconcile while fetching batch from Offset: %d to %d: ",
+				offSet, offSet+managedEnvRowBatchSize))
+
+			// Don't remove the metrics of Managed Environments that may not have been processed yet
+			return
+		}
+
+		// Break the loop if no entries are left in table to be processed.
+		if len(listOfApiCrToDbMapping) == 0 {
+			log.Info("All ACTDM entries are processed by Managed Environment Reconciler.")
+			break
+		}
+
+		// Iterate over batch received above.
+		for i := range listOfApiCrToDbMapping {
+			apiCrToDbMappingFromDB := listOfApiCrToDbMapping[i] // To avoid "Implicit memory aliasing in for loop." error.
+
+			if db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentManagedEnvironment == apiCrToDbMappingFromDB.APIResourceType {
+
+				if r.reconcileManagedEnvironment(ctx, apiCrToDbMappingFromDB, log) {
+					reportedManagedEnvs[types.NamespacedName{
+						Name:      apiCrToDbMappingFromDB.APIResourceName,
+						Namespace: apiCrToDbMappingFromDB.APIResourceNamespace,
+					}] = true
+				}
+
+				log.V(logutil.LogLevel_Debug).Info("Managed Environment Reconcile processed APICRToDatabaseMapping entry: " + apiCrToDbMappingFromDB.APIResourceUID)
+			}
+		}
+
+		// Skip processed entries in next iteration
+		offSet += managedEnvRowBatchSize
+	}
+
+	// Remove the metrics of Managed Environments that were reported in the previous run, but not in this one
+	for managedEnv := range r.reportedManagedEnvs {
+		if !reportedManagedEnvs[managedEnv] {
+			metrics.DeleteManagedEnvironmentConnectivity(managedEnv.Name, managedEnv.Namespace)
+		}
+	}
+	r.reportedManagedEnvs = reportedManagedEnvs
+}
+
+// reconcileManagedEnvironment sends a periodic event for the ManagedEnvironment CR referenced by the ACTDM.
+//
+// Returns true if the event was sent, false otherwise.
+func (r *ManagedEnvReconciler) reconcileManagedEnvironment(ctx context.Context, apiCrToDbMapping db.APICRToDatabaseMapping, logParam logr.Logger) bool {
+
+	log := logParam.WithValues("managedEnvName", apiCrToDbMapping.APIResourceName, "managedEnvNamespace", apiCrToDbMapping.APIResourceNamespace)
+
+	managedEnvCR := managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment{}
+	if err := r.Get(ctx, types.NamespacedName{Name: apiCrToDbMapping.APIResourceName, Namespace: apiCrToDbMapping.APIResourceNamespace}, &managedEnvCR); err != nil {
+		if !apierr.IsNotFound(err) {
+			log.Error(err, "Unable to retrieve ManagedEnvironment CR")
+		}
+		// Orphaned ACTDM entries are cleaned up by the database reconciler
+		return false
+	}
+
+	// The CR may have been deleted and recreated with the same name, in which case the ACTDM entry refers to the old CR
+	if string(managedEnvCR.UID) != apiCrToDbMapping.APIResourceUID {
+		return false
+	}
+
+	r.EventReceiver.EventReceived(ctrl.Request{NamespacedName: types.NamespacedName{
+		Name:      apiCrToDbMapping.APIResourceName,
+		Namespace: apiCrToDbMapping.APIResourceNamespace,
+	}}, eventlooptypes.GitOpsDeploymentManagedEnvironmentTypeName, r.Client, eventlooptypes.ManagedEnvironmentPeriodicTick, apiCrToDbMapping.NamespaceUID)
+
+	return true
+}
@@ -0,0 +1,184 @@
+package eventloop
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	"github.com/redhat-appstudio/managed-gitops/backend/eventloop/eventlooptypes"
+	"github.com/redhat-appstudio/managed-gitops/backend/metrics"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logger "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// fakeManagedEnvironmentEventReceiver records the events it receives
+type fakeManagedEnvironmentEventReceiver struct {
+	requests []ctrl.Request
+	eventIDs []string
+}
+
+func (f *fakeManagedEnvironmentEventReceiver) EventReceived(req ctrl.Request, reqResource eventlooptypes.GitOpsResourceType,
+	client client.Client, eventType eventlooptypes.EventLoopEventType, namespaceID string) {
+
+	Expect(reqResource).To(Equal(eventlooptypes.GitOpsDeploymentManagedEnvironmentTypeName))
+	Expect(eventType).To(Equal(eventlooptypes.ManagedEnvironmentPeriodicTick))
+
+	f.requests = append(f.requests, req)
+	f.eventIDs = append(f.eventIDs, namespaceID)
+}
+
+var _ = Describe("Managed Environment Reconcile Function Tests", func() {
+	Context("Testing reconcileManagedEnvironments function for ManagedEnvironment table entries.", func() {
+
+		var log logr.Logger
+		var ctx context.Context
+		var dbq db.AllDatabaseQueries
+		var k8sClient client.WithWatch
+		var apiNamespace *corev1.Namespace
+		var eventReceiver *fakeManagedEnvironmentEventReceiver
+		var reconciler ManagedEnvReconciler
+
+		BeforeEach(func() {
+			scheme,
+				argocdNamespace,
+				kubesystemNamespace,
+				workspace,
+				err := tests.GenericTestSetup()
+			Expect(err).ToNot(HaveOccurred())
+
+			apiNamespace = workspace
+
+			// Create fake client
+			k8sClient = fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(apiNamespace, argocdNamespace, kubesystemNamespace).
+				Build()
+
+			err = db.SetupForTestingDBGinkgo()
+			Expect(err).ToNot(HaveOccurred())
+
+			ctx = context.Background()
+			log = logger.FromContext(ctx)
+			dbq, err = db.NewUnsafePostgresDBQueries(true, true)
+			Expect(err).ToNot(HaveOccurred())
+
+			eventReceiver = &fakeManagedEnvironmentEventReceiver{}
+
+			reconciler = ManagedEnvReconciler{
+				Client:        k8sClient,
+				DB:            dbq,
+				EventReceiver: eventReceiver,
+			}
+
+			metrics.ClearManagedEnvironmentConnectivityMetrics()
+		})
+
+		AfterEach(func() {
+			dbq.CloseDatabase()
+		})
+
+		createManagedEnvironment := func(name string, apiResourceUID types.UID) {
+
+			clusterCreds := db.ClusterCredentials{
+				Clustercredentials_cred_id:  "test-" + string(uuid.NewUUID()),
+				Host:                        "https://api.fake-unit-test-data.origin-ci-int-gce.dev.rhcloud.com:6443",
+				Serviceaccount_bearer_token: "token",
+				Serviceaccount_ns:           "kube-system",
+			}
+			err := dbq.CreateClusterCredentials(ctx, &clusterCreds)
+			Expect(err).ToNot(HaveOccurred())
+
+			managedEnv := db.ManagedEnvironment{
+				Managedenvironment_id: "test-managed-env-" + string(uuid.NewUUID()),
+				Clustercredentials_id: clusterCreds.Clustercredentials_cred_id,
+				Name:                  name,
+			}
+			err = dbq.CreateManagedEnvironment(ctx, &managedEnv)
+			Expect(err).ToNot(HaveOccurred())
+
+			apiCRToDatabaseMapping := db.APICRToDatabaseMapping{
+				APIResourceType:      db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentManagedEnvironment,
+				APIResourceUID:       string(apiResourceUID),
+				APIResourceName:      name,
+				APIResourceNamespace: apiNamespace.Name,
+				NamespaceUID:         string(apiNamespace.UID),
+				DBRelationType:       db.APICRToDatabaseMapping_DBRelationType_ManagedEnvironment,
+				DBRelationKey:        managedEnv.Managedenvironment_id,
+			}
+			err = dbq.CreateAPICRToDatabaseMapping(ctx, &apiCRToDatabaseMapping)
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		createManagedEnvironmentCR := func(name string) *managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment {
+
+			managedEnvCR := &managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: apiNamespace.Name,
+					UID:       uuid.NewUUID(),
+				},
+			}
+			err := k8sClient.Create(ctx, managedEnvCR)
+			Expect(err).ToNot(HaveOccurred())
+
+			return managedEnvCR
+		}
+
+		It("should send a periodic event for each ManagedEnvironment CR that exists", func() {
+
+			existingEnvCR := createManagedEnvironmentCR("existing-managed-env")
+			createManagedEnvironment(existingEnvCR.Name, existingEnvCR.UID)
+
+			By("creating a ManagedEnvironment CR that was recreated after its database entries were created")
+			recreatedEnvCR := createManagedEnvironmentCR("recreated-managed-env")
+			createManagedEnvironment(recreatedEnvCR.Name, uuid.NewUUID())
+
+			By("creating database entries for a ManagedEnvironment CR that doesn't exist")
+			createManagedEnvironment("missing-managed-env", uuid.NewUUID())
+
+			reconciler.reconcileManagedEnvironments(ctx, log)
+
+			Expect(eventReceiver.requests).To(ContainElement(ctrl.Request{NamespacedName: types.NamespacedName{
+				Name:      existingEnvCR.Name,
+				Namespace: apiNamespace.Name,
+			}}))
+			Expect(eventReceiver.eventIDs).To(ContainElement(string(apiNamespace.UID)))
+
+			for _, request := range eventReceiver.requests {
+				Expect(request.Name).ToNot(Equal(recreatedEnvCR.Name))
+				Expect(request.Name).ToNot(Equal("missing-managed-env"))
+			}
+		})
+
+		It("should remove the metrics of a Managed Environment, once the ManagedEnvironment CR is deleted", func() {
+
+			managedEnvCR := createManagedEnvironmentCR("deleted-managed-env")
+			createManagedEnvironment(managedEnvCR.Name, managedEnvCR.UID)
+
+			reconciler.reconcileManagedEnvironments(ctx, log)
+
+			// The metrics are set when the periodic event is processed by the shared resource loop
+			metrics.SetManagedEnvironmentReachable(managedEnvCR.Name, managedEnvCR.Namespace, time.Second, time.Now())
+
+			err := k8sClient.Delete(ctx, managedEnvCR)
+			Expect(err).ToNot(HaveOccurred())
+
+			reconciler.reconcileManagedEnvironments(ctx, log)
+
+			By("verifying that the metrics of the deleted Managed Environment are removed")
+			Expect(metrics.ManagedEnvReachable.DeleteLabelValues(managedEnvCR.Name, managedEnvCR.Namespace)).To(BeFalse())
+			Expect(metrics.ManagedEnvLastSuccessfulContact.DeleteLabelValues(managedEnvCR.Name, managedEnvCR.Namespace)).To(BeFalse())
+		})
+	})
+})
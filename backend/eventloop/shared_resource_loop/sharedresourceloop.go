@@ -254,6 +254,50 @@ func (srEventLoop *SharedResourceEventLoop) ReconcileRepositoryCredential(ctx co
 
 }
 
+// ReconcileSharedManagedEnvPeriodically performs the periodic maintenance of a managed environment: renewing the token of
+// the ServiceAccount installed by the GitOps Service, probing the API server, and resolving the namespace selector.
+//
+// Returns true if the managed environment should be reconciled (with ReconcileSharedManagedEnv), because its credentials
+// are no longer valid, or because the Namespaces that match its namespace selector have changed.
+func (srEventLoop *SharedResourceEventLoop) ReconcileSharedManagedEnvPeriodically(ctx context.Context,
+	workspaceClient client.Client, workspaceNamespace corev1.Namespace, managedEnvironmentCRName string,
+	k8sClientFactory SRLK8sClientFactory, l logr.Logger) (bool, error) {
+
+	request := sharedResourceLoopMessage_reconcileSharedManagedEnvPeriodicallyRequest{
+		managedEnvironmentCRName: managedEnvironmentCRName,
+		k8sClientFactory:         k8sClientFactory,
+	}
+
+	responseChannel := make(chan any)
+
+	msg := sharedResourceLoopMessage{
+		log:                l,
+		ctx:                ctx,
+		workspaceClient:    workspaceClient,
+		workspaceNamespace: workspaceNamespace,
+		messageType:        sharedResourceLoopMessage_reconcileSharedManagedEnvPeriodically,
+		responseChannel:    responseChannel,
+		payload:            request,
+	}
+
+	srEventLoop.inputChannel <- msg
+
+	var rawResponse any
+
+	select {
+	case rawResponse = <-responseChannel:
+	case <-ctx.Done():
+		return false, fmt.Errorf("context cancelled in ReconcileSharedManagedEnvPeriodically")
+	}
+
+	response, ok := rawResponse.(sharedResourceLoopMessage_reconcileSharedManagedEnvPeriodicallyResponse)
+	if !ok {
+		return false, fmt.Errorf("SEVERE: unexpected response type")
+	}
+
+	return response.reconcileNeeded, response.err
+}
+
 func NewSharedResourceLoop() *SharedResourceEventLoop {
 
 	sharedResourceEventLoop := &SharedResourceEventLoop{
@@ -268,11 +312,12 @@ func NewSharedResourceLoop() *SharedResourceEventLoop {
 type sharedResourceLoopMessageType string
 
 const (
-	sharedResourceLoopMessage_getOrCreateSharedManagedEnv          sharedResourceLoopMessageType = "getOrCreateSharedManagedEnv"
-	sharedResourceLoopMessage_getOrCreateClusterUserByNamespaceUID sharedResourceLoopMessageType = "getOrCreateClusterUserByNamespaceUID"
-	sharedResourceLoopMessage_getGitopsEngineInstanceById          sharedResourceLoopMessageType = "getGitopsEngineInstanceById"
-	sharedResourceLoopMessage_reconcileRepositoryCredential        sharedResourceLoopMessageType = "reconcileRepositoryCredential"
-	sharedResourceLoopMessage_reconcileAppProjectRepositories      sharedResourceLoopMessageType = "reconcileAppProjectRepositories"
+	sharedResourceLoopMessage_getOrCreateSharedManagedEnv           sharedResourceLoopMessageType = "getOrCreateSharedManagedEnv"
+	sharedResourceLoopMessage_getOrCreateClusterUserByNamespaceUID  sharedResourceLoopMessageType = "getOrCreateClusterUserByNamespaceUID"
+	sharedResourceLoopMessage_getGitopsEngineInstanceById           sharedResourceLoopMessageType = "getGitopsEngineInstanceById"
+	sharedResourceLoopMessage_reconcileRepositoryCredential         sharedResourceLoopMessageType = "reconcileRepositoryCredential"
+	sharedResourceLoopMessage_reconcileAppProjectRepositories       sharedResourceLoopMessageType = "reconcileAppProjectRepositories"
+	sharedResourceLoopMessage_reconcileSharedManagedEnvPeriodically sharedResourceLoopMessageType = "reconcileSharedManagedEnvPeriodically"
 )
 
 type sharedResourceLoopMessage struct {
@@ -319,6 +364,19 @@ type sharedResourceLoopMessage_reconcileRepositoryCredentialResponse struct {
 type sharedResourceLoopMessage_reconcileAppProjectRepositoriesRequest struct {
 }
 
+type sharedResourceLoopMessage_reconcileSharedManagedEnvPeriodicallyRequest struct {
+	managedEnvironmentCRName string
+	k8sClientFactory         SRLK8sClientFactory
+}
+
+type sharedResourceLoopMessage_reconcileSharedManagedEnvPeriodicallyResponse struct {
+
+	// reconcileNeeded is true if the managed environment should be reconciled, false otherwise.
+	reconcileNeeded bool
+
+	err error
+}
+
 func newSharedResourceManagedEnvContainer() SharedResourceManagedEnvContainer {
 	return SharedResourceManagedEnvContainer{
 		ClusterUser:          nil,
@@ -516,6 +574,30 @@ func processSharedResourceMessage(ctx context.Context, msg sharedResourceLoopMes
 			msg.responseChannel <- response
 		}()
 
+	} else if msg.messageType == sharedResourceLoopMessage_reconcileSharedManagedEnvPeriodically {
+
+		var err error
+		var reconcileNeeded bool
+
+		payload, ok := (msg.payload).(sharedResourceLoopMessage_reconcileSharedManagedEnvPeriodicallyRequest)
+		if ok {
+			reconcileNeeded, err = internalProcessMessage_ReconcileSharedManagedEnvPeriodically(ctx, msg.workspaceClient,
+				payload.managedEnvironmentCRName, msg.workspaceNamespace, payload.k8sClientFactory, dbQueries, log)
+		} else {
+			err = fmt.Errorf("SEVERE - unexpected cast in internalSharedResourceEventLoop")
+			log.Error(err, err.Error())
+		}
+
+		response := sharedResourceLoopMessage_reconcileSharedManagedEnvPeriodicallyResponse{
+			reconcileNeeded: reconcileNeeded,
+			err:             err,
+		}
+
+		// Reply on a separate goroutine so cancelled callers don't block the event loop
+		go func() {
+			msg.responseChannel <- response
+		}()
+
 	} else {
 		log.Error(nil, "SEVERE: unrecognized sharedResourceLoopMessageType: "+string(msg.messageType))
 	}
@@ -558,9 +640,19 @@ func reconcileAppProjectRepositories(ctx context.Context, namespace corev1.Names
 	// map: normalized git url -> expected db entry
 	expectedDBEntries := map[string]db.AppProjectRepository{}
 
+	// The URL prefixes of the credential templates in the Namespace
+	var templateURLPrefixes []string
+
 	for _, repoCred := range repoCreds.Items {
 		gitURLOfRepoCred := NormalizeGitURL(repoCred.Spec.Repository)
 
+		if repoCred.Spec.Template {
+			// A credential template covers every repository whose URL begins with its URL prefix: the AppProject
+			// source repositories support glob patterns, so a single entry is sufficient for all of those repositories.
+			templateURLPrefixes = append(templateURLPrefixes, repoCred.Spec.Repository)
+			gitURLOfRepoCred += "*"
+		}
+
 		expectedEntry := db.AppProjectRepository{
 			Clusteruser_id: clusterUser.Clusteruser_id,
 			RepoURL:        gitURLOfRepoCred,
@@ -569,13 +661,28 @@ func reconcileAppProjectRepositories(ctx context.Context, namespace corev1.Names
 	}
 
 	for _, gitopsDepl := range gitopsDeployments.Items {
-		gitURLOfGitOpsDepl := NormalizeGitURL(gitopsDepl.Spec.Source.RepoURL)
 
-		expectedEntry := db.AppProjectRepository{
-			Clusteruser_id: clusterUser.Clusteruser_id,
-			RepoURL:        gitURLOfGitOpsDepl,
+		// Multi-source GitOpsDeployments require an entry for the repository of each source
+		sources := []managedgitopsv1alpha1.ApplicationSource{gitopsDepl.Spec.Source}
+		if gitopsDepl.Spec.HasMultipleSources() {
+			sources = gitopsDepl.Spec.Sources
+		}
+
+		for _, source := range sources {
+
+			if isRepositoryCoveredByTemplates(source.RepoURL, templateURLPrefixes) {
+				// The repository is already covered by the entry of the credential template
+				continue
+			}
+
+			gitURLOfGitOpsDepl := NormalizeGitURL(source.RepoURL)
+
+			expectedEntry := db.AppProjectRepository{
+				Clusteruser_id: clusterUser.Clusteruser_id,
+				RepoURL:        gitURLOfGitOpsDepl,
+			}
+			expectedDBEntries[gitURLOfGitOpsDepl] = expectedEntry
 		}
-		expectedDBEntries[gitURLOfGitOpsDepl] = expectedEntry
 	}
 
 	resDatabaseUpdated := false // Whether or not the database was updated by this call
@@ -703,8 +810,57 @@ func compareAndModifyClusterResourceWithDatabaseRow(cr managedgitopsv1alpha1.Git
 		isAuthSSHKeyUpdateNeeded = true
 	}
 
-	return isSecretUpdateNeeded || isRepoUpdateNeeded || isAuthUsernameUpdateNeeded ||
-		isAuthPasswordUpdateNeeded || isAuthSSHKeyUpdateNeeded
+	var isGitHubAppUpdateNeeded bool
+	gitHubAppCreds := gitHubAppCredentials{}
+	if creds := gitHubAppCredentialsFromSecret(secret); creds != nil {
+		gitHubAppCreds = *creds
+	}
+	if gitHubAppCreds.appID != dbr.GithubAppID || gitHubAppCreds.installationID != dbr.GithubAppInstallationID ||
+		gitHubAppCreds.privateKey != dbr.GithubAppPrivateKey || gitHubAppCreds.enterpriseBaseURL != dbr.GithubAppEnterpriseBaseURL {
+		l.Info("GitHub App credentials changed")
+		dbr.GithubAppID = gitHubAppCreds.appID
+		dbr.GithubAppInstallationID = gitHubAppCreds.installationID
+		dbr.GithubAppPrivateKey = gitHubAppCreds.privateKey
+		dbr.GithubAppEnterpriseBaseURL = gitHubAppCreds.enterpriseBaseURL
+		isGitHubAppUpdateNeeded = true
+	}
+
+	var isTemplateUpdateNeeded bool
+	if cr.Spec.Template != dbr.Template {
+		l.Info("Template changed", "old", dbr.Template, "new", cr.Spec.Template)
+		dbr.Template = cr.Spec.Template
+		isTemplateUpdateNeeded = true
+	}
+
+	var isTypeUpdateNeeded bool
+	if string(cr.Spec.Type) != dbr.Type {
+		l.Info("Type changed", "old", dbr.Type, "new", cr.Spec.Type)
+		dbr.Type = string(cr.Spec.Type)
+		isTypeUpdateNeeded = true
+	}
+
+	var isTLSUpdateNeeded bool
+	tlsOptions := repositoryTLSOptionsFromSecret(secret)
+	if tlsOptions.clientCertData != dbr.TLSClientCertData || tlsOptions.clientCertKey != dbr.TLSClientCertKey ||
+		tlsOptions.caCertData != dbr.TLSCACertData || tlsOptions.insecure != dbr.Insecure {
+		l.Info("TLS options changed", "insecure", tlsOptions.insecure)
+		dbr.TLSClientCertData = tlsOptions.clientCertData
+		dbr.TLSClientCertKey = tlsOptions.clientCertKey
+		dbr.TLSCACertData = tlsOptions.caCertData
+		dbr.Insecure = tlsOptions.insecure
+		isTLSUpdateNeeded = true
+	}
+
+	var isKnownHostsUpdateNeeded bool
+	if knownHosts := repositoryKnownHosts(cr.Spec.KnownHosts, secret); knownHosts != dbr.KnownHosts {
+		l.Info("SSH known hosts changed")
+		dbr.KnownHosts = knownHosts
+		isKnownHostsUpdateNeeded = true
+	}
+
+	return isSecretUpdateNeeded || isRepoUpdateNeeded || isAuthUsernameUpdateNeeded || isAuthPasswordUpdateNeeded ||
+		isAuthSSHKeyUpdateNeeded || isGitHubAppUpdateNeeded || isTemplateUpdateNeeded || isTypeUpdateNeeded || isTLSUpdateNeeded ||
+		isKnownHostsUpdateNeeded
 }
 
 func internalProcessMessage_GetGitopsEngineInstanceById(ctx context.Context, id string, dbq db.DatabaseQueries) (*db.GitopsEngineInstance, error) {
@@ -0,0 +1,220 @@
+package shared_resource_loop
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// openShiftClusterVersionGroupKind is only served by OpenShift clusters, and is thus used to detect the platform of a cluster.
+var openShiftClusterVersionGroupKind = schema.GroupKind{Group: "config.openshift.io", Kind: "ClusterVersion"}
+
+// baselineRulesReviewNamespace is a Namespace that is not managed by the client: the rules that the client is granted in it
+// are those that it is granted in every Namespace, and are thus the baseline against which reachable Namespaces are detected.
+const baselineRulesReviewNamespace = "gitops-service-baseline-rules-review"
+
+// ManagedEnvironmentClusterInfo contains facts about the cluster of a managed environment, discovered using its cluster credentials.
+// Facts which could not be discovered are left empty (nil).
+type ManagedEnvironmentClusterInfo struct {
+	Platform            managedgitopsv1alpha1.ManagedEnvironmentPlatform
+	NodeCount           *int32
+	ReachableNamespaces []string
+	ClusterScopedAccess *bool
+}
+
+// DiscoverManagedEnvironmentClusterInfo contacts the cluster of a managed environment using the given cluster credentials,
+// and returns the facts that could be discovered about it. An error is returned if one or more facts could not be discovered.
+func DiscoverManagedEnvironmentClusterInfo(ctx context.Context, clusterCreds db.ClusterCredentials, k8sClientFactory SRLK8sClientFactory) (ManagedEnvironmentClusterInfo, error) {
+
+	configParam, _, err := sanityTestCredentials(clusterCreds)
+	if err != nil {
+		return ManagedEnvironmentClusterInfo{}, err
+	}
+
+	k8sClient, err := k8sClientFactory.BuildK8sClient(configParam)
+	if err != nil {
+		return ManagedEnvironmentClusterInfo{}, fmt.Errorf("unable to create new K8s client to '%v': %w", configParam.Host, err)
+	}
+
+	var namespaces []string
+	if clusterCreds.Namespaces != "" {
+		namespaces = strings.Split(clusterCreds.Namespaces, ",")
+	}
+
+	return discoverClusterInfo(ctx, namespaces, k8sClient)
+}
+
+// discoverClusterInfo returns the facts that could be discovered about the cluster of 'k8sClient'. If 'namespaces' is non-empty,
+// the Namespaces that the client has been granted permissions in are also discovered.
+func discoverClusterInfo(ctx context.Context, namespaces []string, k8sClient client.Client) (ManagedEnvironmentClusterInfo, error) {
+
+	var res ManagedEnvironmentClusterInfo
+	var errs []string
+
+	if _, err := k8sClient.RESTMapper().RESTMapping(openShiftClusterVersionGroupKind); err == nil {
+		res.Platform = managedgitopsv1alpha1.ManagedEnvironmentPlatformOpenShift
+	} else if meta.IsNoMatchError(err) {
+		res.Platform = managedgitopsv1alpha1.ManagedEnvironmentPlatformKubernetes
+	} else {
+		errs = append(errs, fmt.Sprintf("unable to detect platform: %v", err))
+	}
+
+	// Listing Nodes requires cluster-scoped permissions, which the credentials may not have. Only their metadata is
+	// needed to count them.
+	nodeList := metav1.PartialObjectMetadataList{}
+	nodeList.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("NodeList"))
+	if err := k8sClient.List(ctx, &nodeList); err == nil {
+		nodeCount := int32(len(nodeList.Items))
+		res.NodeCount = &nodeCount
+	} else {
+		errs = append(errs, fmt.Sprintf("unable to list nodes: %v", err))
+	}
+
+	clusterAccessReview := authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{Verb: "list", Group: "*", Resource: "*"},
+		},
+	}
+	if err := k8sClient.Create(ctx, &clusterAccessReview); err == nil {
+		clusterScopedAccess := clusterAccessReview.Status.Allowed
+		res.ClusterScopedAccess = &clusterScopedAccess
+	} else {
+		errs = append(errs, fmt.Sprintf("unable to review cluster-scoped access: %v", err))
+	}
+
+	if len(namespaces) > 0 {
+		reachableNamespaces, err := discoverReachableNamespaces(ctx, namespaces, res.ClusterScopedAccess, k8sClient)
+		if err == nil {
+			res.ReachableNamespaces = reachableNamespaces
+		} else {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return res, fmt.Errorf("unable to discover all facts about the cluster: %s", strings.Join(errs, "; "))
+	}
+
+	return res, nil
+}
+
+// discoverReachableNamespaces returns the Namespaces of 'namespaces' in which the client has been granted permissions,
+// other than those that it is granted in every Namespace (such as the permissions to review its own access). These are
+// detected by comparing the rules of each Namespace with those of a Namespace that the client does not manage.
+//
+// As the rules of a client with cluster-scoped access are the same in every Namespace, all the Namespaces are reachable
+// if 'clusterScopedAccess' is true.
+func discoverReachableNamespaces(ctx context.Context, namespaces []string, clusterScopedAccess *bool, k8sClient client.Client) ([]string, error) {
+
+	if clusterScopedAccess != nil && *clusterScopedAccess {
+		return append([]string{}, namespaces...), nil
+	}
+
+	// The baseline Namespace must not be one of the Namespaces that the client manages
+	managedNamespaces := map[string]bool{}
+	for _, namespace := range namespaces {
+		managedNamespaces[namespace] = true
+	}
+	baselineNamespace := baselineRulesReviewNamespace
+	for managedNamespaces[baselineNamespace] {
+		baselineNamespace += "-x"
+	}
+
+	baselineRules, err := reviewNamespaceRules(ctx, baselineNamespace, k8sClient)
+	if err != nil {
+		return nil, err
+	}
+
+	reachableNamespaces := []string{}
+
+	for _, namespace := range namespaces {
+
+		rules, err := reviewNamespaceRules(ctx, namespace, k8sClient)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rule := range rules {
+			if !containsResourceRule(baselineRules, rule) {
+				reachableNamespaces = append(reachableNamespaces, namespace)
+				break
+			}
+		}
+	}
+
+	return reachableNamespaces, nil
+}
+
+// reviewNamespaceRules returns the rules that the client has been granted in the Namespace.
+func reviewNamespaceRules(ctx context.Context, namespace string, k8sClient client.Client) ([]authorizationv1.ResourceRule, error) {
+
+	rulesReview := authorizationv1.SelfSubjectRulesReview{
+		Spec: authorizationv1.SelfSubjectRulesReviewSpec{Namespace: namespace},
+	}
+	if err := k8sClient.Create(ctx, &rulesReview); err != nil {
+		return nil, fmt.Errorf("unable to review access to namespace '%s': %w", namespace, err)
+	}
+
+	return rulesReview.Status.ResourceRules, nil
+}
+
+// containsResourceRule returns true if 'rules' contains a rule equal to 'rule'.
+func containsResourceRule(rules []authorizationv1.ResourceRule, rule authorizationv1.ResourceRule) bool {
+	for _, r := range rules {
+		if equality.Semantic.DeepEqual(r, rule) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetManagedEnvironmentClusterInfo sets the fields of the .status of the ManagedEnvironment CR that describe the cluster,
+// to the given facts. Facts that could not be discovered retain their previous value. The caller is responsible for
+// updating the status of the CR.
+//
+// Returns true if a field was changed, false otherwise.
+func SetManagedEnvironmentClusterInfo(managedEnvironmentCR *managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment,
+	clusterInfo ManagedEnvironmentClusterInfo) bool {
+
+	status := &managedEnvironmentCR.Status
+	changed := false
+
+	if clusterInfo.Platform != "" && status.Platform != clusterInfo.Platform {
+		status.Platform = clusterInfo.Platform
+		changed = true
+	}
+
+	if clusterInfo.NodeCount != nil && (status.NodeCount == nil || *status.NodeCount != *clusterInfo.NodeCount) {
+		status.NodeCount = clusterInfo.NodeCount
+		changed = true
+	}
+
+	if clusterInfo.ClusterScopedAccess != nil && (status.ClusterScopedAccess == nil || *status.ClusterScopedAccess != *clusterInfo.ClusterScopedAccess) {
+		status.ClusterScopedAccess = clusterInfo.ClusterScopedAccess
+		changed = true
+	}
+
+	// Only reported if the managed environment is restricted to Namespaces
+	if len(status.ResolvedNamespaces) == 0 {
+		if status.ReachableNamespaces != nil {
+			status.ReachableNamespaces = nil
+			changed = true
+		}
+	} else if clusterInfo.ReachableNamespaces != nil &&
+		strings.Join(status.ReachableNamespaces, ",") != strings.Join(clusterInfo.ReachableNamespaces, ",") {
+		status.ReachableNamespaces = clusterInfo.ReachableNamespaces
+		changed = true
+	}
+
+	return changed
+}
@@ -0,0 +1,283 @@
+package shared_resource_loop
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// accessReviewClient is a fake client that answers SelfSubjectAccessReviews and SelfSubjectRulesReviews, which the fake
+// client is otherwise unable to handle.
+type accessReviewClient struct {
+	client.Client
+
+	// clusterScopedAccess is the result of every SelfSubjectAccessReview
+	clusterScopedAccess bool
+
+	// namespaceRules contains the rules of the SelfSubjectRulesReview of each Namespace
+	namespaceRules map[string][]authorizationv1.ResourceRule
+}
+
+func (c accessReviewClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+
+	switch review := obj.(type) {
+	case *authorizationv1.SelfSubjectAccessReview:
+		review.Status.Allowed = c.clusterScopedAccess
+		return nil
+	case *authorizationv1.SelfSubjectRulesReview:
+		rules, exists := c.namespaceRules[review.Spec.Namespace]
+		if !exists {
+			return fmt.Errorf("simulated error reviewing namespace '%s'", review.Spec.Namespace)
+		}
+		review.Status.ResourceRules = rules
+		return nil
+	}
+
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+var _ = Describe("SharedResourceEventLoop ManagedEnvironment cluster info Test", func() {
+
+	Context("discoverClusterInfo tests", func() {
+
+		var ctx context.Context
+		var fakeClientBuilder *fake.ClientBuilder
+
+		selfReviewRules := []authorizationv1.ResourceRule{
+			{Verbs: []string{"create"}, APIGroups: []string{"authorization.k8s.io"}, Resources: []string{"selfsubjectaccessreviews", "selfsubjectrulesreviews"}},
+			{Verbs: []string{"create"}, APIGroups: []string{"authentication.k8s.io"}, Resources: []string{"selfsubjectreviews"}},
+		}
+
+		// The rules that OpenShift grants to every user in every Namespace, via the basic-user, self-access-reviewer and
+		// system:oauth-token-deleter cluster roles
+		openShiftBasicUserRules := append([]authorizationv1.ResourceRule{
+			{Verbs: []string{"get"}, APIGroups: []string{"", "user.openshift.io"}, Resources: []string{"users"}, ResourceNames: []string{"~"}},
+			{Verbs: []string{"list"}, APIGroups: []string{"", "project.openshift.io"}, Resources: []string{"projectrequests"}},
+			{Verbs: []string{"get", "list", "watch"}, APIGroups: []string{"", "authorization.openshift.io"}, Resources: []string{"clusterroles"}},
+			{Verbs: []string{"get", "list", "watch"}, APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"clusterroles"}},
+			{Verbs: []string{"get", "list"}, APIGroups: []string{"storage.k8s.io"}, Resources: []string{"storageclasses"}},
+			{Verbs: []string{"list", "watch"}, APIGroups: []string{"", "project.openshift.io"}, Resources: []string{"projects"}},
+			{Verbs: []string{"create"}, APIGroups: []string{"", "authorization.openshift.io"}, Resources: []string{"selfsubjectrulesreviews"}},
+			{Verbs: []string{"delete"}, APIGroups: []string{"", "oauth.openshift.io"}, Resources: []string{"oauthaccesstokens", "useroauthaccesstokens"}},
+		}, selfReviewRules...)
+
+		BeforeEach(func() {
+			scheme, _, _, _, err := tests.GenericTestSetup()
+			Expect(err).ToNot(HaveOccurred())
+
+			ctx = context.Background()
+			fakeClientBuilder = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+				&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}})
+		})
+
+		It("should report a Kubernetes cluster, its node count, and cluster-scoped access", func() {
+
+			k8sClient := accessReviewClient{Client: fakeClientBuilder.Build(), clusterScopedAccess: true}
+
+			clusterInfo, err := discoverClusterInfo(ctx, nil, k8sClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(clusterInfo.Platform).To(Equal(managedgitopsv1alpha1.ManagedEnvironmentPlatformKubernetes))
+			Expect(clusterInfo.NodeCount).ToNot(BeNil())
+			Expect(*clusterInfo.NodeCount).To(Equal(int32(2)))
+			Expect(clusterInfo.ClusterScopedAccess).ToNot(BeNil())
+			Expect(*clusterInfo.ClusterScopedAccess).To(BeTrue())
+			Expect(clusterInfo.ReachableNamespaces).To(BeNil())
+		})
+
+		It("should report an OpenShift cluster", func() {
+
+			configGroupVersion := schema.GroupVersion{Group: "config.openshift.io", Version: "v1"}
+			restMapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{configGroupVersion})
+			restMapper.Add(configGroupVersion.WithKind("ClusterVersion"), meta.RESTScopeRoot)
+
+			k8sClient := accessReviewClient{Client: fakeClientBuilder.WithRESTMapper(restMapper).Build()}
+
+			clusterInfo, err := discoverClusterInfo(ctx, nil, k8sClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(clusterInfo.Platform).To(Equal(managedgitopsv1alpha1.ManagedEnvironmentPlatformOpenShift))
+			Expect(clusterInfo.ClusterScopedAccess).ToNot(BeNil())
+			Expect(*clusterInfo.ClusterScopedAccess).To(BeFalse())
+		})
+
+		It("should only report the namespaces in which permissions other than self review have been granted", func() {
+
+			k8sClient := accessReviewClient{
+				Client: fakeClientBuilder.Build(),
+				namespaceRules: map[string][]authorizationv1.ResourceRule{
+					"reachable-ns": append([]authorizationv1.ResourceRule{
+						{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}},
+					}, selfReviewRules...),
+					"unreachable-ns":             selfReviewRules,
+					baselineRulesReviewNamespace: selfReviewRules,
+				},
+			}
+
+			clusterInfo, err := discoverClusterInfo(ctx, []string{"reachable-ns", "unreachable-ns"}, k8sClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(clusterInfo.ReachableNamespaces).To(Equal([]string{"reachable-ns"}))
+			Expect(clusterInfo.ClusterScopedAccess).ToNot(BeNil())
+			Expect(*clusterInfo.ClusterScopedAccess).To(BeFalse())
+		})
+
+		It("should not report the namespaces in which only the default OpenShift permissions have been granted", func() {
+
+			k8sClient := accessReviewClient{
+				Client: fakeClientBuilder.Build(),
+				namespaceRules: map[string][]authorizationv1.ResourceRule{
+					"reachable-ns": append([]authorizationv1.ResourceRule{
+						{Verbs: []string{"get", "list", "watch"}, APIGroups: []string{"apps"}, Resources: []string{"deployments"}},
+					}, openShiftBasicUserRules...),
+					"unreachable-ns":             openShiftBasicUserRules,
+					baselineRulesReviewNamespace: openShiftBasicUserRules,
+				},
+			}
+
+			clusterInfo, err := discoverClusterInfo(ctx, []string{"reachable-ns", "unreachable-ns"}, k8sClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(clusterInfo.ReachableNamespaces).To(Equal([]string{"reachable-ns"}))
+		})
+
+		It("should not compare against a managed namespace that has the name of the baseline namespace", func() {
+
+			k8sClient := accessReviewClient{
+				Client: fakeClientBuilder.Build(),
+				namespaceRules: map[string][]authorizationv1.ResourceRule{
+					baselineRulesReviewNamespace: append([]authorizationv1.ResourceRule{
+						{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}},
+					}, selfReviewRules...),
+					baselineRulesReviewNamespace + "-x": selfReviewRules,
+				},
+			}
+
+			clusterInfo, err := discoverClusterInfo(ctx, []string{baselineRulesReviewNamespace}, k8sClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(clusterInfo.ReachableNamespaces).To(Equal([]string{baselineRulesReviewNamespace}))
+		})
+
+		It("should report every namespace, if the credentials have cluster-scoped access", func() {
+
+			allRules := append([]authorizationv1.ResourceRule{
+				{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}},
+			}, selfReviewRules...)
+
+			k8sClient := accessReviewClient{
+				Client:              fakeClientBuilder.Build(),
+				clusterScopedAccess: true,
+				namespaceRules: map[string][]authorizationv1.ResourceRule{
+					"ns-1":                       allRules,
+					"ns-2":                       allRules,
+					baselineRulesReviewNamespace: allRules,
+				},
+			}
+
+			clusterInfo, err := discoverClusterInfo(ctx, []string{"ns-1", "ns-2"}, k8sClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(clusterInfo.ReachableNamespaces).To(Equal([]string{"ns-1", "ns-2"}))
+		})
+
+		It("should return an error, but still report the other facts, if a namespace could not be reviewed", func() {
+
+			k8sClient := accessReviewClient{
+				Client: fakeClientBuilder.Build(),
+				namespaceRules: map[string][]authorizationv1.ResourceRule{
+					baselineRulesReviewNamespace: selfReviewRules,
+				},
+			}
+
+			clusterInfo, err := discoverClusterInfo(ctx, []string{"unknown-ns"}, k8sClient)
+			Expect(err).To(HaveOccurred())
+			Expect(clusterInfo.ReachableNamespaces).To(BeNil())
+			Expect(clusterInfo.Platform).To(Equal(managedgitopsv1alpha1.ManagedEnvironmentPlatformKubernetes))
+			Expect(clusterInfo.NodeCount).ToNot(BeNil())
+		})
+
+		It("should discover the facts using the cluster credentials of the managed environment", func() {
+
+			k8sClient := accessReviewClient{
+				Client:              fakeClientBuilder.Build(),
+				clusterScopedAccess: true,
+				namespaceRules: map[string][]authorizationv1.ResourceRule{
+					"ns-1": {{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"pods"}}},
+				},
+			}
+
+			clusterInfo, err := DiscoverManagedEnvironmentClusterInfo(ctx, db.ClusterCredentials{
+				Host:                        "https://api.fake-unit-test-data.origin-ci-int-gce.dev.rhcloud.com:6443",
+				Serviceaccount_bearer_token: "token",
+				Namespaces:                  "ns-1",
+			}, MockSRLK8sClientFactory{fakeClient: k8sClient})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(clusterInfo.ReachableNamespaces).To(Equal([]string{"ns-1"}))
+			Expect(*clusterInfo.NodeCount).To(Equal(int32(2)))
+		})
+	})
+
+	Context("SetManagedEnvironmentClusterInfo tests", func() {
+
+		var managedEnvCR managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment
+
+		int32Ptr := func(i int32) *int32 { return &i }
+		boolPtr := func(b bool) *bool { return &b }
+
+		BeforeEach(func() {
+			managedEnvCR = managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment{
+				Status: managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironmentStatus{
+					ResolvedNamespaces: []string{"ns-1", "ns-2"},
+				},
+			}
+		})
+
+		It("should set the discovered facts, and report whether the status changed", func() {
+
+			clusterInfo := ManagedEnvironmentClusterInfo{
+				Platform:            managedgitopsv1alpha1.ManagedEnvironmentPlatformOpenShift,
+				NodeCount:           int32Ptr(3),
+				ReachableNamespaces: []string{"ns-1"},
+				ClusterScopedAccess: boolPtr(false),
+			}
+
+			Expect(SetManagedEnvironmentClusterInfo(&managedEnvCR, clusterInfo)).To(BeTrue())
+			Expect(managedEnvCR.Status.Platform).To(Equal(managedgitopsv1alpha1.ManagedEnvironmentPlatformOpenShift))
+			Expect(*managedEnvCR.Status.NodeCount).To(Equal(int32(3)))
+			Expect(managedEnvCR.Status.ReachableNamespaces).To(Equal([]string{"ns-1"}))
+			Expect(*managedEnvCR.Status.ClusterScopedAccess).To(BeFalse())
+
+			By("setting the same facts again, which should not change the status")
+			Expect(SetManagedEnvironmentClusterInfo(&managedEnvCR, clusterInfo)).To(BeFalse())
+		})
+
+		It("should retain the previous value of facts that could not be discovered", func() {
+
+			managedEnvCR.Status.Platform = managedgitopsv1alpha1.ManagedEnvironmentPlatformKubernetes
+			managedEnvCR.Status.NodeCount = int32Ptr(5)
+			managedEnvCR.Status.ReachableNamespaces = []string{"ns-2"}
+
+			Expect(SetManagedEnvironmentClusterInfo(&managedEnvCR, ManagedEnvironmentClusterInfo{})).To(BeFalse())
+			Expect(managedEnvCR.Status.Platform).To(Equal(managedgitopsv1alpha1.ManagedEnvironmentPlatformKubernetes))
+			Expect(*managedEnvCR.Status.NodeCount).To(Equal(int32(5)))
+			Expect(managedEnvCR.Status.ReachableNamespaces).To(Equal([]string{"ns-2"}))
+		})
+
+		It("should clear the reachable namespaces, if the managed environment is no longer restricted to namespaces", func() {
+
+			managedEnvCR.Status.ResolvedNamespaces = nil
+			managedEnvCR.Status.ReachableNamespaces = []string{"ns-1"}
+
+			Expect(SetManagedEnvironmentClusterInfo(&managedEnvCR, ManagedEnvironmentClusterInfo{})).To(BeTrue())
+			Expect(managedEnvCR.Status.ReachableNamespaces).To(BeNil())
+		})
+	})
+})
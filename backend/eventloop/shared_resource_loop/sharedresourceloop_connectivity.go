@@ -0,0 +1,167 @@
+package shared_resource_loop
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// connectivityProbeTimeout is the maximum time to wait for the API server of a managed environment to respond to a probe.
+	connectivityProbeTimeout = 10 * time.Second
+
+	// contactDetailsUpdateInterval is the minimum time between writes of the status of a ManagedEnvironment CR, when
+	// the latency and the last successful contact time are the only fields that changed.
+	contactDetailsUpdateInterval = 15 * time.Minute
+)
+
+// ManagedEnvironmentConnectivity is the result of probing the API server of a managed environment.
+type ManagedEnvironmentConnectivity struct {
+	// Reachable is true if the API server responded successfully to the probe, false otherwise.
+	Reachable bool
+
+	// Reason and Message describe the result of the probe, and are set on the Reachable condition of the ManagedEnvironment CR.
+	Reason  managedgitopsv1alpha1.ManagedEnvironmentConditionReason
+	Message string
+
+	// ServerVersion is the Kubernetes version reported by the API server. Only set if Reachable is true.
+	ServerVersion string
+
+	// Latency is the time taken by the API server to respond. Only set if Reachable is true.
+	Latency time.Duration
+
+	// ContactTime is the time at which the probe was sent.
+	ContactTime time.Time
+}
+
+// ProbeManagedEnvironmentConnectivity contacts the API server of a managed environment using the given cluster credentials,
+// and returns whether it was reachable, along with the version it reported and the time it took to respond.
+func ProbeManagedEnvironmentConnectivity(clusterCreds db.ClusterCredentials) ManagedEnvironmentConnectivity {
+
+	res := ManagedEnvironmentConnectivity{ContactTime: time.Now()}
+
+	configParam, _, err := sanityTestCredentials(clusterCreds)
+	if err != nil {
+		res.Reason = managedgitopsv1alpha1.ConditionReasonUnableToRetrieveRestConfig
+		res.Message = fmt.Sprintf("unable to create client configuration from cluster credentials: %v", err)
+		return res
+	}
+	configParam.Timeout = connectivityProbeTimeout
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(configParam)
+	if err != nil {
+		res.Reason = managedgitopsv1alpha1.ConditionReasonUnableToCreateClient
+		res.Message = fmt.Sprintf("unable to create client for '%s': %v", configParam.Host, err)
+		return res
+	}
+
+	versionInfo, err := discoveryClient.ServerVersion()
+	latency := time.Since(res.ContactTime)
+	if err != nil {
+		if apierr.IsUnauthorized(err) || apierr.IsForbidden(err) {
+			res.Reason = managedgitopsv1alpha1.ConditionReasonUnauthorized
+			res.Message = fmt.Sprintf("the API server at '%s' rejected the cluster credentials: %v", configParam.Host, err)
+		} else {
+			res.Reason = managedgitopsv1alpha1.ConditionReasonUnreachable
+			res.Message = fmt.Sprintf("unable to contact the API server at '%s': %v", configParam.Host, err)
+		}
+		return res
+	}
+
+	res.Reachable = true
+	res.Reason = managedgitopsv1alpha1.ConditionReasonSucceeded
+	res.Message = fmt.Sprintf("the API server at '%s' is reachable", configParam.Host)
+	res.ServerVersion = versionInfo.GitVersion
+	res.Latency = latency
+
+	return res
+}
+
+// UpdateManagedEnvironmentConnectivityStatus sets the Reachable condition of the ManagedEnvironment CR, based on the
+// result of a connectivity probe. On success, the server version, latency, and last successful contact time are
+// also updated; otherwise these retain the values of the last successful contact.
+//
+// The latency and the last successful contact time change on every probe, so if they are the only fields that changed,
+// the status is only written once every contactDetailsUpdateInterval. statusChanged should be true if the caller has
+// already changed other fields of the status (for example, the discovered cluster info), in which case the status is
+// always written.
+func UpdateManagedEnvironmentConnectivityStatus(ctx context.Context, managedEnvironment managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment,
+	k8sClient client.Client, connectivity ManagedEnvironmentConnectivity, statusChanged bool) error {
+
+	originalStatus := managedEnvironment.Status.DeepCopy()
+
+	status := metav1.ConditionFalse
+	if connectivity.Reachable {
+		status = metav1.ConditionTrue
+	}
+
+	const conditionType = managedgitopsv1alpha1.ManagedEnvironmentStatusReachable
+	var condition *metav1.Condition = nil
+	for i := range managedEnvironment.Status.Conditions {
+		if managedEnvironment.Status.Conditions[i].Type == conditionType {
+			condition = &managedEnvironment.Status.Conditions[i]
+			break
+		}
+	}
+	if condition == nil {
+		managedEnvironment.Status.Conditions = append(managedEnvironment.Status.Conditions, metav1.Condition{Type: conditionType})
+		condition = &managedEnvironment.Status.Conditions[len(managedEnvironment.Status.Conditions)-1]
+	}
+
+	// The transition time only changes when the API server goes from reachable to unreachable (or vice versa)
+	if condition.Status != status {
+		condition.LastTransitionTime = metav1.NewTime(connectivity.ContactTime)
+		condition.Status = status
+	}
+	condition.Reason = string(connectivity.Reason)
+	condition.Message = connectivity.Message
+
+	if connectivity.Reachable {
+		contactTime := metav1.NewTime(connectivity.ContactTime)
+		managedEnvironment.Status.ServerVersion = connectivity.ServerVersion
+		managedEnvironment.Status.ServerLatency = &metav1.Duration{Duration: connectivity.Latency}
+		managedEnvironment.Status.LastSuccessfulContactTime = &contactTime
+	}
+
+	if !statusChanged && isOnlyContactDetailsChange(*originalStatus, managedEnvironment.Status) &&
+		!isContactDetailsUpdateDue(*originalStatus, connectivity.ContactTime) {
+		return nil
+	}
+
+	if err := k8sClient.Status().Update(ctx, &managedEnvironment); err != nil {
+		return fmt.Errorf("unable to update connectivity status of managed environment '%s': %w", managedEnvironment.Name, err)
+	}
+
+	return nil
+}
+
+// isOnlyContactDetailsChange returns true if the latency and the last successful contact time are the only fields that
+// differ between the two statuses.
+func isOnlyContactDetailsChange(oldStatus, newStatus managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironmentStatus) bool {
+
+	// The first successful contact is always reported
+	if oldStatus.LastSuccessfulContactTime == nil && newStatus.LastSuccessfulContactTime != nil {
+		return false
+	}
+
+	oldStatus.ServerLatency, newStatus.ServerLatency = nil, nil
+	oldStatus.LastSuccessfulContactTime, newStatus.LastSuccessfulContactTime = nil, nil
+
+	return reflect.DeepEqual(oldStatus, newStatus)
+}
+
+// isContactDetailsUpdateDue returns true if the last successful contact time of the status is at least
+// contactDetailsUpdateInterval before the contact time of the probe, so that it does not become stale.
+func isContactDetailsUpdateDue(oldStatus managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironmentStatus, contactTime time.Time) bool {
+
+	return oldStatus.LastSuccessfulContactTime == nil ||
+		contactTime.Sub(oldStatus.LastSuccessfulContactTime.Time) >= contactDetailsUpdateInterval
+}
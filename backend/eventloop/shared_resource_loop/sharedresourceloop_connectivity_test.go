@@ -0,0 +1,220 @@
+package shared_resource_loop
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("SharedResourceEventLoop ManagedEnvironment connectivity Test", func() {
+
+	Context("ProbeManagedEnvironmentConnectivity tests", func() {
+
+		It("should report the version and latency of an API server that accepts the credentials", func() {
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer GinkgoRecover()
+
+				Expect(r.URL.Path).To(Equal("/version"))
+				Expect(r.Header.Get("Authorization")).To(Equal("Bearer valid-token"))
+
+				w.Header().Set("Content-Type", "application/json")
+				Expect(json.NewEncoder(w).Encode(version.Info{GitVersion: "v1.25.4"})).To(Succeed())
+			}))
+			defer server.Close()
+
+			connectivity := ProbeManagedEnvironmentConnectivity(db.ClusterCredentials{
+				Host:                        server.URL,
+				Serviceaccount_bearer_token: "valid-token",
+			})
+
+			Expect(connectivity.Reachable).To(BeTrue())
+			Expect(connectivity.Reason).To(Equal(managedgitopsv1alpha1.ConditionReasonSucceeded))
+			Expect(connectivity.ServerVersion).To(Equal("v1.25.4"))
+			Expect(connectivity.Latency).To(BeNumerically(">", 0))
+			Expect(connectivity.ContactTime).To(BeTemporally("~", time.Now(), time.Minute))
+		})
+
+		It("should report an API server that rejects the credentials as unauthorized", func() {
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnauthorized)
+				_ = json.NewEncoder(w).Encode(metav1.Status{
+					TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+					Status:   metav1.StatusFailure,
+					Reason:   metav1.StatusReasonUnauthorized,
+					Code:     http.StatusUnauthorized,
+				})
+			}))
+			defer server.Close()
+
+			connectivity := ProbeManagedEnvironmentConnectivity(db.ClusterCredentials{
+				Host:                        server.URL,
+				Serviceaccount_bearer_token: "invalid-token",
+			})
+
+			Expect(connectivity.Reachable).To(BeFalse())
+			Expect(connectivity.Reason).To(Equal(managedgitopsv1alpha1.ConditionReasonUnauthorized))
+			Expect(connectivity.ServerVersion).To(BeEmpty())
+		})
+
+		It("should report an API server that can't be contacted as unreachable", func() {
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			serverURL := server.URL
+			server.Close()
+
+			connectivity := ProbeManagedEnvironmentConnectivity(db.ClusterCredentials{
+				Host:                        serverURL,
+				Serviceaccount_bearer_token: "valid-token",
+			})
+
+			Expect(connectivity.Reachable).To(BeFalse())
+			Expect(connectivity.Reason).To(Equal(managedgitopsv1alpha1.ConditionReasonUnreachable))
+		})
+	})
+
+	Context("UpdateManagedEnvironmentConnectivityStatus tests", func() {
+
+		var ctx context.Context
+		var k8sClient client.Client
+		var managedEnv *managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment
+
+		BeforeEach(func() {
+			ctx = context.Background()
+
+			scheme, argocdNamespace, kubesystemNamespace, workspace, err := tests.GenericTestSetup()
+			Expect(err).ToNot(HaveOccurred())
+
+			managedEnv = &managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "managed-env",
+					Namespace: workspace.Name,
+				},
+			}
+
+			k8sClient = fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(workspace, argocdNamespace, kubesystemNamespace, managedEnv).
+				Build()
+		})
+
+		getReachableCondition := func() metav1.Condition {
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(managedEnv), managedEnv)).To(Succeed())
+			for _, condition := range managedEnv.Status.Conditions {
+				if condition.Type == managedgitopsv1alpha1.ManagedEnvironmentStatusReachable {
+					return condition
+				}
+			}
+			Fail("Reachable condition was not set")
+			return metav1.Condition{}
+		}
+
+		It("should set the Reachable condition, and retain the details of the last successful contact when unreachable", func() {
+
+			firstContact := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+			By("recording a successful contact")
+			err := UpdateManagedEnvironmentConnectivityStatus(ctx, *managedEnv, k8sClient, ManagedEnvironmentConnectivity{
+				Reachable:     true,
+				Reason:        managedgitopsv1alpha1.ConditionReasonSucceeded,
+				Message:       "reachable",
+				ServerVersion: "v1.25.4",
+				Latency:       100 * time.Millisecond,
+				ContactTime:   firstContact,
+			}, false)
+			Expect(err).ToNot(HaveOccurred())
+
+			condition := getReachableCondition()
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(string(managedgitopsv1alpha1.ConditionReasonSucceeded)))
+			Expect(managedEnv.Status.ServerVersion).To(Equal("v1.25.4"))
+			Expect(managedEnv.Status.ServerLatency.Duration).To(Equal(100 * time.Millisecond))
+			Expect(managedEnv.Status.LastSuccessfulContactTime.Time).To(BeTemporally("==", firstContact))
+
+			By("recording another successful contact, which only changes the latency and contact time, the status should not be written")
+			err = UpdateManagedEnvironmentConnectivityStatus(ctx, *managedEnv, k8sClient, ManagedEnvironmentConnectivity{
+				Reachable:     true,
+				Reason:        managedgitopsv1alpha1.ConditionReasonSucceeded,
+				Message:       "reachable",
+				ServerVersion: "v1.25.4",
+				Latency:       200 * time.Millisecond,
+				ContactTime:   firstContact.Add(time.Minute),
+			}, false)
+			Expect(err).ToNot(HaveOccurred())
+
+			resourceVersion := managedEnv.ResourceVersion
+			condition = getReachableCondition()
+			Expect(managedEnv.ResourceVersion).To(Equal(resourceVersion))
+			Expect(managedEnv.Status.ServerLatency.Duration).To(Equal(100 * time.Millisecond))
+			Expect(managedEnv.Status.LastSuccessfulContactTime.Time).To(BeTemporally("==", firstContact))
+
+			By("recording another successful contact, once the last successful contact time is due to be updated, the status should be written")
+			err = UpdateManagedEnvironmentConnectivityStatus(ctx, *managedEnv, k8sClient, ManagedEnvironmentConnectivity{
+				Reachable:     true,
+				Reason:        managedgitopsv1alpha1.ConditionReasonSucceeded,
+				Message:       "reachable",
+				ServerVersion: "v1.25.4",
+				Latency:       300 * time.Millisecond,
+				ContactTime:   firstContact.Add(contactDetailsUpdateInterval),
+			}, false)
+			Expect(err).ToNot(HaveOccurred())
+
+			condition = getReachableCondition()
+			Expect(managedEnv.ResourceVersion).ToNot(Equal(resourceVersion))
+			Expect(managedEnv.Status.ServerLatency.Duration).To(Equal(300 * time.Millisecond))
+			Expect(managedEnv.Status.LastSuccessfulContactTime.Time).To(BeTemporally("==", firstContact.Add(contactDetailsUpdateInterval)))
+			Expect(condition.LastTransitionTime.Time).To(BeTemporally("==", firstContact))
+
+			secondContact := firstContact.Add(contactDetailsUpdateInterval + time.Minute)
+
+			By("recording another successful contact, when the caller has changed other fields of the status, the transition time should not change")
+			managedEnv.Status.Platform = managedgitopsv1alpha1.ManagedEnvironmentPlatformOpenShift
+			err = UpdateManagedEnvironmentConnectivityStatus(ctx, *managedEnv, k8sClient, ManagedEnvironmentConnectivity{
+				Reachable:     true,
+				Reason:        managedgitopsv1alpha1.ConditionReasonSucceeded,
+				Message:       "reachable",
+				ServerVersion: "v1.25.4",
+				Latency:       200 * time.Millisecond,
+				ContactTime:   secondContact,
+			}, true)
+			Expect(err).ToNot(HaveOccurred())
+
+			condition = getReachableCondition()
+			Expect(managedEnv.Status.Platform).To(Equal(managedgitopsv1alpha1.ManagedEnvironmentPlatformOpenShift))
+			Expect(condition.LastTransitionTime.Time).To(BeTemporally("==", firstContact))
+			Expect(managedEnv.Status.ServerLatency.Duration).To(Equal(200 * time.Millisecond))
+			Expect(managedEnv.Status.LastSuccessfulContactTime.Time).To(BeTemporally("==", secondContact))
+
+			By("recording a failed contact")
+			err = UpdateManagedEnvironmentConnectivityStatus(ctx, *managedEnv, k8sClient, ManagedEnvironmentConnectivity{
+				Reachable:   false,
+				Reason:      managedgitopsv1alpha1.ConditionReasonUnreachable,
+				Message:     "unreachable",
+				ContactTime: secondContact.Add(time.Minute),
+			}, false)
+			Expect(err).ToNot(HaveOccurred())
+
+			condition = getReachableCondition()
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(string(managedgitopsv1alpha1.ConditionReasonUnreachable)))
+			Expect(condition.Message).To(Equal("unreachable"))
+			Expect(condition.LastTransitionTime.Time).To(BeTemporally("==", secondContact.Add(time.Minute)))
+			Expect(managedEnv.Status.ServerVersion).To(Equal("v1.25.4"))
+			Expect(managedEnv.Status.LastSuccessfulContactTime.Time).To(BeTemporally("==", secondContact))
+		})
+	})
+})
@@ -0,0 +1,201 @@
+package shared_resource_loop
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// defaultGitHubAPIURL is the base URL of the GitHub API, used when a GitHub App does not specify an enterprise base URL.
+	defaultGitHubAPIURL = "https://api.github.com"
+
+	// gitHubAppTokenRequestTimeout is the maximum time to wait for the GitHub API to mint an installation access token.
+	gitHubAppTokenRequestTimeout = 10 * time.Second
+
+	// gitHubAppJWTExpiration is the lifetime of the JWT that authenticates as the GitHub App. GitHub allows at most 10 minutes.
+	gitHubAppJWTExpiration = 5 * time.Minute
+
+	// gitHubAppTokenUsername is the username that is used, together with an installation access token, to access a
+	// Git repository over HTTPS.
+	gitHubAppTokenUsername = "x-access-token"
+)
+
+// The keys of the GitHub App credentials, in both the Secret referenced by a GitOpsDeploymentRepositoryCredential, and
+// the Argo CD repository secret.
+const (
+	secretKeyGitHubAppID                = "githubAppID"
+	secretKeyGitHubAppInstallationID    = "githubAppInstallationID"
+	secretKeyGitHubAppPrivateKey        = "githubAppPrivateKey"
+	secretKeyGitHubAppEnterpriseBaseURL = "githubAppEnterpriseBaseUrl"
+)
+
+// gitHubAppCredentials are the credentials of a GitHub App installation, used to access a private Git repository.
+type gitHubAppCredentials struct {
+	appID             string
+	installationID    string
+	privateKey        string
+	enterpriseBaseURL string
+}
+
+// gitHubAppCredentialsFromSecret returns the GitHub App credentials of the Secret, or nil if the Secret does not
+// contain GitHub App credentials.
+func gitHubAppCredentialsFromSecret(secret *corev1.Secret) *gitHubAppCredentials {
+
+	res := gitHubAppCredentials{
+		appID:             string(secret.Data[secretKeyGitHubAppID]),
+		installationID:    string(secret.Data[secretKeyGitHubAppInstallationID]),
+		privateKey:        string(secret.Data[secretKeyGitHubAppPrivateKey]),
+		enterpriseBaseURL: string(secret.Data[secretKeyGitHubAppEnterpriseBaseURL]),
+	}
+
+	if res.appID == "" && res.installationID == "" && res.privateKey == "" {
+		return nil
+	}
+
+	return &res
+}
+
+// apiURL returns the base URL of the GitHub API that the GitHub App is installed in.
+func (c gitHubAppCredentials) apiURL() string {
+	if c.enterpriseBaseURL != "" {
+		return strings.TrimSuffix(c.enterpriseBaseURL, "/")
+	}
+	return defaultGitHubAPIURL
+}
+
+// mintGitHubAppInstallationToken requests an installation access token from the GitHub API, using the HTTP client,
+// authenticating as the GitHub App using a JWT that is signed with its private key. The token grants access to the
+// repositories of the installation.
+func mintGitHubAppInstallationToken(ctx context.Context, httpClient *http.Client, creds gitHubAppCredentials) (string, error) {
+
+	if _, err := strconv.ParseInt(creds.appID, 10, 64); err != nil {
+		return "", fmt.Errorf("GitHub App ID '%s' is not a number", creds.appID)
+	}
+
+	if _, err := strconv.ParseInt(creds.installationID, 10, 64); err != nil {
+		return "", fmt.Errorf("GitHub App installation ID '%s' is not a number", creds.installationID)
+	}
+
+	// The JWT and the token are credentials, so they must not be sent in the clear
+	apiURL, err := url.Parse(creds.apiURL())
+	if err != nil || apiURL.Scheme != "https" || apiURL.Host == "" {
+		return "", fmt.Errorf("GitHub Enterprise base URL '%s' must be an HTTPS URL", creds.enterpriseBaseURL)
+	}
+
+	appJWT, err := generateGitHubAppJWT(creds.appID, creds.privateKey, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, gitHubAppTokenRequestTimeout)
+	defer cancel()
+
+	tokenURL := fmt.Sprintf("%s/app/installations/%s/access_tokens", creds.apiURL(), creds.installationID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("unable to create GitHub App installation token request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+appJWT)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("unable to request GitHub App installation token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+	if err != nil {
+		return "", fmt.Errorf("unable to read GitHub App installation token response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("GitHub App installation token request was rejected with status code %d", resp.StatusCode)
+	}
+
+	var tokenResponse struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(body, &tokenResponse); err != nil || tokenResponse.Token == "" {
+		return "", fmt.Errorf("GitHub App installation token response did not contain a token")
+	}
+
+	return tokenResponse.Token, nil
+}
+
+// generateGitHubAppJWT returns a JWT that authenticates as the GitHub App, signed (RS256) with its private key.
+// See https://docs.github.com/en/apps/creating-github-apps/authenticating-with-a-github-app/generating-a-json-web-token-jwt-for-a-github-app
+func generateGitHubAppJWT(appID string, privateKeyPEM string, now time.Time) (string, error) {
+
+	privateKey, err := parseGitHubAppPrivateKey(privateKeyPEM)
+	if err != nil {
+		return "", err
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+
+	// Backdate the issue time, to allow for clock drift between us and GitHub
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(gitHubAppJWTExpiration).Unix(),
+		"iss": appID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	hash := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", fmt.Errorf("unable to sign GitHub App JWT: %w", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parseGitHubAppPrivateKey parses a PEM encoded RSA private key, in either PKCS#1 (as generated by GitHub) or PKCS#8 form.
+func parseGitHubAppPrivateKey(privateKeyPEM string) (*rsa.PrivateKey, error) {
+
+	block, _ := pem.Decode([]byte(privateKeyPEM))
+	if block == nil {
+		return nil, fmt.Errorf("GitHub App private key is not PEM encoded")
+	}
+
+	if privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return privateKey, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse GitHub App private key: %w", err)
+	}
+
+	privateKey, isRSA := key.(*rsa.PrivateKey)
+	if !isRSA {
+		return nil, fmt.Errorf("GitHub App private key is not an RSA key")
+	}
+
+	return privateKey, nil
+}
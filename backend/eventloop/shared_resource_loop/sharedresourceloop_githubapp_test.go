@@ -0,0 +1,271 @@
+package shared_resource_loop
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// gitHubStandIn is a local stand-in for the GitHub API, which mints installation tokens for a single GitHub App
+// installation, and which serves a Git repository that is only accessible using those tokens.
+type gitHubStandIn struct {
+	*httptest.Server
+
+	appID          string
+	installationID string
+	publicKey      *rsa.PublicKey
+
+	// token is the installation token that is minted by the stand-in
+	token string
+
+	// tokenRequests is the number of installation token requests that were received
+	tokenRequests int
+}
+
+func newGitHubStandIn(appID string, installationID string, publicKey *rsa.PublicKey) *gitHubStandIn {
+
+	standIn := &gitHubStandIn{appID: appID, installationID: installationID, publicKey: publicKey, token: "ghs_test-installation-token"}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/app/installations/", func(w http.ResponseWriter, r *http.Request) {
+		standIn.tokenRequests++
+
+		if r.Method != http.MethodPost || r.URL.Path != "/app/installations/"+standIn.installationID+"/access_tokens" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if !standIn.isValidAppJWT(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": standIn.token})
+	})
+
+	// The Git smart HTTP protocol: advertise a single branch of the repository
+	mux.HandleFunc("/org/repo/info/refs", func(w http.ResponseWriter, r *http.Request) {
+
+		if username, password, ok := r.BasicAuth(); !ok || username != gitHubAppTokenUsername || password != standIn.token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		pktLine := func(s string) string { return fmt.Sprintf("%04x%s", len(s)+4, s) }
+		sha := strings.Repeat("a", 40)
+
+		w.Header().Set("Content-Type", "application/x-git-upload-pack-advertisement")
+		_, _ = w.Write([]byte(pktLine("# service=git-upload-pack\n") + "0000" +
+			pktLine(sha+" HEAD\x00symref=HEAD:refs/heads/main\n") + pktLine(sha+" refs/heads/main\n") + "0000"))
+	})
+
+	standIn.Server = httptest.NewTLSServer(mux)
+
+	return standIn
+}
+
+// isValidAppJWT returns true if the JWT was issued by the GitHub App, and signed with its private key.
+func (s *gitHubStandIn) isValidAppJWT(jwt string) bool {
+
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return false
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(s.publicKey, crypto.SHA256, hash[:], signature); err != nil {
+		return false
+	}
+
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+	var claims struct {
+		Iss string `json:"iss"`
+		Iat int64  `json:"iat"`
+		Exp int64  `json:"exp"`
+	}
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		return false
+	}
+
+	return claims.Iss == s.appID && claims.Exp > claims.Iat
+}
+
+var _ = Describe("SharedResourceEventLoop GitHub App Tests", func() {
+
+	Context("Test GitHub App installation tokens", func() {
+
+		const (
+			appID          = "123456"
+			installationID = "7890"
+		)
+
+		var (
+			ctx           context.Context
+			privateKeyPEM string
+			standIn       *gitHubStandIn
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+
+			privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+			Expect(err).ToNot(HaveOccurred())
+			privateKeyPEM = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}))
+
+			standIn = newGitHubStandIn(appID, installationID, &privateKey.PublicKey)
+		})
+
+		AfterEach(func() {
+			standIn.Close()
+		})
+
+		It("should mint an installation token, authenticating as the GitHub App", func() {
+
+			token, err := mintGitHubAppInstallationToken(ctx, standIn.Client(), gitHubAppCredentials{
+				appID:             appID,
+				installationID:    installationID,
+				privateKey:        privateKeyPEM,
+				enterpriseBaseURL: standIn.URL + "/",
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(token).To(Equal(standIn.token))
+		})
+
+		It("should accept a private key in PKCS#8 form", func() {
+
+			privateKey, err := parseGitHubAppPrivateKey(privateKeyPEM)
+			Expect(err).ToNot(HaveOccurred())
+			pkcs8, err := x509.MarshalPKCS8PrivateKey(privateKey)
+			Expect(err).ToNot(HaveOccurred())
+
+			token, err := mintGitHubAppInstallationToken(ctx, standIn.Client(), gitHubAppCredentials{
+				appID:             appID,
+				installationID:    installationID,
+				privateKey:        string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})),
+				enterpriseBaseURL: standIn.URL,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(token).To(Equal(standIn.token))
+		})
+
+		DescribeTable("should return an error if the GitHub App credentials are rejected",
+			func(modify func(creds *gitHubAppCredentials)) {
+
+				creds := gitHubAppCredentials{
+					appID:             appID,
+					installationID:    installationID,
+					privateKey:        privateKeyPEM,
+					enterpriseBaseURL: standIn.URL,
+				}
+				modify(&creds)
+
+				_, err := mintGitHubAppInstallationToken(ctx, standIn.Client(), creds)
+				Expect(err).To(HaveOccurred())
+			},
+			Entry("the app ID is of another GitHub App", func(creds *gitHubAppCredentials) { creds.appID = "654321" }),
+			Entry("the installation ID is of another installation", func(creds *gitHubAppCredentials) { creds.installationID = "1" }),
+			Entry("the private key is of another GitHub App", func(creds *gitHubAppCredentials) {
+				otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+				Expect(err).ToNot(HaveOccurred())
+				creds.privateKey = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(otherKey)}))
+			}),
+		)
+
+		DescribeTable("should return an error, without contacting GitHub, if the GitHub App credentials are malformed",
+			func(modify func(creds *gitHubAppCredentials)) {
+
+				creds := gitHubAppCredentials{
+					appID:             appID,
+					installationID:    installationID,
+					privateKey:        privateKeyPEM,
+					enterpriseBaseURL: standIn.URL,
+				}
+				modify(&creds)
+
+				_, err := mintGitHubAppInstallationToken(ctx, standIn.Client(), creds)
+				Expect(err).To(HaveOccurred())
+				Expect(standIn.tokenRequests).To(Equal(0))
+			},
+			Entry("the app ID is not a number", func(creds *gitHubAppCredentials) { creds.appID = "my-app" }),
+			Entry("the installation ID is missing", func(creds *gitHubAppCredentials) { creds.installationID = "" }),
+			Entry("the private key is not PEM encoded", func(creds *gitHubAppCredentials) { creds.privateKey = "not-a-key" }),
+			Entry("the enterprise base URL is not HTTPS", func(creds *gitHubAppCredentials) {
+				creds.enterpriseBaseURL = strings.Replace(creds.enterpriseBaseURL, "https://", "http://", 1)
+			}),
+		)
+
+		It("should validate the repository credentials using the installation token", func() {
+
+			secret := &corev1.Secret{
+				Data: map[string][]byte{
+					"githubAppID":                []byte(appID),
+					"githubAppInstallationID":    []byte(installationID),
+					"githubAppPrivateKey":        []byte(privateKeyPEM),
+					"githubAppEnterpriseBaseUrl": []byte(standIn.URL),
+					// The stand-in uses a self-signed certificate
+					"tlsCACertData": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: standIn.Certificate().Raw}),
+				},
+			}
+
+			err := validateRepositoryCredentials(ctx, standIn.URL+"/org/repo.git", secret, "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(standIn.tokenRequests).To(Equal(1))
+
+			By("rejecting GitHub App credentials that are unable to mint a token")
+			secret.Data["githubAppInstallationID"] = []byte("1")
+			err = validateRepositoryCredentials(ctx, standIn.URL+"/org/repo.git", secret, "")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).ToNot(ContainSubstring("not found"), "the credentials, not the repository, are invalid")
+
+			By("rejecting GitHub App credentials for a repository URL that is not HTTPS")
+			err = validateRepositoryCredentials(ctx, "git@github.com:org/repo.git", secret, "")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("Test gitHubAppCredentialsFromSecret", func() {
+
+		It("should only return GitHub App credentials if the Secret contains them", func() {
+
+			Expect(gitHubAppCredentialsFromSecret(&corev1.Secret{Data: map[string][]byte{
+				"username": []byte("username"),
+				"password": []byte("password"),
+			}})).To(BeNil())
+
+			creds := gitHubAppCredentialsFromSecret(&corev1.Secret{Data: map[string][]byte{
+				"githubAppID":             []byte("123456"),
+				"githubAppInstallationID": []byte("7890"),
+				"githubAppPrivateKey":     []byte("key"),
+			}})
+			Expect(creds).ToNot(BeNil())
+			Expect(creds.appID).To(Equal("123456"))
+			Expect(creds.installationID).To(Equal("7890"))
+			Expect(creds.privateKey).To(Equal("key"))
+			Expect(creds.apiURL()).To(Equal(defaultGitHubAPIURL))
+		})
+	})
+})
@@ -0,0 +1,317 @@
+package shared_resource_loop
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// registryRequestTimeout is the maximum time to wait for a Helm chart repository or OCI registry to validate the credentials.
+	registryRequestTimeout = 10 * time.Second
+
+	// registryMaxResponseSize is the maximum size of a response that is read from a Helm chart repository or OCI registry.
+	registryMaxResponseSize = 10 * 1024 * 1024
+)
+
+// validateRepositoryCredentialsOfType validates the credentials of the Secret against the repository, in the way that is
+// appropriate for the type of the repository. The SSH known hosts entries are only used by Git repositories.
+func validateRepositoryCredentialsOfType(ctx context.Context, repoType managedgitopsv1alpha1.RepositoryCredentialType, rawRepoURL string, secret *corev1.Secret, knownHosts string) error {
+
+	if repoType != managedgitopsv1alpha1.RepositoryCredentialTypeHelm && repoType != managedgitopsv1alpha1.RepositoryCredentialTypeOCI {
+		return validateRepositoryCredentials(ctx, rawRepoURL, secret, knownHosts)
+	}
+
+	httpClient, err := repositoryTLSOptionsFromSecret(secret).httpClient(registryRequestTimeout)
+	if err != nil {
+		return err
+	}
+
+	if repoType == managedgitopsv1alpha1.RepositoryCredentialTypeHelm {
+		return validateHelmRepositoryCredentials(ctx, httpClient, rawRepoURL, secret)
+	}
+
+	return validateOCIRegistryCredentials(ctx, httpClient, rawRepoURL, secret)
+}
+
+// registryCredentialsFromSecret returns the username and password of the Secret, which are the only credentials that
+// are supported by Helm chart repositories and OCI registries.
+func registryCredentialsFromSecret(secret *corev1.Secret) (string, string, error) {
+
+	if len(secret.Data["sshPrivateKey"]) > 0 {
+		return "", "", fmt.Errorf("SSH private keys can only be used with Git repositories")
+	}
+
+	if gitHubAppCredentialsFromSecret(secret) != nil {
+		return "", "", fmt.Errorf("GitHub App credentials can only be used with Git repositories")
+	}
+
+	return string(secret.Data["username"]), string(secret.Data["password"]), nil
+}
+
+// validateHelmRepositoryCredentials validates the credentials by fetching the index of the Helm chart repository.
+func validateHelmRepositoryCredentials(ctx context.Context, httpClient *http.Client, repoURL string, secret *corev1.Secret) error {
+
+	username, password, err := registryCredentialsFromSecret(secret)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, registryRequestTimeout)
+	defer cancel()
+
+	indexURL := strings.TrimSuffix(repoURL, "/") + "/index.yaml"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, indexURL, nil)
+	if err != nil {
+		return fmt.Errorf("unable to create Helm chart repository index request: %w", err)
+	}
+	if username != "" || password != "" {
+		req.SetBasicAuth(username, password)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to fetch Helm chart repository index '%s': %w", indexURL, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return fmt.Errorf("Helm chart repository index '%s' not found", indexURL)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("Helm chart repository rejected the credentials with status code %d", resp.StatusCode)
+	default:
+		return fmt.Errorf("unexpected status code %d when fetching Helm chart repository index '%s'", resp.StatusCode, indexURL)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, registryMaxResponseSize))
+	if err != nil {
+		return fmt.Errorf("unable to read Helm chart repository index '%s': %w", indexURL, err)
+	}
+
+	// Ensure that the URL is actually a Helm chart repository, rather than (for example) a login page
+	var index struct {
+		APIVersion string `yaml:"apiVersion"`
+	}
+	if err := yaml.Unmarshal(body, &index); err != nil || index.APIVersion == "" {
+		return fmt.Errorf("Helm chart repository not found: '%s' is not a Helm chart repository index", indexURL)
+	}
+
+	return nil
+}
+
+// validateOCIRegistryCredentials validates the credentials by authenticating with the OCI registry, following the
+// authentication challenge of its version check endpoint, and then pinging that endpoint with the resulting token.
+// See https://distribution.github.io/distribution/spec/auth/token/
+func validateOCIRegistryCredentials(ctx context.Context, httpClient *http.Client, repoURL string, secret *corev1.Secret) error {
+
+	username, password, err := registryCredentialsFromSecret(secret)
+	if err != nil {
+		return err
+	}
+
+	// As with Argo CD, an OCI registry is referenced by its host and path, without a scheme
+	registryURL, err := url.Parse("oci://" + strings.TrimPrefix(repoURL, "oci://"))
+	if err != nil || registryURL.Host == "" {
+		return fmt.Errorf("OCI registry '%s' not found: the URL must be a registry host and path", repoURL)
+	}
+	repository := strings.Trim(registryURL.Path, "/")
+
+	ctx, cancel := context.WithTimeout(ctx, registryRequestTimeout)
+	defer cancel()
+
+	pingURL := "https://" + registryURL.Host + "/v2/"
+
+	resp, err := doRegistryRequest(ctx, httpClient, pingURL, nil)
+	if err != nil {
+		return fmt.Errorf("unable to contact OCI registry '%s': %w", registryURL.Host, err)
+	}
+
+	switch resp.statusCode {
+	case http.StatusOK:
+		// The registry allows anonymous access, so there is no challenge to authenticate against
+		return nil
+	case http.StatusUnauthorized:
+	case http.StatusNotFound:
+		return fmt.Errorf("OCI registry '%s' not found: the registry does not support the OCI distribution API", registryURL.Host)
+	default:
+		return fmt.Errorf("unexpected status code %d when contacting OCI registry '%s'", resp.statusCode, registryURL.Host)
+	}
+
+	scheme, params := parseRegistryAuthChallenge(resp.header.Get("WWW-Authenticate"))
+
+	var authorization string
+
+	switch scheme {
+	case "basic":
+		authorization = "Basic " + basicAuth(username, password)
+
+	case "bearer":
+		token, err := requestRegistryToken(ctx, httpClient, params, repository, username, password)
+		if err != nil {
+			return err
+		}
+		authorization = "Bearer " + token
+
+	default:
+		return fmt.Errorf("OCI registry '%s' requested an unsupported authentication scheme '%s'", registryURL.Host, scheme)
+	}
+
+	// Ping the registry again, this time authenticated
+	resp, err = doRegistryRequest(ctx, httpClient, pingURL, map[string]string{"Authorization": authorization})
+	if err != nil {
+		return fmt.Errorf("unable to contact OCI registry '%s': %w", registryURL.Host, err)
+	}
+
+	if resp.statusCode != http.StatusOK {
+		return fmt.Errorf("OCI registry rejected the credentials with status code %d", resp.statusCode)
+	}
+
+	return nil
+}
+
+// requestRegistryToken requests a token, for pulling from the repository, from the token service of the bearer
+// authentication challenge of an OCI registry.
+func requestRegistryToken(ctx context.Context, httpClient *http.Client, challengeParams map[string]string, repository string, username string, password string) (string, error) {
+
+	realm := challengeParams["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("OCI registry authentication challenge did not contain a token service realm")
+	}
+
+	tokenURL, err := url.Parse(realm)
+	if err != nil {
+		return "", fmt.Errorf("OCI registry token service realm '%s' is invalid: %w", realm, err)
+	}
+
+	// The credentials are sent to the token service, so they must not be sent in the clear
+	if tokenURL.Scheme != "https" {
+		return "", fmt.Errorf("OCI registry token service realm '%s' must be an HTTPS URL", realm)
+	}
+
+	query := tokenURL.Query()
+	if service := challengeParams["service"]; service != "" {
+		query.Set("service", service)
+	}
+	if repository != "" {
+		query.Set("scope", "repository:"+repository+":pull")
+	}
+	tokenURL.RawQuery = query.Encode()
+
+	headers := map[string]string{}
+	if username != "" || password != "" {
+		headers["Authorization"] = "Basic " + basicAuth(username, password)
+	}
+
+	resp, err := doRegistryRequest(ctx, httpClient, tokenURL.String(), headers)
+	if err != nil {
+		return "", fmt.Errorf("unable to request token from OCI registry token service: %w", err)
+	}
+
+	if resp.statusCode == http.StatusUnauthorized || resp.statusCode == http.StatusForbidden {
+		return "", fmt.Errorf("OCI registry token service rejected the credentials with status code %d", resp.statusCode)
+	} else if resp.statusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code %d from OCI registry token service", resp.statusCode)
+	}
+
+	var tokenResponse struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(resp.body, &tokenResponse); err != nil {
+		return "", fmt.Errorf("unable to parse OCI registry token service response: %w", err)
+	}
+
+	if tokenResponse.Token != "" {
+		return tokenResponse.Token, nil
+	} else if tokenResponse.AccessToken != "" {
+		return tokenResponse.AccessToken, nil
+	}
+
+	return "", fmt.Errorf("OCI registry token service response did not contain a token")
+}
+
+// registryResponse is the status, headers, and (size limited) body of a response from an OCI registry.
+type registryResponse struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+// doRegistryRequest sends a GET request, with the given headers, to an OCI registry, and reads the response.
+func doRegistryRequest(ctx context.Context, httpClient *http.Client, requestURL string, headers map[string]string) (*registryResponse, error) {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, registryMaxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+
+	return &registryResponse{statusCode: resp.StatusCode, header: resp.Header, body: body}, nil
+}
+
+// parseRegistryAuthChallenge parses a WWW-Authenticate header, for example:
+// 'Bearer realm="https://auth.example.com/token",service="registry.example.com"', returning the (lowercase) scheme,
+// and the parameters of the challenge.
+func parseRegistryAuthChallenge(header string) (string, map[string]string) {
+
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+
+	params := map[string]string{}
+
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest), ",")) {
+
+		key, afterKey, found := strings.Cut(rest, "=")
+		if !found {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+
+		var value string
+		if strings.HasPrefix(afterKey, `"`) {
+			// Quoted values may contain commas, for example: scope="repository:my-repo:pull,push"
+			end := strings.Index(afterKey[1:], `"`)
+			if end == -1 {
+				value, rest = afterKey[1:], ""
+			} else {
+				value, rest = afterKey[1:end+1], afterKey[end+2:]
+			}
+		} else {
+			value, rest, _ = strings.Cut(afterKey, ",")
+		}
+
+		params[key] = strings.TrimSpace(value)
+	}
+
+	return strings.ToLower(scheme), params
+}
+
+// basicAuth returns the base64 encoded username and password of HTTP basic authentication.
+func basicAuth(username string, password string) string {
+	return base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+}
@@ -0,0 +1,258 @@
+package shared_resource_loop
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	registryTestUsername = "test-user"
+	registryTestPassword = "test-password"
+)
+
+// newHelmRepositoryServer returns a Helm chart repository, at '/charts', that is only accessible using the test credentials.
+func newHelmRepositoryServer() *httptest.Server {
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/charts/index.yaml", func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != registryTestUsername || password != registryTestPassword {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("apiVersion: v1\nentries:\n  my-chart:\n  - name: my-chart\n    version: 1.0.0\n"))
+	})
+
+	// A page that is not a Helm chart repository index
+	mux.HandleFunc("/login/index.yaml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html><body>Please log in</body></html>"))
+	})
+
+	return httptest.NewServer(mux)
+}
+
+// ociRegistryStandIn is a local stand-in for an OCI registry, which challenges clients to authenticate using either
+// bearer tokens (from its token service) or basic authentication.
+type ociRegistryStandIn struct {
+	*httptest.Server
+
+	// challengeScheme is the authentication scheme that the registry challenges clients with: 'Bearer' or 'Basic'
+	challengeScheme string
+
+	// anonymous is true if the registry allows anonymous access
+	anonymous bool
+
+	// token is the bearer token that is issued by the token service
+	token string
+
+	// tokenScopes are the scopes of the token requests that were received
+	tokenScopes []string
+}
+
+func newOCIRegistryStandIn() *ociRegistryStandIn {
+
+	standIn := &ociRegistryStandIn{challengeScheme: "Bearer", token: "test-registry-token"}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+
+		authorization := r.Header.Get("Authorization")
+
+		if standIn.anonymous ||
+			(standIn.challengeScheme == "Bearer" && authorization == "Bearer "+standIn.token) ||
+			(standIn.challengeScheme == "Basic" && authorization == "Basic "+basicAuth(registryTestUsername, registryTestPassword)) {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if standIn.challengeScheme == "Bearer" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+standIn.URL+`/token",service="test-registry"`)
+		} else {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test-registry"`)
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+
+		standIn.tokenScopes = append(standIn.tokenScopes, r.URL.Query().Get("scope"))
+
+		if username, password, ok := r.BasicAuth(); !ok || username != registryTestUsername || password != registryTestPassword ||
+			r.URL.Query().Get("service") != "test-registry" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": standIn.token})
+	})
+
+	standIn.Server = httptest.NewTLSServer(mux)
+
+	return standIn
+}
+
+// repositoryURL returns the URL of a repository of the registry, in the form used by Argo CD: without a scheme.
+func (s *ociRegistryStandIn) repositoryURL() string {
+	return strings.TrimPrefix(s.URL, "https://") + "/my-org/charts"
+}
+
+var _ = Describe("SharedResourceEventLoop Helm and OCI registry Tests", func() {
+
+	var (
+		ctx    context.Context
+		secret *corev1.Secret
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		secret = &corev1.Secret{
+			Data: map[string][]byte{
+				"username": []byte(registryTestUsername),
+				"password": []byte(registryTestPassword),
+			},
+		}
+	})
+
+	Context("Test validateHelmRepositoryCredentials", func() {
+
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			server = newHelmRepositoryServer()
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should validate the credentials by fetching the index of the Helm chart repository", func() {
+
+			err := validateRepositoryCredentialsOfType(ctx, managedgitopsv1alpha1.RepositoryCredentialTypeHelm, server.URL+"/charts/", secret, "")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should report credentials that are rejected by the Helm chart repository as invalid", func() {
+
+			secret.Data["password"] = []byte("wrong-password")
+
+			err := validateHelmRepositoryCredentials(ctx, server.Client(), server.URL+"/charts", secret)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).ToNot(ContainSubstring("not found"), "the credentials, not the repository, are invalid")
+		})
+
+		DescribeTable("should report a URL that is not a Helm chart repository as not found",
+			func(path string) {
+
+				err := validateHelmRepositoryCredentials(ctx, server.Client(), server.URL+path, secret)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("not found"))
+			},
+			Entry("the index does not exist", "/other-charts"),
+			Entry("the index is not a Helm chart repository index", "/login"),
+		)
+
+		It("should reject credentials that are only supported by Git repositories", func() {
+
+			secret.Data = map[string][]byte{"sshPrivateKey": []byte("key")}
+
+			err := validateHelmRepositoryCredentials(ctx, server.Client(), server.URL+"/charts", secret)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Git repositories"))
+		})
+	})
+
+	Context("Test validateOCIRegistryCredentials", func() {
+
+		var standIn *ociRegistryStandIn
+
+		BeforeEach(func() {
+			standIn = newOCIRegistryStandIn()
+		})
+
+		AfterEach(func() {
+			standIn.Close()
+		})
+
+		It("should validate the credentials by requesting a token from the token service, and pinging the registry with it", func() {
+
+			err := validateOCIRegistryCredentials(ctx, standIn.Client(), standIn.repositoryURL(), secret)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(standIn.tokenScopes).To(Equal([]string{"repository:my-org/charts:pull"}))
+		})
+
+		It("should report credentials that are rejected by the token service as invalid", func() {
+
+			secret.Data["password"] = []byte("wrong-password")
+
+			err := validateOCIRegistryCredentials(ctx, standIn.Client(), standIn.repositoryURL(), secret)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).ToNot(ContainSubstring("not found"), "the credentials, not the registry, are invalid")
+		})
+
+		It("should validate the credentials using basic authentication, if the registry requests it", func() {
+
+			standIn.challengeScheme = "Basic"
+
+			err := validateOCIRegistryCredentials(ctx, standIn.Client(), standIn.repositoryURL(), secret)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(standIn.tokenScopes).To(BeEmpty())
+
+			By("using the wrong password, which should be rejected by the registry")
+			secret.Data["password"] = []byte("wrong-password")
+			err = validateOCIRegistryCredentials(ctx, standIn.Client(), standIn.repositoryURL(), secret)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should accept a registry that allows anonymous access", func() {
+
+			standIn.anonymous = true
+
+			err := validateOCIRegistryCredentials(ctx, standIn.Client(), standIn.repositoryURL(), secret)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should not send the credentials to a token service realm that is not HTTPS", func() {
+
+			_, err := requestRegistryToken(ctx, standIn.Client(), map[string]string{
+				"realm": strings.Replace(standIn.URL, "https://", "http://", 1) + "/token",
+			}, "my-org/charts", registryTestUsername, registryTestPassword)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("must be an HTTPS URL"))
+			Expect(standIn.tokenScopes).To(BeEmpty())
+		})
+
+		It("should report a registry URL that is invalid as not found", func() {
+
+			err := validateOCIRegistryCredentials(ctx, standIn.Client(), "/my-org/charts", secret)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("not found"))
+		})
+	})
+
+	Context("Test parseRegistryAuthChallenge", func() {
+
+		DescribeTable("Test scenarios for parseRegistryAuthChallenge", func(header string, expectedScheme string, expectedParams map[string]string) {
+
+			scheme, params := parseRegistryAuthChallenge(header)
+			Expect(scheme).To(Equal(expectedScheme))
+			Expect(params).To(Equal(expectedParams))
+		},
+			Entry("Bearer challenge", `Bearer realm="https://auth.example.com/token",service="registry.example.com"`, "bearer",
+				map[string]string{"realm": "https://auth.example.com/token", "service": "registry.example.com"}),
+			Entry("Bearer challenge with a scope containing a comma", `Bearer realm="https://auth.example.com/token", scope="repository:my-repo:pull,push"`, "bearer",
+				map[string]string{"realm": "https://auth.example.com/token", "scope": "repository:my-repo:pull,push"}),
+			Entry("Basic challenge", `Basic realm="Registry Realm"`, "basic", map[string]string{"realm": "Registry Realm"}),
+			Entry("No challenge", "", "", map[string]string{}),
+		)
+	})
+})
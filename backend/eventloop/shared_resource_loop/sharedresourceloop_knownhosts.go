@@ -0,0 +1,147 @@
+package shared_resource_loop
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	cryptossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// secretKeyKnownHosts is the key of the SSH known hosts entries in the Secret referenced by a GitOpsDeploymentRepositoryCredential.
+const secretKeyKnownHosts = "knownHosts"
+
+// repositoryKnownHosts returns the SSH known hosts entries of the GitOpsDeploymentRepositoryCredential, followed by those
+// of its Secret (if any), one per line, without comments, blank lines, or duplicates.
+func repositoryKnownHosts(crKnownHosts string, secret *corev1.Secret) string {
+
+	knownHostsSources := []string{crKnownHosts}
+	if secret != nil {
+		knownHostsSources = append(knownHostsSources, string(secret.Data[secretKeyKnownHosts]))
+	}
+
+	var entries []string
+	seen := map[string]bool{}
+
+	for _, knownHosts := range knownHostsSources {
+		for _, line := range strings.Split(knownHosts, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") || seen[line] {
+				continue
+			}
+			seen[line] = true
+			entries = append(entries, line)
+		}
+	}
+
+	if len(entries) == 0 {
+		return ""
+	}
+
+	return strings.Join(entries, "\n") + "\n"
+}
+
+// validateKnownHosts returns an error if a (non-comment) line is not a valid SSH known hosts entry.
+func validateKnownHosts(knownHosts string) error {
+
+	for idx, line := range strings.Split(knownHosts, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, _, _, _, _, err := cryptossh.ParseKnownHosts([]byte(line)); err != nil {
+			return fmt.Errorf("invalid SSH known hosts entry on line %d: %w", idx+1, err)
+		}
+	}
+
+	return nil
+}
+
+// hostKeyError is returned when the host key of an SSH server cannot be verified against the known hosts entries: either
+// the key does not match the entries of the host, or there are no entries for the host.
+type hostKeyError struct {
+	host string
+
+	// mismatch is true if the host has known hosts entries, but none of them match the key of the server
+	mismatch bool
+}
+
+func (e *hostKeyError) Error() string {
+	if e.mismatch {
+		return fmt.Sprintf("SSH host key of '%s' does not match its known hosts entries", e.host)
+	}
+	return fmt.Sprintf("SSH host '%s' has no known hosts entry", e.host)
+}
+
+// strictHostKeyChecker verifies the host key of an SSH server against known hosts entries, rejecting servers without an
+// entry, and records the host key error (if any) of the connection.
+type strictHostKeyChecker struct {
+	callback cryptossh.HostKeyCallback
+
+	// err is the host key error of the connection, if the host key could not be verified
+	err *hostKeyError
+}
+
+// newStrictHostKeyChecker returns a checker of the known hosts entries or, if there are none, of the known hosts files
+// of the system (as with go-git).
+func newStrictHostKeyChecker(knownHosts string) (*strictHostKeyChecker, error) {
+
+	if strings.TrimSpace(knownHosts) == "" {
+		callback, err := ssh.NewKnownHostsCallback()
+		if err != nil {
+			return nil, fmt.Errorf("unable to verify SSH host key, as no known hosts entries were provided: %w", err)
+		}
+		return &strictHostKeyChecker{callback: callback}, nil
+	}
+
+	if err := validateKnownHosts(knownHosts); err != nil {
+		return nil, err
+	}
+
+	// knownhosts only reads entries from files
+	file, err := os.CreateTemp("", "known_hosts-")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create SSH known hosts file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(knownHosts); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("unable to write SSH known hosts file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("unable to write SSH known hosts file: %w", err)
+	}
+
+	callback, err := knownhosts.New(file.Name())
+	if err != nil {
+		return nil, fmt.Errorf("unable to read SSH known hosts entries: %w", err)
+	}
+
+	return &strictHostKeyChecker{callback: callback}, nil
+}
+
+// hostKeyCallback is the SSH host key callback of the checker.
+func (c *strictHostKeyChecker) hostKeyCallback(hostname string, remote net.Addr, key cryptossh.PublicKey) error {
+
+	err := c.callback(hostname, remote, key)
+
+	// go-git first calls the callback with a placeholder key, to determine the key algorithms of the known hosts
+	// entries of the host: only the errors of the actual host key of the server are recorded.
+	if _, parseErr := cryptossh.ParsePublicKey(key.Marshal()); parseErr != nil {
+		return err
+	}
+
+	var keyErr *knownhosts.KeyError
+	if errors.As(err, &keyErr) {
+		c.err = &hostKeyError{host: hostname, mismatch: len(keyErr.Want) > 0}
+		return c.err
+	}
+
+	return err
+}
@@ -0,0 +1,227 @@
+package shared_resource_loop
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	cryptossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// generateTestSSHKey returns an SSH key pair: the signer, and the PEM encoded private key.
+func generateTestSSHKey() (cryptossh.Signer, string) {
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+
+	signer, err := cryptossh.NewSignerFromKey(privateKey)
+	Expect(err).ToNot(HaveOccurred())
+
+	der, err := x509.MarshalECPrivateKey(privateKey)
+	Expect(err).ToNot(HaveOccurred())
+
+	return signer, string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+}
+
+// newSSHServerStandIn returns a listener of an SSH server, with the host key, which completes the SSH handshake, but
+// does not serve any Git repositories.
+func newSSHServerStandIn(hostKey cryptossh.Signer) net.Listener {
+
+	config := &cryptossh.ServerConfig{NoClientAuth: true}
+	config.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, _, _, _ = cryptossh.NewServerConn(conn, config)
+				conn.Close()
+			}()
+		}
+	}()
+
+	return listener
+}
+
+var _ = Describe("SharedResourceEventLoop SSH known hosts Tests", func() {
+
+	Context("Test repositoryKnownHosts", func() {
+
+		It("should combine the known hosts entries of the CR and the Secret, without comments or duplicates", func() {
+
+			secret := &corev1.Secret{Data: map[string][]byte{
+				"knownHosts": []byte("# a comment\ngit.example.com ssh-ed25519 AAAA-b\n\ngit.example.com ssh-ed25519 AAAA-a\n"),
+			}}
+
+			Expect(repositoryKnownHosts("  git.example.com ssh-ed25519 AAAA-a  \n", secret)).
+				To(Equal("git.example.com ssh-ed25519 AAAA-a\ngit.example.com ssh-ed25519 AAAA-b\n"))
+
+			Expect(repositoryKnownHosts("", &corev1.Secret{})).To(BeEmpty())
+			Expect(repositoryKnownHosts("# only a comment", nil)).To(BeEmpty())
+		})
+	})
+
+	Context("Test strictHostKeyChecker", func() {
+
+		var (
+			hostKey cryptossh.Signer
+			remote  *net.TCPAddr
+		)
+
+		BeforeEach(func() {
+			hostKey, _ = generateTestSSHKey()
+			remote = &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 22}
+		})
+
+		It("should accept a host key that matches the known hosts entry of the host", func() {
+
+			checker, err := newStrictHostKeyChecker(knownhosts.Line([]string{"git.example.com"}, hostKey.PublicKey()))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(checker.hostKeyCallback("git.example.com:22", remote, hostKey.PublicKey())).To(Succeed())
+			Expect(checker.err).To(BeNil())
+		})
+
+		It("should report a host key that does not match the known hosts entry of the host as a mismatch", func() {
+
+			otherKey, _ := generateTestSSHKey()
+
+			checker, err := newStrictHostKeyChecker(knownhosts.Line([]string{"git.example.com"}, otherKey.PublicKey()))
+			Expect(err).ToNot(HaveOccurred())
+
+			err = checker.hostKeyCallback("git.example.com:22", remote, hostKey.PublicKey())
+			Expect(err).To(HaveOccurred())
+			Expect(checker.err).ToNot(BeNil())
+			Expect(checker.err.mismatch).To(BeTrue())
+		})
+
+		It("should reject a host without a known hosts entry", func() {
+
+			checker, err := newStrictHostKeyChecker(knownhosts.Line([]string{"other.example.com"}, hostKey.PublicKey()))
+			Expect(err).ToNot(HaveOccurred())
+
+			err = checker.hostKeyCallback("git.example.com:22", remote, hostKey.PublicKey())
+			Expect(err).To(HaveOccurred())
+			Expect(checker.err).ToNot(BeNil())
+			Expect(checker.err.mismatch).To(BeFalse())
+		})
+
+		It("should reject known hosts entries that are invalid", func() {
+
+			_, err := newStrictHostKeyChecker("git.example.com ssh-ed25519 not-a-key")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("line 1"))
+		})
+	})
+
+	Context("Test generateValidRepositoryCredentialsConditions with SSH known hosts", func() {
+
+		var (
+			ctx        context.Context
+			listener   net.Listener
+			hostKey    cryptossh.Signer
+			repoURL    string
+			secret     *corev1.Secret
+			repoCredCR managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+
+			hostKey, _ = generateTestSSHKey()
+			listener = newSSHServerStandIn(hostKey)
+
+			repoURL = "ssh://git@" + listener.Addr().String() + "/org/repo.git"
+
+			_, clientKeyPEM := generateTestSSHKey()
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-secret"},
+				Data:       map[string][]byte{"sshPrivateKey": []byte(clientKeyPEM)},
+			}
+
+			repoCredCR = managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-repocred"},
+				Spec: managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialSpec{
+					Repository: repoURL,
+					Secret:     secret.Name,
+				},
+			}
+		})
+
+		AfterEach(func() {
+			listener.Close()
+		})
+
+		DescribeTable("should report a host key that cannot be verified with the HostKeyMismatch reason",
+			func(knownHostsAddress func() string, expectedMessage string) {
+
+				otherKey, _ := generateTestSSHKey()
+				repoCredCR.Spec.KnownHosts = knownhosts.Line([]string{knownHostsAddress()}, otherKey.PublicKey())
+
+				conditions := generateValidRepositoryCredentialsConditions(repoCredCR, repoURL, ctx, secret)
+
+				for _, condition := range conditions {
+					Expect(condition.Reason).To(Equal(managedgitopsv1alpha1.RepositoryCredentialReasonHostKeyMismatch), condition.Type)
+				}
+				Expect(conditions[0].Status).To(Equal(metav1.ConditionTrue))
+				Expect(conditions[0].Message).To(ContainSubstring(expectedMessage))
+			},
+			Entry("the host key does not match the known hosts entry of the host", func() string { return listener.Addr().String() }, "does not match"),
+			Entry("the host has no known hosts entry for the port of the repository", func() string { return "127.0.0.1" }, "has no known hosts entry"),
+		)
+
+		DescribeTable("should report known hosts entries that may not be used for the repository with the InvalidKnownHosts reason",
+			func(knownHosts func() string, expectedMessage string) {
+
+				repoCredCR.Spec.KnownHosts = knownHosts()
+
+				conditions := generateValidRepositoryCredentialsConditions(repoCredCR, repoURL, ctx, secret)
+
+				for _, condition := range conditions {
+					Expect(condition.Reason).To(Equal(managedgitopsv1alpha1.RepositoryCredentialReasonInvalidKnownHosts), condition.Type)
+				}
+				Expect(conditions[0].Status).To(Equal(metav1.ConditionTrue))
+				Expect(conditions[0].Message).To(ContainSubstring(expectedMessage))
+			},
+			Entry("the entry is for another host", func() string {
+				return knownhosts.Line([]string{"git.example.com"}, hostKey.PublicKey())
+			}, "only entries for the host of the repository"),
+			Entry("the entry is a certificate authority", func() string {
+				return "@cert-authority " + knownhosts.Line([]string{listener.Addr().String()}, hostKey.PublicKey())
+			}, "@cert-authority marker"),
+			Entry("the entry of the Secret is a wildcard", func() string {
+				secret.Data["knownHosts"] = []byte(knownhosts.Line([]string{"*"}, hostKey.PublicKey()))
+				return ""
+			}, "wildcard"),
+		)
+
+		It("should accept the host key of the known hosts entry of the Secret", func() {
+
+			secret.Data["knownHosts"] = []byte(knownhosts.Line([]string{listener.Addr().String()}, hostKey.PublicKey()))
+
+			err := validateRepositoryCredentials(ctx, repoURL, secret, repositoryKnownHosts(repoCredCR.Spec.KnownHosts, secret))
+
+			// The stand-in does not serve the repository, but the host key should have been accepted
+			Expect(err).To(HaveOccurred())
+			var hostKeyErr *hostKeyError
+			Expect(errors.As(err, &hostKeyErr)).To(BeFalse(), err.Error())
+		})
+	})
+})
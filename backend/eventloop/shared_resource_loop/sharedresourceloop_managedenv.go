@@ -2,8 +2,13 @@ package shared_resource_loop
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"os"
 	"sort"
 	"strings"
 
@@ -12,6 +17,7 @@ import (
 	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
 	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	argosharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/argocd"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/gitopserrors"
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/operations"
@@ -19,6 +25,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
@@ -31,6 +38,11 @@ import (
 const (
 	KubeconfigKey                 = "kubeconfig"
 	UnableToCreateRestConfigError = "unable to create k8s client from restConfig from managed environment secret"
+
+	// ExecPluginAllowlistEnvVar is the environment variable that contains the (comma separated) exec plugin commands that
+	// may be used by the kubeconfig of a ManagedEnvironment. Exec plugins are run by the backend, so only commands that are
+	// trusted by the operator may be used: by default, exec plugins are not supported.
+	ExecPluginAllowlistEnvVar = "MANAGED_ENVIRONMENT_EXEC_PLUGIN_ALLOWLIST"
 )
 
 func internalProcessMessage_ReconcileSharedManagedEnv(ctx context.Context, workspaceClient client.Client,
@@ -47,8 +59,17 @@ func internalProcessMessage_ReconcileSharedManagedEnv(ctx context.Context, works
 
 	if condition.reason != "" && condition.managedEnvCR.Name != "" {
 
+		managedEnvCR := condition.managedEnvCR
+
+		// On success, report the Namespaces that Argo CD is able to deploy to. The facts about the cluster are expensive
+		// to discover, and so are only refreshed periodically.
+		statusFieldsChanged := false
+		if err == nil && container.ManagedEnv != nil && condition.status == metav1.ConditionTrue {
+			statusFieldsChanged = setManagedEnvironmentResolvedNamespaces(ctx, &managedEnvCR, *container.ManagedEnv, dbQueries, log)
+		}
+
 		// If a metav1.Condition{} needs to be set, set it here.
-		updateManagedEnvironmentConnectionStatus(ctx, condition.managedEnvCR, workspaceClient, condition, log)
+		updateManagedEnvironmentConnectionStatus(ctx, managedEnvCR, workspaceClient, condition, statusFieldsChanged, log)
 
 	}
 
@@ -187,28 +208,54 @@ func internalProcessMessage_internalReconcileSharedManagedEnv(ctx context.Contex
 
 	}
 
-	managedEnvNamespaceSliceList, err := convertManagedEnvNamespacesFieldToCommaSeparatedList(managedEnvironmentCR.Spec.Namespaces)
+	serviceAccountRulesField, err := convertManagedEnvServiceAccountRulesFieldToJSON(managedEnvironmentCR.Spec)
 	if err != nil {
-		msg := fmt.Sprintf("user specified an invalid namespace: %v", err)
 		return newSharedResourceManagedEnvContainer(),
-			connectionInitializedCondition{
-				managedEnvCR: managedEnvironmentCR,
-				status:       metav1.ConditionUnknown,
-				reason:       managedgitopsv1alpha1.ConditionReasonInvalidNamespaceList,
-				message:      msg,
-			}, errors.New(msg)
+			convertErrToEnvInitCondition(managedgitopsv1alpha1.ConditionReasonInvalidServiceAccountRules, err, managedEnvironmentCR),
+			err
+	}
+
+	clusterLabelsField, clusterAnnotationsField, err := convertManagedEnvClusterSecretMetadataFieldsToJSON(managedEnvironmentCR.Spec)
+	if err != nil {
+		return newSharedResourceManagedEnvContainer(),
+			convertErrToEnvInitCondition(managedgitopsv1alpha1.ConditionReasonInvalidClusterSecretMetadata, err, managedEnvironmentCR),
+			err
 	}
 
 	// We found the managed env, now verify that the ManagedEnv's .spec values match the corresponding fields in the ClusterCredentials row
 	if clusterCreds.Host != managedEnvironmentCR.Spec.APIURL ||
 		clusterCreds.AllowInsecureSkipTLSVerify != managedEnvironmentCR.Spec.AllowInsecureSkipTLSVerify ||
 		clusterCreds.ClusterResources != managedEnvironmentCR.Spec.ClusterResources ||
-		clusterCreds.Namespaces != managedEnvNamespaceSliceList {
+		clusterCreds.Serviceaccount_rules != serviceAccountRulesField ||
+		clusterCreds.Proxy_url != managedEnvironmentCR.Spec.ProxyURL ||
+		// The installation of the ServiceAccount is not recorded in cluster credentials created by earlier versions: the
+		// ServiceAccount they installed has a ClusterRole that grants all permissions, which is replaced when it is installed again.
+		managedEnvironmentCR.Spec.CreateNewServiceAccount != (clusterCreds.Serviceaccount_installation_uid != "") ||
+		(clusterCreds.Ca_data != "" && !isSameCertificateAuthorityData(*clusterCreds, managedEnvironmentCR, secretCR)) {
 		// C) If at least one of the fields in the managed env CR has changed, then replace the cluster credentials of the managed environment
 		return replaceExistingManagedEnv(ctx, gitopsEngineClient, workspaceClient, *clusterUser, isNewUser, managedEnvironmentCR, secretCR, *managedEnv,
 			workspaceNamespace, k8sClientFactory, dbQueries, log)
 	}
 
+	// Whether the Argo CD cluster secret of the managed environment should be updated, as the cluster credentials were
+	// updated in place
+	isClusterSecretUpdateNeeded := false
+
+	// Cluster credentials that were created before the CA bundle was stored have no CA bundle: their CA bundle is unknown,
+	// rather than changed, so it is backfilled in place, rather than replacing the cluster credentials.
+	if clusterCreds.Ca_data == "" {
+		if caData, err := desiredCertificateAuthorityData(managedEnvironmentCR, secretCR); err == nil && caData != "" {
+			clusterCreds.Ca_data = caData
+			if err := dbQueries.UpdateClusterCredentials(ctx, clusterCreds); err != nil {
+				return newSharedResourceManagedEnvContainer(),
+					createGenericDatabaseErrorEnvInitCondition(managedEnvironmentCR),
+					fmt.Errorf("unable to update CA bundle of cluster credentials '%s': %w", clusterCreds.Clustercredentials_cred_id, err)
+			}
+			log.Info("backfilled CA bundle of ClusterCredentials", "clusterCreds", clusterCreds.Clustercredentials_cred_id)
+			isClusterSecretUpdateNeeded = true
+		}
+	}
+
 	// Verify that we are able to connect to the cluster using the service account token we stored
 	validClusterCreds, err := verifyClusterCredentialsWithNamespaceList(ctx, *clusterCreds, managedEnvironmentCR, k8sClientFactory)
 	if !validClusterCreds || err != nil {
@@ -219,6 +266,78 @@ func internalProcessMessage_internalReconcileSharedManagedEnv(ctx context.Contex
 			workspaceNamespace, k8sClientFactory, dbQueries, log)
 	}
 
+	// If the ManagedEnv selects Namespaces by label, resolve them using the credentials we stored: if this fails, the
+	// credentials may no longer be valid, so reacquire them using the Secret (which resolves the Namespaces again).
+	managedEnvNamespaces, reason, err := resolveManagedEnvNamespacesWithClusterCredentials(ctx, managedEnvironmentCR.Spec, *clusterCreds, k8sClientFactory)
+	if err != nil {
+		if reason == managedgitopsv1alpha1.ConditionReasonNoMatchingNamespaces {
+			return newSharedResourceManagedEnvContainer(), convertErrToEnvInitCondition(reason, err, managedEnvironmentCR), err
+		}
+
+		log.Info("was unable to resolve namespace selector using existing cluster credentials, so acquiring new ones.",
+			"clusterCreds", clusterCreds.Clustercredentials_cred_id, "error", err.Error())
+
+		return replaceExistingManagedEnv(ctx, gitopsEngineClient, workspaceClient, *clusterUser, isNewUser, managedEnvironmentCR, secretCR, *managedEnv,
+			workspaceNamespace, k8sClientFactory, dbQueries, log)
+	}
+
+	managedEnvNamespaceSliceList, err := convertManagedEnvNamespacesFieldToCommaSeparatedList(managedEnvNamespaces)
+	if err != nil {
+		msg := fmt.Sprintf("user specified an invalid namespace: %v", err)
+		return newSharedResourceManagedEnvContainer(),
+			connectionInitializedCondition{
+				managedEnvCR: managedEnvironmentCR,
+				status:       metav1.ConditionUnknown,
+				reason:       managedgitopsv1alpha1.ConditionReasonInvalidNamespaceList,
+				message:      msg,
+			}, errors.New(msg)
+	}
+
+	// The credentials remain valid when the Namespaces change, so there is no need to acquire new ones: just grant the
+	// ServiceAccount we installed (if any) access to the new Namespaces, and update the existing ClusterCredentials row.
+	if clusterCreds.Namespaces != managedEnvNamespaceSliceList {
+
+		if clusterCreds.Serviceaccount_installation_uid != "" {
+			if condition, err := updateInstalledServiceAccountRBAC(ctx, managedEnvironmentCR, secretCR, *clusterCreds, managedEnvNamespaces,
+				k8sClientFactory, log); err != nil {
+				return newSharedResourceManagedEnvContainer(), condition, err
+			}
+		}
+
+		clusterCreds.Namespaces = managedEnvNamespaceSliceList
+		if err := dbQueries.UpdateClusterCredentials(ctx, clusterCreds); err != nil {
+			return newSharedResourceManagedEnvContainer(),
+				createGenericDatabaseErrorEnvInitCondition(managedEnvironmentCR),
+				fmt.Errorf("unable to update namespaces of cluster credentials '%s': %w", clusterCreds.Clustercredentials_cred_id, err)
+		}
+		log.Info("updated namespaces of ClusterCredentials", "clusterCreds", clusterCreds.Clustercredentials_cred_id, "namespaces", clusterCreds.Namespaces)
+		isClusterSecretUpdateNeeded = true
+	}
+
+	// The cluster labels and annotations are only used in the metadata of the Argo CD cluster Secret, so there is no need to
+	// acquire new credentials when they change: just update them in the existing ClusterCredentials row.
+	if clusterCreds.Cluster_labels != clusterLabelsField || clusterCreds.Cluster_annotations != clusterAnnotationsField {
+		clusterCreds.Cluster_labels = clusterLabelsField
+		clusterCreds.Cluster_annotations = clusterAnnotationsField
+
+		if err := dbQueries.UpdateClusterCredentials(ctx, clusterCreds); err != nil {
+			return newSharedResourceManagedEnvContainer(),
+				createGenericDatabaseErrorEnvInitCondition(managedEnvironmentCR),
+				fmt.Errorf("unable to update cluster labels and annotations of cluster credentials '%s': %w", clusterCreds.Clustercredentials_cred_id, err)
+		}
+		log.Info("updated cluster labels and annotations of ClusterCredentials", "clusterCreds", clusterCreds.Clustercredentials_cred_id)
+		isClusterSecretUpdateNeeded = true
+	}
+
+	// Inform the cluster-agent that the Argo CD cluster secret should be updated with the updated cluster credentials
+	if isClusterSecretUpdateNeeded {
+		if err := createManagedEnvironmentOperations(ctx, *managedEnv, k8sClientFactory, dbQueries, log); err != nil {
+			return newSharedResourceManagedEnvContainer(),
+				createGenericDatabaseErrorEnvInitCondition(managedEnvironmentCR),
+				err
+		}
+	}
+
 	// The API url hasn't changed, the existing service account still works, so no more work needed.
 
 	// E) We already have an existing managed env from the database, so get or create the remaining items for it
@@ -445,8 +564,23 @@ func replaceExistingManagedEnv(ctx context.Context,
 		}
 	}
 
-	// 4) Delete the old credentials
-	rowsDeleted, err := dbQueries.DeleteClusterCredentialsById(ctx, oldClusterCredentialsPrimaryKey)
+	// 4) Delete the old credentials. If we no longer use the ServiceAccount that we installed using the old credentials
+	//    (for example, because .spec.createNewServiceAccount is now false), remove it as well.
+	oldClusterCredentials := db.ClusterCredentials{Clustercredentials_cred_id: oldClusterCredentialsPrimaryKey}
+	if err := dbQueries.GetClusterCredentialsById(ctx, &oldClusterCredentials); err != nil {
+		log.Error(err, "Unable to retrieve old ClusterCredentials row which is no longer used by ManagedEnv", "clusterCredentials", oldClusterCredentialsPrimaryKey)
+
+		return SharedResourceManagedEnvContainer{},
+			createGenericDatabaseErrorEnvInitCondition(managedEnvironmentCR),
+			fmt.Errorf("unable to retrieve old cluster credentials '%s': %w", oldClusterCredentialsPrimaryKey, err)
+	}
+	if oldClusterCredentials.Serviceaccount_installation_uid == clusterCredentials.Serviceaccount_installation_uid {
+		// The ServiceAccount is still used by the new credentials
+		oldClusterCredentials.Serviceaccount_installation_uid = ""
+	}
+
+	rowsDeleted, deleted, err := deleteClusterCredentialsAndServiceAccount(ctx, oldClusterCredentials,
+		&types.NamespacedName{Name: managedEnvironmentCR.Name, Namespace: managedEnvironmentCR.Namespace}, k8sClientFactory, dbQueries, log)
 	if err != nil {
 		log.Error(err, "Unable to delete old ClusterCredentials row which is no longer used by ManagedEnv", "clusterCredentials", oldClusterCredentialsPrimaryKey)
 
@@ -454,12 +588,14 @@ func replaceExistingManagedEnv(ctx context.Context,
 			createGenericDatabaseErrorEnvInitCondition(managedEnvironmentCR),
 			fmt.Errorf("unable to delete old cluster credentials '%s': %w", oldClusterCredentialsPrimaryKey, err)
 	}
-	if rowsDeleted != 1 {
+	if deleted && rowsDeleted != 1 {
 		log.V(logutil.LogLevel_Warn).Info("unexpected number of rows deleted when deleting cluster credentials",
 			"clusterCredentialsID", oldClusterCredentialsPrimaryKey)
 		return SharedResourceManagedEnvContainer{}, createGenericDatabaseErrorEnvInitCondition(managedEnvironmentCR), nil
 	}
-	log.Info("Deleted old ClusterCredentials row which is no longer used by ManagedEnv", "clusterCredentials", oldClusterCredentialsPrimaryKey)
+	if deleted {
+		log.Info("Deleted old ClusterCredentials row which is no longer used by ManagedEnv", "clusterCredentials", oldClusterCredentialsPrimaryKey)
+	}
 
 	// 5) Retrieve/create the other env vars for the managed env, and return
 	engineInstance, isNewEngineInstance, clusterAccess,
@@ -738,16 +874,24 @@ func DeleteManagedEnvironmentResources(ctx context.Context, managedEnvID string,
 	}
 	log.Info("Deleted ManagedEnvironment row")
 
-	// 6) Delete the cluster credentials row of the managed environment
+	// 6) Delete the cluster credentials row of the managed environment, and the ServiceAccount that we installed using them (if any)
 	if managedEnvCR != nil {
 		log := log.WithValues("clusterCredentialsId", managedEnvCR.Clustercredentials_id)
 
-		rowsDeleted, err = dbQueries.DeleteClusterCredentialsById(ctx, managedEnvCR.Clustercredentials_id)
-		if err != nil || rowsDeleted != 1 {
+		clusterCreds := db.ClusterCredentials{Clustercredentials_cred_id: managedEnvCR.Clustercredentials_id}
+		if err := dbQueries.GetClusterCredentialsById(ctx, &clusterCreds); err != nil {
+			log.Error(err, "Unable to retrieve ClusterCredentials of the managed environment")
+			return fmt.Errorf("unable to retrieve cluster credentials '%s' for managed environment: %v", managedEnvCR.Clustercredentials_id, err)
+		}
+
+		rowsDeleted, deleted, err := deleteClusterCredentialsAndServiceAccount(ctx, clusterCreds, nil, k8sClientFactory, dbQueries, log)
+		if err != nil || (deleted && rowsDeleted != 1) {
 			log.Error(err, "Unable to delete ClusterCredentials of the managed environment")
 			return fmt.Errorf("unable to delete cluster credentials '%s' for managed environment: %v (%v)", managedEnvCR.Clustercredentials_id, err, rowsDeleted)
 		}
-		log.Info("Deleted ClusterCredentials of the managed environment")
+		if deleted {
+			log.Info("Deleted ClusterCredentials of the managed environment")
+		}
 	}
 
 	// 7) For each Argo CD instances that was involved, create a new Operation to delete the managed environment
@@ -823,13 +967,75 @@ func (DefaultK8sClientFactory) BuildK8sClient(restConfig *rest.Config) (client.C
 
 }
 
-func createNewClusterCredentials(ctx context.Context, managedEnvironment managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment,
-	secret corev1.Secret, k8sClientFactory SRLK8sClientFactory, dbQueries db.DatabaseQueries, log logr.Logger,
-	workspaceClient client.Client) (db.ClusterCredentials, connectionInitializedCondition, error) {
+// isSameCertificateAuthorityData returns true if the CA bundle of the cluster credentials is the one that
+// createNewClusterCredentials would store for the current .spec of the ManagedEnvironment.
+func isSameCertificateAuthorityData(clusterCreds db.ClusterCredentials, managedEnvironment managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment,
+	secret corev1.Secret) bool {
+
+	caData, err := desiredCertificateAuthorityData(managedEnvironment, secret)
+	if err != nil {
+		return false
+	}
+
+	return clusterCreds.Ca_data == caData
+}
+
+// desiredCertificateAuthorityData returns the CA bundle that createNewClusterCredentials would store for the current
+// .spec of the ManagedEnvironment: that of .spec.certificateAuthorityData, falling back to the
+// 'certificate-authority-data' of the kubeconfig in the Secret.
+func desiredCertificateAuthorityData(managedEnvironment managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment,
+	secret corev1.Secret) (string, error) {
+
+	// The CA bundle is not stored if the TLS certificate of the cluster is not verified
+	if managedEnvironment.Spec.AllowInsecureSkipTLSVerify {
+		return "", nil
+	}
+
+	if managedEnvironment.Spec.CertificateAuthorityData != "" {
+		return managedEnvironment.Spec.CertificateAuthorityData, nil
+	}
+
+	config, err := clientcmd.Load(secret.Data[KubeconfigKey])
+	if err != nil {
+		return "", err
+	}
+
+	matchingContextName, _, err := locateContextThatMatchesAPIURL(config, managedEnvironment.Spec.APIURL)
+	if err != nil {
+		return "", err
+	}
+
+	restConfig, err := clientcmd.NewNonInteractiveClientConfig(*config, matchingContextName, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+	if err != nil {
+		return "", err
+	}
+
+	return string(restConfig.TLSClientConfig.CAData), nil
+}
+
+// managedEnvironmentSecretConfig is the configuration of the kubeconfig in the Secret of a ManagedEnvironment, for the
+// context that matches the API URL of the ManagedEnvironment.
+type managedEnvironmentSecretConfig struct {
+	config      *clientcmdapi.Config
+	contextName string
+	context     clientcmdapi.Context
+
+	// restConfig is used to contact the cluster with the credentials of the kubeconfig user. It has been vetted by
+	// validateRestConfigAuthPlugins, and has the CA bundle and proxy of the ManagedEnvironment applied.
+	restConfig *rest.Config
+
+	// caData is the CA bundle to store in the cluster credentials
+	caData string
+}
+
+// loadManagedEnvironmentSecretConfig parses the kubeconfig in the Secret of the ManagedEnvironment, and returns the
+// configuration of the context that matches the API URL of the ManagedEnvironment.
+func loadManagedEnvironmentSecretConfig(managedEnvironment managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment,
+	secret corev1.Secret) (managedEnvironmentSecretConfig, connectionInitializedCondition, error) {
 
 	if secret.Type != sharedutil.ManagedEnvironmentSecretType {
 		err := fmt.Errorf("invalid secret type: %s", secret.Type)
-		return db.ClusterCredentials{},
+		return managedEnvironmentSecretConfig{},
 			convertErrToEnvInitCondition(managedgitopsv1alpha1.ConditionReasonInvalidSecretType, err, managedEnvironment),
 			err
 	}
@@ -838,7 +1044,7 @@ func createNewClusterCredentials(ctx context.Context, managedEnvironment managed
 	if !exists {
 		err := fmt.Errorf("missing %s field in Secret", KubeconfigKey)
 
-		return db.ClusterCredentials{},
+		return managedEnvironmentSecretConfig{},
 			convertErrToEnvInitCondition(managedgitopsv1alpha1.ConditionReasonMissingKubeConfigField, err, managedEnvironment),
 			err
 	}
@@ -848,7 +1054,7 @@ func createNewClusterCredentials(ctx context.Context, managedEnvironment managed
 	if err != nil {
 		err := fmt.Errorf("unable to parse kubeconfig data: %w", err)
 
-		return db.ClusterCredentials{},
+		return managedEnvironmentSecretConfig{},
 			convertErrToEnvInitCondition(managedgitopsv1alpha1.ConditionReasonUnableToParseKubeconfigData, err, managedEnvironment),
 			err
 
@@ -856,7 +1062,7 @@ func createNewClusterCredentials(ctx context.Context, managedEnvironment managed
 
 	matchingContextName, matchingContext, err := locateContextThatMatchesAPIURL(config, managedEnvironment.Spec.APIURL)
 	if err != nil {
-		return db.ClusterCredentials{},
+		return managedEnvironmentSecretConfig{},
 			convertErrToEnvInitCondition(managedgitopsv1alpha1.ConditionReasonUnableToLocateContext, err, managedEnvironment),
 			err
 	}
@@ -867,19 +1073,76 @@ func createNewClusterCredentials(ctx context.Context, managedEnvironment managed
 	if err != nil {
 		err := fmt.Errorf("unable to retrieve restConfig from managed environment secret: %w", err)
 
-		return db.ClusterCredentials{},
+		return managedEnvironmentSecretConfig{},
 			convertErrToEnvInitCondition(managedgitopsv1alpha1.ConditionReasonUnableToRetrieveRestConfig, err, managedEnvironment),
 			err
 	}
 
-	// Ignore the self-signed certificate
+	// The rest config is used to contact the cluster below, whether or not a new service account is created, so the
+	// authentication plugins of the kubeconfig user must be vetted before any client is built from it.
+	if err := validateRestConfigAuthPlugins(restConfig, matchingContextName); err != nil {
+		return managedEnvironmentSecretConfig{},
+			convertErrToEnvInitCondition(managedgitopsv1alpha1.ConditionReasonUnsupportedAuthInfo, err, managedEnvironment),
+			err
+	}
+
+	if err := managedgitopsv1alpha1.ValidateCertificateAuthorityData(managedEnvironment.Spec); err != nil {
+		return managedEnvironmentSecretConfig{},
+			convertErrToEnvInitCondition(managedgitopsv1alpha1.ConditionReasonInvalidCertificateAuthorityData, err, managedEnvironment),
+			err
+	}
+
+	var caData string
 	if managedEnvironment.Spec.AllowInsecureSkipTLSVerify {
+		// Ignore the self-signed certificate
 		restConfig.Insecure = true
 		restConfig.TLSClientConfig.CAFile = ""
 		restConfig.TLSClientConfig.CAData = nil
+
+	} else {
+		if managedEnvironment.Spec.CertificateAuthorityData != "" {
+			// The CA bundle of the ManagedEnvironment takes precedence over the 'certificate-authority-data' of the kubeconfig
+			restConfig.Insecure = false
+			restConfig.TLSClientConfig.CAFile = ""
+			restConfig.TLSClientConfig.CAData = []byte(managedEnvironment.Spec.CertificateAuthorityData)
+		}
+		caData = string(restConfig.TLSClientConfig.CAData)
+	}
+
+	if err := managedgitopsv1alpha1.ValidateProxyURL(managedEnvironment.Spec); err != nil {
+		return managedEnvironmentSecretConfig{},
+			convertErrToEnvInitCondition(managedgitopsv1alpha1.ConditionReasonInvalidProxyURL, err, managedEnvironment),
+			err
+	}
+
+	// Contact the cluster via the proxy, if one was specified (rather than via any proxy of the kubeconfig)
+	if managedEnvironment.Spec.ProxyURL != "" {
+		restConfig.Proxy, err = buildProxyFunc(managedEnvironment.Spec.ProxyURL)
+		if err != nil {
+			return managedEnvironmentSecretConfig{},
+				convertErrToEnvInitCondition(managedgitopsv1alpha1.ConditionReasonInvalidProxyURL, err, managedEnvironment),
+				err
+		}
 	}
 
-	k8sClient, err := k8sClientFactory.BuildK8sClient(restConfig)
+	return managedEnvironmentSecretConfig{
+		config:      config,
+		contextName: matchingContextName,
+		context:     matchingContext,
+		restConfig:  restConfig,
+		caData:      caData,
+	}, connectionInitializedCondition{}, nil
+}
+
+func createNewClusterCredentials(ctx context.Context, managedEnvironment managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment,
+	secret corev1.Secret, k8sClientFactory SRLK8sClientFactory, dbQueries db.DatabaseQueries, log logr.Logger,
+	workspaceClient client.Client) (db.ClusterCredentials, connectionInitializedCondition, error) {
+
+	secretConfig, condition, err := loadManagedEnvironmentSecretConfig(managedEnvironment, secret)
+	if err != nil {
+		return db.ClusterCredentials{}, condition, err
+	}
+	k8sClient, err := k8sClientFactory.BuildK8sClient(secretConfig.restConfig)
 	if err != nil {
 		err := fmt.Errorf("%s: %w", UnableToCreateRestConfigError, err)
 
@@ -888,26 +1151,71 @@ func createNewClusterCredentials(ctx context.Context, managedEnvironment managed
 			err
 	}
 
-	var saBearerToken string
+	// Resolve the .spec.namespaceSelector field (if specified) using the credentials in the Secret
+	namespaces, reason, err := resolveManagedEnvNamespaces(ctx, managedEnvironment.Spec, k8sClient)
+	if err != nil {
+		return db.ClusterCredentials{},
+			convertErrToEnvInitCondition(reason, err, managedEnvironment),
+			err
+	}
+
+	// Convert the namespaces to a comma-separated list of namespaces
+	var namespacesField string
+	if len(namespaces) > 0 {
+
+		namespacesField, err = convertManagedEnvNamespacesFieldToCommaSeparatedList(namespaces)
+		if err != nil {
+			log.Error(err, "ManagedEnvironment contains an invalid namespace slice", "namespaceSlice", namespaces)
+
+			return db.ClusterCredentials{},
+				connectionInitializedCondition{
+					managedEnvCR: managedEnvironment,
+					status:       metav1.ConditionUnknown,
+					reason:       managedgitopsv1alpha1.ConditionReasonInvalidNamespaceList,
+					message:      err.Error(),
+				}, fmt.Errorf("user specified an invalid namespace: %v", err)
+		}
+
+	}
+
+	serviceAccountRulesField, err := convertManagedEnvServiceAccountRulesFieldToJSON(managedEnvironment.Spec)
+	if err != nil {
+		return db.ClusterCredentials{},
+			convertErrToEnvInitCondition(managedgitopsv1alpha1.ConditionReasonInvalidServiceAccountRules, err, managedEnvironment),
+			err
+	}
+
+	clusterLabelsField, clusterAnnotationsField, err := convertManagedEnvClusterSecretMetadataFieldsToJSON(managedEnvironment.Spec)
+	if err != nil {
+		return db.ClusterCredentials{},
+			convertErrToEnvInitCondition(managedgitopsv1alpha1.ConditionReasonInvalidClusterSecretMetadata, err, managedEnvironment),
+			err
+	}
+
+	var userCredentials kubeconfigUserCredentials
+	var serviceAccountInstallationUID string
 	log.Info("createNewServiceAccount is ", "CreateNewServiceAccount", managedEnvironment.Spec.CreateNewServiceAccount)
 	if managedEnvironment.Spec.CreateNewServiceAccount {
 		// This is the original behaviour, where we create a new service account
-		saBearerToken, _, err = sharedutil.InstallServiceAccount(ctx, k8sClient, string(managedEnvironment.UID), serviceAccountNamespaceKubeSystem, log)
+		userCredentials.bearerToken, _, err = sharedutil.InstallServiceAccount(ctx, k8sClient, string(managedEnvironment.UID),
+			serviceAccountNamespaceKubeSystem, generateServiceAccountRBACOptions(managedEnvironment.Spec, namespaces), log)
 		if err != nil {
 			err2 := fmt.Errorf("unable to install service account from secret '%s': %w", secret.Name, err)
 
 			return db.ClusterCredentials{},
-				convertErrToEnvInitCondition(managedgitopsv1alpha1.ConditionReasonUnableToInstallServiceAccount, err, managedEnvironment),
+				convertErrToEnvInitCondition(serviceAccountInstallationFailureReason(err), err, managedEnvironment),
 				err2
 
 		}
+		// Record the installation, so that the ServiceAccount can be removed when it is no longer used
+		serviceAccountInstallationUID = string(managedEnvironment.UID)
 	} else {
-		// If an existing service account is used instead, we just simply take the provided secret as the token for the cluster credentials
+		// If an existing service account is used instead, we just simply take the credentials of the kubeconfig user as the cluster credentials
 
-		val, exists := config.AuthInfos[matchingContext.AuthInfo]
+		val, exists := secretConfig.config.AuthInfos[secretConfig.context.AuthInfo]
 
 		if !exists {
-			msg := "unable to extract remote cluster configuration from kubeconfig, missing auth info for " + matchingContextName
+			msg := "unable to extract remote cluster configuration from kubeconfig, missing auth info for " + secretConfig.contextName
 			return db.ClusterCredentials{}, connectionInitializedCondition{
 				managedEnvCR: managedEnvironment,
 				status:       metav1.ConditionFalse,
@@ -916,36 +1224,16 @@ func createNewClusterCredentials(ctx context.Context, managedEnvironment managed
 			}, fmt.Errorf("%s", msg)
 		}
 
-		if val.Token == "" {
-			msg := fmt.Sprintf("kubeconfig must have a service account token for the user in context \"%s\". client-certificate is not supported at this time.", matchingContextName)
+		var reason managedgitopsv1alpha1.ManagedEnvironmentConditionReason
+		userCredentials, reason, err = extractKubeconfigUserCredentials(*val, secretConfig.contextName)
+		if err != nil {
 			return db.ClusterCredentials{}, connectionInitializedCondition{
 				managedEnvCR: managedEnvironment,
 				status:       metav1.ConditionFalse,
-				reason:       managedgitopsv1alpha1.ConditionReasonUnableToParseKubeconfigData,
-				message:      msg,
-			}, fmt.Errorf("%s", msg)
+				reason:       reason,
+				message:      err.Error(),
+			}, err
 		}
-
-		saBearerToken = val.Token
-	}
-
-	// Convert the .spec.namespaces field to a comma-separated list of namespaces
-	var namespacesField string
-	if len(managedEnvironment.Spec.Namespaces) > 0 {
-
-		namespacesField, err = convertManagedEnvNamespacesFieldToCommaSeparatedList(managedEnvironment.Spec.Namespaces)
-		if err != nil {
-			log.Error(err, "ManagedEnvironment contains an invalid namespace slice", "namespaceSlice", managedEnvironment.Spec.Namespaces)
-
-			return db.ClusterCredentials{},
-				connectionInitializedCondition{
-					managedEnvCR: managedEnvironment,
-					status:       metav1.ConditionUnknown,
-					reason:       managedgitopsv1alpha1.ConditionReasonInvalidNamespaceList,
-					message:      err.Error(),
-				}, fmt.Errorf("user specified an invalid namespace: %v", err)
-		}
-
 	}
 
 	insecureVerifyTLS := managedEnvironment.Spec.AllowInsecureSkipTLSVerify
@@ -953,11 +1241,21 @@ func createNewClusterCredentials(ctx context.Context, managedEnvironment managed
 		Host:                        managedEnvironment.Spec.APIURL,
 		Kube_config:                 "",
 		Kube_config_context:         "",
-		Serviceaccount_bearer_token: saBearerToken,
+		Serviceaccount_bearer_token: userCredentials.bearerToken,
 		Serviceaccount_ns:           serviceAccountNamespaceKubeSystem,
 		AllowInsecureSkipTLSVerify:  insecureVerifyTLS,
 		Namespaces:                  namespacesField,
 		ClusterResources:            managedEnvironment.Spec.ClusterResources,
+		Ca_data:                     secretConfig.caData,
+		Cert_data:                   userCredentials.certData,
+		Key_data:                    userCredentials.keyData,
+		Exec_provider_config:        userCredentials.execProviderConfig,
+		Serviceaccount_rules:        serviceAccountRulesField,
+		Proxy_url:                   managedEnvironment.Spec.ProxyURL,
+		Cluster_labels:              clusterLabelsField,
+		Cluster_annotations:         clusterAnnotationsField,
+
+		Serviceaccount_installation_uid: serviceAccountInstallationUID,
 	}
 	// If an existing service account is used instead, we should verify the cluster credentials based on the provided token
 	if !managedEnvironment.Spec.CreateNewServiceAccount {
@@ -967,11 +1265,12 @@ func createNewClusterCredentials(ctx context.Context, managedEnvironment managed
 
 			log.Error(err, "Unable to verify ClusterCredentials using provided token", clusterCredentials.GetAsLogKeyValues()...)
 
-			message := "Unable to validate the credentials provided in the ManagedEnvironment Secret. Verify the API URL, and service account token are correct."
+			message := "Unable to validate the credentials provided in the ManagedEnvironment Secret. Verify the API URL, and service account token (or client certificate) are correct."
 			if apierr.IsForbidden(err) {
 				message = "Provided service account does not have permission to access resources in the cluster. Verify that the service account has the correct Role and RoleBinding."
 			} else if isCertificateSignedByUnknownAuthority(err) {
-				message = "Certificate signed by unknown authority. Note that the '.spec.allowInsecureSkipTLSVerify' field can be used to ignore this error."
+				message = "Certificate signed by unknown authority. Note that the '.spec.certificateAuthorityData' field can be used to provide the CA bundle of the cluster, " +
+					"or the '.spec.allowInsecureSkipTLSVerify' field can be used to ignore this error."
 			}
 			return db.ClusterCredentials{}, connectionInitializedCondition{
 				managedEnvCR: managedEnvironment,
@@ -1000,6 +1299,167 @@ func createNewClusterCredentials(ctx context.Context, managedEnvironment managed
 
 }
 
+// updateInstalledServiceAccountRBAC grants the ServiceAccount that was installed for the cluster credentials access to
+// the given Namespaces. The ServiceAccount is not able to change its own permissions, so the credentials of the
+// ManagedEnvironment Secret are used.
+func updateInstalledServiceAccountRBAC(ctx context.Context, managedEnvironment managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment,
+	secret corev1.Secret, clusterCreds db.ClusterCredentials, namespaces []string, k8sClientFactory SRLK8sClientFactory,
+	log logr.Logger) (connectionInitializedCondition, error) {
+
+	secretConfig, condition, err := loadManagedEnvironmentSecretConfig(managedEnvironment, secret)
+	if err != nil {
+		return condition, err
+	}
+
+	k8sClient, err := k8sClientFactory.BuildK8sClient(secretConfig.restConfig)
+	if err != nil {
+		err := fmt.Errorf("%s: %w", UnableToCreateRestConfigError, err)
+		return convertErrToEnvInitCondition(managedgitopsv1alpha1.ConditionReasonUnableToCreateClient, err, managedEnvironment), err
+	}
+
+	if err := sharedutil.UpdateServiceAccountRBAC(ctx, k8sClient, clusterCreds.Serviceaccount_installation_uid, clusterCreds.Serviceaccount_ns,
+		generateServiceAccountRBACOptions(managedEnvironment.Spec, namespaces), log); err != nil {
+		err = fmt.Errorf("unable to update the permissions of the service account from secret '%s': %w", secret.Name, err)
+		return convertErrToEnvInitCondition(serviceAccountInstallationFailureReason(err), err, managedEnvironment), err
+	}
+
+	return connectionInitializedCondition{}, nil
+}
+
+// serviceAccountInstallationFailureReason returns the condition reason for an error returned when installing (or updating
+// the permissions of) a ServiceAccount.
+func serviceAccountInstallationFailureReason(err error) managedgitopsv1alpha1.ManagedEnvironmentConditionReason {
+
+	var namespacesNotFoundErr sharedutil.NamespacesNotFoundError
+	if errors.As(err, &namespacesNotFoundErr) {
+		return managedgitopsv1alpha1.ConditionReasonNamespaceNotFound
+	}
+
+	return managedgitopsv1alpha1.ConditionReasonUnableToInstallServiceAccount
+}
+
+// generateServiceAccountRBACOptions returns the permissions to grant to the ServiceAccount that is installed for the
+// ManagedEnvironment, given the Namespaces that it resolves to.
+func generateServiceAccountRBACOptions(spec managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironmentSpec, namespaces []string) sharedutil.ServiceAccountRBACOptions {
+	return sharedutil.ServiceAccountRBACOptions{
+		Namespaces:       namespaces,
+		ClusterResources: spec.ClusterResources,
+		Rules:            spec.ServiceAccountRules,
+		// The ServiceAccount must be able to resolve the namespace selector itself, on subsequent reconciles
+		ListNamespaces: spec.NamespaceSelector != nil,
+	}
+}
+
+// kubeconfigUserCredentials contains the credentials of a kubeconfig user, which Argo CD will use to connect to the cluster.
+// Only one of the authentication methods is set.
+type kubeconfigUserCredentials struct {
+	bearerToken string
+
+	// certData and keyData are the PEM-encoded client certificate and key
+	certData string
+	keyData  string
+
+	// execProviderConfig is the JSON-encoded exec plugin configuration (argosharedutil.ClusterSecretExecProviderConfigJSON)
+	execProviderConfig string
+}
+
+// extractKubeconfigUserCredentials returns the credentials of the kubeconfig user of the given context. The supported
+// authentication methods are: service account token, client certificate (client-certificate-data/client-key-data), and exec plugin.
+// returns:
+// - the credentials of the user
+// - the condition reason to set on the ManagedEnvironment, if the credentials are invalid or unsupported
+// - error, if the credentials are invalid or unsupported
+func extractKubeconfigUserCredentials(authInfo clientcmdapi.AuthInfo, contextName string) (kubeconfigUserCredentials, managedgitopsv1alpha1.ManagedEnvironmentConditionReason, error) {
+
+	if authInfo.Token != "" {
+		return kubeconfigUserCredentials{bearerToken: authInfo.Token}, "", nil
+	}
+
+	if len(authInfo.ClientCertificateData) > 0 || len(authInfo.ClientKeyData) > 0 {
+
+		if len(authInfo.ClientCertificateData) == 0 || len(authInfo.ClientKeyData) == 0 {
+			return kubeconfigUserCredentials{}, managedgitopsv1alpha1.ConditionReasonInvalidAuthInfo,
+				fmt.Errorf("kubeconfig user in context \"%s\" must specify both client-certificate-data and client-key-data", contextName)
+		}
+
+		if _, err := tls.X509KeyPair(authInfo.ClientCertificateData, authInfo.ClientKeyData); err != nil {
+			return kubeconfigUserCredentials{}, managedgitopsv1alpha1.ConditionReasonInvalidAuthInfo,
+				fmt.Errorf("kubeconfig user in context \"%s\" has an invalid client certificate and key: %v", contextName, err)
+		}
+
+		return kubeconfigUserCredentials{
+			certData: string(authInfo.ClientCertificateData),
+			keyData:  string(authInfo.ClientKeyData),
+		}, "", nil
+	}
+
+	if authInfo.Exec != nil {
+
+		if authInfo.Exec.Command == "" {
+			return kubeconfigUserCredentials{}, managedgitopsv1alpha1.ConditionReasonInvalidAuthInfo,
+				fmt.Errorf("kubeconfig user in context \"%s\" has an exec plugin without a command", contextName)
+		}
+
+		if !isExecPluginCommandAllowed(authInfo.Exec.Command) {
+			return kubeconfigUserCredentials{}, managedgitopsv1alpha1.ConditionReasonUnsupportedAuthInfo,
+				fmt.Errorf("kubeconfig user in context \"%s\" has an exec plugin with command \"%s\", which is not allowed by the GitOps Service", contextName, authInfo.Exec.Command)
+		}
+
+		execProviderConfig := argosharedutil.ClusterSecretExecProviderConfigJSON{
+			Command:     authInfo.Exec.Command,
+			Args:        authInfo.Exec.Args,
+			APIVersion:  authInfo.Exec.APIVersion,
+			InstallHint: authInfo.Exec.InstallHint,
+		}
+		if len(authInfo.Exec.Env) > 0 {
+			execProviderConfig.Env = map[string]string{}
+			for _, envVar := range authInfo.Exec.Env {
+				execProviderConfig.Env[envVar.Name] = envVar.Value
+			}
+		}
+
+		jsonBytes, err := json.Marshal(execProviderConfig)
+		if err != nil {
+			return kubeconfigUserCredentials{}, managedgitopsv1alpha1.ConditionReasonInvalidAuthInfo,
+				fmt.Errorf("unable to marshal exec plugin of kubeconfig user in context \"%s\": %v", contextName, err)
+		}
+
+		return kubeconfigUserCredentials{execProviderConfig: string(jsonBytes)}, "", nil
+	}
+
+	return kubeconfigUserCredentials{}, managedgitopsv1alpha1.ConditionReasonUnsupportedAuthInfo,
+		fmt.Errorf("kubeconfig must have a service account token, client-certificate-data/client-key-data, or allowed exec plugin for the user in context \"%s\". "+
+			"Other authentication methods are not supported at this time.", contextName)
+}
+
+// validateRestConfigAuthPlugins returns an error if a client built from the rest config would run an exec plugin that is
+// not allowed by the GitOps Service, or would use an auth provider plugin, which is not supported. Exec plugins are run
+// when a client first contacts the cluster, so this must be checked before a client is built from the rest config.
+func validateRestConfigAuthPlugins(restConfig *rest.Config, contextName string) error {
+
+	if restConfig.AuthProvider != nil {
+		return fmt.Errorf("kubeconfig user in context \"%s\" has an auth provider \"%s\", which is not supported by the GitOps Service", contextName, restConfig.AuthProvider.Name)
+	}
+
+	if restConfig.ExecProvider != nil && !isExecPluginCommandAllowed(restConfig.ExecProvider.Command) {
+		return fmt.Errorf("kubeconfig user in context \"%s\" has an exec plugin with command \"%s\", which is not allowed by the GitOps Service", contextName, restConfig.ExecProvider.Command)
+	}
+
+	return nil
+}
+
+// isExecPluginCommandAllowed returns true if the exec plugin command is in the allowlist of the operator (see ExecPluginAllowlistEnvVar).
+func isExecPluginCommandAllowed(command string) bool {
+
+	for _, allowedCommand := range strings.Split(os.Getenv(ExecPluginAllowlistEnvVar), ",") {
+		if allowedCommand = strings.TrimSpace(allowedCommand); allowedCommand != "" && allowedCommand == command {
+			return true
+		}
+	}
+
+	return false
+}
+
 // locateContextThatMatchesAPIURL examines a kubeconfig (Config struct), and looks for the context that
 // matches the cluster with the given API URL.
 // See 'sharedresourceloop_managedend_test.go' for an example of a kubeconfig.
@@ -1057,8 +1517,9 @@ func sanityTestCredentials(clusterCreds db.ClusterCredentials) (*rest.Config, bo
 	if clusterCreds.Host == "" {
 		return nil, false, fmt.Errorf("cluster credentials is missing host")
 	}
-	if clusterCreds.Serviceaccount_bearer_token == "" {
-		return nil, false, fmt.Errorf("cluster credentials is missing service account bearer token")
+	if clusterCreds.Serviceaccount_bearer_token == "" && (clusterCreds.Cert_data == "" || clusterCreds.Key_data == "") &&
+		clusterCreds.Exec_provider_config == "" {
+		return nil, false, fmt.Errorf("cluster credentials is missing service account bearer token, client certificate, or exec provider config")
 	}
 
 	configParam := &rest.Config{
@@ -1066,13 +1527,72 @@ func sanityTestCredentials(clusterCreds db.ClusterCredentials) (*rest.Config, bo
 		BearerToken: clusterCreds.Serviceaccount_bearer_token,
 	}
 
+	if clusterCreds.Cert_data != "" && clusterCreds.Key_data != "" {
+		configParam.TLSClientConfig.CertData = []byte(clusterCreds.Cert_data)
+		configParam.TLSClientConfig.KeyData = []byte(clusterCreds.Key_data)
+	}
+
+	if clusterCreds.Exec_provider_config != "" {
+		var execProviderConfig argosharedutil.ClusterSecretExecProviderConfigJSON
+		if err := json.Unmarshal([]byte(clusterCreds.Exec_provider_config), &execProviderConfig); err != nil {
+			return nil, false, fmt.Errorf("unable to unmarshal exec provider config of cluster credentials: %w", err)
+		}
+
+		// The allowlist may have changed since the cluster credentials were created: the exec plugin must never be run
+		// unless it is (still) allowed.
+		if !isExecPluginCommandAllowed(execProviderConfig.Command) {
+			return nil, false, fmt.Errorf("exec plugin command \"%s\" of cluster credentials is not allowed by the GitOps Service", execProviderConfig.Command)
+		}
+
+		configParam.ExecProvider = &clientcmdapi.ExecConfig{
+			Command:         execProviderConfig.Command,
+			Args:            execProviderConfig.Args,
+			APIVersion:      execProviderConfig.APIVersion,
+			InstallHint:     execProviderConfig.InstallHint,
+			InteractiveMode: clientcmdapi.NeverExecInteractiveMode,
+		}
+
+		// Sort the environment variables, so that the resulting config is deterministic
+		envNames := make([]string, 0, len(execProviderConfig.Env))
+		for name := range execProviderConfig.Env {
+			envNames = append(envNames, name)
+		}
+		sort.Strings(envNames)
+		for _, name := range envNames {
+			configParam.ExecProvider.Env = append(configParam.ExecProvider.Env,
+				clientcmdapi.ExecEnvVar{Name: name, Value: execProviderConfig.Env[name]})
+		}
+	}
+
 	configParam.Insecure = clusterCreds.AllowInsecureSkipTLSVerify
 
+	if !clusterCreds.AllowInsecureSkipTLSVerify && clusterCreds.Ca_data != "" {
+		configParam.TLSClientConfig.CAData = []byte(clusterCreds.Ca_data)
+	}
+
+	if clusterCreds.Proxy_url != "" {
+		proxy, err := buildProxyFunc(clusterCreds.Proxy_url)
+		if err != nil {
+			return nil, false, err
+		}
+		configParam.Proxy = proxy
+	}
+
 	configParam.ServerName = ""
 
 	return configParam, true, nil
 }
 
+// buildProxyFunc returns a rest.Config Proxy function that sends all requests to the cluster via the given HTTP(S) proxy URL.
+func buildProxyFunc(proxyURL string) (func(*http.Request) (*url.URL, error), error) {
+	parsedURL, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse proxy URL of cluster credentials: %w", err)
+	}
+
+	return http.ProxyURL(parsedURL), nil
+}
+
 // connectionInitializedCondition is returned by functions in this file, to indicate that a Condition should be set in the
 // .status.conditions field of the ManagedEnvironment CR, of type ManagedEnvironmentStatusConnectionInitializationSucceeded.
 type connectionInitializedCondition struct {
@@ -1157,7 +1677,7 @@ func createUnknownErrorEnvInitCondition() connectionInitializedCondition {
 // to preserve the LastTransitionTime (see https://pkg.go.dev/k8s.io/apimachinery/pkg/apis/meta/v1#Condition.LastTransitionTime )
 func updateManagedEnvironmentConnectionStatus(ctx context.Context,
 	managedEnvironment managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment,
-	client client.Client, connInitCondition connectionInitializedCondition, log logr.Logger) {
+	client client.Client, connInitCondition connectionInitializedCondition, statusChanged bool, log logr.Logger) {
 
 	const conditionType = managedgitopsv1alpha1.ManagedEnvironmentStatusConnectionInitializationSucceeded
 	var condition *metav1.Condition = nil
@@ -1178,12 +1698,42 @@ func updateManagedEnvironmentConnectionStatus(ctx context.Context,
 		condition.Message = connInitCondition.message
 		condition.LastTransitionTime = metav1.Now()
 		condition.Status = connInitCondition.status
+		statusChanged = true
+	}
+
+	if statusChanged {
 		if err := client.Status().Update(ctx, &managedEnvironment); err != nil {
 			log.Error(err, "updating managed environment status condition")
 		}
 	}
 }
 
+// setManagedEnvironmentResolvedNamespaces sets the .status.resolvedNamespaces field of the ManagedEnvironment CR to the
+// Namespaces of the cluster credentials of the managed environment.
+//
+// Returns true if the field was changed, false otherwise.
+func setManagedEnvironmentResolvedNamespaces(ctx context.Context, managedEnvironmentCR *managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment,
+	managedEnv db.ManagedEnvironment, dbQueries db.DatabaseQueries, log logr.Logger) bool {
+
+	clusterCreds := db.ClusterCredentials{Clustercredentials_cred_id: managedEnv.Clustercredentials_id}
+	if err := dbQueries.GetClusterCredentialsById(ctx, &clusterCreds); err != nil {
+		log.Error(err, "Unable to retrieve ClusterCredentials, to report the resolved namespaces of the ManagedEnvironment")
+		return false
+	}
+
+	var resolvedNamespaces []string
+	if clusterCreds.Namespaces != "" {
+		resolvedNamespaces = strings.Split(clusterCreds.Namespaces, ",")
+	}
+
+	if strings.Join(managedEnvironmentCR.Status.ResolvedNamespaces, ",") == clusterCreds.Namespaces {
+		return false
+	}
+
+	managedEnvironmentCR.Status.ResolvedNamespaces = resolvedNamespaces
+	return true
+}
+
 // verifyClusterCredentialsWithNamespaceList returns true if we were able to successfully connect with the credentials, false otherwise.
 func verifyClusterCredentialsWithNamespaceList(ctx context.Context, clusterCreds db.ClusterCredentials, managedEnvCR managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment,
 	k8sClientFactory SRLK8sClientFactory) (bool, error) {
@@ -1205,11 +1755,12 @@ func verifyClusterCredentialsWithNamespaceList(ctx context.Context, clusterCreds
 		return false, fmt.Errorf("unable to create new K8s client to '%v': %w", configParam.Host, err)
 	}
 
-	if len(managedEnvCR.Spec.Namespaces) > 0 {
-		// If the managed environment contains a namespace, use it to validate that the k8s client (based on the credentials) is valid
+	if clusterCreds.Namespaces != "" {
+		// If the cluster credentials are restricted to a list of namespaces, use one to validate that the k8s client (based on
+		// the credentials) is valid
 		firstNamespaceName := corev1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
-				Name: managedEnvCR.Spec.Namespaces[0],
+				Name: strings.Split(clusterCreds.Namespaces, ",")[0],
 			},
 		}
 		if err := clientObj.Get(ctx, client.ObjectKeyFromObject(&firstNamespaceName), &firstNamespaceName); err != nil {
@@ -1249,6 +1800,155 @@ func convertManagedEnvNamespacesFieldToCommaSeparatedList(namespaces []string) (
 
 }
 
+// resolveManagedEnvNamespaces returns the Namespaces that Argo CD is able to deploy to: the Namespaces of .spec.namespaces,
+// plus the Namespaces of the target cluster that match .spec.namespaceSelector (if specified). If a selector is specified,
+// k8sClient must be able to list the Namespaces of the target cluster.
+//
+// Returns the sorted list of Namespaces, or the reason and error if they could not be resolved.
+func resolveManagedEnvNamespaces(ctx context.Context, spec managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironmentSpec,
+	k8sClient client.Client) ([]string, managedgitopsv1alpha1.ManagedEnvironmentConditionReason, error) {
+
+	if spec.NamespaceSelector == nil {
+		return spec.Namespaces, "", nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(spec.NamespaceSelector)
+	if err != nil {
+		return nil, managedgitopsv1alpha1.ConditionReasonInvalidNamespaceSelector, fmt.Errorf("invalid namespace selector: %w", err)
+	}
+
+	var namespaceList corev1.NamespaceList
+	if err := k8sClient.List(ctx, &namespaceList, &client.ListOptions{LabelSelector: selector}); err != nil {
+		return nil, managedgitopsv1alpha1.ConditionReasonUnableToValidateClusterCredentials,
+			fmt.Errorf("unable to list the namespaces that match the namespace selector: %w", err)
+	}
+
+	namespaceSet := map[string]bool{}
+	for _, namespace := range spec.Namespaces {
+		namespaceSet[namespace] = true
+	}
+	for _, namespace := range namespaceList.Items {
+		// Namespaces that are being deleted can no longer be deployed to
+		if namespace.DeletionTimestamp == nil {
+			namespaceSet[namespace.Name] = true
+		}
+	}
+
+	// An empty list would grant Argo CD access to all Namespaces of the cluster
+	if len(namespaceSet) == 0 {
+		return nil, managedgitopsv1alpha1.ConditionReasonNoMatchingNamespaces,
+			fmt.Errorf("no namespaces of the cluster match the namespace selector '%s'", selector.String())
+	}
+
+	namespaces := make([]string, 0, len(namespaceSet))
+	for namespace := range namespaceSet {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	return namespaces, "", nil
+}
+
+// resolveManagedEnvNamespacesWithClusterCredentials calls resolveManagedEnvNamespaces, using the given cluster credentials
+// to connect to the target cluster.
+func resolveManagedEnvNamespacesWithClusterCredentials(ctx context.Context, spec managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironmentSpec,
+	clusterCreds db.ClusterCredentials, k8sClientFactory SRLK8sClientFactory) ([]string, managedgitopsv1alpha1.ManagedEnvironmentConditionReason, error) {
+
+	if spec.NamespaceSelector == nil {
+		return spec.Namespaces, "", nil
+	}
+
+	configParam, _, err := sanityTestCredentials(clusterCreds)
+	if err != nil {
+		return nil, managedgitopsv1alpha1.ConditionReasonUnableToRetrieveRestConfig, err
+	}
+
+	k8sClient, err := k8sClientFactory.BuildK8sClient(configParam)
+	if err != nil {
+		return nil, managedgitopsv1alpha1.ConditionReasonUnableToCreateClient,
+			fmt.Errorf("unable to create new K8s client to '%v': %w", configParam.Host, err)
+	}
+
+	return resolveManagedEnvNamespaces(ctx, spec, k8sClient)
+}
+
+// ManagedEnvironmentNamespacesChanged returns true if the Namespaces that match the .spec.namespaceSelector of the
+// ManagedEnvironment CR no longer match the Namespaces of its cluster credentials. In this case, the ManagedEnvironment
+// should be reconciled, which updates the cluster credentials, and the Argo CD cluster secret.
+func ManagedEnvironmentNamespacesChanged(ctx context.Context, managedEnvCR managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment,
+	clusterCreds db.ClusterCredentials, k8sClientFactory SRLK8sClientFactory) (bool, error) {
+
+	if managedEnvCR.Spec.NamespaceSelector == nil {
+		return false, nil
+	}
+
+	namespaces, reason, err := resolveManagedEnvNamespacesWithClusterCredentials(ctx, managedEnvCR.Spec, clusterCreds, k8sClientFactory)
+	if err != nil {
+		// All of the matching Namespaces were deleted: the ManagedEnvironment should report this in its status
+		if reason == managedgitopsv1alpha1.ConditionReasonNoMatchingNamespaces {
+			return true, nil
+		}
+		return false, err
+	}
+
+	namespacesField, err := convertManagedEnvNamespacesFieldToCommaSeparatedList(namespaces)
+	if err != nil {
+		return false, err
+	}
+
+	return namespacesField != clusterCreds.Namespaces, nil
+}
+
+// convertManagedEnvServiceAccountRulesFieldToJSON validates the .spec.serviceAccountRules field, and converts it to JSON.
+// An empty string is returned if no ServiceAccount is created, or if the default rules are used.
+func convertManagedEnvServiceAccountRulesFieldToJSON(spec managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironmentSpec) (string, error) {
+
+	if err := managedgitopsv1alpha1.ValidateServiceAccountRules(spec); err != nil {
+		return "", err
+	}
+
+	if !spec.CreateNewServiceAccount || len(spec.ServiceAccountRules) == 0 {
+		return "", nil
+	}
+
+	jsonBytes, err := json.Marshal(spec.ServiceAccountRules)
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal service account rules: %w", err)
+	}
+
+	return string(jsonBytes), nil
+}
+
+// convertManagedEnvClusterSecretMetadataFieldsToJSON validates the .spec.clusterLabels and .spec.clusterAnnotations fields,
+// and converts each of them to JSON. An empty string is returned for a field that is not specified.
+func convertManagedEnvClusterSecretMetadataFieldsToJSON(spec managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironmentSpec) (string, string, error) {
+
+	if err := managedgitopsv1alpha1.ValidateClusterSecretMetadata(spec); err != nil {
+		return "", "", err
+	}
+
+	var clusterLabelsField, clusterAnnotationsField string
+
+	// Map keys are sorted by json.Marshal, so the result is deterministic
+	if len(spec.ClusterLabels) > 0 {
+		jsonBytes, err := json.Marshal(spec.ClusterLabels)
+		if err != nil {
+			return "", "", fmt.Errorf("unable to marshal cluster labels: %w", err)
+		}
+		clusterLabelsField = string(jsonBytes)
+	}
+
+	if len(spec.ClusterAnnotations) > 0 {
+		jsonBytes, err := json.Marshal(spec.ClusterAnnotations)
+		if err != nil {
+			return "", "", fmt.Errorf("unable to marshal cluster annotations: %w", err)
+		}
+		clusterAnnotationsField = string(jsonBytes)
+	}
+
+	return clusterLabelsField, clusterAnnotationsField, nil
+}
+
 // A namespace is valid if it conforms to RFC 1123 DNS label standard
 func isValidNamespaceName(namespaceName string) bool {
 	return len(validation.IsDNS1123Label(namespaceName)) == 0
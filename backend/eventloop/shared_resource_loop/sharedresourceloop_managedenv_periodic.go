@@ -0,0 +1,123 @@
+package shared_resource_loop
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	"github.com/redhat-appstudio/managed-gitops/backend/metrics"
+)
+
+// internalProcessMessage_ReconcileSharedManagedEnvPeriodically performs the periodic maintenance of a managed environment:
+// - the token of the ServiceAccount that the GitOps Service installed into the cluster is renewed, if needed
+// - the API server is probed, and the result is recorded in the status of the ManagedEnvironment CR, and in the
+// connectivity metrics of the managed environment
+// - the .spec.namespaceSelector of the ManagedEnvironment CR, if any, is resolved against the cluster
+//
+// As this runs on the shared resource loop, it is serialized with the reconciliation of the managed environment by
+// internalProcessMessage_ReconcileSharedManagedEnv, which may replace the database entries of the managed environment.
+//
+// Returns true if the managed environment should be reconciled, because its credentials are no longer valid, or because
+// the Namespaces that match its namespace selector have changed; false otherwise.
+func internalProcessMessage_ReconcileSharedManagedEnvPeriodically(ctx context.Context, workspaceClient client.Client,
+	managedEnvironmentCRName string, workspaceNamespace corev1.Namespace, k8sClientFactory SRLK8sClientFactory,
+	dbQueries db.DatabaseQueries, logParam logr.Logger) (bool, error) {
+
+	log := logParam.WithValues("managedEnvName", managedEnvironmentCRName, "managedEnvNamespace", workspaceNamespace.Name)
+
+	managedEnvCR := managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment{}
+	if err := workspaceClient.Get(ctx, types.NamespacedName{Name: managedEnvironmentCRName, Namespace: workspaceNamespace.Name}, &managedEnvCR); err != nil {
+		if apierr.IsNotFound(err) {
+			// The database entries of a deleted ManagedEnvironment are cleaned up when it is reconciled
+			return false, nil
+		}
+		return false, fmt.Errorf("unable to retrieve ManagedEnvironment CR '%s': %w", managedEnvironmentCRName, err)
+	}
+
+	apiCRToDBMapping := db.APICRToDatabaseMapping{
+		APIResourceType: db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentManagedEnvironment,
+		APIResourceUID:  string(managedEnvCR.UID),
+		DBRelationType:  db.APICRToDatabaseMapping_DBRelationType_ManagedEnvironment,
+	}
+	if err := dbQueries.GetDatabaseMappingForAPICR(ctx, &apiCRToDBMapping); err != nil {
+		if db.IsResultNotFoundError(err) {
+			// The ManagedEnvironment has not been reconciled yet
+			return false, nil
+		}
+		return false, fmt.Errorf("unable to retrieve managed environment APICRToDatabaseMapping for %s: %w", apiCRToDBMapping.APIResourceUID, err)
+	}
+
+	managedEnv := db.ManagedEnvironment{Managedenvironment_id: apiCRToDBMapping.DBRelationKey}
+	if err := dbQueries.GetManagedEnvironmentById(ctx, &managedEnv); err != nil {
+		if db.IsResultNotFoundError(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("unable to retrieve managed environment '%s': %w", managedEnv.Managedenvironment_id, err)
+	}
+
+	// 1) Renew the ServiceAccount token
+	_, invalidCreds, err := RenewManagedEnvironmentToken(ctx, managedEnv, k8sClientFactory, dbQueries, log)
+	if err != nil {
+		log.Error(err, "Unable to renew ServiceAccount token of Managed Environment", "managedEnvID", managedEnv.Managedenvironment_id)
+	}
+	if invalidCreds {
+		log.Info("Credentials of Managed Environment are no longer valid, so reconciling Managed Environment to reacquire them")
+		return true, nil
+	}
+
+	// Retrieve the cluster credentials after the token is renewed, as a new token replaces the old one
+	clusterCreds := db.ClusterCredentials{Clustercredentials_cred_id: managedEnv.Clustercredentials_id}
+	if err := dbQueries.GetClusterCredentialsById(ctx, &clusterCreds); err != nil {
+		if db.IsResultNotFoundError(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("unable to retrieve cluster credentials '%s': %w", clusterCreds.Clustercredentials_cred_id, err)
+	}
+
+	// 2) Probe the API server
+	connectivity := ProbeManagedEnvironmentConnectivity(clusterCreds)
+	clusterInfoChanged := false
+
+	if connectivity.Reachable {
+		metrics.SetManagedEnvironmentReachable(managedEnvCR.Name, managedEnvCR.Namespace, connectivity.Latency, connectivity.ContactTime)
+
+		clusterInfo, err := DiscoverManagedEnvironmentClusterInfo(ctx, clusterCreds, k8sClientFactory)
+		if err != nil {
+			log.Info("Unable to discover all facts about the cluster of Managed Environment", "error", err.Error())
+		}
+		clusterInfoChanged = SetManagedEnvironmentClusterInfo(&managedEnvCR, clusterInfo)
+
+	} else {
+		log.Info("API server of Managed Environment is not reachable", "reason", connectivity.Reason, "message", connectivity.Message)
+		metrics.SetManagedEnvironmentUnreachable(managedEnvCR.Name, managedEnvCR.Namespace)
+	}
+
+	if err := UpdateManagedEnvironmentConnectivityStatus(ctx, managedEnvCR, workspaceClient, connectivity, clusterInfoChanged); err != nil {
+		log.Error(err, "Unable to update connectivity status of Managed Environment")
+	}
+
+	// 3) Resolve the namespace selector: only Managed Environments that select Namespaces by label need to be resolved periodically
+	if managedEnvCR.Spec.NamespaceSelector == nil {
+		return false, nil
+	}
+
+	changed, err := ManagedEnvironmentNamespacesChanged(ctx, managedEnvCR, clusterCreds, k8sClientFactory)
+	if err != nil {
+		// The credentials are reacquired (or their failure is reported) the next time the ManagedEnvironment is reconciled
+		log.Error(err, "Unable to resolve namespace selector of Managed Environment")
+		return false, nil
+	}
+
+	if changed {
+		log.Info("Namespaces that match the namespace selector of Managed Environment have changed, so reconciling Managed Environment")
+	}
+
+	return changed, nil
+}
@@ -0,0 +1,272 @@
+package shared_resource_loop
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	"github.com/redhat-appstudio/managed-gitops/backend/metrics"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/version"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("SharedResourceEventLoop ManagedEnvironment periodic reconcile Test", func() {
+
+	Context("internalProcessMessage_ReconcileSharedManagedEnvPeriodically tests", func() {
+
+		var k8sClient client.WithWatch
+		var dbQueries db.AllDatabaseQueries
+		var log logr.Logger
+		var ctx context.Context
+		var namespace *corev1.Namespace
+		var server *httptest.Server
+
+		BeforeEach(func() {
+
+			err := db.SetupForTestingDBGinkgo()
+			Expect(err).ToNot(HaveOccurred())
+
+			ctx = context.Background()
+			log = logf.FromContext(ctx)
+
+			scheme,
+				argocdNamespace,
+				kubesystemNamespace,
+				innerNamespace, err := tests.GenericTestSetup()
+			Expect(err).ToNot(HaveOccurred())
+
+			namespace = innerNamespace
+
+			// The fake client is also used as the cluster of the Managed Environments
+			k8sClient = fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(namespace, argocdNamespace, kubesystemNamespace,
+					&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}},
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a-1", Labels: map[string]string{"tenant": "a"}}},
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a-2", Labels: map[string]string{"tenant": "a"}}}).
+				Build()
+
+			dbQueries, err = db.NewUnsafePostgresDBQueries(false, true)
+			Expect(err).ToNot(HaveOccurred())
+
+			// A fake API server, which only accepts 'valid-token'
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if r.Header.Get("Authorization") != "Bearer valid-token" {
+					w.WriteHeader(http.StatusUnauthorized)
+					_ = json.NewEncoder(w).Encode(metav1.Status{
+						TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+						Status:   metav1.StatusFailure,
+						Reason:   metav1.StatusReasonUnauthorized,
+						Code:     http.StatusUnauthorized,
+					})
+					return
+				}
+				_ = json.NewEncoder(w).Encode(version.Info{GitVersion: "v1.25.4"})
+			}))
+
+			metrics.ClearManagedEnvironmentConnectivityMetrics()
+		})
+
+		AfterEach(func() {
+			server.Close()
+			dbQueries.CloseDatabase()
+		})
+
+		createManagedEnvironment := func(name string, namespaceSelector *metav1.LabelSelector,
+			clusterCreds db.ClusterCredentials) *managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment {
+
+			managedEnvCR := &managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace.Name,
+					UID:       uuid.NewUUID(),
+				},
+				Spec: managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironmentSpec{
+					NamespaceSelector: namespaceSelector,
+				},
+			}
+			err := k8sClient.Create(ctx, managedEnvCR)
+			Expect(err).ToNot(HaveOccurred())
+
+			clusterCreds.Clustercredentials_cred_id = "test-" + string(uuid.NewUUID())
+			clusterCreds.Serviceaccount_ns = "kube-system"
+			err = dbQueries.CreateClusterCredentials(ctx, &clusterCreds)
+			Expect(err).ToNot(HaveOccurred())
+
+			managedEnv := db.ManagedEnvironment{
+				Managedenvironment_id: "test-managed-env-" + string(uuid.NewUUID()),
+				Clustercredentials_id: clusterCreds.Clustercredentials_cred_id,
+				Name:                  name,
+			}
+			err = dbQueries.CreateManagedEnvironment(ctx, &managedEnv)
+			Expect(err).ToNot(HaveOccurred())
+
+			apiCRToDatabaseMapping := db.APICRToDatabaseMapping{
+				APIResourceType:      db.APICRToDatabaseMapping_ResourceType_GitOpsDeploymentManagedEnvironment,
+				APIResourceUID:       string(managedEnvCR.UID),
+				APIResourceName:      name,
+				APIResourceNamespace: namespace.Name,
+				NamespaceUID:         string(namespace.UID),
+				DBRelationType:       db.APICRToDatabaseMapping_DBRelationType_ManagedEnvironment,
+				DBRelationKey:        managedEnv.Managedenvironment_id,
+			}
+			err = dbQueries.CreateAPICRToDatabaseMapping(ctx, &apiCRToDatabaseMapping)
+			Expect(err).ToNot(HaveOccurred())
+
+			return managedEnvCR
+		}
+
+		reconcilePeriodically := func(managedEnvCR *managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment) bool {
+			reconcileNeeded, err := internalProcessMessage_ReconcileSharedManagedEnvPeriodically(ctx, k8sClient, managedEnvCR.Name,
+				*namespace, MockSRLK8sClientFactory{fakeClient: k8sClient}, dbQueries, log)
+			Expect(err).ToNot(HaveOccurred())
+			return reconcileNeeded
+		}
+
+		getReachableCondition := func(managedEnvCR *managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment) *metav1.Condition {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(managedEnvCR), managedEnvCR)
+			Expect(err).ToNot(HaveOccurred())
+
+			for i := range managedEnvCR.Status.Conditions {
+				if managedEnvCR.Status.Conditions[i].Type == managedgitopsv1alpha1.ManagedEnvironmentStatusReachable {
+					return &managedEnvCR.Status.Conditions[i]
+				}
+			}
+			return nil
+		}
+
+		It("should request a reconcile, if the token of the ServiceAccount installed by the GitOps Service has expired", func() {
+
+			managedEnvCR := createManagedEnvironment("expired-managed-env", nil, db.ClusterCredentials{
+				Host:                            server.URL,
+				Serviceaccount_bearer_token:     "expired-token",
+				Serviceaccount_installation_uid: "test-" + string(uuid.NewUUID()),
+				Serviceaccount_token_expiration: time.Now().Add(-time.Hour),
+			})
+
+			Expect(reconcilePeriodically(managedEnvCR)).To(BeTrue())
+		})
+
+		It("should not request a reconcile, if the credentials were provided by the user, or the token is not due for renewal", func() {
+
+			userProvidedEnvCR := createManagedEnvironment("user-provided-managed-env", nil, db.ClusterCredentials{
+				Host:                        server.URL,
+				Serviceaccount_bearer_token: "valid-token",
+			})
+			Expect(reconcilePeriodically(userProvidedEnvCR)).To(BeFalse())
+
+			validEnvCR := createManagedEnvironment("valid-managed-env", nil, db.ClusterCredentials{
+				Host:                            server.URL,
+				Serviceaccount_bearer_token:     "valid-token",
+				Serviceaccount_installation_uid: "test-" + string(uuid.NewUUID()),
+				Serviceaccount_token_expiration: time.Now().Add(ServiceAccountTokenExpiration),
+			})
+			Expect(reconcilePeriodically(validEnvCR)).To(BeFalse())
+		})
+
+		It("should record the connectivity of the Managed Environment in its status and metrics", func() {
+
+			reachableEnv := createManagedEnvironment("reachable-managed-env", nil, db.ClusterCredentials{
+				Host:                        server.URL,
+				Serviceaccount_bearer_token: "valid-token",
+			})
+			unauthorizedEnv := createManagedEnvironment("unauthorized-managed-env", nil, db.ClusterCredentials{
+				Host:                        server.URL,
+				Serviceaccount_bearer_token: "invalid-token",
+			})
+
+			Expect(reconcilePeriodically(reachableEnv)).To(BeFalse())
+			Expect(reconcilePeriodically(unauthorizedEnv)).To(BeFalse())
+
+			By("verifying the status of the reachable Managed Environment")
+			condition := getReachableCondition(reachableEnv)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(string(managedgitopsv1alpha1.ConditionReasonSucceeded)))
+			Expect(reachableEnv.Status.ServerVersion).To(Equal("v1.25.4"))
+			Expect(reachableEnv.Status.ServerLatency).ToNot(BeNil())
+			Expect(reachableEnv.Status.LastSuccessfulContactTime).ToNot(BeNil())
+			Expect(reachableEnv.Status.Platform).To(Equal(managedgitopsv1alpha1.ManagedEnvironmentPlatformKubernetes))
+			Expect(reachableEnv.Status.NodeCount).ToNot(BeNil())
+			Expect(*reachableEnv.Status.NodeCount).To(Equal(int32(1)))
+			Expect(testutil.ToFloat64(metrics.ManagedEnvReachable.WithLabelValues(reachableEnv.Name, reachableEnv.Namespace))).To(Equal(float64(1)))
+
+			By("verifying the status of the Managed Environment whose credentials are rejected")
+			condition = getReachableCondition(unauthorizedEnv)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(string(managedgitopsv1alpha1.ConditionReasonUnauthorized)))
+			Expect(unauthorizedEnv.Status.ServerVersion).To(BeEmpty())
+			Expect(unauthorizedEnv.Status.LastSuccessfulContactTime).To(BeNil())
+			Expect(unauthorizedEnv.Status.Platform).To(BeEmpty())
+			Expect(unauthorizedEnv.Status.NodeCount).To(BeNil())
+			Expect(testutil.ToFloat64(metrics.ManagedEnvReachable.WithLabelValues(unauthorizedEnv.Name, unauthorizedEnv.Namespace))).To(Equal(float64(0)))
+		})
+
+		It("should request a reconcile, only if the namespaces that match the namespace selector have changed", func() {
+
+			tenantSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}}
+
+			changedEnvCR := createManagedEnvironment("changed-managed-env", tenantSelector, db.ClusterCredentials{
+				Host:                        server.URL,
+				Serviceaccount_bearer_token: "valid-token",
+				Namespaces:                  "tenant-a-1",
+			})
+			Expect(reconcilePeriodically(changedEnvCR)).To(BeTrue())
+
+			unchangedEnvCR := createManagedEnvironment("unchanged-managed-env", tenantSelector, db.ClusterCredentials{
+				Host:                        server.URL,
+				Serviceaccount_bearer_token: "valid-token",
+				Namespaces:                  "tenant-a-1,tenant-a-2",
+			})
+			Expect(reconcilePeriodically(unchangedEnvCR)).To(BeFalse())
+
+			noSelectorEnvCR := createManagedEnvironment("no-selector-managed-env", nil, db.ClusterCredentials{
+				Host:                        server.URL,
+				Serviceaccount_bearer_token: "valid-token",
+				Namespaces:                  "tenant-a-1",
+			})
+			Expect(reconcilePeriodically(noSelectorEnvCR)).To(BeFalse())
+		})
+
+		It("should not request a reconcile, if the ManagedEnvironment CR doesn't exist, or has not been reconciled yet", func() {
+
+			managedEnvCR := createManagedEnvironment("deleted-managed-env", nil, db.ClusterCredentials{
+				Host:                        server.URL,
+				Serviceaccount_bearer_token: "valid-token",
+			})
+			err := k8sClient.Delete(ctx, managedEnvCR)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(reconcilePeriodically(managedEnvCR)).To(BeFalse())
+
+			By("creating a ManagedEnvironment CR without database entries")
+			newManagedEnvCR := &managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "new-managed-env",
+					Namespace: namespace.Name,
+					UID:       uuid.NewUUID(),
+				},
+			}
+			err = k8sClient.Create(ctx, newManagedEnvCR)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(reconcilePeriodically(newManagedEnvCR)).To(BeFalse())
+		})
+	})
+})
@@ -2,9 +2,11 @@ package shared_resource_loop
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/go-git/go-git/v5/config"
@@ -182,6 +184,9 @@ func internalProcessMessage_ReconcileRepositoryCredential(ctx context.Context,
 	}
 
 	var privateURL, authUsername, authPassword, authSSHKey, secretObj string
+	var gitHubAppCreds gitHubAppCredentials
+	var tlsOptions repositoryTLSOptions
+	var knownHosts string
 	secret := &corev1.Secret{
 		TypeMeta: metav1.TypeMeta{
 			Kind: "Secret",
@@ -213,6 +218,11 @@ func internalProcessMessage_ReconcileRepositoryCredential(ctx context.Context,
 		authUsername = string(secret.Data["username"])
 		authPassword = string(secret.Data["password"])
 		authSSHKey = string(secret.Data["sshPrivateKey"])
+		if creds := gitHubAppCredentialsFromSecret(secret); creds != nil {
+			gitHubAppCreds = *creds
+		}
+		tlsOptions = repositoryTLSOptionsFromSecret(secret)
+		knownHosts = repositoryKnownHosts(gitopsDeploymentRepositoryCredentialCR.Spec.KnownHosts, secret)
 		secretObj = secret.Name
 	}
 
@@ -221,6 +231,11 @@ func internalProcessMessage_ReconcileRepositoryCredential(ctx context.Context,
 		l.Error(err, fmt.Sprintf("error updating status of GitopsDeploymentRepositoryCredential %v", gitopsDeploymentRepositoryCredentialCR))
 	}
 
+	// The SSH known hosts entries are shared by all the users of the Argo CD instance, so invalid entries are never stored
+	if err := managedgitopsv1alpha1.ValidateKnownHosts(knownHosts, privateURL); err != nil {
+		return nil, fmt.Errorf("invalid SSH known hosts entries: %v", err)
+	}
+
 	// 6) If there is no existing APICRToDBMapping for this CR, then let's create one
 	if currentAPICRToDBMapping == nil {
 		dbRepoCred := db.RepositoryCredentials{
@@ -231,6 +246,20 @@ func internalProcessMessage_ReconcileRepositoryCredential(ctx context.Context,
 			AuthSSHKey:      authSSHKey,
 			SecretObj:       secretObj,
 			EngineClusterID: gitopsEngineInstance.Gitopsengineinstance_id, // comply with the constraint 'fk_gitopsengineinstance_id',
+			Template:        gitopsDeploymentRepositoryCredentialCR.Spec.Template,
+			Type:            string(gitopsDeploymentRepositoryCredentialCR.Spec.Type),
+
+			TLSClientCertData: tlsOptions.clientCertData,
+			TLSClientCertKey:  tlsOptions.clientCertKey,
+			TLSCACertData:     tlsOptions.caCertData,
+			Insecure:          tlsOptions.insecure,
+
+			KnownHosts: knownHosts,
+
+			GithubAppID:                gitHubAppCreds.appID,
+			GithubAppInstallationID:    gitHubAppCreds.installationID,
+			GithubAppPrivateKey:        gitHubAppCreds.privateKey,
+			GithubAppEnterpriseBaseURL: gitHubAppCreds.enterpriseBaseURL,
 		}
 
 		if err := dbQueries.CreateRepositoryCredentials(ctx, &dbRepoCred); err != nil {
@@ -439,8 +468,19 @@ func createRepoCredOperation(ctx context.Context, dbRepoCred db.RepositoryCreden
 // to preserve the LastTransitionTime (see https://pkg.go.dev/k8s.io/apimachinery/pkg/apis/meta/v1#Condition.LastTransitionTime )
 func UpdateGitopsDeploymentRepositoryCredentialStatus(ctx context.Context, repositoryCredential *managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential, k8sClient client.Client, secret *corev1.Secret, log logr.Logger) error {
 
+	repositoryToValidate := repositoryCredential.Spec.Repository
+	if repositoryCredential.Spec.Template {
+		// The URL prefix of a credential template is not itself a repository, so instead validate the credentials
+		// against one of the repositories that it covers
+		var err error
+		if repositoryToValidate, err = findRepositoryCoveredByTemplate(ctx, *repositoryCredential, k8sClient); err != nil {
+			log.Error(err, "unable to find a repository covered by the credential template")
+			return err
+		}
+	}
+
 	// if the condition was sent along with the function call, we don't need to perform additional checks
-	newConditions := generateValidRepositoryCredentialsConditions(*repositoryCredential, ctx, secret)
+	newConditions := generateValidRepositoryCredentialsConditions(*repositoryCredential, repositoryToValidate, ctx, secret)
 
 	needToUpdateConditions := false
 	for _, condition := range newConditions {
@@ -476,7 +516,10 @@ func UpdateGitopsDeploymentRepositoryCredentialStatus(ctx context.Context, repos
 	return nil
 }
 
-func generateValidRepositoryCredentialsConditions(repositoryCredential managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential, ctx context.Context, secret *corev1.Secret) []metav1.Condition {
+// generateValidRepositoryCredentialsConditions validates the credentials of the Secret against 'repositoryToValidate',
+// which is the repository of the GitOpsDeploymentRepositoryCredential, or, for a credential template, one of the
+// repositories that it covers (empty if there are none).
+func generateValidRepositoryCredentialsConditions(repositoryCredential managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential, repositoryToValidate string, ctx context.Context, secret *corev1.Secret) []metav1.Condition {
 
 	var validRepoUrlCondition, validRepoCredCondition metav1.Condition
 
@@ -513,10 +556,74 @@ func generateValidRepositoryCredentialsConditions(repositoryCredential managedgi
 			Status:  metav1.ConditionFalse,
 			Message: errorOccuredCondition.Message,
 		}
+	} else if repositoryCredential.Spec.Template && repositoryToValidate == "" {
+		// None of the repositories covered by the credential template are used yet, so there is nothing to validate against
+		errorOccuredCondition = metav1.Condition{
+			Type:    managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialConditionErrorOccurred,
+			Reason:  managedgitopsv1alpha1.RepositoryCredentialReasonTemplateNotValidated,
+			Status:  metav1.ConditionFalse,
+			Message: "RepositoryCredentials template is not yet used by a GitOpsDeployment",
+		}
+		validRepoUrlCondition = metav1.Condition{
+			Type:    managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialConditionValidRepositoryUrl,
+			Reason:  managedgitopsv1alpha1.RepositoryCredentialReasonValidRepositoryUrl,
+			Status:  metav1.ConditionTrue,
+			Message: fmt.Sprintf("Repository URL prefix %s is valid", repositoryCredential.Spec.Repository),
+		}
+		validRepoCredCondition = metav1.Condition{
+			Type:    managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialConditionValidRepositoryCredential,
+			Reason:  managedgitopsv1alpha1.RepositoryCredentialReasonTemplateNotValidated,
+			Status:  metav1.ConditionUnknown,
+			Message: fmt.Sprintf("Repository Credentials provided %s will be validated once a GitOpsDeployment uses a repository under %s", secret.Name, repositoryCredential.Spec.Repository),
+		}
+	} else if knownHostsErr := managedgitopsv1alpha1.ValidateKnownHosts(repositoryKnownHosts(repositoryCredential.Spec.KnownHosts, secret),
+		repositoryCredential.Spec.Repository); knownHostsErr != nil {
+		// The SSH known hosts entries may not be used for the repository, so the credentials are not validated
+		validRepoUrlCondition = metav1.Condition{
+			Type:    managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialConditionValidRepositoryUrl,
+			Reason:  managedgitopsv1alpha1.RepositoryCredentialReasonInvalidKnownHosts,
+			Status:  metav1.ConditionUnknown,
+			Message: fmt.Sprintf("Repository %s could not be verified: %s", repositoryCredential.Spec.Repository, knownHostsErr.Error()),
+		}
+		validRepoCredCondition = metav1.Condition{
+			Type:    managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialConditionValidRepositoryCredential,
+			Reason:  managedgitopsv1alpha1.RepositoryCredentialReasonInvalidKnownHosts,
+			Status:  metav1.ConditionUnknown,
+			Message: fmt.Sprintf("Repository Credentials provided %s were not validated, as the SSH known hosts entries are invalid", secret.Name),
+		}
+		errorOccuredCondition = metav1.Condition{
+			Type:    managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialConditionErrorOccurred,
+			Reason:  managedgitopsv1alpha1.RepositoryCredentialReasonInvalidKnownHosts,
+			Status:  metav1.ConditionTrue,
+			Message: fmt.Sprintf("Invalid SSH known hosts entries: %s", knownHostsErr.Error()),
+		}
 	} else {
-		err := validateRepositoryCredentials(repositoryCredential.Spec.Repository, secret)
+		knownHosts := repositoryKnownHosts(repositoryCredential.Spec.KnownHosts, secret)
+
+		err := validateRepositoryCredentialsOfType(ctx, repositoryCredential.Spec.Type, repositoryToValidate, secret, knownHosts)
 		if err != nil {
-			if strings.Contains(err.Error(), "not found") {
+			var hostKeyErr *hostKeyError
+			if errors.As(err, &hostKeyErr) {
+				// The server of the repository could not be verified, so the credentials were not sent
+				validRepoUrlCondition = metav1.Condition{
+					Type:    managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialConditionValidRepositoryUrl,
+					Reason:  managedgitopsv1alpha1.RepositoryCredentialReasonHostKeyMismatch,
+					Status:  metav1.ConditionUnknown,
+					Message: fmt.Sprintf("Repository %s could not be verified: %s", repositoryToValidate, err.Error()),
+				}
+				validRepoCredCondition = metav1.Condition{
+					Type:    managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialConditionValidRepositoryCredential,
+					Reason:  managedgitopsv1alpha1.RepositoryCredentialReasonHostKeyMismatch,
+					Status:  metav1.ConditionFalse,
+					Message: fmt.Sprintf("Repository Credentials provided %s for Repository %s could not be validated: %s", secret.Name, repositoryToValidate, err.Error()),
+				}
+				errorOccuredCondition = metav1.Condition{
+					Type:    managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialConditionErrorOccurred,
+					Reason:  managedgitopsv1alpha1.RepositoryCredentialReasonHostKeyMismatch,
+					Status:  metav1.ConditionTrue,
+					Message: fmt.Sprintf("Host key verification failed for Repository %s: %s", repositoryToValidate, err.Error()),
+				}
+			} else if strings.Contains(err.Error(), "not found") {
 				// Repository does not exist
 				validRepoUrlCondition = metav1.Condition{
 					Type:    managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialConditionValidRepositoryUrl,
@@ -542,19 +649,19 @@ func generateValidRepositoryCredentialsConditions(repositoryCredential managedgi
 					Type:    managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialConditionValidRepositoryUrl,
 					Reason:  managedgitopsv1alpha1.RepositoryCredentialReasonValidRepositoryUrl,
 					Status:  metav1.ConditionTrue,
-					Message: fmt.Sprintf("Repository %s exists", repositoryCredential.Spec.Repository),
+					Message: fmt.Sprintf("Repository %s exists", repositoryToValidate),
 				}
 				validRepoCredCondition = metav1.Condition{
 					Type:    managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialConditionValidRepositoryCredential,
 					Reason:  managedgitopsv1alpha1.RepositoryCredentialReasonInvalidCredentials,
 					Status:  metav1.ConditionFalse,
-					Message: fmt.Sprintf("Repository Credentials provided %s for Repository %s are invalid", secret.Name, repositoryCredential.Spec.Repository),
+					Message: fmt.Sprintf("Repository Credentials provided %s for Repository %s are invalid", secret.Name, repositoryToValidate),
 				}
 				errorOccuredCondition = metav1.Condition{
 					Type:    managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialConditionErrorOccurred,
 					Reason:  managedgitopsv1alpha1.RepositoryCredentialReasonInvalidCredentials,
 					Status:  metav1.ConditionTrue,
-					Message: fmt.Sprintf("Repository Credentials provided %s for Repository %s are invalid", secret.Name, repositoryCredential.Spec.Repository),
+					Message: fmt.Sprintf("Repository Credentials provided %s for Repository %s are invalid", secret.Name, repositoryToValidate),
 				}
 			}
 		} else {
@@ -569,13 +676,13 @@ func generateValidRepositoryCredentialsConditions(repositoryCredential managedgi
 				Type:    managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialConditionValidRepositoryUrl,
 				Reason:  managedgitopsv1alpha1.RepositoryCredentialReasonValidRepositoryUrl,
 				Status:  metav1.ConditionTrue,
-				Message: fmt.Sprintf("Repository %s exists", repositoryCredential.Spec.Repository),
+				Message: fmt.Sprintf("Repository %s exists", repositoryToValidate),
 			}
 			validRepoCredCondition = metav1.Condition{
 				Type:    managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialConditionValidRepositoryCredential,
 				Reason:  managedgitopsv1alpha1.RepositoryCredentialReasonCredentialsUpToDate,
 				Status:  metav1.ConditionTrue,
-				Message: fmt.Sprintf("Repository Credentials provided %s for Repository %s are valid", secret.Name, repositoryCredential.Spec.Repository),
+				Message: fmt.Sprintf("Repository Credentials provided %s for Repository %s are valid", secret.Name, repositoryToValidate),
 			}
 		}
 	}
@@ -583,7 +690,9 @@ func generateValidRepositoryCredentialsConditions(repositoryCredential managedgi
 	return []metav1.Condition{errorOccuredCondition, validRepoUrlCondition, validRepoCredCondition}
 }
 
-func validateRepositoryCredentials(rawRepoURL string, secret *corev1.Secret) error {
+// validateRepositoryCredentials validates the credentials of the Secret by listing the references of the Git repository.
+// For SSH repository URLs, the host key of the server is verified against the known hosts entries.
+func validateRepositoryCredentials(ctx context.Context, rawRepoURL string, secret *corev1.Secret, knownHosts string) error {
 
 	normalizedRepoUrl := NormalizeGitURL(rawRepoURL)
 	rem := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
@@ -598,11 +707,49 @@ func validateRepositoryCredentials(rawRepoURL string, secret *corev1.Secret) err
 
 	listOptions := &git.ListOptions{}
 
-	if authSSHKey != "" {
+	var hostKeyChecker *strictHostKeyChecker
+
+	isSSH, _ := isSSHURL(rawRepoURL)
+
+	tlsOptions := repositoryTLSOptionsFromSecret(secret)
+	if isSSH && tlsOptions.hasCertificates() {
+		return fmt.Errorf("TLS certificates can only be used with an HTTPS repository URL")
+	}
+
+	if gitHubAppCreds := gitHubAppCredentialsFromSecret(secret); gitHubAppCreds != nil {
+
+		if isSSH {
+			return fmt.Errorf("GitHub App credentials can only be used with an HTTPS repository URL")
+		}
+
+		// As with Argo CD, the TLS options of the repository are also used to connect to the GitHub API
+		httpClient, err := tlsOptions.httpClient(gitHubAppTokenRequestTimeout)
+		if err != nil {
+			return err
+		}
+
+		// The credentials are valid if GitHub is willing to mint an installation token with them
+		token, err := mintGitHubAppInstallationToken(ctx, httpClient, *gitHubAppCreds)
+		if err != nil {
+			return err
+		}
+		listOptions.Auth = &http.BasicAuth{
+			Username: gitHubAppTokenUsername,
+			Password: token,
+		}
+
+	} else if authSSHKey != "" {
 		privateKey, err := ssh.NewPublicKeys("git", []byte(authSSHKey), "")
 		if err != nil {
 			return err
 		}
+
+		// Strict host key checking: the server must have a matching known hosts entry
+		if hostKeyChecker, err = newStrictHostKeyChecker(knownHosts); err != nil {
+			return err
+		}
+		privateKey.HostKeyCallback = hostKeyChecker.hostKeyCallback
+
 		listOptions.Auth = privateKey
 	} else {
 		listOptions.Auth = &http.BasicAuth{
@@ -611,7 +758,16 @@ func validateRepositoryCredentials(rawRepoURL string, secret *corev1.Secret) err
 		}
 	}
 
+	if !isSSH && !tlsOptions.isEmpty() {
+		// Remote.List does not support TLS client certificates, so list the references using a client that does
+		return listRemoteReferencesWithTLS(ctx, normalizedRepoUrl, listOptions.Auth, tlsOptions)
+	}
+
 	_, err := rem.List(listOptions)
+	if hostKeyChecker != nil && hostKeyChecker.err != nil {
+		// Report the host key error, rather than the (less specific) error of the connection
+		return hostKeyChecker.err
+	}
 	return err
 }
 
@@ -665,6 +821,57 @@ func isSSHURL(url string) (bool, string) {
 	return false, ""
 }
 
+// isRepositoryCoveredByTemplates returns true if the URL of the repository begins with the URL prefix of one of the
+// credential templates.
+func isRepositoryCoveredByTemplates(repoURL string, templateURLPrefixes []string) bool {
+
+	normalizedRepoURL := NormalizeGitURL(repoURL)
+
+	for _, templateURLPrefix := range templateURLPrefixes {
+		normalizedTemplateURLPrefix := NormalizeGitURL(templateURLPrefix)
+
+		if normalizedTemplateURLPrefix != "" && strings.HasPrefix(normalizedRepoURL, normalizedTemplateURLPrefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findRepositoryCoveredByTemplate returns the first (by URL) repository of the GitOpsDeployments in the Namespace of the
+// credential template that is covered by the template, or an empty string if there is no such repository.
+func findRepositoryCoveredByTemplate(ctx context.Context, repositoryCredential managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential, k8sClient client.Client) (string, error) {
+
+	var gitopsDeployments managedgitopsv1alpha1.GitOpsDeploymentList
+	if err := k8sClient.List(ctx, &gitopsDeployments, &client.ListOptions{Namespace: repositoryCredential.Namespace}); err != nil {
+		return "", fmt.Errorf("unable to list GitOpsDeployments in Namespace '%s': %w", repositoryCredential.Namespace, err)
+	}
+
+	var coveredRepositories []string
+
+	for _, gitopsDepl := range gitopsDeployments.Items {
+
+		sources := []managedgitopsv1alpha1.ApplicationSource{gitopsDepl.Spec.Source}
+		if gitopsDepl.Spec.HasMultipleSources() {
+			sources = gitopsDepl.Spec.Sources
+		}
+
+		for _, source := range sources {
+			if isRepositoryCoveredByTemplates(source.RepoURL, []string{repositoryCredential.Spec.Repository}) {
+				coveredRepositories = append(coveredRepositories, source.RepoURL)
+			}
+		}
+	}
+
+	if len(coveredRepositories) == 0 {
+		return "", nil
+	}
+
+	sort.Strings(coveredRepositories)
+
+	return coveredRepositories[0], nil
+}
+
 func processAppProjectRepository(ctx context.Context, resourceNS corev1.Namespace, apiNamespaceClient client.Client, dbQueries db.DatabaseQueries, l logr.Logger) (bool, error) {
 
 	return reconcileAppProjectRepositories(ctx, resourceNS, apiNamespaceClient, dbQueries, l)
@@ -275,13 +275,94 @@ var _ = Describe("SharedResourceEventLoop Repository Credential Tests", func() {
 
 			Expect(gitopsDeploymentRepositoryCredentialCR).Should(SatisfyAll(haveErrOccurredConditionSet(expectedRepoCredStatus, false)))
 		})
+		It("should not validate a credential template that does not yet cover a repository of a GitOpsDeployment", func() {
+			gitopsDeploymentRepositoryCredentialCR.Spec.Secret = "test"
+			gitopsDeploymentRepositoryCredentialCR.Spec.Repository = "https://gitlab.com/my-group/"
+			gitopsDeploymentRepositoryCredentialCR.Spec.Template = true
+
+			expectedRepoCredStatus := managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:   managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialConditionErrorOccurred,
+						Reason: managedgitopsv1alpha1.RepositoryCredentialReasonTemplateNotValidated,
+						Status: metav1.ConditionFalse,
+					}, {
+						Type:   managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialConditionValidRepositoryUrl,
+						Reason: managedgitopsv1alpha1.RepositoryCredentialReasonValidRepositoryUrl,
+						Status: metav1.ConditionTrue,
+					}, {
+						Type:   managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialConditionValidRepositoryCredential,
+						Reason: managedgitopsv1alpha1.RepositoryCredentialReasonTemplateNotValidated,
+						Status: metav1.ConditionUnknown,
+					},
+				},
+			}
+
+			err := UpdateGitopsDeploymentRepositoryCredentialStatus(ctx, gitopsDeploymentRepositoryCredentialCR, k8sClient, &corev1.Secret{}, log.FromContext(ctx))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(gitopsDeploymentRepositoryCredentialCR).Should(SatisfyAll(haveErrOccurredConditionSet(expectedRepoCredStatus, false)))
+		})
+	})
+
+	Context("Test credential templates", func() {
+
+		DescribeTable("Test scenarios for isRepositoryCoveredByTemplates", func(repoUrl string, templateUrlPrefixes []string, expected bool) {
+
+			Expect(isRepositoryCoveredByTemplates(repoUrl, templateUrlPrefixes)).To(Equal(expected))
+		},
+			Entry("Repository under the URL prefix", "https://gitlab.com/my-group/my-repo.git", []string{"https://gitlab.com/my-group/"}, true),
+			Entry("Repository under the URL prefix, in another case", "https://GitLab.com/My-Group/my-repo", []string{"https://gitlab.com/my-group/"}, true),
+			Entry("SSH repository under the URL prefix", "git@gitlab.com:my-group/my-repo.git", []string{"git@gitlab.com:my-group/"}, true),
+			Entry("Repository under the second URL prefix", "https://github.com/my-org/my-repo", []string{"https://gitlab.com/my-group/", "https://github.com/my-org/"}, true),
+			Entry("Repository not under the URL prefix", "https://gitlab.com/other-group/my-repo", []string{"https://gitlab.com/my-group/"}, false),
+			Entry("No templates", "https://gitlab.com/my-group/my-repo", nil, false),
+		)
+
+		It("should find the first repository of the GitOpsDeployments that is covered by the template", func() {
+
+			scheme, _, _, workspace, err := tests.GenericTestSetup()
+			Expect(err).ToNot(HaveOccurred())
+
+			newGitOpsDeployment := func(name string, repoURL string) *managedgitopsv1alpha1.GitOpsDeployment {
+				return &managedgitopsv1alpha1.GitOpsDeployment{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: workspace.Name},
+					Spec: managedgitopsv1alpha1.GitOpsDeploymentSpec{
+						Source: managedgitopsv1alpha1.ApplicationSource{RepoURL: repoURL},
+					},
+				}
+			}
+
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				newGitOpsDeployment("depl-1", "https://github.com/my-org/my-repo"),
+				newGitOpsDeployment("depl-2", "https://gitlab.com/my-group/repo-b"),
+				newGitOpsDeployment("depl-3", "https://gitlab.com/my-group/repo-a")).Build()
+
+			repoCred := managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-repocred", Namespace: workspace.Name},
+				Spec: managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialSpec{
+					Repository: "https://gitlab.com/my-group/",
+					Template:   true,
+				},
+			}
+
+			repository, err := findRepositoryCoveredByTemplate(context.Background(), repoCred, k8sClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(repository).To(Equal("https://gitlab.com/my-group/repo-a"))
+
+			By("using a URL prefix that does not cover any of the repositories")
+			repoCred.Spec.Repository = "https://gitlab.com/other-group/"
+			repository, err = findRepositoryCoveredByTemplate(context.Background(), repoCred, k8sClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(repository).To(BeEmpty())
+		})
 	})
 
 	Context("Test validateRepositoryCredentials", func() {
 
 		DescribeTable("Test scenarios for validateRepositoryCredentials", func(repoUrl string, secret *corev1.Secret, expectedString string) {
 
-			err := validateRepositoryCredentials(repoUrl, secret)
+			err := validateRepositoryCredentials(context.Background(), repoUrl, secret, "")
 
 			Expect(err).To(HaveOccurred())
 			Expect(strings.Contains(err.Error(), expectedString)).To(BeTrue())
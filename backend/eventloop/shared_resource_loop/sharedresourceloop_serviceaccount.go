@@ -0,0 +1,262 @@
+package shared_resource_loop
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	"github.com/redhat-appstudio/managed-gitops/backend/metrics"
+)
+
+// serviceAccountCleanupRetryLoop retries the removal of ServiceAccounts (and their RBAC resources) that the GitOps Service
+// installed into a managed cluster, when they could not be removed at the time their ClusterCredentials were no longer used.
+// For example, because the managed cluster was unreachable.
+var serviceAccountCleanupRetryLoop = sharedutil.NewTaskRetryLoop("managed-env-service-account-cleanup")
+
+// maxServiceAccountCleanupAttempts is the number of times the removal of a ServiceAccount is attempted by the
+// serviceAccountCleanupRetryLoop. After that, the removal is only retried when the database reconciler next finds the
+// (orphaned) ClusterCredentials row.
+const maxServiceAccountCleanupAttempts = 10
+
+// serviceAccountCleanupManagedEnvs contains the ManagedEnvironment CR that used each ClusterCredentials whose ServiceAccount
+// could not be removed, so that the ServiceAccountRemovalFailed condition of the CR can still be updated when the removal is
+// re-queued by the database reconciler (which does not know the CR).
+var serviceAccountCleanupManagedEnvs = struct {
+	sync.Mutex
+	byClusterCredentialsID map[string]types.NamespacedName
+}{byClusterCredentialsID: map[string]types.NamespacedName{}}
+
+// deleteClusterCredentialsAndServiceAccount deletes a ClusterCredentials row that is no longer used, after removing the
+// ServiceAccount that the GitOps Service installed using those credentials (if any).
+//
+// Returns the number of rows deleted, and whether the row was deleted. If the ServiceAccount could not be removed, the row
+// is NOT deleted: instead, the removal is retried in the background (followed by the deletion of the row). managedEnvironment
+// is the ManagedEnvironment CR that used the ClusterCredentials, if it still exists (nil otherwise): the failure to remove
+// the ServiceAccount is reported in its conditions.
+func deleteClusterCredentialsAndServiceAccount(ctx context.Context, clusterCreds db.ClusterCredentials, managedEnvironment *types.NamespacedName,
+	k8sClientFactory SRLK8sClientFactory, dbQueries db.DatabaseQueries, log logr.Logger) (int, bool, error) {
+
+	if err := uninstallServiceAccountOfClusterCredentials(ctx, clusterCreds, k8sClientFactory, log); err != nil {
+		log.Error(err, "Unable to remove ServiceAccount from managed cluster, removal will be retried", clusterCreds.GetAsLogKeyValues()...)
+
+		metrics.AddLeftoverServiceAccount(clusterCreds.Clustercredentials_cred_id)
+		QueueServiceAccountCleanup(clusterCreds.Clustercredentials_cred_id, managedEnvironment, k8sClientFactory, dbQueries, log)
+		return 0, false, nil
+	}
+
+	rowsDeleted, err := dbQueries.DeleteClusterCredentialsById(ctx, clusterCreds.Clustercredentials_cred_id)
+	return rowsDeleted, true, err
+}
+
+// uninstallServiceAccountOfClusterCredentials removes the ServiceAccount that the GitOps Service installed into the managed
+// cluster of the ClusterCredentials, using the credentials of that ServiceAccount. If no ServiceAccount was installed, this is a no-op.
+func uninstallServiceAccountOfClusterCredentials(ctx context.Context, clusterCreds db.ClusterCredentials,
+	k8sClientFactory SRLK8sClientFactory, log logr.Logger) error {
+
+	if clusterCreds.Serviceaccount_installation_uid == "" {
+		return nil
+	}
+
+	configParam, _, err := sanityTestCredentials(clusterCreds)
+	if err != nil {
+		return err
+	}
+
+	k8sClient, err := k8sClientFactory.BuildK8sClient(configParam)
+	if err != nil {
+		return fmt.Errorf("unable to create new K8s client to '%v': %w", configParam.Host, err)
+	}
+
+	if err := sharedutil.UninstallServiceAccount(ctx, k8sClient, clusterCreds.Serviceaccount_installation_uid, clusterCreds.Serviceaccount_ns, log); err != nil {
+		return fmt.Errorf("unable to uninstall service account from '%v': %w", configParam.Host, err)
+	}
+
+	log.Info("Removed ServiceAccount from managed cluster", "host", clusterCreds.Host,
+		"serviceAccountInstallationUID", clusterCreds.Serviceaccount_installation_uid)
+
+	return nil
+}
+
+// QueueServiceAccountCleanup starts a background task that removes the ServiceAccount installed using the (no longer used)
+// ClusterCredentials, and then deletes the ClusterCredentials row. The task is attempted up to maxServiceAccountCleanupAttempts
+// times: the database reconciler queues it again if the ClusterCredentials row still exists after that.
+//
+// managedEnvironment is the ManagedEnvironment CR that used the ClusterCredentials, if known (nil otherwise). The
+// ServiceAccountRemovalFailed condition of the CR is set while the ServiceAccount cannot be removed.
+func QueueServiceAccountCleanup(clusterCredentialsID string, managedEnvironment *types.NamespacedName, k8sClientFactory SRLK8sClientFactory,
+	dbQueries db.DatabaseQueries, log logr.Logger) {
+
+	if managedEnvironment == nil {
+		serviceAccountCleanupManagedEnvs.Lock()
+		if existing, exists := serviceAccountCleanupManagedEnvs.byClusterCredentialsID[clusterCredentialsID]; exists {
+			managedEnvironment = &existing
+		}
+		serviceAccountCleanupManagedEnvs.Unlock()
+	}
+
+	task := &serviceAccountCleanupTask{
+		clusterCredentialsID: clusterCredentialsID,
+		managedEnvironment:   managedEnvironment,
+		k8sClientFactory:     k8sClientFactory,
+		dbQueries:            dbQueries,
+		log:                  log.WithValues("clusterCredentialsId", clusterCredentialsID),
+	}
+
+	serviceAccountCleanupRetryLoop.AddTaskIfNotPresent("service-account-cleanup-"+clusterCredentialsID, task,
+		sharedutil.ExponentialBackoff{Factor: 2, Min: time.Second * 5, Max: time.Minute * 5, Jitter: true})
+}
+
+type serviceAccountCleanupTask struct {
+	clusterCredentialsID string
+	managedEnvironment   *types.NamespacedName
+	k8sClientFactory     SRLK8sClientFactory
+	dbQueries            db.DatabaseQueries
+	log                  logr.Logger
+
+	// attempts is the number of times the task has been performed
+	attempts int
+}
+
+// Returns true if the task should be retried, false otherwise, plus an error
+func (task *serviceAccountCleanupTask) PerformTask(taskContext context.Context) (bool, error) {
+
+	task.attempts++
+
+	clusterCreds := db.ClusterCredentials{Clustercredentials_cred_id: task.clusterCredentialsID}
+	if err := task.dbQueries.GetClusterCredentialsById(taskContext, &clusterCreds); err != nil {
+		if db.IsResultNotFoundError(err) {
+			// The row has already been deleted, so there is nothing left to clean up
+			task.removalCompleted(taskContext)
+			return false, nil
+		}
+		return task.attempts < maxServiceAccountCleanupAttempts, fmt.Errorf("unable to retrieve cluster credentials '%s': %w", task.clusterCredentialsID, err)
+	}
+
+	if err := uninstallServiceAccountOfClusterCredentials(taskContext, clusterCreds, task.k8sClientFactory, task.log); err != nil {
+		metrics.AddLeftoverServiceAccount(task.clusterCredentialsID)
+		task.updateManagedEnvironmentCondition(taskContext, err)
+
+		if task.attempts >= maxServiceAccountCleanupAttempts {
+			task.log.Error(err, "Unable to remove ServiceAccount from managed cluster, the removal will be retried by the database reconciler",
+				"attempts", task.attempts)
+			return false, err
+		}
+		return true, err
+	}
+	task.removalCompleted(taskContext)
+
+	rowsDeleted, err := task.dbQueries.DeleteClusterCredentialsById(taskContext, task.clusterCredentialsID)
+	if err != nil {
+		return task.attempts < maxServiceAccountCleanupAttempts, fmt.Errorf("unable to delete cluster credentials '%s': %w", task.clusterCredentialsID, err)
+	}
+	if rowsDeleted != 1 {
+		task.log.V(logutil.LogLevel_Warn).Info("unexpected number of rows deleted when deleting cluster credentials", "rowsDeleted", rowsDeleted)
+	}
+	task.log.Info("Deleted ClusterCredentials row, after removing its ServiceAccount from the managed cluster")
+
+	return false, nil
+}
+
+// removalCompleted is called once the ServiceAccount no longer exists in the managed cluster.
+func (task *serviceAccountCleanupTask) removalCompleted(ctx context.Context) {
+
+	metrics.RemoveLeftoverServiceAccount(task.clusterCredentialsID)
+	task.updateManagedEnvironmentCondition(ctx, nil)
+
+	serviceAccountCleanupManagedEnvs.Lock()
+	delete(serviceAccountCleanupManagedEnvs.byClusterCredentialsID, task.clusterCredentialsID)
+	serviceAccountCleanupManagedEnvs.Unlock()
+}
+
+// updateManagedEnvironmentCondition reports the result of the removal of the ServiceAccount in the ServiceAccountRemovalFailed
+// condition of the ManagedEnvironment CR of the task, if any. Errors are logged, rather than failing the task.
+func (task *serviceAccountCleanupTask) updateManagedEnvironmentCondition(ctx context.Context, removalErr error) {
+
+	if task.managedEnvironment == nil {
+		return
+	}
+
+	if removalErr != nil {
+		serviceAccountCleanupManagedEnvs.Lock()
+		serviceAccountCleanupManagedEnvs.byClusterCredentialsID[task.clusterCredentialsID] = *task.managedEnvironment
+		serviceAccountCleanupManagedEnvs.Unlock()
+	}
+
+	workspaceClient, err := task.k8sClientFactory.GetK8sClientForServiceWorkspace()
+	if err != nil {
+		task.log.Error(err, "Unable to create client to update the ServiceAccountRemovalFailed condition of the ManagedEnvironment")
+		return
+	}
+
+	if err := setServiceAccountRemovalFailedCondition(ctx, workspaceClient, *task.managedEnvironment, removalErr); err != nil {
+		task.log.Error(err, "Unable to update the ServiceAccountRemovalFailed condition of the ManagedEnvironment",
+			"managedEnvName", task.managedEnvironment.Name, "managedEnvNamespace", task.managedEnvironment.Namespace)
+	}
+}
+
+// setServiceAccountRemovalFailedCondition sets the ServiceAccountRemovalFailed condition of the ManagedEnvironment CR to True
+// if removalErr is non-nil, or to False otherwise. If the ServiceAccount was removed, and the CR does not have the
+// condition, the condition is not added.
+func setServiceAccountRemovalFailedCondition(ctx context.Context, workspaceClient client.Client, managedEnvironment types.NamespacedName,
+	removalErr error) error {
+
+	status := metav1.ConditionFalse
+	reason := managedgitopsv1alpha1.ConditionReasonSucceeded
+	message := ""
+	if removalErr != nil {
+		status = metav1.ConditionTrue
+		reason = managedgitopsv1alpha1.ConditionReasonUnableToRemoveServiceAccount
+		message = "Unable to remove a ServiceAccount that is no longer used from the cluster: " + removalErr.Error()
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+
+		managedEnvCR := &managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment{}
+		if err := workspaceClient.Get(ctx, managedEnvironment, managedEnvCR); err != nil {
+			if apierr.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+
+		const conditionType = managedgitopsv1alpha1.ManagedEnvironmentStatusServiceAccountRemovalFailed
+		var condition *metav1.Condition = nil
+		for i := range managedEnvCR.Status.Conditions {
+			if managedEnvCR.Status.Conditions[i].Type == conditionType {
+				condition = &managedEnvCR.Status.Conditions[i]
+				break
+			}
+		}
+		if condition == nil {
+			if removalErr == nil {
+				return nil
+			}
+			managedEnvCR.Status.Conditions = append(managedEnvCR.Status.Conditions, metav1.Condition{Type: conditionType})
+			condition = &managedEnvCR.Status.Conditions[len(managedEnvCR.Status.Conditions)-1]
+		}
+
+		if condition.Status == status && condition.Reason == string(reason) && condition.Message == message {
+			return nil
+		}
+		if condition.Status != status {
+			condition.LastTransitionTime = metav1.Now()
+			condition.Status = status
+		}
+		condition.Reason = string(reason)
+		condition.Message = message
+
+		return workspaceClient.Status().Update(ctx, managedEnvCR)
+	})
+}
@@ -0,0 +1,150 @@
+package shared_resource_loop
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1/mocks"
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	dbmocks "github.com/redhat-appstudio/managed-gitops/backend-shared/util/mocks"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logger "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("SharedResourceEventLoop ServiceAccount cleanup Test", func() {
+
+	Context("serviceAccountCleanupTask tests", func() {
+
+		var ctx context.Context
+		var k8sClient client.Client
+		var managedEnvCR *managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment
+		var clusterCreds db.ClusterCredentials
+
+		BeforeEach(func() {
+			ctx = context.Background()
+
+			scheme, argocdNamespace, kubesystemNamespace, workspace, err := tests.GenericTestSetup()
+			Expect(err).ToNot(HaveOccurred())
+
+			managedEnvCR = &managedgitopsv1alpha1.GitOpsDeploymentManagedEnvironment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-managed-env",
+					Namespace: workspace.Name,
+				},
+			}
+
+			k8sClient = fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(workspace, argocdNamespace, kubesystemNamespace, managedEnvCR).
+				Build()
+
+			clusterCreds = db.ClusterCredentials{
+				Clustercredentials_cred_id:      "test-" + string(uuid.NewUUID()),
+				Host:                            "https://api.fake-unit-test-data.origin-ci-int-gce.dev.rhcloud.com:6443",
+				Serviceaccount_bearer_token:     "token",
+				Serviceaccount_ns:               "kube-system",
+				Serviceaccount_installation_uid: "test-" + string(uuid.NewUUID()),
+			}
+		})
+
+		getRemovalFailedCondition := func() *metav1.Condition {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(managedEnvCR), managedEnvCR)
+			Expect(err).ToNot(HaveOccurred())
+
+			for i := range managedEnvCR.Status.Conditions {
+				if managedEnvCR.Status.Conditions[i].Type == managedgitopsv1alpha1.ManagedEnvironmentStatusServiceAccountRemovalFailed {
+					return &managedEnvCR.Status.Conditions[i]
+				}
+			}
+			return nil
+		}
+
+		It("should report a failure to remove the ServiceAccount in a condition, give up after the maximum number of attempts, and clear the condition once the removal succeeds", func() {
+
+			mockCtrl := gomock.NewController(GinkgoT())
+			defer mockCtrl.Finish()
+
+			mockDB := dbmocks.NewMockDatabaseQueries(mockCtrl)
+			mockDB.EXPECT().GetClusterCredentialsById(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, clusterCredentials *db.ClusterCredentials) error {
+					*clusterCredentials = clusterCreds
+					return nil
+				}).AnyTimes()
+
+			failingClient := mocks.NewMockClient(mockCtrl)
+			failingClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Return(fmt.Errorf("fake unable to connect")).AnyTimes()
+
+			factory := &SimulateFailingClientMockSRLK8sClientFactory{
+				limit:          maxServiceAccountCleanupAttempts,
+				failingClient:  failingClient,
+				realFakeClient: k8sClient,
+			}
+
+			task := &serviceAccountCleanupTask{
+				clusterCredentialsID: clusterCreds.Clustercredentials_cred_id,
+				managedEnvironment:   &types.NamespacedName{Name: managedEnvCR.Name, Namespace: managedEnvCR.Namespace},
+				k8sClientFactory:     factory,
+				dbQueries:            mockDB,
+				log:                  logger.FromContext(ctx),
+			}
+
+			By("verifying the task is retried, and the condition is set, while the ServiceAccount cannot be removed")
+			for attempt := 1; attempt < maxServiceAccountCleanupAttempts; attempt++ {
+				retry, err := task.PerformTask(ctx)
+				Expect(err).To(HaveOccurred())
+				Expect(retry).To(BeTrue())
+			}
+
+			condition := getRemovalFailedCondition()
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(string(managedgitopsv1alpha1.ConditionReasonUnableToRemoveServiceAccount)))
+			Expect(condition.Message).To(ContainSubstring("fake unable to connect"))
+
+			By("verifying the task is no longer retried after the maximum number of attempts")
+			retry, err := task.PerformTask(ctx)
+			Expect(err).To(HaveOccurred())
+			Expect(retry).To(BeFalse())
+
+			By("verifying that a task queued again for the same credentials, without a ManagedEnvironment, clears the condition once the ServiceAccount is removed")
+			mockDB.EXPECT().DeleteClusterCredentialsById(gomock.Any(), clusterCreds.Clustercredentials_cred_id).Return(1, nil)
+
+			QueueServiceAccountCleanup(clusterCreds.Clustercredentials_cred_id, nil, factory, mockDB, logger.FromContext(ctx))
+
+			Eventually(func() metav1.ConditionStatus {
+				condition := getRemovalFailedCondition()
+				if condition == nil {
+					return ""
+				}
+				return condition.Status
+			}, "60s", "500ms").Should(Equal(metav1.ConditionFalse))
+
+			condition = getRemovalFailedCondition()
+			Expect(condition.Reason).To(Equal(string(managedgitopsv1alpha1.ConditionReasonSucceeded)))
+		})
+
+		It("should not add the condition to a ManagedEnvironment whose ServiceAccount was removed on the first attempt", func() {
+
+			err := setServiceAccountRemovalFailedCondition(ctx, k8sClient, client.ObjectKeyFromObject(managedEnvCR), nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(getRemovalFailedCondition()).To(BeNil())
+		})
+
+		It("should ignore a ManagedEnvironment that no longer exists", func() {
+
+			err := setServiceAccountRemovalFailedCondition(ctx, k8sClient,
+				types.NamespacedName{Name: "missing-managed-env", Namespace: managedEnvCR.Namespace}, fmt.Errorf("fake error"))
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+})
@@ -1188,6 +1188,60 @@ var _ = Describe("SharedResourceEventLoop Test", func() {
 			})
 		})
 
+		When("a GitOpsDeploymentRepositoryCredential is a credential template", func() {
+
+			It("should create a single AppProjectRepository covering every repository of the GitOpsDeployments under the URL prefix", func() {
+
+				By("creating a credential template, and GitOpsDeployments referencing repositories inside and outside of its URL prefix")
+
+				gitopsRepoCred := managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredential{
+					ObjectMeta: metav1.ObjectMeta{Name: "my-repo-cred-template", Namespace: namespace.Name},
+					Spec: managedgitopsv1alpha1.GitOpsDeploymentRepositoryCredentialSpec{
+						Repository: "https://gitlab.com/my-group/",
+						Template:   true,
+					},
+				}
+				Expect(k8sClient.Create(ctx, &gitopsRepoCred)).Error().ToNot(HaveOccurred())
+
+				for name, repoURL := range map[string]string{
+					"my-gitops-depl-1": "https://gitlab.com/my-group/repo-1",
+					"my-gitops-depl-2": "https://gitlab.com/my-group/repo-2.git",
+					"my-gitops-depl-3": gitRepoURL,
+				} {
+					gitopsDepl := managedgitopsv1alpha1.GitOpsDeployment{
+						ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace.Name},
+						Spec: managedgitopsv1alpha1.GitOpsDeploymentSpec{
+							Source: managedgitopsv1alpha1.ApplicationSource{
+								RepoURL: repoURL,
+							},
+						},
+					}
+					Expect(k8sClient.Create(ctx, &gitopsDepl)).Error().ToNot(HaveOccurred())
+				}
+
+				By("calling the function being tested")
+				dbUpdated, err := reconcileAppProjectRepositories(ctx, namespace, k8sClient, dbq, l)
+				Expect(dbUpdated).To(BeTrue())
+				Expect(err).ToNot(HaveOccurred())
+
+				By("verifying that only the URL prefix, and the repository outside of it, exist in the database")
+				res := []db.AppProjectRepository{}
+				Expect(dbq.ListAppProjectRepositoryByClusterUserId(ctx, clusterUser.Clusteruser_id, &res)).Error().ToNot(HaveOccurred())
+
+				repoURLs := []string{}
+				for _, appProjectRepo := range res {
+					repoURLs = append(repoURLs, appProjectRepo.RepoURL)
+				}
+				Expect(repoURLs).To(ConsistOf("https://gitlab.com/my-group/*", gitRepoURL))
+
+				By("calling the function being tested again, but this time with nothing changes")
+				dbUpdated, err = reconcileAppProjectRepositories(ctx, namespace, k8sClient, dbq, l)
+				Expect(dbUpdated).To(BeFalse(), "since nothing changed, the function should report that the database was not updated")
+				Expect(err).ToNot(HaveOccurred())
+
+			})
+		})
+
 		When("the repo URL of a GitOpsDeployment was changed from one value to another", func() {
 
 			It("should update the AppProject rows from the first url to the second URL", func() {
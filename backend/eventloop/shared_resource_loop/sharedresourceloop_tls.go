@@ -0,0 +1,134 @@
+package shared_resource_loop
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// gitRequestTimeout is the maximum time to wait for a Git repository server to list the references of a repository, when
+// connecting using TLS options.
+const gitRequestTimeout = 30 * time.Second
+
+// The keys of the TLS options, in both the Secret referenced by a GitOpsDeploymentRepositoryCredential, and the Argo CD
+// repository secret.
+const (
+	secretKeyTLSClientCertData = "tlsClientCertData"
+	secretKeyTLSClientCertKey  = "tlsClientCertKey"
+	secretKeyTLSCACertData     = "tlsCACertData"
+	secretKeyInsecure          = "insecure"
+)
+
+// repositoryTLSOptions are the TLS options that are used to connect to a repository server: a client certificate for
+// mutual TLS, a bundle of CAs that are trusted to sign the certificate of the server, and whether that certificate is
+// verified at all.
+type repositoryTLSOptions struct {
+	clientCertData string
+	clientCertKey  string
+	caCertData     string
+	insecure       bool
+}
+
+// repositoryTLSOptionsFromSecret returns the TLS options of the Secret.
+func repositoryTLSOptionsFromSecret(secret *corev1.Secret) repositoryTLSOptions {
+
+	// An invalid value is treated as false, as with Argo CD
+	insecure, _ := strconv.ParseBool(string(secret.Data[secretKeyInsecure]))
+
+	return repositoryTLSOptions{
+		clientCertData: string(secret.Data[secretKeyTLSClientCertData]),
+		clientCertKey:  string(secret.Data[secretKeyTLSClientCertKey]),
+		caCertData:     string(secret.Data[secretKeyTLSCACertData]),
+		insecure:       insecure,
+	}
+}
+
+// isEmpty returns true if no TLS options were specified, in which case the defaults (system CAs, no client certificate) are used.
+func (o repositoryTLSOptions) isEmpty() bool {
+	return o == repositoryTLSOptions{}
+}
+
+// hasCertificates returns true if a client certificate or CA bundle was specified, which are only meaningful for HTTPS.
+func (o repositoryTLSOptions) hasCertificates() bool {
+	return o.clientCertData != "" || o.clientCertKey != "" || o.caCertData != ""
+}
+
+// tlsConfig returns the TLS configuration of the options.
+func (o repositoryTLSOptions) tlsConfig() (*tls.Config, error) {
+
+	// #nosec G402 -- skipping verification is explicitly requested by the user, as is supported by Argo CD
+	tlsConfig := &tls.Config{InsecureSkipVerify: o.insecure}
+
+	if o.clientCertData != "" || o.clientCertKey != "" {
+		clientCert, err := tls.X509KeyPair([]byte(o.clientCertData), []byte(o.clientCertKey))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse TLS client certificate and key: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
+	}
+
+	if o.caCertData != "" {
+		// As with Argo CD, the CA bundle replaces the system CAs
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM([]byte(o.caCertData)) {
+			return nil, fmt.Errorf("TLS CA bundle does not contain a valid PEM encoded certificate")
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	return tlsConfig, nil
+}
+
+// httpClient returns an HTTP client that connects using the TLS options (or the default transport, if there are none),
+// and that gives up on a request after the timeout.
+func (o repositoryTLSOptions) httpClient(timeout time.Duration) (*http.Client, error) {
+
+	if o.isEmpty() {
+		return &http.Client{Timeout: timeout}, nil
+	}
+
+	tlsConfig, err := o.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+	}, nil
+}
+
+// listRemoteReferencesWithTLS lists the references of the Git repository, as with 'git ls-remote', connecting using
+// the TLS options. Unlike Remote.List, this supports TLS client certificates.
+func listRemoteReferencesWithTLS(ctx context.Context, repoURL string, auth transport.AuthMethod, tlsOptions repositoryTLSOptions) error {
+
+	endpoint, err := transport.NewEndpoint(repoURL)
+	if err != nil {
+		return err
+	}
+
+	httpClient, err := tlsOptions.httpClient(gitRequestTimeout)
+	if err != nil {
+		return err
+	}
+
+	session, err := githttp.NewClient(httpClient).NewUploadPackSession(endpoint, auth)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	_, err = session.AdvertisedReferencesContext(ctx)
+	return err
+}
@@ -0,0 +1,223 @@
+package shared_resource_loop
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	managedgitopsv1alpha1 "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// generateTestClientCertificate returns a self-signed TLS client certificate, and its private key, both PEM encoded.
+func generateTestClientCertificate() (*x509.Certificate, string, string) {
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).ToNot(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	Expect(err).ToNot(HaveOccurred())
+
+	cert, err := x509.ParseCertificate(certDER)
+	Expect(err).ToNot(HaveOccurred())
+
+	return cert,
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}))
+}
+
+// newMutualTLSGitServer returns a Git server, signed by its own CA, that requires clients to present the client certificate.
+func newMutualTLSGitServer(clientCert *x509.Certificate) *httptest.Server {
+
+	mux := http.NewServeMux()
+
+	// The Git smart HTTP protocol: advertise a single branch of the repository
+	mux.HandleFunc("/org/repo/info/refs", func(w http.ResponseWriter, r *http.Request) {
+
+		pktLine := func(s string) string { return fmt.Sprintf("%04x%s", len(s)+4, s) }
+		sha := strings.Repeat("a", 40)
+
+		w.Header().Set("Content-Type", "application/x-git-upload-pack-advertisement")
+		_, _ = w.Write([]byte(pktLine("# service=git-upload-pack\n") + "0000" +
+			pktLine(sha+" HEAD\x00symref=HEAD:refs/heads/main\n") + pktLine(sha+" refs/heads/main\n") + "0000"))
+	})
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	server := httptest.NewUnstartedServer(mux)
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	server.StartTLS()
+
+	return server
+}
+
+var _ = Describe("SharedResourceEventLoop repository TLS options Tests", func() {
+
+	Context("Test validateRepositoryCredentials with TLS options", func() {
+
+		var (
+			ctx           context.Context
+			server        *httptest.Server
+			serverCAData  string
+			clientCertPEM string
+			clientKeyPEM  string
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+
+			var clientCert *x509.Certificate
+			clientCert, clientCertPEM, clientKeyPEM = generateTestClientCertificate()
+
+			server = newMutualTLSGitServer(clientCert)
+			serverCAData = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should validate the credentials using the TLS client certificate, trusting the CA bundle", func() {
+
+			secret := &corev1.Secret{
+				Data: map[string][]byte{
+					"tlsClientCertData": []byte(clientCertPEM),
+					"tlsClientCertKey":  []byte(clientKeyPEM),
+					"tlsCACertData":     []byte(serverCAData),
+				},
+			}
+
+			err := validateRepositoryCredentialsOfType(ctx, managedgitopsv1alpha1.RepositoryCredentialTypeGit, server.URL+"/org/repo.git", secret, "")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should only trust the CA bundle, instead of the system CAs, as with Argo CD", func() {
+
+			tlsConfig, err := repositoryTLSOptions{caCertData: serverCAData}.tlsConfig()
+			Expect(err).ToNot(HaveOccurred())
+
+			expectedRootCAs := x509.NewCertPool()
+			Expect(expectedRootCAs.AppendCertsFromPEM([]byte(serverCAData))).To(BeTrue())
+			Expect(tlsConfig.RootCAs.Equal(expectedRootCAs)).To(BeTrue())
+		})
+
+		It("should validate the credentials without verifying the server certificate, if insecure is true", func() {
+
+			secret := &corev1.Secret{
+				Data: map[string][]byte{
+					"tlsClientCertData": []byte(clientCertPEM),
+					"tlsClientCertKey":  []byte(clientKeyPEM),
+					"insecure":          []byte("true"),
+				},
+			}
+
+			err := validateRepositoryCredentials(ctx, server.URL+"/org/repo.git", secret, "")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		DescribeTable("should report the credentials as invalid if the TLS options are not accepted",
+			func(modify func(data map[string][]byte)) {
+
+				secret := &corev1.Secret{
+					Data: map[string][]byte{
+						"tlsClientCertData": []byte(clientCertPEM),
+						"tlsClientCertKey":  []byte(clientKeyPEM),
+						"tlsCACertData":     []byte(serverCAData),
+					},
+				}
+				modify(secret.Data)
+
+				err := validateRepositoryCredentials(ctx, server.URL+"/org/repo.git", secret, "")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).ToNot(ContainSubstring("not found"), "the credentials, not the repository, are invalid")
+			},
+			Entry("no client certificate is presented", func(data map[string][]byte) {
+				delete(data, "tlsClientCertData")
+				delete(data, "tlsClientCertKey")
+			}),
+			Entry("the server certificate is not signed by a trusted CA", func(data map[string][]byte) { delete(data, "tlsCACertData") }),
+			Entry("the client certificate does not match its key", func(data map[string][]byte) { data["tlsClientCertKey"] = []byte("not-a-key") }),
+			Entry("the CA bundle is not PEM encoded", func(data map[string][]byte) { data["tlsCACertData"] = []byte("not-a-ca") }),
+		)
+
+		It("should reject TLS certificates for a repository URL that is not HTTPS", func() {
+
+			secret := &corev1.Secret{
+				Data: map[string][]byte{
+					"sshPrivateKey":     []byte("key"),
+					"tlsClientCertData": []byte(clientCertPEM),
+					"tlsClientCertKey":  []byte(clientKeyPEM),
+				},
+			}
+
+			err := validateRepositoryCredentials(ctx, "git@github.com:org/repo.git", secret, "")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("HTTPS"))
+		})
+	})
+
+	Context("Test repositoryTLSOptionsFromSecret", func() {
+
+		It("should read the TLS options of the Secret", func() {
+
+			Expect(repositoryTLSOptionsFromSecret(&corev1.Secret{Data: map[string][]byte{
+				"username": []byte("username"),
+			}}).isEmpty()).To(BeTrue())
+
+			tlsOptions := repositoryTLSOptionsFromSecret(&corev1.Secret{Data: map[string][]byte{
+				"tlsClientCertData": []byte("cert"),
+				"tlsClientCertKey":  []byte("key"),
+				"tlsCACertData":     []byte("ca"),
+				"insecure":          []byte("true"),
+			}})
+			Expect(tlsOptions).To(Equal(repositoryTLSOptions{clientCertData: "cert", clientCertKey: "key", caCertData: "ca", insecure: true}))
+
+			By("treating an invalid insecure value as false")
+			Expect(repositoryTLSOptionsFromSecret(&corev1.Secret{Data: map[string][]byte{
+				"insecure": []byte("yes please"),
+			}}).insecure).To(BeFalse())
+		})
+	})
+
+	Context("Test repositoryTLSOptions httpClient", func() {
+
+		It("should return a client with a timeout, whether or not there are TLS options", func() {
+
+			httpClient, err := repositoryTLSOptions{}.httpClient(time.Minute)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(httpClient).ToNot(BeIdenticalTo(http.DefaultClient))
+			Expect(httpClient.Timeout).To(Equal(time.Minute))
+
+			httpClient, err = repositoryTLSOptions{insecure: true}.httpClient(time.Minute)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(httpClient.Timeout).To(Equal(time.Minute))
+		})
+	})
+})
@@ -0,0 +1,168 @@
+package shared_resource_loop
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+
+	db "github.com/redhat-appstudio/managed-gitops/backend-shared/db"
+	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
+	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/operations"
+)
+
+const (
+	// ServiceAccountTokenExpiration is the requested lifetime of the tokens that are issued for the ServiceAccounts
+	// installed by the GitOps Service. The API server may choose a different lifetime.
+	ServiceAccountTokenExpiration = 24 * time.Hour
+
+	// ServiceAccountTokenRenewalThreshold is how long before it expires that a ServiceAccount token is renewed.
+	ServiceAccountTokenRenewalThreshold = 8 * time.Hour
+)
+
+// requestServiceAccountToken is a var so that it can be replaced by unit tests, which do not have a K8s API server
+// that implements the TokenRequest API.
+var requestServiceAccountToken = sharedutil.RequestServiceAccountToken
+
+// RenewManagedEnvironmentToken renews the bearer token of the ServiceAccount that the GitOps Service installed into the
+// cluster of the managed environment, if the token is due for renewal. The new token is issued by the TokenRequest API,
+// using the existing token, and is stored in the ClusterCredentials row of the managed environment. An Operation is then
+// created for each Argo CD instance that targets the managed environment, so that the cluster-agent updates the
+// corresponding Argo CD cluster secret.
+//
+// Returns:
+// - true if the token was renewed, false otherwise.
+// - true if the existing credentials are no longer valid, in which case they must be reacquired using the
+// ManagedEnvironment Secret, false otherwise.
+// - error, if any
+func RenewManagedEnvironmentToken(ctx context.Context, managedEnv db.ManagedEnvironment, k8sClientFactory SRLK8sClientFactory,
+	dbQueries db.DatabaseQueries, log logr.Logger) (bool, bool, error) {
+
+	log = log.WithValues("managedEnvID", managedEnv.Managedenvironment_id, "clusterCredentialsId", managedEnv.Clustercredentials_id)
+
+	clusterCreds := db.ClusterCredentials{Clustercredentials_cred_id: managedEnv.Clustercredentials_id}
+	if err := dbQueries.GetClusterCredentialsById(ctx, &clusterCreds); err != nil {
+		return false, false, fmt.Errorf("unable to retrieve cluster credentials '%s': %w", clusterCreds.Clustercredentials_cred_id, err)
+	}
+
+	// We are only able to renew the token of a ServiceAccount that we installed: the credentials of an existing
+	// ServiceAccount (or user) are managed by the user.
+	if clusterCreds.Serviceaccount_installation_uid == "" || clusterCreds.Serviceaccount_bearer_token == "" {
+		return false, false, nil
+	}
+
+	configParam, _, err := sanityTestCredentials(clusterCreds)
+	if err != nil {
+		return false, true, fmt.Errorf("cluster credentials '%s' are not valid: %w", clusterCreds.Clustercredentials_cred_id, err)
+	}
+
+	expiration := clusterCreds.Serviceaccount_token_expiration
+	if !expiration.IsZero() && !expiration.After(time.Now()) {
+		// The token has already expired, so it can't be used to request a new one
+		return false, true, fmt.Errorf("service account token of cluster credentials '%s' expired at %v",
+			clusterCreds.Clustercredentials_cred_id, expiration)
+	}
+
+	// Tokens that were read from a ServiceAccount token Secret do not expire: these are replaced with a token from the
+	// TokenRequest API, so that they may be renewed from then on.
+	if !expiration.IsZero() && time.Until(expiration) > ServiceAccountTokenRenewalThreshold {
+		return false, false, nil
+	}
+
+	serviceAccountName := sharedutil.GenerateServiceAccountName(clusterCreds.Serviceaccount_installation_uid)
+
+	token, newExpiration, err := requestServiceAccountToken(ctx, configParam, serviceAccountName, clusterCreds.Serviceaccount_ns,
+		ServiceAccountTokenExpiration)
+	if err != nil {
+		// If the API server rejected the existing token, then it is no longer valid: for example, the ServiceAccount
+		// or its token Secret was deleted.
+		invalidCreds := apierr.IsUnauthorized(err) || apierr.IsForbidden(err)
+		return false, invalidCreds, fmt.Errorf("unable to renew service account token of cluster credentials '%s': %w",
+			clusterCreds.Clustercredentials_cred_id, err)
+	}
+
+	clusterCreds.Serviceaccount_bearer_token = token
+	clusterCreds.Serviceaccount_token_expiration = newExpiration
+
+	if err := dbQueries.UpdateClusterCredentials(ctx, &clusterCreds); err != nil {
+		log.Error(err, "Unable to update ClusterCredentials with renewed ServiceAccount token")
+		return false, false, fmt.Errorf("unable to update cluster credentials '%s': %w", clusterCreds.Clustercredentials_cred_id, err)
+	}
+	log.Info("Renewed ServiceAccount token of ClusterCredentials", "expiration", newExpiration)
+
+	if err := createManagedEnvironmentOperations(ctx, managedEnv, k8sClientFactory, dbQueries, log); err != nil {
+		return true, false, err
+	}
+
+	// Now that a token from the TokenRequest API is stored, the token Secret that was created when the ServiceAccount was
+	// installed is no longer needed: it is deleted, so that its token (which does not expire) is no longer valid. This is
+	// attempted on every renewal, so that it is retried if it fails.
+	configParam.BearerToken = token
+	k8sClient, err := k8sClientFactory.BuildK8sClient(configParam)
+	if err != nil {
+		return true, false, fmt.Errorf("unable to create k8s client for cluster credentials '%s': %w", clusterCreds.Clustercredentials_cred_id, err)
+	}
+	if err := sharedutil.DeleteServiceAccountTokenSecrets(ctx, k8sClient, clusterCreds.Serviceaccount_installation_uid,
+		clusterCreds.Serviceaccount_ns, log); err != nil {
+		return true, false, err
+	}
+
+	return true, false, nil
+}
+
+// createManagedEnvironmentOperations creates an Operation for each Argo CD instance that has access to the managed
+// environment, to inform the cluster-agent that the Argo CD cluster secret of the managed environment should be updated.
+func createManagedEnvironmentOperations(ctx context.Context, managedEnv db.ManagedEnvironment, k8sClientFactory SRLK8sClientFactory,
+	dbQueries db.DatabaseQueries, log logr.Logger) error {
+
+	clusterAccesses := []db.ClusterAccess{}
+	if err := dbQueries.ListClusterAccessesByManagedEnvironmentID(ctx, managedEnv.Managedenvironment_id, &clusterAccesses); err != nil {
+		return fmt.Errorf("unable to list cluster accesses by managed id '%s': %w", managedEnv.Managedenvironment_id, err)
+	}
+
+	// Only one Operation is needed per Argo CD instance, even if multiple users have access to the managed environment.
+	processedGitopsEngineInstances := map[string]bool{}
+
+	for idx := range clusterAccesses {
+		clusterAccess := clusterAccesses[idx]
+
+		if processedGitopsEngineInstances[clusterAccess.Clusteraccess_gitops_engine_instance_id] {
+			continue
+		}
+		processedGitopsEngineInstances[clusterAccess.Clusteraccess_gitops_engine_instance_id] = true
+
+		gitopsEngineInstance := &db.GitopsEngineInstance{
+			Gitopsengineinstance_id: clusterAccess.Clusteraccess_gitops_engine_instance_id,
+		}
+		if err := dbQueries.GetGitopsEngineInstanceById(ctx, gitopsEngineInstance); err != nil {
+			return fmt.Errorf("unable to retrieve gitopsengineinstance '%s' for managed environment '%s': %w",
+				gitopsEngineInstance.Gitopsengineinstance_id, managedEnv.Managedenvironment_id, err)
+		}
+
+		client, err := k8sClientFactory.GetK8sClientForGitOpsEngineInstance(ctx, gitopsEngineInstance)
+		if err != nil {
+			return fmt.Errorf("unable to retrieve k8s client for engine instance '%s': %w", gitopsEngineInstance.Gitopsengineinstance_id, err)
+		}
+
+		operation := db.Operation{
+			Instance_id:             gitopsEngineInstance.Gitopsengineinstance_id,
+			Operation_owner_user_id: clusterAccess.Clusteraccess_user_id,
+			Resource_type:           db.OperationResourceType_ManagedEnvironment,
+			Resource_id:             managedEnv.Managedenvironment_id,
+		}
+
+		log.Info("Creating Operation to update Argo CD cluster secret, referencing managed environment",
+			"gitopsEngineInstanceID", gitopsEngineInstance.Gitopsengineinstance_id)
+
+		// Don't wait for the Operation to complete, just create it and continue with the next.
+		_, _, err = operations.CreateOperation(ctx, false, operation, clusterAccess.Clusteraccess_user_id,
+			gitopsEngineInstance.Namespace_name, dbQueries, client, log)
+		if err != nil {
+			return fmt.Errorf("unable to create operation for managed environment '%s': %w", managedEnv.Managedenvironment_id, err)
+		}
+	}
+
+	return nil
+}
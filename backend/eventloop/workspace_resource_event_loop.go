@@ -144,6 +144,12 @@ func internalWorkspaceResourceEventLoop(inputChan chan workspaceResourceLoopMess
 
 			mapKey = "managed-env-" + evlMsg.Event.Request.Namespace + "-" + evlMsg.Event.Request.Name
 
+			// Periodic events are keyed separately, so that a waiting periodic event never causes a subsequent
+			// ManagedEnvironment change to be ignored
+			if evlMsg.Event.EventType == eventlooptypes.ManagedEnvironmentPeriodicTick {
+				mapKey = "managed-env-periodic-" + evlMsg.Event.Request.Namespace + "-" + evlMsg.Event.Request.Name
+			}
+
 		} else {
 			l.Error(nil, "SEVERE: Unexpected message type: "+string(msg.messageType))
 			continue
@@ -287,6 +293,27 @@ func handleResourceLoopManagedEnvironment(ctx context.Context, msg workspaceReso
 		return noRetry, nil
 	}
 
+	if evlMessage.Event.EventType == eventlooptypes.ManagedEnvironmentPeriodicTick {
+
+		// Ask the shared resource loop to perform the periodic maintenance of the managed environment
+		reconcileNeeded, err := sharedResourceLoop.ReconcileSharedManagedEnvPeriodically(ctx, msg.apiNamespaceClient, *namespace, req.Name,
+			k8sClientFactory, log)
+		if err != nil {
+			// The managed environment is processed again on the next tick
+			return noRetry, fmt.Errorf("unable to periodically reconcile shared managed env: %v", err)
+		}
+
+		if !reconcileNeeded {
+			return noRetry, nil
+		}
+
+		// The managed environment must be reconciled, and the GitOpsDeployments that reference it informed, as for any
+		// other change to the ManagedEnvironment
+		event := *evlMessage.Event
+		event.EventType = eventlooptypes.ManagedEnvironmentModified
+		evlMessage.Event = &event
+	}
+
 	// Ask the shared resource loop to ensure the managed environment is reconciled
 	_, err := sharedResourceLoop.ReconcileSharedManagedEnv(ctx, msg.apiNamespaceClient, *namespace, req.Name, req.Namespace,
 		false, k8sClientFactory, log)
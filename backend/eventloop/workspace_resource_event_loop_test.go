@@ -323,6 +323,18 @@ var _ = Describe("Test Workspace Resource Loop", func() {
 					ContainSubstring("error on retrieving GetClusterUserByUsername: context deadline exceeded")))
 
 			})
+
+			It("should not reconcile the ManagedEnvironment, or inform the workspace event loop, if a periodic event does not require it", func() {
+				evlMsg := msg.payload.(eventlooptypes.EventLoopMessage)
+				evlMsg.Event.EventType = eventlooptypes.ManagedEnvironmentPeriodicTick
+
+				// The ManagedEnvironment CR doesn't exist, so there is nothing to do
+				shouldRetry, err := internalProcessWorkspaceResourceMessage(ctx, msg, sharedResourceLoop, workspaceChan, dbQueries, mockClientFactory, testLog)
+
+				Expect(shouldRetry).To(BeFalse())
+				Expect(err).ToNot(HaveOccurred())
+				Consistently(workspaceChan, "1s").ShouldNot(Receive())
+			})
 		})
 
 		Context("Test RepositoryCredential messages", func() {
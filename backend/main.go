@@ -88,6 +88,11 @@ func main() {
 		go sharedutil.StartProfilers(profilerAddr)
 	}
 
+	if err := db.ValidateColumnEncryptionKey(); err != nil {
+		setupLog.Error(err, "invalid database column encryption key")
+		os.Exit(1)
+	}
+
 	ctx := ctrl.SetupSignalHandler()
 
 	// Default to the backend running from backend folder
@@ -188,6 +193,7 @@ func main() {
 	startDBReconciler(mgr)
 	startRepoCredReconciler(mgr)
 	startDBMetricsReconciler(mgr)
+	startManagedEnvReconciler(mgr, preprocessEventLoop)
 
 	startClusterReconciler(mgr)
 
@@ -248,6 +254,24 @@ func startRepoCredReconciler(mgr ctrl.Manager) {
 	repoCredReconciler.StartRepoCredReconciler()
 }
 
+func startManagedEnvReconciler(mgr ctrl.Manager, preprocessEventLoop *preprocess_event_loop.PreprocessEventLoop) {
+
+	dbQueries, err := db.NewSharedProductionPostgresDBQueries(false)
+	if err != nil {
+		setupLog.Error(err, "never able to connect to database")
+		os.Exit(1)
+	}
+
+	managedEnvReconciler := eventloop.ManagedEnvReconciler{
+		DB:            dbQueries,
+		Client:        mgr.GetClient(),
+		EventReceiver: preprocessEventLoop,
+	}
+
+	// Start goroutine for Managed Environment reconciler
+	managedEnvReconciler.StartManagedEnvReconciler()
+}
+
 func startDBMetricsReconciler(mgr ctrl.Manager) {
 
 	dbQueries, err := db.NewSharedProductionPostgresDBQueries(false)
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	ManagedEnvReachable = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "managedEnvironment_reachable",
+			Help: "Whether the API server of the managed environment was reachable the last time it was contacted (1), or not (0)",
+		},
+		[]string{"name", "namespace"},
+	)
+
+	ManagedEnvServerLatency = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "managedEnvironment_server_latency_seconds",
+			Help: "Time taken by the API server of the managed environment to respond, the last time it was successfully contacted",
+		},
+		[]string{"name", "namespace"},
+	)
+
+	ManagedEnvLastSuccessfulContact = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "managedEnvironment_last_successful_contact_timestamp_seconds",
+			Help: "Unix time of the last successful contact with the API server of the managed environment",
+		},
+		[]string{"name", "namespace"},
+	)
+)
+
+// SetManagedEnvironmentReachable records that the API server of the managed environment was successfully contacted
+func SetManagedEnvironmentReachable(name string, namespace string, latency time.Duration, contactTime time.Time) {
+	ManagedEnvReachable.WithLabelValues(name, namespace).Set(1)
+	ManagedEnvServerLatency.WithLabelValues(name, namespace).Set(latency.Seconds())
+	ManagedEnvLastSuccessfulContact.WithLabelValues(name, namespace).Set(float64(contactTime.Unix()))
+}
+
+// SetManagedEnvironmentUnreachable records that the API server of the managed environment could not be contacted. The
+// latency and last successful contact time of the previous successful contact are retained.
+func SetManagedEnvironmentUnreachable(name string, namespace string) {
+	ManagedEnvReachable.WithLabelValues(name, namespace).Set(0)
+}
+
+// DeleteManagedEnvironmentConnectivity removes the connectivity metrics of a managed environment that no longer exists
+func DeleteManagedEnvironmentConnectivity(name string, namespace string) {
+	ManagedEnvReachable.DeleteLabelValues(name, namespace)
+	ManagedEnvServerLatency.DeleteLabelValues(name, namespace)
+	ManagedEnvLastSuccessfulContact.DeleteLabelValues(name, namespace)
+}
+
+func ClearManagedEnvironmentConnectivityMetrics() {
+	ManagedEnvReachable.Reset()
+	ManagedEnvServerLatency.Reset()
+	ManagedEnvLastSuccessfulContact.Reset()
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("Test for managed environment connectivity metrics", func() {
+	Context("Prometheus metrics responds to the connectivity of managed environments", func() {
+
+		BeforeEach(func() {
+			ClearManagedEnvironmentConnectivityMetrics()
+		})
+
+		It("should report the reachability, latency and last contact time of each managed environment", func() {
+
+			contactTime := time.Now()
+
+			By("recording a successful contact with one managed environment, and a failed contact with another")
+			SetManagedEnvironmentReachable("env-a", "ns", 250*time.Millisecond, contactTime)
+			SetManagedEnvironmentUnreachable("env-b", "ns")
+
+			Expect(testutil.ToFloat64(ManagedEnvReachable.WithLabelValues("env-a", "ns"))).To(Equal(float64(1)))
+			Expect(testutil.ToFloat64(ManagedEnvServerLatency.WithLabelValues("env-a", "ns"))).To(Equal(0.25))
+			Expect(testutil.ToFloat64(ManagedEnvLastSuccessfulContact.WithLabelValues("env-a", "ns"))).To(Equal(float64(contactTime.Unix())))
+			Expect(testutil.ToFloat64(ManagedEnvReachable.WithLabelValues("env-b", "ns"))).To(Equal(float64(0)))
+
+			By("recording that the first managed environment is no longer reachable, the last contact time should be retained")
+			SetManagedEnvironmentUnreachable("env-a", "ns")
+			Expect(testutil.ToFloat64(ManagedEnvReachable.WithLabelValues("env-a", "ns"))).To(Equal(float64(0)))
+			Expect(testutil.ToFloat64(ManagedEnvLastSuccessfulContact.WithLabelValues("env-a", "ns"))).To(Equal(float64(contactTime.Unix())))
+
+			By("deleting the metrics of a managed environment")
+			DeleteManagedEnvironmentConnectivity("env-a", "ns")
+			Expect(testutil.CollectAndCount(ManagedEnvReachable)).To(Equal(1))
+			Expect(testutil.CollectAndCount(ManagedEnvLastSuccessfulContact)).To(Equal(0))
+		})
+	})
+})
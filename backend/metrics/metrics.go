@@ -130,5 +130,6 @@ func ClearMetrics() {
 
 func init() {
 	metric.Registry.MustRegister(Gitopsdepl, GitopsdeplFailures, OperationDBRows, OperationDBRowsInWaitingState, OperationDBRowsIn_InProgressState,
-		OperationDBRowsInCompletedState, OperationDBRowsInErrorState, TotalOperationDBRowsInCompletedState, TotalOperationDBRowsInNonCompleteState)
+		OperationDBRowsInCompletedState, OperationDBRowsInErrorState, TotalOperationDBRowsInCompletedState, TotalOperationDBRowsInNonCompleteState,
+		ManagedEnvServiceAccountLeftovers, ManagedEnvReachable, ManagedEnvServerLatency, ManagedEnvLastSuccessfulContact)
 }
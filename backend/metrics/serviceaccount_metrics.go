@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	ManagedEnvServiceAccountLeftovers = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name:        "managedEnvironment_serviceAccount_leftovers",
+			Help:        "Number of ServiceAccounts (and their RBAC resources) of deleted managed environments, that could not be removed from the managed cluster",
+			ConstLabels: map[string]string{"serviceAccountCleanup": "fail"},
+		},
+	)
+
+	leftoverServiceAccounts = leftoverServiceAccountSet{
+		mutex:              sync.Mutex{},
+		clusterCredentials: map[string]bool{},
+	}
+)
+
+type leftoverServiceAccountSet struct {
+	mutex sync.Mutex
+
+	// clusterCredentials contains the IDs of the ClusterCredentials rows, whose ServiceAccount could not be removed.
+	// NOTE: Before reading/writing from this list, acquire the mutex.
+	clusterCredentials map[string]bool
+}
+
+// AddLeftoverServiceAccount records that the ServiceAccount of the given ClusterCredentials could not be removed
+func AddLeftoverServiceAccount(clusterCredentialsID string) {
+	leftoverServiceAccounts.mutex.Lock()
+	defer leftoverServiceAccounts.mutex.Unlock()
+
+	// Use the same upper bound as GitOpsDeployments, to bound memory usage
+	if len(leftoverServiceAccounts.clusterCredentials) <= maxTrackedDeployments {
+		leftoverServiceAccounts.clusterCredentials[clusterCredentialsID] = true
+	}
+
+	ManagedEnvServiceAccountLeftovers.Set((float64)(len(leftoverServiceAccounts.clusterCredentials)))
+}
+
+// RemoveLeftoverServiceAccount records that the ServiceAccount of the given ClusterCredentials has been removed
+func RemoveLeftoverServiceAccount(clusterCredentialsID string) {
+	leftoverServiceAccounts.mutex.Lock()
+	defer leftoverServiceAccounts.mutex.Unlock()
+
+	delete(leftoverServiceAccounts.clusterCredentials, clusterCredentialsID)
+
+	ManagedEnvServiceAccountLeftovers.Set((float64)(len(leftoverServiceAccounts.clusterCredentials)))
+}
+
+func ClearServiceAccountMetrics() {
+	ManagedEnvServiceAccountLeftovers.Set(0)
+	leftoverServiceAccounts.mutex.Lock()
+	defer leftoverServiceAccounts.mutex.Unlock()
+
+	leftoverServiceAccounts.clusterCredentials = map[string]bool{}
+}
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("Test for managed environment ServiceAccount metrics", func() {
+	Context("Prometheus metrics responds to ServiceAccounts that could not be removed", func() {
+
+		BeforeEach(func() {
+			ClearServiceAccountMetrics()
+		})
+
+		It("should count each leftover ServiceAccount once, until it is removed", func() {
+
+			By("adding the same cluster credentials twice, and another once")
+			AddLeftoverServiceAccount("cred-a")
+			AddLeftoverServiceAccount("cred-a")
+			AddLeftoverServiceAccount("cred-b")
+			Expect(testutil.ToFloat64(ManagedEnvServiceAccountLeftovers)).To(Equal(float64(2)))
+
+			By("removing a leftover, and one that was never added")
+			RemoveLeftoverServiceAccount("cred-a")
+			RemoveLeftoverServiceAccount("cred-c")
+			Expect(testutil.ToFloat64(ManagedEnvServiceAccountLeftovers)).To(Equal(float64(1)))
+
+			RemoveLeftoverServiceAccount("cred-b")
+			Expect(testutil.ToFloat64(ManagedEnvServiceAccountLeftovers)).To(Equal(float64(0)))
+		})
+	})
+})
@@ -540,7 +540,8 @@ func recreateClusterSecrets(ctx context.Context, dbQueries db.DatabaseQueries, k
 	}
 }
 
-// recreateClusterSecrets_ManagedEnvironments goes through list of ManagedEnvironments created in cluster and recreates Secrets that are missing from cluster.
+// recreateClusterSecrets_ManagedEnvironments goes through list of ManagedEnvironments created in cluster and recreates Secrets that are missing from cluster,
+// or whose labels and annotations no longer match the cluster labels/annotations of the ManagedEnvironment.
 func recreateClusterSecrets_ManagedEnvironments(ctx context.Context, dbQueries db.DatabaseQueries, k8sClient client.Client, listOfClusterAccessFromDB []db.ClusterAccess, listOfApplicationFromDB []db.Application, instance db.GitopsEngineInstance, logger logr.Logger) {
 
 	log := logger.WithValues(sharedutil.Log_JobKey, sharedutil.Log_JobKeyValue).
@@ -589,40 +590,62 @@ func recreateClusterSecrets_ManagedEnvironments(ctx context.Context, dbQueries d
 			argoSecret := corev1.Secret{}
 			if err := k8sClient.Get(ctx, types.NamespacedName{Name: secretName, Namespace: instance.Namespace_name}, &argoSecret); err != nil {
 
+				if !apierr.IsNotFound(err) {
+					log.Error(err, "Error occurred in recreateClusterSecrets_ManagedEnvironments while fetching Secret:"+secretName+" from Namespace: "+instance.Namespace_name)
+					continue
+				}
+
 				// If Secret is not present, then create Operation to recreate the Secret.
-				if apierr.IsNotFound(err) {
+				log.Info("Secret: " + secretName + " not found in Namespace:" + instance.Namespace_name + ", recreating it.")
 
-					log.Info("Secret: " + secretName + " not found in Namespace:" + instance.Namespace_name + ", recreating it.")
+			} else {
 
-					// Get Special user from DB because we need ClusterUser for creating Operation and we don't have one.
-					// Hence created a dummy Cluster User for internal purpose.
-					var specialClusterUser db.ClusterUser
-					if err := dbQueries.GetOrCreateSpecialClusterUser(ctx, &specialClusterUser); err != nil {
-						log.Error(err, "Error occurred in recreateClusterSecrets_ManagedEnvironments while fetching clusterUser.")
-						return
-					}
+				// If Secret is present, then verify that its labels and annotations match the cluster labels/annotations of the ManagedEnvironment.
+				labels, annotations, err := controllers.GenerateArgoCDClusterSecretMetadata(managedEnvironment.Managedenvironment_id, clusterCreds)
+				if err != nil {
+					log.Error(err, "Error occurred in recreateClusterSecrets_ManagedEnvironments while generating labels and annotations of Secret:"+secretName)
+					continue
+				}
+
+				if controllers.IsArgoCDClusterSecretMetadataEqual(argoSecret, labels, annotations) {
+					continue
+				}
 
-					// We need to create an Operation to recreate the Secret, which requires Application details running in current ManagedEnvironment
-					// hence we iterate through list of Application entries from DB to find that Application.
-					if ok, application := getApplicationRunningInManagedEnvironment(listOfApplicationFromDB, managedEnvironment.Managedenvironment_id); ok {
+				// The labels and annotations do not depend on the Application, so update them directly, rather than via an Operation
+				log.Info("Secret: " + secretName + " in Namespace:" + instance.Namespace_name + " has out of date labels or annotations, updating it.")
 
-						// We need to recreate Secret, to do that create Operation to inform Argo CD about it.
-						dbOperationInput := db.Operation{
-							Instance_id:   application.Engine_instance_inst_id,
-							Resource_id:   application.Application_id,
-							Resource_type: db.OperationResourceType_Application,
-						}
+				controllers.ApplyArgoCDClusterSecretMetadata(&argoSecret, labels, annotations)
+				if err := k8sClient.Update(ctx, &argoSecret); err != nil {
+					log.Error(err, "Error occurred in recreateClusterSecrets_ManagedEnvironments while updating labels and annotations of Secret:"+secretName)
+				}
+				continue
+			}
 
-						if _, _, err := operations.CreateOperation(ctx, false, dbOperationInput, specialClusterUser.Clusteruser_id, instance.Namespace_name, dbQueries, k8sClient, log); err != nil {
-							log.Error(err, "Error occurred in recreateClusterSecrets_ManagedEnvironments while creating Operation.")
-							continue
-						}
+			// Get Special user from DB because we need ClusterUser for creating Operation and we don't have one.
+			// Hence created a dummy Cluster User for internal purpose.
+			var specialClusterUser db.ClusterUser
+			if err := dbQueries.GetOrCreateSpecialClusterUser(ctx, &specialClusterUser); err != nil {
+				log.Error(err, "Error occurred in recreateClusterSecrets_ManagedEnvironments while fetching clusterUser.")
+				return
+			}
 
-						log.Info("Operation " + dbOperationInput.Operation_id + " is created to create Secret: managed-env-" + managedEnvironment.Managedenvironment_id)
-					}
-				} else {
-					log.Error(err, "Error occurred in recreateClusterSecrets_ManagedEnvironments while fetching Secret:"+secretName+" from Namespace: "+instance.Namespace_name)
+			// We need to create an Operation to recreate the Secret, which requires Application details running in current ManagedEnvironment
+			// hence we iterate through list of Application entries from DB to find that Application.
+			if ok, application := getApplicationRunningInManagedEnvironment(listOfApplicationFromDB, managedEnvironment.Managedenvironment_id); ok {
+
+				// We need to recreate Secret, to do that create Operation to inform Argo CD about it.
+				dbOperationInput := db.Operation{
+					Instance_id:   application.Engine_instance_inst_id,
+					Resource_id:   application.Application_id,
+					Resource_type: db.OperationResourceType_Application,
 				}
+
+				if _, _, err := operations.CreateOperation(ctx, false, dbOperationInput, specialClusterUser.Clusteruser_id, instance.Namespace_name, dbQueries, k8sClient, log); err != nil {
+					log.Error(err, "Error occurred in recreateClusterSecrets_ManagedEnvironments while creating Operation.")
+					continue
+				}
+
+				log.Info("Operation " + dbOperationInput.Operation_id + " is created to create Secret: managed-env-" + managedEnvironment.Managedenvironment_id)
 			}
 		}
 	}
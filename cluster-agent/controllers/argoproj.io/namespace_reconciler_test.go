@@ -15,6 +15,7 @@ import (
 	argosharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/argocd"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/operations"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	"github.com/redhat-appstudio/managed-gitops/cluster-agent/controllers"
 	"github.com/redhat-appstudio/managed-gitops/cluster-agent/controllers/argoproj.io/application_info_cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -856,6 +857,10 @@ var _ = Describe("Namespace Reconciler Tests.", func() {
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      argosharedutil.GenerateArgoCDClusterSecretName(managedEnvironment),
 					Namespace: gitopsEngineInstance.Namespace_name,
+					Labels: map[string]string{
+						sharedutil.ArgoCDSecretTypeIdentifierKey: sharedutil.ArgoCDSecretClusterTypeValue,
+						SecretDbIdentifierKey:                    managedEnvironment.Managedenvironment_id,
+					},
 				},
 			}
 
@@ -902,6 +907,46 @@ var _ = Describe("Namespace Reconciler Tests.", func() {
 			Expect(operation).To(BeEmpty())
 		})
 
+		It("Should update the labels of the Secret without creating an Operation, since its labels don't match the cluster labels of the ManagedEnvironment.", func() {
+
+			By("Set cluster labels of the ClusterCredentials, and 'Created_on' field more than 30 Minutes.")
+
+			clusterCredentials := db.ClusterCredentials{Clustercredentials_cred_id: managedEnvironment.Clustercredentials_id}
+			Expect(dbq.GetClusterCredentialsById(ctx, &clusterCredentials)).To(Succeed())
+			clusterCredentials.Cluster_labels = `{"region":"eu"}`
+			Expect(dbq.UpdateClusterCredentials(ctx, &clusterCredentials)).To(Succeed())
+
+			managedEnvironment.Created_on = time.Now().Add(time.Duration(-(31 * time.Minute)))
+			Expect(dbq.UpdateManagedEnvironment(ctx, &managedEnvironment)).To(Succeed())
+
+			By("Create Secret CR in cluster, without the cluster labels.")
+
+			Expect(k8sClient.Create(ctx, &secret)).To(Succeed())
+
+			By("Call function to recreate Secret if missing from cluster.")
+
+			recreateClusterSecrets(ctx, dbq, k8sClient, log)
+
+			By("Get list of Operations after calling function.")
+
+			Expect(dbq.ListOperationsByResourceIdAndTypeAndOwnerId(ctx, application.Application_id, db.OperationResourceType_Application, &operation, db.SpecialClusterUserName)).To(Succeed())
+			Expect(operation).To(BeEmpty())
+
+			By("Verify that the cluster labels were added to the Secret.")
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&secret), &secret)).To(Succeed())
+			Expect(secret.Labels).To(HaveKeyWithValue("region", "eu"))
+			Expect(secret.Annotations).To(HaveKeyWithValue(controllers.ArgoCDClusterSecretManagedLabelsAnnotation, "region"))
+
+			By("Call function again, to verify that nothing else is changed.")
+
+			resourceVersion := secret.ResourceVersion
+			recreateClusterSecrets(ctx, dbq, k8sClient, log)
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&secret), &secret)).To(Succeed())
+			Expect(secret.ResourceVersion).To(Equal(resourceVersion))
+		})
+
 		It("Should create Operation to recreate Secret, since it is not present in Cluster.", func() {
 
 			By("Set 'Created_on' field more than 30 Minutes.")
@@ -682,7 +682,8 @@ func isOperationRunning(ctx context.Context, k8sClient client.Client, appName, a
 
 // syncFuncs is a wrapper over sync and terminate functions and is used in unit testing different sync scenarios
 type syncFuncs struct {
-	appSync            func(context.Context, string, string, string, client.Client, *utils.CredentialService, bool) error
+	appSync            func(context.Context, string, utils.AppSyncParameters, string, client.Client, *utils.CredentialService, bool) error
+	appRollback        func(context.Context, string, int64, utils.AppSyncParameters, string, client.Client, *utils.CredentialService, bool) error
 	terminateOperation func(context.Context, string, corev1.Namespace, *utils.CredentialService, client.Client, time.Duration, logr.Logger) error
 
 	refreshApp func(context.Context, client.Client, string, string) error
@@ -691,6 +692,7 @@ type syncFuncs struct {
 func defaultSyncFuncs() *syncFuncs {
 	return &syncFuncs{
 		appSync:            utils.AppSync,
+		appRollback:        utils.AppRollback,
 		terminateOperation: utils.TerminateOperation,
 		refreshApp:         refreshApplication,
 	}
@@ -702,9 +704,13 @@ func runAppSync(ctx context.Context, dbOperation db.Operation, dbSyncOperation d
 
 	log := opConfig.log
 
-	completeChan := make(chan bool)
+	syncParams, err := convertSyncOperationToAppSyncParameters(dbSyncOperation)
+	if err != nil {
+		log.Error(err, "unable to convert the SyncOperation into sync parameters")
+		return shouldRetryFalse, err
+	}
 
-	var err error
+	completeChan := make(chan bool)
 
 	cancellableCtx, cancelFunc := context.WithCancel(ctx)
 
@@ -712,8 +718,14 @@ func runAppSync(ctx context.Context, dbOperation db.Operation, dbSyncOperation d
 
 	// Start the AppSync operation in a separate thread.
 	go func() {
-		err = opConfig.syncFuncs.appSync(cancellableCtx, dbApplication.Name, dbSyncOperation.Revision, opConfig.argoCDNamespace.Name, opConfig.eventClient,
-			opConfig.credentialService, false)
+		if dbSyncOperation.Rollback_history_id != nil {
+			// The SyncOperation rolls back the Application to a previous deployment, from the history of the Application
+			err = opConfig.syncFuncs.appRollback(cancellableCtx, dbApplication.Name, *dbSyncOperation.Rollback_history_id, syncParams,
+				opConfig.argoCDNamespace.Name, opConfig.eventClient, opConfig.credentialService, false)
+		} else {
+			err = opConfig.syncFuncs.appSync(cancellableCtx, dbApplication.Name, syncParams, opConfig.argoCDNamespace.Name, opConfig.eventClient,
+				opConfig.credentialService, false)
+		}
 
 		var failed bool
 		if err != nil {
@@ -773,15 +785,44 @@ outer:
 	return shouldRetry, err
 }
 
+// convertSyncOperationToAppSyncParameters converts the fields of a SyncOperation row into the parameters of an Argo CD sync operation
+func convertSyncOperationToAppSyncParameters(dbSyncOperation db.SyncOperation) (utils.AppSyncParameters, error) {
+
+	syncParams := utils.AppSyncParameters{
+		Revision: dbSyncOperation.Revision,
+		Prune:    dbSyncOperation.Prune,
+		DryRun:   dbSyncOperation.Dry_run,
+		Force:    dbSyncOperation.Force,
+		Strategy: dbSyncOperation.Sync_strategy,
+	}
+
+	resources, err := db.DecodeSyncOperationResources(dbSyncOperation.Resources)
+	if err != nil {
+		return utils.AppSyncParameters{}, err
+	}
+
+	for _, resource := range resources {
+		syncParams.Resources = append(syncParams.Resources, appv1.SyncOperationResource{
+			Group:     resource.Group,
+			Kind:      resource.Kind,
+			Namespace: resource.Namespace,
+			Name:      resource.Name,
+		})
+	}
+
+	return syncParams, nil
+}
+
 // processOperation_ManagedEnvironment handles an Operation that targets an Application.
 // Returns true if the task should be retried (eg due to failure), false otherwise.
 func processOperation_ManagedEnvironment(ctx context.Context, dbOperation db.Operation, crOperation operation.Operation,
 	opConfig operationConfig) (bool, error) {
 
-	// The only operation we currently support for managed environment is deletion (creation is handled by Application operations).
-	// Thus, we expect the ManagedEnvironment database entry here to not be found.
+	// Creation of the Argo CD cluster secret is handled by Application operations. Operations on the managed environment
+	// itself either delete the cluster secret (if the ManagedEnvironment database entry no longer exists), or update it
+	// (for example, when the ServiceAccount token of the managed environment has been renewed).
 
-	// 1) Make sure the managed environment db entry DOESN'T exist (see above)
+	// 1) If the managed environment db entry still exists, ensure the cluster secret is consistent with it (see above)
 	{
 		managedEnv := &db.ManagedEnvironment{
 			Managedenvironment_id: dbOperation.Resource_id, // managed env id referencing managed env row
@@ -791,8 +832,12 @@ func processOperation_ManagedEnvironment(ctx context.Context, dbOperation db.Ope
 				return shouldRetryTrue, fmt.Errorf("an unexpected error occcurred on retrieving managed env: %v", err)
 			}
 		} else {
-			// The database entry still exists, so return an error
-			return shouldRetryFalse, fmt.Errorf("managed environment still exists in the database")
+			// The database entry still exists, so update the cluster secret
+			if err := ensureManagedEnvironmentExists(ctx, db.Application{Managed_environment_id: managedEnv.Managedenvironment_id},
+				opConfig, opConfig.log); err != nil {
+				return shouldRetryTrue, fmt.Errorf("unable to update Argo CD cluster secret of managed environment: %v", err)
+			}
+			return shouldRetryFalse, nil
 		}
 	}
 
@@ -942,8 +987,10 @@ func processOperation_Application(ctx context.Context, dbOperation db.Operation,
 
 		app.Spec.Destination = specFieldApp.Spec.Destination
 		app.Spec.Source = specFieldApp.Spec.Source
+		app.Spec.Sources = specFieldApp.Spec.Sources
 		app.Spec.Project = specFieldApp.Spec.Project
 		app.Spec.SyncPolicy = specFieldApp.Spec.SyncPolicy
+		app.Spec.IgnoreDifferences = specFieldApp.Spec.IgnoreDifferences
 
 		if err := opConfig.eventClient.Update(ctx, app); err != nil {
 			log.Error(err, "unable to update application after difference detected.")
@@ -1227,11 +1274,14 @@ func ensureManagedEnvironmentExists(ctx context.Context, application db.Applicat
 	}
 
 	// B) Secret already exists, so compare
-	if reflect.DeepEqual(existingSecret.Data, expectedSecret.Data) {
+	if reflect.DeepEqual(existingSecret.Data, expectedSecret.Data) &&
+		controllers.IsArgoCDClusterSecretMetadataEqual(*existingSecret, expectedSecret.Labels, expectedSecret.Annotations) {
 		// No work required, so exit.
 		return nil
 	}
 	existingSecret.Data = expectedSecret.Data
+	// Only the labels and annotations that are managed by GitOps Service are updated
+	controllers.ApplyArgoCDClusterSecretMetadata(existingSecret, expectedSecret.Labels, expectedSecret.Annotations)
 
 	// C) Secret exists, but is different from what is expected, so update it.
 	if err := opConfig.eventClient.Update(ctx, existingSecret); err != nil {
@@ -1303,6 +1353,32 @@ func generateExpectedClusterSecret(ctx context.Context, application db.Applicati
 		},
 	}
 
+	// Argo CD should verify the TLS certificate of the cluster using the CA bundle, if one was provided
+	if !insecureVerifyTLS && clusterCredentials.Ca_data != "" {
+		clusterSecretConfigJSON.TLSClientConfig.CAData = []byte(clusterCredentials.Ca_data)
+	}
+
+	// Argo CD should authenticate using the client certificate, if one was provided
+	if clusterCredentials.Cert_data != "" && clusterCredentials.Key_data != "" {
+		clusterSecretConfigJSON.TLSClientConfig.CertData = []byte(clusterCredentials.Cert_data)
+		clusterSecretConfigJSON.TLSClientConfig.KeyData = []byte(clusterCredentials.Key_data)
+	}
+
+	// Argo CD should authenticate using the exec plugin, if one was provided
+	if clusterCredentials.Exec_provider_config != "" {
+		execProviderConfig := argosharedutil.ClusterSecretExecProviderConfigJSON{}
+		if err := json.Unmarshal([]byte(clusterCredentials.Exec_provider_config), &execProviderConfig); err != nil {
+			return corev1.Secret{}, deleteSecret_false,
+				fmt.Errorf("unable to unmarshal exec provider config of cluster credentials '%s': %v", clusterCredentials.Clustercredentials_cred_id, err)
+		}
+		clusterSecretConfigJSON.ExecProviderConfig = &execProviderConfig
+	}
+
+	// Argo CD should contact the cluster via the proxy, if one was provided
+	if clusterCredentials.Proxy_url != "" {
+		clusterSecretConfigJSON.ProxyURL = clusterCredentials.Proxy_url
+	}
+
 	jsonString, err := json.Marshal(clusterSecretConfigJSON)
 	if err != nil {
 		return corev1.Secret{}, deleteSecret_false, fmt.Errorf("SEVERE: unable to marshal JSON")
@@ -1317,14 +1393,18 @@ func generateExpectedClusterSecret(ctx context.Context, application db.Applicati
 	// to allow Argo CD to manage the cluster via multiple ServiceAccounts on the same cluster.
 	clusterCredentialsHost := clusterCredentials.Host + ManagedEnvironmentQueryParameter + managedEnvID
 
+	// The cluster labels/annotations of the managed environment allow the cluster to be targeted by label (for example, by ApplicationSets)
+	labels, annotations, err := controllers.GenerateArgoCDClusterSecretMetadata(managedEnvID, *clusterCredentials)
+	if err != nil {
+		return corev1.Secret{}, deleteSecret_false, err
+	}
+
 	managedEnvironmentSecret := corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: opConfig.argoCDNamespace.Name,
-			Labels: map[string]string{
-				sharedutil.ArgoCDSecretTypeIdentifierKey:       sharedutil.ArgoCDSecretClusterTypeValue,
-				controllers.ArgoCDClusterSecretDatabaseIDLabel: managedEnvID,
-			},
+			Name:        name,
+			Namespace:   opConfig.argoCDNamespace.Name,
+			Labels:      labels,
+			Annotations: annotations,
 		},
 		Data: map[string][]byte{
 			"name":   ([]byte)(name),
@@ -2,14 +2,17 @@ package eventloop
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/redhat-appstudio/managed-gitops/cluster-agent/controllers"
 	"github.com/redhat-appstudio/managed-gitops/cluster-agent/utils"
 	"gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
@@ -146,6 +149,150 @@ var _ = Describe("Operation Controller", func() {
 
 		})
 
+		DescribeTable("generateExpectedClusterSecret should include the CA bundle of the cluster credentials, unless TLS verification is skipped",
+			func(insecure bool, expectedCAData []byte) {
+
+				clusterCredentials := db.ClusterCredentials{
+					Clustercredentials_cred_id:  "test-cluster-creds-test",
+					Host:                        "https://my-cluster-url.com",
+					Serviceaccount_bearer_token: db.DefaultServiceaccount_bearer_token,
+					Serviceaccount_ns:           "Serviceaccount_ns",
+					AllowInsecureSkipTLSVerify:  insecure,
+					Ca_data:                     fakeCertificateAuthorityData,
+				}
+				err := dbQueries.CreateClusterCredentials(ctx, &clusterCredentials)
+				Expect(err).ToNot(HaveOccurred())
+
+				managedEnvironment := db.ManagedEnvironment{
+					Managedenvironment_id: "test-managed-env",
+					Clustercredentials_id: clusterCredentials.Clustercredentials_cred_id,
+					Name:                  "my env",
+				}
+				err = dbQueries.CreateManagedEnvironment(ctx, &managedEnvironment)
+				Expect(err).ToNot(HaveOccurred())
+
+				applicationDB := &db.Application{
+					Application_id:          "test-my-application",
+					Name:                    name,
+					Spec_field:              "{}",
+					Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+					Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+				}
+				err = dbQueries.CreateApplication(ctx, applicationDB)
+				Expect(err).ToNot(HaveOccurred())
+
+				secret, shouldDelete, err := generateExpectedClusterSecret(ctx, *applicationDB, opConfigVal)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(shouldDelete).To(BeFalse())
+
+				secretJSON := argosharedutil.ClusterSecretConfigJSON{}
+				err = json.Unmarshal(secret.Data["config"], &secretJSON)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(secretJSON.TLSClientConfig.Insecure).To(Equal(insecure))
+				Expect(secretJSON.TLSClientConfig.CAData).To(Equal(expectedCAData))
+
+				if expectedCAData != nil {
+					By("verifying the CA bundle is base64-encoded in the 'caData' field, as expected by Argo CD")
+					Expect(string(secret.Data["config"])).To(ContainSubstring(`"caData":"` + base64.StdEncoding.EncodeToString(expectedCAData) + `"`))
+				}
+			},
+			Entry("CA bundle is included when TLS verification is enabled", false, []byte(fakeCertificateAuthorityData)),
+			Entry("CA bundle is excluded when TLS verification is skipped", true, nil),
+		)
+
+		It("generateExpectedClusterSecret should include the client certificate and exec plugin of the cluster credentials", func() {
+
+			// The client certificate key is encrypted in the database
+			os.Setenv(db.ColumnEncryptionKeyEnvVar, base64.StdEncoding.EncodeToString(make([]byte, 32)))
+			DeferCleanup(os.Unsetenv, db.ColumnEncryptionKeyEnvVar)
+
+			clusterCredentials := db.ClusterCredentials{
+				Clustercredentials_cred_id: "test-cluster-creds-test",
+				Host:                       "https://my-cluster-url.com",
+				Serviceaccount_ns:          "Serviceaccount_ns",
+				Cert_data:                  "cert-data",
+				Key_data:                   "key-data",
+				Exec_provider_config:       `{"command":"my-plugin","args":["a"],"env":{"b":"c"},"apiVersion":"client.authentication.k8s.io/v1beta1"}`,
+			}
+			err := dbQueries.CreateClusterCredentials(ctx, &clusterCredentials)
+			Expect(err).ToNot(HaveOccurred())
+
+			managedEnvironment := db.ManagedEnvironment{
+				Managedenvironment_id: "test-managed-env",
+				Clustercredentials_id: clusterCredentials.Clustercredentials_cred_id,
+				Name:                  "my env",
+			}
+			err = dbQueries.CreateManagedEnvironment(ctx, &managedEnvironment)
+			Expect(err).ToNot(HaveOccurred())
+
+			applicationDB := &db.Application{
+				Application_id:          "test-my-application",
+				Name:                    name,
+				Spec_field:              "{}",
+				Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+				Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+			}
+			err = dbQueries.CreateApplication(ctx, applicationDB)
+			Expect(err).ToNot(HaveOccurred())
+
+			secret, shouldDelete, err := generateExpectedClusterSecret(ctx, *applicationDB, opConfigVal)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shouldDelete).To(BeFalse())
+
+			secretJSON := argosharedutil.ClusterSecretConfigJSON{}
+			err = json.Unmarshal(secret.Data["config"], &secretJSON)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(secretJSON.BearerToken).To(BeEmpty())
+			Expect(secretJSON.TLSClientConfig.CertData).To(Equal([]byte("cert-data")))
+			Expect(secretJSON.TLSClientConfig.KeyData).To(Equal([]byte("key-data")))
+			Expect(secretJSON.ExecProviderConfig).To(Equal(&argosharedutil.ClusterSecretExecProviderConfigJSON{
+				Command:    "my-plugin",
+				Args:       []string{"a"},
+				Env:        map[string]string{"b": "c"},
+				APIVersion: "client.authentication.k8s.io/v1beta1",
+			}))
+		})
+
+		It("generateExpectedClusterSecret should include the proxy URL of the cluster credentials", func() {
+
+			clusterCredentials := db.ClusterCredentials{
+				Clustercredentials_cred_id:  "test-cluster-creds-test",
+				Host:                        "https://my-cluster-url.com",
+				Serviceaccount_bearer_token: db.DefaultServiceaccount_bearer_token,
+				Serviceaccount_ns:           "Serviceaccount_ns",
+				Proxy_url:                   "http://proxy.example.com:3128",
+			}
+			err := dbQueries.CreateClusterCredentials(ctx, &clusterCredentials)
+			Expect(err).ToNot(HaveOccurred())
+
+			managedEnvironment := db.ManagedEnvironment{
+				Managedenvironment_id: "test-managed-env",
+				Clustercredentials_id: clusterCredentials.Clustercredentials_cred_id,
+				Name:                  "my env",
+			}
+			err = dbQueries.CreateManagedEnvironment(ctx, &managedEnvironment)
+			Expect(err).ToNot(HaveOccurred())
+
+			applicationDB := &db.Application{
+				Application_id:          "test-my-application",
+				Name:                    name,
+				Spec_field:              "{}",
+				Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+				Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+			}
+			err = dbQueries.CreateApplication(ctx, applicationDB)
+			Expect(err).ToNot(HaveOccurred())
+
+			secret, shouldDelete, err := generateExpectedClusterSecret(ctx, *applicationDB, opConfigVal)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shouldDelete).To(BeFalse())
+
+			By("verifying the proxy URL is in the 'proxyUrl' field, as expected by Argo CD")
+			Expect(string(secret.Data["config"])).To(ContainSubstring(`"proxyUrl":"http://proxy.example.com:3128"`))
+		})
+
 		It("generateExpectedClusterSecret should reject an invalid URL containing query parameters", func() {
 
 			clusterCredentials := db.ClusterCredentials{
@@ -329,6 +476,99 @@ var _ = Describe("Operation Controller", func() {
 
 		})
 
+		It("EnsureManagedEnvironment should reconcile the cluster labels and annotations of the Secret, even if its data is unchanged", func() {
+
+			clusterCredentials := db.ClusterCredentials{
+				Clustercredentials_cred_id:  "test-cluster-creds-test",
+				Host:                        "https://my-cluster-url.com",
+				Serviceaccount_bearer_token: db.DefaultServiceaccount_bearer_token,
+				Serviceaccount_ns:           "Serviceaccount_ns",
+				Cluster_labels:              `{"region":"eu"}`,
+				Cluster_annotations:         `{"example.com/owner":"team-a"}`,
+			}
+			err := dbQueries.CreateClusterCredentials(ctx, &clusterCredentials)
+			Expect(err).ToNot(HaveOccurred())
+
+			managedEnvironment := db.ManagedEnvironment{
+				Managedenvironment_id: "test-managed-env",
+				Clustercredentials_id: clusterCredentials.Clustercredentials_cred_id,
+				Name:                  "my env",
+			}
+			err = dbQueries.CreateManagedEnvironment(ctx, &managedEnvironment)
+			Expect(err).ToNot(HaveOccurred())
+
+			applicationDB := &db.Application{
+				Application_id:          "test-my-application",
+				Name:                    name,
+				Spec_field:              "{}",
+				Engine_instance_inst_id: gitopsEngineInstance.Gitopsengineinstance_id,
+				Managed_environment_id:  managedEnvironment.Managedenvironment_id,
+			}
+			err = dbQueries.CreateApplication(ctx, applicationDB)
+			Expect(err).ToNot(HaveOccurred())
+
+			By("creating the Secret")
+			err = ensureManagedEnvironmentExists(ctx, *applicationDB, opConfigVal, logger)
+			Expect(err).ToNot(HaveOccurred())
+
+			managedEnvironmentSecret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      argosharedutil.GenerateArgoCDClusterSecretName(managedEnvironment),
+					Namespace: argoCDNamespace.Name,
+				},
+			}
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvironmentSecret), &managedEnvironmentSecret)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(managedEnvironmentSecret.Labels).To(Equal(map[string]string{
+				"argocd.argoproj.io/secret-type": "cluster",
+				dbID:                             managedEnvironment.Managedenvironment_id,
+				"region":                         "eu",
+			}))
+			expectedAnnotations := map[string]string{
+				"example.com/owner": "team-a",
+				controllers.ArgoCDClusterSecretManagedLabelsAnnotation:      "region",
+				controllers.ArgoCDClusterSecretManagedAnnotationsAnnotation: "example.com/owner",
+			}
+			Expect(managedEnvironmentSecret.Annotations).To(Equal(expectedAnnotations))
+
+			By("modifying the labels and annotations of the Secret, without modifying its data")
+			managedEnvironmentSecret.Labels["region"] = "us"
+			managedEnvironmentSecret.Labels["other-tool-label"] = "true"
+			managedEnvironmentSecret.Annotations = nil
+			err = k8sClient.Update(ctx, &managedEnvironmentSecret)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = ensureManagedEnvironmentExists(ctx, *applicationDB, opConfigVal, logger)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvironmentSecret), &managedEnvironmentSecret)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(managedEnvironmentSecret.Labels).To(HaveKeyWithValue("region", "eu"))
+			Expect(managedEnvironmentSecret.Labels).To(HaveKeyWithValue("other-tool-label", "true"),
+				"labels that are not managed by GitOps Service should not be removed")
+			Expect(managedEnvironmentSecret.Annotations).To(Equal(expectedAnnotations))
+
+			By("removing the cluster labels from the ClusterCredentials, to ensure they are removed from the Secret")
+			clusterCredentials.Cluster_labels = ""
+			err = dbQueries.UpdateClusterCredentials(ctx, &clusterCredentials)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = ensureManagedEnvironmentExists(ctx, *applicationDB, opConfigVal, logger)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&managedEnvironmentSecret), &managedEnvironmentSecret)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(managedEnvironmentSecret.Labels).ToNot(HaveKey("region"))
+			Expect(managedEnvironmentSecret.Labels).To(HaveKeyWithValue("other-tool-label", "true"))
+			Expect(managedEnvironmentSecret.Annotations).To(Equal(map[string]string{
+				"example.com/owner": "team-a",
+				controllers.ArgoCDClusterSecretManagedAnnotationsAnnotation: "example.com/owner",
+			}))
+		})
+
 		It("EnsureManagedEnvironment should delete Secret if ManagedEnvironment doesn't exist", func() {
 
 			applicationDB := &db.Application{
@@ -2331,7 +2571,7 @@ var _ = Describe("Operation Controller", func() {
 
 				By("verify there is no retry for a successful sync")
 				task.syncFuncs = &syncFuncs{
-					appSync: func(ctx context.Context, s1, s2, s3 string, c client.Client, cs *utils.CredentialService, b bool) error {
+					appSync: func(ctx context.Context, s1 string, p utils.AppSyncParameters, s2 string, c client.Client, cs *utils.CredentialService, b bool) error {
 						return nil
 					},
 					refreshApp: refreshApplication,
@@ -2345,6 +2585,47 @@ var _ = Describe("Operation Controller", func() {
 				Expect(<-refreshAnnotationFound).To(Equal(struct{}{}))
 			})
 
+			It("should roll back the Application if the SyncOperation references a history ID", func() {
+
+				By("create a SyncOperation in the database that rolls back to a previous deployment")
+				historyID := int64(3)
+				syncOperation := db.SyncOperation{
+					SyncOperation_id:    "test-syncoperation",
+					Application_id:      applicationDB.Application_id,
+					DeploymentNameField: "test",
+					Revision:            "abc123",
+					DesiredState:        db.SyncOperation_DesiredState_Running,
+					Rollback_history_id: &historyID,
+				}
+				err = dbQueries.CreateSyncOperation(ctx, &syncOperation)
+				Expect(err).ToNot(HaveOccurred())
+
+				By("create Operation DB row and CR for the SyncOperation")
+				createOperationDBAndCR(syncOperation.SyncOperation_id, gitopsEngineInstanceID)
+
+				By("verify the Application is rolled back to the history ID, rather than synced")
+				var rolledBackTo *int64
+				task.syncFuncs = &syncFuncs{
+					appSync: func(ctx context.Context, s1 string, p utils.AppSyncParameters, s2 string, c client.Client, cs *utils.CredentialService, b bool) error {
+						return fmt.Errorf("unexpected call to appSync")
+					},
+					appRollback: func(ctx context.Context, s1 string, id int64, p utils.AppSyncParameters, s2 string, c client.Client, cs *utils.CredentialService, b bool) error {
+						rolledBackTo = &id
+						return nil
+					},
+					refreshApp: refreshApplication,
+				}
+
+				retry, err := task.PerformTask(ctx)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(retry).To(BeFalse())
+				Expect(rolledBackTo).ToNot(BeNil())
+				Expect(*rolledBackTo).To(Equal(historyID))
+
+				By("verify if the refresh annotation was added")
+				Expect(<-refreshAnnotationFound).To(Equal(struct{}{}))
+			})
+
 			It("should return an error and retry if the sync fails", func() {
 
 				By("create a SyncOperation in the database")
@@ -2364,7 +2645,7 @@ var _ = Describe("Operation Controller", func() {
 				By("check if the sync failed error is returned with retry")
 				expectedErr := "sync failed due to xyz reason"
 				task.syncFuncs = &syncFuncs{
-					appSync: func(ctx context.Context, s1, s2, s3 string, c client.Client, cs *utils.CredentialService, b bool) error {
+					appSync: func(ctx context.Context, s1 string, p utils.AppSyncParameters, s2 string, c client.Client, cs *utils.CredentialService, b bool) error {
 						return fmt.Errorf(expectedErr)
 					},
 					refreshApp: refreshApplication,
@@ -2438,7 +2719,7 @@ var _ = Describe("Operation Controller", func() {
 				Expect(apierr.IsConflict(err)).To(BeTrue())
 
 				task.syncFuncs = &syncFuncs{
-					appSync: func(ctx context.Context, s1, s2, s3 string, c client.Client, cs *utils.CredentialService, b bool) error {
+					appSync: func(ctx context.Context, s1 string, p utils.AppSyncParameters, s2 string, c client.Client, cs *utils.CredentialService, b bool) error {
 						return nil
 					},
 					refreshApp: refreshApplication,
@@ -2460,7 +2741,7 @@ var _ = Describe("Operation Controller", func() {
 
 				By("check if SyncOperation not found error is handled")
 				task.syncFuncs = &syncFuncs{
-					appSync: func(ctx context.Context, s1, s2, s3 string, c client.Client, cs *utils.CredentialService, b bool) error {
+					appSync: func(ctx context.Context, s1 string, p utils.AppSyncParameters, s2 string, c client.Client, cs *utils.CredentialService, b bool) error {
 						return nil
 					},
 				}
@@ -2488,7 +2769,7 @@ var _ = Describe("Operation Controller", func() {
 				createOperationDBAndCR(syncOperation.SyncOperation_id, gitopsEngineInstanceID)
 
 				task.syncFuncs = &syncFuncs{
-					appSync: func(ctx context.Context, s1, s2, s3 string, c client.Client, cs *utils.CredentialService, b bool) error {
+					appSync: func(ctx context.Context, s1 string, p utils.AppSyncParameters, s2 string, c client.Client, cs *utils.CredentialService, b bool) error {
 						return nil
 					},
 				}
@@ -3237,6 +3518,41 @@ var _ = Describe("Operation Controller", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 	})
+
+	Context("convertSyncOperationToAppSyncParameters Function Test", func() {
+
+		It("should convert the fields of the SyncOperation into the parameters of the sync", func() {
+			syncParams, err := convertSyncOperationToAppSyncParameters(db.SyncOperation{
+				Revision:      "main",
+				Prune:         true,
+				Dry_run:       true,
+				Force:         true,
+				Sync_strategy: "apply",
+				Resources:     ":ConfigMap:jane/my-config-map,apps:Deployment:jane/my-deployment",
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(syncParams).To(Equal(utils.AppSyncParameters{
+				Revision: "main",
+				Prune:    true,
+				DryRun:   true,
+				Force:    true,
+				Strategy: "apply",
+				Resources: []appv1.SyncOperationResource{
+					{Kind: "ConfigMap", Namespace: "jane", Name: "my-config-map"},
+					{Group: "apps", Kind: "Deployment", Namespace: "jane", Name: "my-deployment"},
+				},
+			}))
+		})
+
+		It("should sync all resources if no resources are specified, and return an error for invalid resources", func() {
+			syncParams, err := convertSyncOperationToAppSyncParameters(db.SyncOperation{Revision: "main"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(syncParams).To(Equal(utils.AppSyncParameters{Revision: "main"}))
+
+			_, err = convertSyncOperationToAppSyncParameters(db.SyncOperation{Revision: "main", Resources: "ConfigMap"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
 })
 
 func testTeardown() {
@@ -3422,3 +3738,17 @@ func createApplicationWithSyncOption(syncOptionParam string) (appv1.Application,
 
 	return dummyApplicationSpec, string(dummyApplicationSpecBytes), nil
 }
+
+// fakeCertificateAuthorityData is a self-signed CA certificate that is only used by unit tests.
+const fakeCertificateAuthorityData = `-----BEGIN CERTIFICATE-----
+MIIBkDCCATWgAwIBAgIUD9pFGwMP2FkvjLhb6fYoD5lEtWIwCgYIKoZIzj0EAwIw
+HDEaMBgGA1UEAwwRZmFrZS11bml0LXRlc3QtY2EwIBcNMjYxMDE3MDIwNTA0WhgP
+MjEyNjA5MjMwMjA1MDRaMBwxGjAYBgNVBAMMEWZha2UtdW5pdC10ZXN0LWNhMFkw
+EwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEl31+6OpmfWtNZDkd321EShFmzZ5m33ic
+OonfWwOoRw1cjPr1xiBqHDKfDX0dsP1cWPonnd777yHjGJfrTDT4T6NTMFEwHQYD
+VR0OBBYEFOlReddJFnowAk8mFHsPNMG5PjGcMB8GA1UdIwQYMBaAFOlReddJFnow
+Ak8mFHsPNMG5PjGcMA8GA1UdEwEB/wQFMAMBAf8wCgYIKoZIzj0EAwIDSQAwRgIh
+ALlZO2pH/c17u1/PCAX6AJ3qj/nrBQhaQ6OUMDe+OoQ3AiEA/SRwBTpOy7m+AqkI
+Lb18o8ujSr+CYuJpVrE7+MGIIX8=
+-----END CERTIFICATE-----
+`
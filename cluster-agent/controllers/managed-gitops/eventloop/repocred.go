@@ -3,6 +3,7 @@ package eventloop
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/argoproj/argo-cd/v2/common"
 	"github.com/go-logr/logr"
@@ -34,6 +35,8 @@ const (
 	// #nosec G101
 	errSecretLabelList        = "unable to complete Argo CD Secret list"
 	errSevereNumOfItemsInList = "SEVERE: unexpected number (more than one) of related ArgoCD secrets"
+	errGetArgoCDConfigMap     = "unable to retrieve Argo CD ConfigMap"
+	errUpdateArgoCDConfigMap  = "unable to update Argo CD ConfigMap"
 )
 
 // deleteArgoCDSecretLeftovers best effort attempt to clean up ArgoCD Secret leftovers.
@@ -94,6 +97,11 @@ func deleteArgoCDSecretLeftovers(ctx context.Context, databaseID string, argoCDN
 		return retry, firstDeletionErr
 	}
 
+	// Remove the CA bundle and SSH known hosts of the deleted secret (if any) from the ConfigMaps of the Argo CD instance
+	if err := reconcileArgoCDRepositoryConfigMaps(ctx, argoCDNamespace.Name, eventClient, l); err != nil {
+		return retry, err
+	}
+
 	return noRetry, nil
 }
 
@@ -184,16 +192,22 @@ func processOperation_RepositoryCredentials(ctx context.Context, dbOperation db.
 
 	}
 
+	// 5. Ensure the CA bundle and SSH known hosts of the secret (if any) are trusted by the Argo CD instance.
+	if err := reconcileArgoCDRepositoryConfigMaps(ctx, opConfig.argoCDNamespace.Name, opConfig.eventClient, l); err != nil {
+		return retry, err
+	}
+
 	return noRetry, nil
 }
 
 func compareClusterResourceWithDatabaseRow(dbRepositoryCredentials db.RepositoryCredentials, argoCDSecret *corev1.Secret, l logr.Logger, decodedSecret *db.RepositoryCredentials) bool {
 	labelDatabaseIDPrivateRepoSecret := fmt.Sprintf("%s: %s", controllers.RepoCredDatabaseIDLabel, dbRepositoryCredentials.RepositoryCredentialsID)
-	labelArgoCDPrivateRepoSecret := fmt.Sprintf("%s: %s", common.LabelKeySecretType, common.LabelValueSecretTypeRepository)
+	argoCDSecretType := argoCDSecretTypeOfRepoCred(dbRepositoryCredentials)
+	labelArgoCDPrivateRepoSecret := fmt.Sprintf("%s: %s", common.LabelKeySecretType, argoCDSecretType)
 	annotationArgoCDPrivateRepoSecret := fmt.Sprintf("%s: %s", common.AnnotationKeyManagedBy, common.AnnotationValueManagedByArgoCD)
 	var argoCDLabelFound, repoCredLabelFound, repoCredAnnotationFound bool
 
-	if keyValue, isKeyExists := argoCDSecret.Labels[common.LabelKeySecretType]; isKeyExists && keyValue == common.LabelValueSecretTypeRepository {
+	if keyValue, isKeyExists := argoCDSecret.Labels[common.LabelKeySecretType]; isKeyExists && keyValue == argoCDSecretType {
 		argoCDLabelFound = true
 	}
 
@@ -208,7 +222,7 @@ func compareClusterResourceWithDatabaseRow(dbRepositoryCredentials db.Repository
 	var isArgoCDLabelUpdateNeeded bool
 	if !argoCDLabelFound {
 		l.Info("Secret is missing ArgoCD label! Syncing with database...", "AddLabel", labelArgoCDPrivateRepoSecret)
-		addSecretArgoCDMetadata(argoCDSecret, common.LabelValueSecretTypeRepository)
+		addSecretArgoCDMetadata(argoCDSecret, argoCDSecretType)
 		isArgoCDLabelUpdateNeeded = true
 	}
 
@@ -261,10 +275,53 @@ func compareClusterResourceWithDatabaseRow(dbRepositoryCredentials db.Repository
 		isSSHKeyUpdateNeeded = true
 	}
 
+	var isGitHubAppUpdateNeeded bool
+	if decodedSecret.GithubAppID != dbRepositoryCredentials.GithubAppID ||
+		decodedSecret.GithubAppInstallationID != dbRepositoryCredentials.GithubAppInstallationID ||
+		decodedSecret.GithubAppPrivateKey != dbRepositoryCredentials.GithubAppPrivateKey ||
+		decodedSecret.GithubAppEnterpriseBaseURL != dbRepositoryCredentials.GithubAppEnterpriseBaseURL {
+		l.Info("Secret has wrong GitHub App credentials! Syncing with database...", "UpdateFrom (App ID)", decodedSecret.GithubAppID, "UpdateTo (App ID)", dbRepositoryCredentials.GithubAppID)
+		updateSecretGitHubApp(argoCDSecret, dbRepositoryCredentials)
+		isGitHubAppUpdateNeeded = true
+	}
+
+	var isRepositoryTypeUpdateNeeded bool
+	expectedRepositoryType, expectedEnableOCI := argoCDRepositoryTypeOfRepoCred(dbRepositoryCredentials)
+	if string(argoCDSecret.Data["type"]) != expectedRepositoryType || (string(argoCDSecret.Data["enableOCI"]) == "true") != expectedEnableOCI {
+		l.Info("Secret has wrong repository type! Syncing with database...", "UpdateFrom", string(argoCDSecret.Data["type"]), "UpdateTo", expectedRepositoryType, "enableOCI", expectedEnableOCI)
+		updateSecretRepositoryType(argoCDSecret, dbRepositoryCredentials)
+		isRepositoryTypeUpdateNeeded = true
+	}
+
+	var isKnownHostsUpdateNeeded bool
+	if decodedSecret.KnownHosts != dbRepositoryCredentials.KnownHosts {
+		l.Info("Secret has wrong SSH known hosts! Syncing with database...")
+		updateSecretString(argoCDSecret, argoCDSecretKeyKnownHosts, dbRepositoryCredentials.KnownHosts)
+		isKnownHostsUpdateNeeded = true
+	}
+
+	var isClusterUserIDUpdateNeeded bool
+	if decodedSecret.UserID != dbRepositoryCredentials.UserID {
+		l.Info("Secret has wrong cluster user! Syncing with database...", "UpdateFrom", decodedSecret.UserID, "UpdateTo", dbRepositoryCredentials.UserID)
+		updateSecretString(argoCDSecret, argoCDSecretKeyClusterUserID, dbRepositoryCredentials.UserID)
+		isClusterUserIDUpdateNeeded = true
+	}
+
+	var isTLSUpdateNeeded bool
+	if decodedSecret.TLSClientCertData != dbRepositoryCredentials.TLSClientCertData ||
+		decodedSecret.TLSClientCertKey != dbRepositoryCredentials.TLSClientCertKey ||
+		decodedSecret.TLSCACertData != dbRepositoryCredentials.TLSCACertData ||
+		decodedSecret.Insecure != dbRepositoryCredentials.Insecure {
+		l.Info("Secret has wrong TLS options! Syncing with database...", "UpdateFrom (insecure)", decodedSecret.Insecure, "UpdateTo (insecure)", dbRepositoryCredentials.Insecure)
+		updateSecretTLS(argoCDSecret, dbRepositoryCredentials)
+		isTLSUpdateNeeded = true
+	}
+
 	// If any of the above steps have been performed, then we need to update the cluster secret resource.
 	isUpdateNeeded := isArgoCDLabelUpdateNeeded || isRepoCredLabelUpdateNeeded || isRepoCredAnnotationUpdateNeeded ||
 		isPrivateURLUpdateNeeded || isPasswordUpdateNeeded || isUsernameUpdateNeeded || isSSHKeyUpdateNeeded ||
-		isSecretNameUpdateNeeded
+		isSecretNameUpdateNeeded || isGitHubAppUpdateNeeded || isRepositoryTypeUpdateNeeded || isTLSUpdateNeeded ||
+		isKnownHostsUpdateNeeded || isClusterUserIDUpdateNeeded
 
 	return isUpdateNeeded
 }
@@ -279,26 +336,93 @@ func convertRepoCredToSecret(repoCred db.RepositoryCredentials, secret *corev1.S
 	updateSecretString(secret, "username", repoCred.AuthUsername)
 	updateSecretString(secret, "password", repoCred.AuthPassword)
 	updateSecretString(secret, "sshPrivateKey", repoCred.AuthSSHKey)
-	addSecretArgoCDMetadata(secret, common.LabelValueSecretTypeRepository) // adds the ArgoCD Label
-	addSecretRepoCredMetadata(secret, repoCred.RepositoryCredentialsID)    // adds the DatabaseID Label
+	updateSecretGitHubApp(secret, repoCred)
+	updateSecretRepositoryType(secret, repoCred)
+	updateSecretTLS(secret, repoCred)
+	updateSecretString(secret, argoCDSecretKeyKnownHosts, repoCred.KnownHosts)
+	updateSecretString(secret, argoCDSecretKeyClusterUserID, repoCred.UserID)
+	addSecretArgoCDMetadata(secret, argoCDSecretTypeOfRepoCred(repoCred)) // adds the ArgoCD Label
+	addSecretRepoCredMetadata(secret, repoCred.RepositoryCredentialsID)   // adds the DatabaseID Label
 
 	// Values Supported by ArgoCD but not yet part of GitOps Repository Credentials as part of the MVP
 	// -----------------------------------------------------------------------------------------------
 	//updateSecretString(secret, "project", "") not supported yet
-	//updateSecretBool(secret, "enableOCI", repository.EnableOCI)
-	//updateSecretString(secret, "tlsClientCertData", repository.TLSClientCertData)
-	//updateSecretString(secret, "tlsClientCertKey", repository.TLSClientCertKey)
-	//updateSecretString(secret, "type", repository.Type)
-	//updateSecretString(secret, "githubAppPrivateKey", repository.GithubAppPrivateKey)
-	//updateSecretInt(secret, "githubAppID", repository.GithubAppId)
-	//updateSecretInt(secret, "githubAppInstallationID", repository.GithubAppInstallationId)
-	//updateSecretString(secret, "githubAppEnterpriseBaseUrl", repository.GitHubAppEnterpriseBaseURL)
 	//updateSecretBool(secret, "insecureIgnoreHostKey", repository.InsecureIgnoreHostKey)
-	//updateSecretBool(secret, "insecure", repository.Insecure)
 	//updateSecretBool(secret, "enableLfs", repository.EnableLFS)
 	//updateSecretString(secret, "proxy", repository.Proxy)
 }
 
+// updateSecretGitHubApp sets the GitHub App credentials of the repository credentials in the Argo CD repository secret.
+// See https://argo-cd.readthedocs.io/en/stable/operator-manual/declarative-setup/#github-app-credential
+func updateSecretGitHubApp(secret *corev1.Secret, repoCred db.RepositoryCredentials) {
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+
+	updateSecretString(secret, "githubAppID", repoCred.GithubAppID)
+	updateSecretString(secret, "githubAppInstallationID", repoCred.GithubAppInstallationID)
+	updateSecretString(secret, "githubAppPrivateKey", repoCred.GithubAppPrivateKey)
+	updateSecretString(secret, "githubAppEnterpriseBaseUrl", repoCred.GithubAppEnterpriseBaseURL)
+}
+
+// updateSecretTLS sets the TLS options of the repository credentials in the Argo CD repository secret. The CA bundle
+// is not read by Argo CD from the secret: it must be added to the TLS certs ConfigMap of the Argo CD instance by an
+// administrator, see reconcileArgoCDTLSCertsConfigMap.
+func updateSecretTLS(secret *corev1.Secret, repoCred db.RepositoryCredentials) {
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+
+	updateSecretString(secret, "tlsClientCertData", repoCred.TLSClientCertData)
+	updateSecretString(secret, "tlsClientCertKey", repoCred.TLSClientCertKey)
+	updateSecretString(secret, argoCDSecretKeyTLSCACertData, repoCred.TLSCACertData)
+
+	if repoCred.Insecure {
+		secret.Data["insecure"] = []byte("true")
+	} else {
+		delete(secret.Data, "insecure")
+	}
+}
+
+// updateSecretRepositoryType sets the repository type of the repository credentials in the Argo CD repository secret.
+func updateSecretRepositoryType(secret *corev1.Secret, repoCred db.RepositoryCredentials) {
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+
+	repositoryType, enableOCI := argoCDRepositoryTypeOfRepoCred(repoCred)
+
+	secret.Data["type"] = []byte(repositoryType)
+	if enableOCI {
+		secret.Data["enableOCI"] = []byte("true")
+	} else {
+		delete(secret.Data, "enableOCI")
+	}
+}
+
+// argoCDRepositoryTypeOfRepoCred returns the Argo CD repository type of the repository credentials, and whether OCI
+// should be enabled: Argo CD treats an OCI registry as a Helm chart repository with OCI enabled.
+func argoCDRepositoryTypeOfRepoCred(repoCred db.RepositoryCredentials) (string, bool) {
+	switch repoCred.Type {
+	case string(operation.RepositoryCredentialTypeHelm):
+		return "helm", false
+	case string(operation.RepositoryCredentialTypeOCI):
+		return "helm", true
+	default:
+		return "git", false
+	}
+}
+
+// argoCDSecretTypeOfRepoCred returns the Argo CD secret type of the repository credentials: a credential template is a
+// 'repo-creds' secret, which Argo CD uses for every repository whose URL begins with the URL of the secret.
+// See https://argo-cd.readthedocs.io/en/stable/operator-manual/declarative-setup/#repository-credentials
+func argoCDSecretTypeOfRepoCred(repoCred db.RepositoryCredentials) string {
+	if repoCred.Template {
+		return common.LabelValueSecretTypeRepoCreds
+	}
+	return common.LabelValueSecretTypeRepository
+}
+
 func updateSecretString(secret *corev1.Secret, key, value string) {
 	if _, present := secret.Data[key]; present || value != "" {
 		secret.Data[key] = []byte(value)
@@ -343,5 +467,108 @@ func secretToRepoCred(secret *corev1.Secret) (repoCred *db.RepositoryCredentials
 		AuthPassword: string(secret.Data["password"]),
 		AuthSSHKey:   string(secret.Data["sshPrivateKey"]),
 		SecretObj:    secret.Name,
+
+		GithubAppID:                string(secret.Data["githubAppID"]),
+		GithubAppInstallationID:    string(secret.Data["githubAppInstallationID"]),
+		GithubAppPrivateKey:        string(secret.Data["githubAppPrivateKey"]),
+		GithubAppEnterpriseBaseURL: string(secret.Data["githubAppEnterpriseBaseUrl"]),
+
+		TLSClientCertData: string(secret.Data["tlsClientCertData"]),
+		TLSClientCertKey:  string(secret.Data["tlsClientCertKey"]),
+		TLSCACertData:     string(secret.Data[argoCDSecretKeyTLSCACertData]),
+		Insecure:          string(secret.Data["insecure"]) == "true",
+
+		KnownHosts: string(secret.Data[argoCDSecretKeyKnownHosts]),
+
+		UserID: string(secret.Data[argoCDSecretKeyClusterUserID]),
+	}
+}
+
+// listManagedArgoCDRepositorySecrets returns the Argo CD repository secrets, in the Argo CD namespace, that are managed
+// by GitOps Service, sorted by name (so that the contents derived from them are stable between reconciles).
+func listManagedArgoCDRepositorySecrets(ctx context.Context, argoCDNamespace string, eventClient client.Client, l logr.Logger) ([]corev1.Secret, error) {
+
+	req, err := labels.NewRequirement(controllers.RepoCredDatabaseIDLabel, selection.Exists, nil)
+	if err != nil {
+		l.Error(err, errSevereLabelNotFound)
+		return nil, err
+	}
+
+	list := corev1.SecretList{}
+	if err := eventClient.List(ctx, &list, &client.ListOptions{
+		Namespace:     argoCDNamespace,
+		LabelSelector: labels.NewSelector().Add(*req),
+	}); err != nil {
+		l.Error(err, errSecretLabelList)
+		return nil, err
+	}
+
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].Name < list.Items[j].Name
+	})
+
+	return list.Items, nil
+}
+
+// reconcileArgoCDRepositoryConfigMaps ensures that the ConfigMaps of the Argo CD instance, which are derived from the
+// Argo CD repository secrets (the TLS certs, and the SSH known hosts), are consistent with those secrets.
+func reconcileArgoCDRepositoryConfigMaps(ctx context.Context, argoCDNamespace string, eventClient client.Client, l logr.Logger) error {
+
+	if err := reconcileArgoCDTLSCertsConfigMap(ctx, argoCDNamespace, eventClient, l); err != nil {
+		return err
+	}
+
+	return reconcileArgoCDKnownHostsConfigMap(ctx, argoCDNamespace, eventClient, l)
+}
+
+// reconcileArgoCDConfigMap retrieves the ConfigMap of the Argo CD instance, and calls 'update' to modify it, creating
+// or updating the ConfigMap if 'update' returns true. If the ConfigMap doesn't exist, it is only created if
+// 'createIfMissing' is true.
+func reconcileArgoCDConfigMap(ctx context.Context, name string, argoCDNamespace string, createIfMissing bool,
+	update func(configMap *corev1.ConfigMap) bool, eventClient client.Client, l logr.Logger) error {
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: argoCDNamespace,
+		},
+	}
+
+	l = l.WithValues("configMap", configMap.Name, "namespace", configMap.Namespace)
+
+	configMapExists := true
+	if err := eventClient.Get(ctx, client.ObjectKeyFromObject(configMap), configMap); err != nil {
+		if !apierr.IsNotFound(err) {
+			l.Error(err, errGetArgoCDConfigMap)
+			return err
+		}
+		configMapExists = false
+	}
+
+	if !configMapExists && !createIfMissing {
+		// Nothing to add, and nothing to remove
+		return nil
 	}
+
+	if !update(configMap) {
+		l.V(logutil.LogLevel_Debug).Info("Argo CD ConfigMap is up to date")
+		return nil
+	}
+
+	if !configMapExists {
+		if err := eventClient.Create(ctx, configMap); err != nil {
+			l.Error(err, errUpdateArgoCDConfigMap)
+			return err
+		}
+		logutil.LogAPIResourceChangeEvent(configMap.Namespace, configMap.Name, configMap, logutil.ResourceCreated, l)
+		return nil
+	}
+
+	if err := eventClient.Update(ctx, configMap); err != nil {
+		l.Error(err, errUpdateArgoCDConfigMap)
+		return err
+	}
+	logutil.LogAPIResourceChangeEvent(configMap.Namespace, configMap.Name, configMap, logutil.ResourceModified, l)
+
+	return nil
 }
@@ -0,0 +1,216 @@
+package eventloop
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/argoproj/argo-cd/v2/common"
+	"github.com/go-logr/logr"
+	operation "github.com/redhat-appstudio/managed-gitops/backend-shared/apis/managed-gitops/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// argoCDSecretKeyKnownHosts is the key of the SSH known hosts entries in the Argo CD repository secret. Argo CD does
+	// not read this key: it is instead used to populate the SSH known hosts ConfigMap of the Argo CD instance.
+	argoCDSecretKeyKnownHosts = "knownHosts"
+
+	// argoCDKnownHostsConfigMapKey is the key of the SSH known hosts entries in the SSH known hosts ConfigMap of Argo CD.
+	argoCDKnownHostsConfigMapKey = "ssh_known_hosts"
+
+	// managedKnownHostsBegin and managedKnownHostsEnd delimit the SSH known hosts entries that are managed by GitOps
+	// Service, within the SSH known hosts ConfigMap of the Argo CD instance. Entries outside of them are not modified.
+	managedKnownHostsBegin = "# BEGIN entries managed by GitOps Service"
+	managedKnownHostsEnd   = "# END entries managed by GitOps Service"
+)
+
+// reconcileArgoCDKnownHostsConfigMap ensures that the SSH known hosts ConfigMap of the Argo CD instance contains the SSH
+// known hosts entries of the Argo CD repository secrets that are managed by GitOps Service. Entries that are no longer
+// in any of the secrets are removed. As the ConfigMap is shared by all the users of the Argo CD instance, the entries of a
+// secret are ignored if they are not valid for the host of its repository, if the host is used by other users, or if
+// the host already has entries that are not managed by GitOps Service.
+func reconcileArgoCDKnownHostsConfigMap(ctx context.Context, argoCDNamespace string, eventClient client.Client, l logr.Logger) error {
+
+	secrets, err := listManagedArgoCDRepositorySecrets(ctx, argoCDNamespace, eventClient, l)
+	if err != nil {
+		return err
+	}
+
+	hosts, entriesByHost := desiredArgoCDKnownHosts(secrets, l)
+
+	return reconcileArgoCDConfigMap(ctx, common.ArgoCDKnownHostsConfigMapName, argoCDNamespace, len(entriesByHost) > 0,
+		func(configMap *corev1.ConfigMap) bool {
+
+			unmanagedHosts := knownHostsHosts(unmanagedKnownHostsLines(configMap.Data[argoCDKnownHostsConfigMapKey]))
+
+			var desiredEntries []string
+			for _, host := range hosts {
+				if unmanagedHosts[host] {
+					l.Info("The host already has SSH known hosts entries that are not managed by GitOps Service, so the entries of the Argo CD secrets are ignored", "host", host)
+					continue
+				}
+				desiredEntries = append(desiredEntries, entriesByHost[host]...)
+			}
+
+			knownHosts := mergeManagedKnownHosts(configMap.Data[argoCDKnownHostsConfigMapKey], desiredEntries)
+			if knownHosts == configMap.Data[argoCDKnownHostsConfigMapKey] {
+				return false
+			}
+
+			if configMap.Data == nil {
+				configMap.Data = map[string]string{}
+			}
+			configMap.Data[argoCDKnownHostsConfigMapKey] = knownHosts
+			return true
+
+		}, eventClient, l)
+}
+
+// desiredArgoCDKnownHosts returns the SSH known hosts entries of the Argo CD repository secrets that are managed by
+// GitOps Service, keyed by the host of the repository, along with the hosts (in the order of the secrets).
+//
+// Argo CD accepts any of the entries of a host, for every repository on the host: so the entries of a host are only
+// added if all the secrets of repositories on the host are owned by the same user.
+func desiredArgoCDKnownHosts(secrets []corev1.Secret, l logr.Logger) ([]string, map[string][]string) {
+
+	var hosts []string
+	entriesByHost := map[string][]string{}
+	ownersByHost := map[string]map[string]bool{}
+
+	for _, secret := range secrets {
+
+		knownHosts := string(secret.Data[argoCDSecretKeyKnownHosts])
+		if err := operation.ValidateKnownHosts(knownHosts, string(secret.Data["url"])); err != nil {
+			l.Error(err, "SSH known hosts entries of the Argo CD secret are invalid, so they are ignored", "secret", secret.Name)
+			knownHosts = ""
+		}
+
+		host := repositoryHost(string(secret.Data["url"]))
+		if host == "" {
+			continue
+		}
+
+		// A secret without an owner is treated as owned by a different user than all the other secrets
+		owner := string(secret.Data[argoCDSecretKeyClusterUserID])
+		if owner == "" {
+			owner = "secret/" + secret.Name
+		}
+		if ownersByHost[host] == nil {
+			ownersByHost[host] = map[string]bool{}
+		}
+		ownersByHost[host][owner] = true
+
+		for _, entry := range strings.Split(knownHosts, "\n") {
+			if entry = strings.TrimSpace(entry); entry != "" && !containsString(entriesByHost[host], entry) {
+				if len(entriesByHost[host]) == 0 {
+					hosts = append(hosts, host)
+				}
+				entriesByHost[host] = append(entriesByHost[host], entry)
+			}
+		}
+	}
+
+	var res []string
+	for _, host := range hosts {
+		if len(ownersByHost[host]) > 1 {
+			l.Info("Repositories on the host are used by multiple users, so the SSH known hosts entries of the host are ignored", "host", host)
+			delete(entriesByHost, host)
+			continue
+		}
+		res = append(res, host)
+	}
+
+	return res, entriesByHost
+}
+
+// mergeManagedKnownHosts replaces the SSH known hosts entries that are managed by GitOps Service, within the contents of
+// an SSH known hosts file, with 'entries'. The other contents of the file are preserved.
+func mergeManagedKnownHosts(knownHosts string, entries []string) string {
+
+	if len(entries) == 0 && !strings.Contains(knownHosts, managedKnownHostsBegin) {
+		// There are no managed entries to remove
+		return knownHosts
+	}
+
+	res := strings.Join(unmanagedKnownHostsLines(knownHosts), "\n")
+	if res != "" {
+		res += "\n"
+	}
+
+	if len(entries) > 0 {
+		res += managedKnownHostsBegin + "\n" + strings.Join(entries, "\n") + "\n" + managedKnownHostsEnd + "\n"
+	}
+
+	return res
+}
+
+// unmanagedKnownHostsLines returns the lines of the contents of an SSH known hosts file that are not managed by GitOps
+// Service.
+func unmanagedKnownHostsLines(knownHosts string) []string {
+
+	var unmanagedLines []string
+	inManagedEntries := false
+
+	for _, line := range strings.Split(strings.TrimRight(knownHosts, "\n"), "\n") {
+		switch {
+		case line == managedKnownHostsBegin:
+			inManagedEntries = true
+		case line == managedKnownHostsEnd:
+			inManagedEntries = false
+		case !inManagedEntries:
+			unmanagedLines = append(unmanagedLines, line)
+		}
+	}
+
+	return unmanagedLines
+}
+
+// knownHostsHosts returns the hosts (without port, in lower case) of SSH known hosts entries. Hashed host patterns are
+// not returned.
+func knownHostsHosts(lines []string) map[string]bool {
+
+	res := map[string]bool{}
+
+	for _, line := range lines {
+
+		fields := strings.Fields(line)
+		if len(fields) > 0 && strings.HasPrefix(fields[0], "@") {
+			// Skip the marker (for example, '@cert-authority')
+			fields = fields[1:]
+		}
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		for _, host := range strings.Split(fields[0], ",") {
+			// A host with a non-default port is of the form '[host]:port'
+			if strings.HasPrefix(host, "[") && strings.Contains(host, "]") {
+				host = host[1:strings.Index(host, "]")]
+			}
+			res[strings.ToLower(host)] = true
+		}
+	}
+
+	return res
+}
+
+// repositoryHost returns the host (without port, in lower case) of a repository URL, which may also be an SCP-like SSH
+// URL (for example, 'git@github.com:org/repo.git'), or an empty string if it has none.
+func repositoryHost(repoURL string) string {
+
+	if !strings.Contains(repoURL, "://") {
+		// An SCP-like SSH URL, or an OCI registry (which is referenced without a scheme)
+		repoURL = strings.SplitN(repoURL, "/", 2)[0]
+		repoURL = strings.SplitN(repoURL, ":", 2)[0]
+		return strings.ToLower(repoURL[strings.LastIndex(repoURL, "@")+1:])
+	}
+
+	parsedURL, err := url.Parse(repoURL)
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(parsedURL.Hostname())
+}
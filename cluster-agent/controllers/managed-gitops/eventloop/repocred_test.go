@@ -508,3 +508,436 @@ var _ = Describe("Testing Repository Credentials Operation", func() {
 		})
 	})
 })
+
+var _ = Describe("Testing the GitHub App credentials of the Argo CD repository secret", func() {
+
+	repositoryCredential := db.RepositoryCredentials{
+		RepositoryCredentialsID:    "test-my-repo-creds-github-app",
+		PrivateURL:                 "https://github.com/my-org/my-repo",
+		SecretObj:                  "test-fake-secret-obj",
+		GithubAppID:                "123456",
+		GithubAppInstallationID:    "7890",
+		GithubAppPrivateKey:        "test-fake-github-app-private-key",
+		GithubAppEnterpriseBaseURL: "https://ghe.example.com/api/v3",
+	}
+
+	It("should write the GitHub App credentials of the RepositoryCredentials DB row to the secret", func() {
+
+		secret := &corev1.Secret{}
+		convertRepoCredToSecret(repositoryCredential, secret)
+
+		Expect(string(secret.Data["githubAppID"])).To(Equal(repositoryCredential.GithubAppID))
+		Expect(string(secret.Data["githubAppInstallationID"])).To(Equal(repositoryCredential.GithubAppInstallationID))
+		Expect(string(secret.Data["githubAppPrivateKey"])).To(Equal(repositoryCredential.GithubAppPrivateKey))
+		Expect(string(secret.Data["githubAppEnterpriseBaseUrl"])).To(Equal(repositoryCredential.GithubAppEnterpriseBaseURL))
+		Expect(secret.Data).ToNot(HaveKey("username"))
+		Expect(secret.Data).ToNot(HaveKey("password"))
+	})
+
+	It("should update the secret if its GitHub App credentials differ from the RepositoryCredentials DB row", func() {
+
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: repositoryCredential.SecretObj}}
+		convertRepoCredToSecret(repositoryCredential, secret)
+
+		Expect(compareClusterResourceWithDatabaseRow(repositoryCredential, secret, logr.Discard(), secretToRepoCred(secret))).To(BeFalse())
+
+		By("modifying the private key in the secret, which should be reverted")
+		secret.Data["githubAppPrivateKey"] = []byte("wrong-private-key")
+		Expect(compareClusterResourceWithDatabaseRow(repositoryCredential, secret, logr.Discard(), secretToRepoCred(secret))).To(BeTrue())
+		Expect(string(secret.Data["githubAppPrivateKey"])).To(Equal(repositoryCredential.GithubAppPrivateKey))
+
+		By("removing the GitHub App from the DB row, which should clear it from the secret")
+		withoutGitHubApp := repositoryCredential
+		withoutGitHubApp.GithubAppID, withoutGitHubApp.GithubAppInstallationID = "", ""
+		withoutGitHubApp.GithubAppPrivateKey, withoutGitHubApp.GithubAppEnterpriseBaseURL = "", ""
+		Expect(compareClusterResourceWithDatabaseRow(withoutGitHubApp, secret, logr.Discard(), secretToRepoCred(secret))).To(BeTrue())
+		Expect(secretToRepoCred(secret).GithubAppPrivateKey).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Testing the Argo CD secret type of credential templates", func() {
+
+	repositoryCredential := db.RepositoryCredentials{
+		RepositoryCredentialsID: "test-my-repo-creds-template",
+		PrivateURL:              "https://gitlab.com/my-group/",
+		AuthUsername:            "test-fake-auth-username",
+		AuthPassword:            "test-fake-auth-password",
+		SecretObj:               "test-fake-secret-obj",
+		Template:                true,
+	}
+
+	It("should write a credential template as an Argo CD 'repo-creds' secret", func() {
+
+		secret := &corev1.Secret{}
+		convertRepoCredToSecret(repositoryCredential, secret)
+
+		Expect(secret.Labels[common.LabelKeySecretType]).To(Equal(common.LabelValueSecretTypeRepoCreds))
+		Expect(string(secret.Data["url"])).To(Equal(repositoryCredential.PrivateURL))
+	})
+
+	It("should update the secret type if the RepositoryCredentials DB row is changed to or from a template", func() {
+
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: repositoryCredential.SecretObj}}
+		convertRepoCredToSecret(repositoryCredential, secret)
+
+		Expect(compareClusterResourceWithDatabaseRow(repositoryCredential, secret, logr.Discard(), secretToRepoCred(secret))).To(BeFalse())
+
+		By("changing the DB row to no longer be a template, which should make the secret a repository secret")
+		notTemplate := repositoryCredential
+		notTemplate.Template = false
+		Expect(compareClusterResourceWithDatabaseRow(notTemplate, secret, logr.Discard(), secretToRepoCred(secret))).To(BeTrue())
+		Expect(secret.Labels[common.LabelKeySecretType]).To(Equal(common.LabelValueSecretTypeRepository))
+
+		By("changing the DB row back to a template, which should make the secret a 'repo-creds' secret again")
+		Expect(compareClusterResourceWithDatabaseRow(repositoryCredential, secret, logr.Discard(), secretToRepoCred(secret))).To(BeTrue())
+		Expect(secret.Labels[common.LabelKeySecretType]).To(Equal(common.LabelValueSecretTypeRepoCreds))
+	})
+})
+
+var _ = Describe("Testing the repository type of the Argo CD repository secret", func() {
+
+	repositoryCredential := db.RepositoryCredentials{
+		RepositoryCredentialsID: "test-my-repo-creds-oci",
+		PrivateURL:              "registry.example.com/my-org/charts",
+		AuthUsername:            "test-fake-auth-username",
+		AuthPassword:            "test-fake-auth-password",
+		SecretObj:               "test-fake-secret-obj",
+		Type:                    "oci",
+	}
+
+	DescribeTable("should write the Argo CD repository type of the RepositoryCredentials DB row to the secret",
+		func(repoCredType string, expectedType string, expectedEnableOCI bool) {
+
+			repoCred := repositoryCredential
+			repoCred.Type = repoCredType
+
+			secret := &corev1.Secret{}
+			convertRepoCredToSecret(repoCred, secret)
+
+			Expect(string(secret.Data["type"])).To(Equal(expectedType))
+			if expectedEnableOCI {
+				Expect(string(secret.Data["enableOCI"])).To(Equal("true"))
+			} else {
+				Expect(secret.Data).ToNot(HaveKey("enableOCI"))
+			}
+		},
+		Entry("a DB row without a type is a Git repository", "", "git", false),
+		Entry("a Git repository", "git", "git", false),
+		Entry("a Helm chart repository", "helm", "helm", false),
+		Entry("an OCI registry is a Helm chart repository with OCI enabled", "oci", "helm", true),
+	)
+
+	It("should update the secret if its repository type differs from the RepositoryCredentials DB row", func() {
+
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: repositoryCredential.SecretObj}}
+		convertRepoCredToSecret(repositoryCredential, secret)
+
+		Expect(compareClusterResourceWithDatabaseRow(repositoryCredential, secret, logr.Discard(), secretToRepoCred(secret))).To(BeFalse())
+
+		By("changing the DB row from an OCI registry to a Helm chart repository, which should disable OCI")
+		helmRepository := repositoryCredential
+		helmRepository.Type = "helm"
+		Expect(compareClusterResourceWithDatabaseRow(helmRepository, secret, logr.Discard(), secretToRepoCred(secret))).To(BeTrue())
+		Expect(string(secret.Data["type"])).To(Equal("helm"))
+		Expect(secret.Data).ToNot(HaveKey("enableOCI"))
+
+		By("changing the DB row back to an OCI registry, which should enable OCI again")
+		Expect(compareClusterResourceWithDatabaseRow(repositoryCredential, secret, logr.Discard(), secretToRepoCred(secret))).To(BeTrue())
+		Expect(string(secret.Data["enableOCI"])).To(Equal("true"))
+	})
+})
+
+var _ = Describe("Testing the TLS options of the Argo CD repository secret", func() {
+
+	repositoryCredential := db.RepositoryCredentials{
+		RepositoryCredentialsID: "test-my-repo-creds-tls",
+		PrivateURL:              "https://git.example.com:8443/my-org/my-repo.git",
+		AuthUsername:            "test-fake-auth-username",
+		AuthPassword:            "test-fake-auth-password",
+		SecretObj:               "test-fake-secret-obj",
+		TLSClientCertData:       "test-client-cert",
+		TLSClientCertKey:        "test-client-key",
+		TLSCACertData:           "test-ca-cert",
+		Insecure:                true,
+		UserID:                  "test-user",
+	}
+
+	It("should write the TLS options of the RepositoryCredentials DB row to the secret", func() {
+
+		secret := &corev1.Secret{}
+		convertRepoCredToSecret(repositoryCredential, secret)
+
+		Expect(string(secret.Data["tlsClientCertData"])).To(Equal("test-client-cert"))
+		Expect(string(secret.Data["tlsClientCertKey"])).To(Equal("test-client-key"))
+		Expect(string(secret.Data["tlsCACertData"])).To(Equal("test-ca-cert"))
+		Expect(string(secret.Data["insecure"])).To(Equal("true"))
+		Expect(string(secret.Data["clusterUserID"])).To(Equal("test-user"))
+	})
+
+	It("should update the secret if its TLS options differ from the RepositoryCredentials DB row", func() {
+
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: repositoryCredential.SecretObj}}
+		convertRepoCredToSecret(repositoryCredential, secret)
+
+		Expect(compareClusterResourceWithDatabaseRow(repositoryCredential, secret, logr.Discard(), secretToRepoCred(secret))).To(BeFalse())
+
+		By("removing the TLS options from the DB row, which should remove them from the secret")
+		withoutTLS := repositoryCredential
+		withoutTLS.TLSClientCertData, withoutTLS.TLSClientCertKey, withoutTLS.TLSCACertData, withoutTLS.Insecure = "", "", "", false
+
+		Expect(compareClusterResourceWithDatabaseRow(withoutTLS, secret, logr.Discard(), secretToRepoCred(secret))).To(BeTrue())
+		Expect(string(secret.Data["tlsCACertData"])).To(BeEmpty())
+		Expect(secret.Data).ToNot(HaveKey("insecure"))
+		Expect(compareClusterResourceWithDatabaseRow(withoutTLS, secret, logr.Discard(), secretToRepoCred(secret))).To(BeFalse())
+
+		By("adding the owner to a secret that was created without one")
+		delete(secret.Data, "clusterUserID")
+		Expect(compareClusterResourceWithDatabaseRow(withoutTLS, secret, logr.Discard(), secretToRepoCred(secret))).To(BeTrue())
+		Expect(string(secret.Data["clusterUserID"])).To(Equal("test-user"))
+	})
+
+	Context("Test reconcileArgoCDTLSCertsConfigMap", func() {
+
+		const argoCDNamespace = "gitops-service-argocd"
+
+		var (
+			ctx       context.Context
+			k8sClient client.Client
+		)
+
+		getConfigMap := func() *corev1.ConfigMap {
+			configMap := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: argoCDNamespace, Name: common.ArgoCDTLSCertsConfigMapName}, configMap)).To(Succeed())
+			return configMap
+		}
+
+		BeforeEach(func() {
+			ctx = context.Background()
+		})
+
+		It("should remove the CA bundles that were added by GitOps Service, without modifying other entries", func() {
+
+			k8sClient = fake.NewClientBuilder().WithObjects(
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        common.ArgoCDTLSCertsConfigMapName,
+						Namespace:   argoCDNamespace,
+						Annotations: map[string]string{managedTLSCertHostsAnnotation: "git.example.com,registry.example.com"},
+					},
+					Data: map[string]string{
+						"git.example.com":      "ca-a\nca-b\n",
+						"registry.example.com": "ca-c\n",
+						"other.example.com":    "other-ca",
+					},
+				},
+			).Build()
+
+			Expect(reconcileArgoCDTLSCertsConfigMap(ctx, argoCDNamespace, k8sClient, logr.Discard())).To(Succeed())
+
+			configMap := getConfigMap()
+			Expect(configMap.Data).To(Equal(map[string]string{"other.example.com": "other-ca"}))
+			Expect(configMap.Annotations).ToNot(HaveKey(managedTLSCertHostsAnnotation))
+		})
+
+		It("should not add the CA bundles of the secrets to the ConfigMap, as Argo CD would use them for the repositories of other users", func() {
+
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "secret-a",
+					Namespace: argoCDNamespace,
+					Labels:    map[string]string{controllers.RepoCredDatabaseIDLabel: "secret-a"},
+				},
+				Data: map[string][]byte{
+					"url":           []byte("https://github.com/org/repo-a.git"),
+					"clusterUserID": []byte("test-user"),
+					"tlsCACertData": []byte("ca-a"),
+				},
+			}
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: common.ArgoCDTLSCertsConfigMapName, Namespace: argoCDNamespace},
+				Data:       map[string]string{"other.example.com": "other-ca"},
+			}
+
+			k8sClient = fake.NewClientBuilder().WithObjects(secret, configMap).Build()
+
+			Expect(reconcileArgoCDTLSCertsConfigMap(ctx, argoCDNamespace, k8sClient, logr.Discard())).To(Succeed())
+			Expect(getConfigMap().Data).To(Equal(map[string]string{"other.example.com": "other-ca"}))
+
+			By("deleting the ConfigMap, which should not be recreated")
+			Expect(k8sClient.Delete(ctx, configMap)).To(Succeed())
+
+			Expect(reconcileArgoCDTLSCertsConfigMap(ctx, argoCDNamespace, k8sClient, logr.Discard())).To(Succeed())
+			err := k8sClient.Get(ctx, types.NamespacedName{Namespace: argoCDNamespace, Name: common.ArgoCDTLSCertsConfigMapName}, &corev1.ConfigMap{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("Testing the SSH known hosts of the Argo CD repository secret", func() {
+
+	const argoCDNamespace = "gitops-service-argocd"
+
+	var (
+		ctx       context.Context
+		k8sClient client.Client
+	)
+
+	// Valid SSH public keys, for the known hosts entries
+	const (
+		keyA = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEXYJchXQqL1l3AwO9/hhZ2BMfTNfATULHpmVpHKsJQ3"
+		keyB = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIFHslSy36wPO2ASGMi8jGN+dlrPNNrjnhjSO2CyFKY2Y"
+	)
+
+	// newRepoSecret returns an Argo CD repository secret, managed by GitOps Service, of the repository with the SSH known hosts entries
+	newRepoSecret := func(name string, repoURL string, knownHosts string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: argoCDNamespace,
+				Labels:    map[string]string{controllers.RepoCredDatabaseIDLabel: name},
+			},
+			Data: map[string][]byte{"url": []byte(repoURL), "knownHosts": []byte(knownHosts), "clusterUserID": []byte("test-user")},
+		}
+	}
+
+	getKnownHosts := func() string {
+		configMap := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: argoCDNamespace, Name: common.ArgoCDKnownHostsConfigMapName}, configMap)).To(Succeed())
+		return configMap.Data["ssh_known_hosts"]
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("should write the SSH known hosts of the RepositoryCredentials DB row to the secret, and update it if they differ", func() {
+
+		repositoryCredential := db.RepositoryCredentials{
+			RepositoryCredentialsID: "test-my-repo-creds-ssh",
+			PrivateURL:              "git@git.example.com:my-org/my-repo.git",
+			AuthSSHKey:              "test-fake-ssh-key",
+			SecretObj:               "test-fake-secret-obj",
+			KnownHosts:              "git.example.com ssh-ed25519 AAAA-a\n",
+		}
+
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: repositoryCredential.SecretObj}}
+		convertRepoCredToSecret(repositoryCredential, secret)
+		Expect(string(secret.Data["knownHosts"])).To(Equal("git.example.com ssh-ed25519 AAAA-a\n"))
+
+		Expect(compareClusterResourceWithDatabaseRow(repositoryCredential, secret, logr.Discard(), secretToRepoCred(secret))).To(BeFalse())
+
+		repositoryCredential.KnownHosts = "git.example.com ssh-ed25519 AAAA-b\n"
+		Expect(compareClusterResourceWithDatabaseRow(repositoryCredential, secret, logr.Discard(), secretToRepoCred(secret))).To(BeTrue())
+		Expect(string(secret.Data["knownHosts"])).To(Equal("git.example.com ssh-ed25519 AAAA-b\n"))
+	})
+
+	It("should merge the SSH known hosts entries of the secrets into the ConfigMap, without modifying other entries", func() {
+
+		existingKnownHosts := "# Argo CD defaults\ngithub.com ssh-ed25519 AAAA-github\n"
+
+		k8sClient = fake.NewClientBuilder().WithObjects(
+			newRepoSecret("secret-a", "ssh://git@git.example.com/org/repo-a", "git.example.com "+keyA+"\n"),
+			newRepoSecret("secret-b", "ssh://git@git.example.com/org/repo-b", "git.example.com "+keyA+"\n"),
+			newRepoSecret("secret-c", "ssh://git@other.example.com/org/repo", "other.example.com "+keyB+"\n"),
+			newRepoSecret("secret-d", "ssh://git@git.example.com/org/repo-d", ""),
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: common.ArgoCDKnownHostsConfigMapName, Namespace: argoCDNamespace},
+				Data:       map[string]string{"ssh_known_hosts": existingKnownHosts},
+			},
+		).Build()
+
+		Expect(reconcileArgoCDKnownHostsConfigMap(ctx, argoCDNamespace, k8sClient, logr.Discard())).To(Succeed())
+
+		Expect(getKnownHosts()).To(Equal(existingKnownHosts +
+			"# BEGIN entries managed by GitOps Service\n" +
+			"git.example.com " + keyA + "\n" +
+			"other.example.com " + keyB + "\n" +
+			"# END entries managed by GitOps Service\n"))
+
+		By("reconciling again, which should not modify the ConfigMap")
+		knownHosts := getKnownHosts()
+		Expect(reconcileArgoCDKnownHostsConfigMap(ctx, argoCDNamespace, k8sClient, logr.Discard())).To(Succeed())
+		Expect(getKnownHosts()).To(Equal(knownHosts))
+
+		By("deleting the secrets, which should remove only the managed entries")
+		Expect(k8sClient.Delete(ctx, newRepoSecret("secret-a", "", ""))).To(Succeed())
+		Expect(k8sClient.Delete(ctx, newRepoSecret("secret-b", "", ""))).To(Succeed())
+		Expect(k8sClient.Delete(ctx, newRepoSecret("secret-c", "", ""))).To(Succeed())
+
+		Expect(reconcileArgoCDKnownHostsConfigMap(ctx, argoCDNamespace, k8sClient, logr.Discard())).To(Succeed())
+		Expect(getKnownHosts()).To(Equal(existingKnownHosts))
+	})
+
+	It("should create the ConfigMap if it does not exist, but only if there are SSH known hosts entries to add", func() {
+
+		k8sClient = fake.NewClientBuilder().WithObjects(newRepoSecret("secret-a", "ssh://git@git.example.com/org/repo-a", "")).Build()
+
+		Expect(reconcileArgoCDKnownHostsConfigMap(ctx, argoCDNamespace, k8sClient, logr.Discard())).To(Succeed())
+		err := k8sClient.Get(ctx, types.NamespacedName{Namespace: argoCDNamespace, Name: common.ArgoCDKnownHostsConfigMapName}, &corev1.ConfigMap{})
+		Expect(err).To(HaveOccurred())
+
+		Expect(k8sClient.Create(ctx, newRepoSecret("secret-b", "ssh://git@git.example.com/org/repo-b", "git.example.com "+keyB+"\n"))).To(Succeed())
+
+		Expect(reconcileArgoCDKnownHostsConfigMap(ctx, argoCDNamespace, k8sClient, logr.Discard())).To(Succeed())
+		Expect(getKnownHosts()).To(Equal("# BEGIN entries managed by GitOps Service\ngit.example.com " + keyB + "\n# END entries managed by GitOps Service\n"))
+	})
+
+	It("should ignore the SSH known hosts entries of a secret that are not valid for the host of its repository", func() {
+
+		k8sClient = fake.NewClientBuilder().WithObjects(
+			newRepoSecret("secret-a", "ssh://git@git.example.com/org/repo-a", "git.example.com "+keyA+"\n"),
+			newRepoSecret("secret-b", "ssh://git@evil.example.com/org/repo", "git.example.com "+keyB+"\n"),
+			newRepoSecret("secret-c", "ssh://git@evil.example.com/org/repo", "* "+keyB+"\n"),
+			newRepoSecret("secret-d", "ssh://git@evil.example.com/org/repo", "@cert-authority evil.example.com "+keyB+"\n"),
+		).Build()
+
+		Expect(reconcileArgoCDKnownHostsConfigMap(ctx, argoCDNamespace, k8sClient, logr.Discard())).To(Succeed())
+		Expect(getKnownHosts()).To(Equal("# BEGIN entries managed by GitOps Service\ngit.example.com " + keyA + "\n# END entries managed by GitOps Service\n"))
+	})
+
+	It("should ignore the SSH known hosts entries of a host whose repositories are used by other users", func() {
+
+		otherUserSecret := newRepoSecret("secret-b", "git@git.example.com:other-org/repo-b.git", "")
+		otherUserSecret.Data["clusterUserID"] = []byte("other-user")
+
+		unownedSecret := newRepoSecret("secret-d", "ssh://git@registry.example.com/org/repo-d", "")
+		delete(unownedSecret.Data, "clusterUserID")
+
+		k8sClient = fake.NewClientBuilder().WithObjects(
+			newRepoSecret("secret-a", "ssh://git@git.example.com/org/repo-a", "git.example.com "+keyA+"\n"),
+			otherUserSecret,
+			newRepoSecret("secret-c", "ssh://git@registry.example.com/org/repo-c", "registry.example.com "+keyA+"\n"),
+			unownedSecret,
+			newRepoSecret("secret-e", "ssh://git@other.example.com/org/repo-e", "other.example.com "+keyB+"\n"),
+		).Build()
+
+		Expect(reconcileArgoCDKnownHostsConfigMap(ctx, argoCDNamespace, k8sClient, logr.Discard())).To(Succeed())
+		Expect(getKnownHosts()).To(Equal("# BEGIN entries managed by GitOps Service\nother.example.com " + keyB + "\n# END entries managed by GitOps Service\n"))
+
+		By("deleting the secret of the other user, which should add the entries of its host")
+		Expect(k8sClient.Delete(ctx, otherUserSecret)).To(Succeed())
+
+		Expect(reconcileArgoCDKnownHostsConfigMap(ctx, argoCDNamespace, k8sClient, logr.Discard())).To(Succeed())
+		Expect(getKnownHosts()).To(Equal("# BEGIN entries managed by GitOps Service\n" +
+			"git.example.com " + keyA + "\nother.example.com " + keyB + "\n# END entries managed by GitOps Service\n"))
+	})
+
+	It("should ignore the SSH known hosts entries of a host that already has entries which are not managed by GitOps Service", func() {
+
+		existingKnownHosts := "# Argo CD defaults\ngithub.com " + keyA + "\n[git.example.com]:2222 " + keyA + "\n"
+
+		k8sClient = fake.NewClientBuilder().WithObjects(
+			newRepoSecret("secret-a", "ssh://git@github.com/org/repo-a", "github.com "+keyB+"\n"),
+			newRepoSecret("secret-b", "ssh://git@git.example.com:2222/org/repo-b", "[git.example.com]:2222 "+keyB+"\n"),
+			newRepoSecret("secret-c", "ssh://git@other.example.com/org/repo-c", "other.example.com "+keyB+"\n"),
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: common.ArgoCDKnownHostsConfigMapName, Namespace: argoCDNamespace},
+				Data:       map[string]string{"ssh_known_hosts": existingKnownHosts},
+			},
+		).Build()
+
+		Expect(reconcileArgoCDKnownHostsConfigMap(ctx, argoCDNamespace, k8sClient, logr.Discard())).To(Succeed())
+		Expect(getKnownHosts()).To(Equal(existingKnownHosts +
+			"# BEGIN entries managed by GitOps Service\nother.example.com " + keyB + "\n# END entries managed by GitOps Service\n"))
+	})
+})
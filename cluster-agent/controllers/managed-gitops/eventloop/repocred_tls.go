@@ -0,0 +1,68 @@
+package eventloop
+
+import (
+	"context"
+	"strings"
+
+	"github.com/argoproj/argo-cd/v2/common"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// argoCDSecretKeyTLSCACertData is the key of the CA bundle in the Argo CD repository secret. Argo CD does not read
+	// this key, and the CA bundle is not added to the TLS certs ConfigMap of the Argo CD instance: see
+	// reconcileArgoCDTLSCertsConfigMap.
+	argoCDSecretKeyTLSCACertData = "tlsCACertData"
+
+	// argoCDSecretKeyClusterUserID is the key of the ID of the ClusterUser that owns the Argo CD repository secret. Argo CD
+	// does not read this key: it is instead used to prevent the entries of a user, in the ConfigMaps of the Argo CD
+	// instance, from being used for the repositories of other users.
+	argoCDSecretKeyClusterUserID = "clusterUserID"
+
+	// managedTLSCertHostsAnnotation is the annotation, on the TLS certs ConfigMap of the Argo CD instance, that lists the
+	// (comma separated) hosts whose entries were added by GitOps Service. The entries of other hosts are not modified.
+	managedTLSCertHostsAnnotation = "managed-gitops.redhat.com/managed-tls-cert-hosts"
+)
+
+// reconcileArgoCDTLSCertsConfigMap removes the CA bundles that were previously added by GitOps Service from the TLS certs
+// ConfigMap of the Argo CD instance.
+//
+// Argo CD uses the CA bundle of a host, instead of the system CAs, for every repository on the host: including the
+// repositories of other users, which may have no repository credentials (for example, public repositories on
+// github.com). As GitOps Service cannot know every user of a host, the CA bundles of repository credentials are not
+// added to the ConfigMap: the CA bundle of a host must instead be added to the ConfigMap by an administrator.
+func reconcileArgoCDTLSCertsConfigMap(ctx context.Context, argoCDNamespace string, eventClient client.Client, l logr.Logger) error {
+
+	return reconcileArgoCDConfigMap(ctx, common.ArgoCDTLSCertsConfigMapName, argoCDNamespace, false,
+		removeManagedArgoCDTLSCerts, eventClient, l)
+}
+
+// removeManagedArgoCDTLSCerts removes the entries of the hosts that were added by GitOps Service from the ConfigMap. It
+// returns true if the ConfigMap was modified.
+func removeManagedArgoCDTLSCerts(configMap *corev1.ConfigMap) bool {
+
+	managedHosts, exists := configMap.Annotations[managedTLSCertHostsAnnotation]
+	if !exists {
+		return false
+	}
+
+	for _, host := range strings.Split(managedHosts, ",") {
+		if host != "" {
+			delete(configMap.Data, host)
+		}
+	}
+	delete(configMap.Annotations, managedTLSCertHostsAnnotation)
+
+	return true
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -2,7 +2,11 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 	"time"
 
 	appv1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
@@ -10,6 +14,7 @@ import (
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
 	sharedutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util"
 	logutil "github.com/redhat-appstudio/managed-gitops/backend-shared/util/log"
+	corev1 "k8s.io/api/core/v1"
 	apierr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -30,6 +35,13 @@ const (
 	ArgoCDClusterSecretDatabaseIDLabel = "databaseID"
 	ArgoCDApplicationDatabaseIDLabel   = "databaseID"
 	RepoCredDatabaseIDLabel            = "databaseID"
+
+	// ArgoCDClusterSecretManagedLabelsAnnotation and ArgoCDClusterSecretManagedAnnotationsAnnotation are the annotations, on
+	// the Argo CD cluster secret of a managed environment, that list the (comma separated) keys of the cluster labels and
+	// annotations that were added by GitOps Service. Only these are updated or removed: other labels and annotations of
+	// the Secret (for example, those added by other tools) are not modified.
+	ArgoCDClusterSecretManagedLabelsAnnotation      = "managed-gitops.redhat.com/managed-cluster-labels"
+	ArgoCDClusterSecretManagedAnnotationsAnnotation = "managed-gitops.redhat.com/managed-cluster-annotations"
 )
 
 // DeleteArgoCDApplication attempts to gracefully delete an Argo CD application:
@@ -200,8 +212,35 @@ func DeleteArgoCDApplication(ctx context.Context, appFromList appv1.Application,
 // otherwise returning the specific difference.
 func CompareApplication(argoCDApp appv1.Application, dbApplication db.Application, log logr.Logger) (string, error) {
 
+	// reflect.DeepEqual will treat empty Helm/Kustomize slices and maps differently depending on how they are defined,
+	// so we ensure that in every case, they are defined as nil.
+	sanitizeSource := func(source *appv1.ApplicationSource) {
+		if source.Helm != nil {
+
+			if len(source.Helm.ValueFiles) == 0 {
+				source.Helm.ValueFiles = nil
+			}
+			if len(source.Helm.Parameters) == 0 {
+				source.Helm.Parameters = nil
+			}
+		}
+
+		if source.Kustomize != nil {
+
+			if len(source.Kustomize.Images) == 0 {
+				source.Kustomize.Images = nil
+			}
+			if len(source.Kustomize.CommonLabels) == 0 {
+				source.Kustomize.CommonLabels = nil
+			}
+			if len(source.Kustomize.CommonAnnotations) == 0 {
+				source.Kustomize.CommonAnnotations = nil
+			}
+		}
+	}
+
 	// reflect.DeepEqual will treat empty slices differently depending on how they are defined, so we ensure that
-	// in every case, an empty slice is defined as a appv1.SyncOptions{}
+	// in every case, an empty slice is defined as a appv1.SyncOptions{}, and empty sources and ignoreDifferences are defined as nil
 	sanitizeApp := func(input appv1.Application) appv1.Application {
 		if input.Spec.SyncPolicy != nil {
 
@@ -209,6 +248,34 @@ func CompareApplication(argoCDApp appv1.Application, dbApplication db.Applicatio
 				input.Spec.SyncPolicy.SyncOptions = appv1.SyncOptions{}
 			}
 		}
+
+		if input.Spec.Source != nil {
+			sanitizeSource(input.Spec.Source)
+		}
+
+		if len(input.Spec.Sources) == 0 {
+			input.Spec.Sources = nil
+		}
+		for idx := range input.Spec.Sources {
+			sanitizeSource(&input.Spec.Sources[idx])
+		}
+
+		if len(input.Spec.IgnoreDifferences) == 0 {
+			input.Spec.IgnoreDifferences = nil
+		}
+		for idx := range input.Spec.IgnoreDifferences {
+			ignoreDifference := &input.Spec.IgnoreDifferences[idx]
+			if len(ignoreDifference.JSONPointers) == 0 {
+				ignoreDifference.JSONPointers = nil
+			}
+			if len(ignoreDifference.JQPathExpressions) == 0 {
+				ignoreDifference.JQPathExpressions = nil
+			}
+			if len(ignoreDifference.ManagedFieldsManagers) == 0 {
+				ignoreDifference.ManagedFieldsManagers = nil
+			}
+		}
+
 		return input
 	}
 	argoCDApp = sanitizeApp(*argoCDApp.DeepCopy())
@@ -227,14 +294,170 @@ func CompareApplication(argoCDApp appv1.Application, dbApplication db.Applicatio
 	var specDiff string
 	if !reflect.DeepEqual(specFieldAppFromDB.Spec.Source, argoCDApp.Spec.Source) {
 		specDiff = "spec.source fields differ"
+	} else if !reflect.DeepEqual(specFieldAppFromDB.Spec.Sources, argoCDApp.Spec.Sources) {
+		specDiff = "spec.sources fields differ"
 	} else if !reflect.DeepEqual(specFieldAppFromDB.Spec.Destination, argoCDApp.Spec.Destination) {
 		specDiff = "spec.destination fields differ"
 	} else if specFieldAppFromDB.Spec.Project != argoCDApp.Spec.Project {
 		specDiff = "spec project fields differ"
 	} else if !reflect.DeepEqual(specFieldAppFromDB.Spec.SyncPolicy, argoCDApp.Spec.SyncPolicy) {
 		specDiff = "sync policy fields differ"
+	} else if !reflect.DeepEqual(specFieldAppFromDB.Spec.IgnoreDifferences, argoCDApp.Spec.IgnoreDifferences) {
+		specDiff = "spec.ignoreDifferences fields differ"
 	}
 
 	return specDiff, nil
 
 }
+
+// GenerateArgoCDClusterSecretMetadata returns the labels and annotations of the Argo CD cluster secret of a managed environment:
+// the cluster labels/annotations of its cluster credentials, plus the labels that identify the Secret as the Argo CD cluster
+// secret of the managed environment (which take precedence over the cluster labels), plus the annotations that record which
+// cluster labels/annotations were added.
+func GenerateArgoCDClusterSecretMetadata(managedEnvID string, clusterCredentials db.ClusterCredentials) (map[string]string, map[string]string, error) {
+
+	clusterLabels := map[string]string{}
+	if clusterCredentials.Cluster_labels != "" {
+		if err := json.Unmarshal([]byte(clusterCredentials.Cluster_labels), &clusterLabels); err != nil {
+			return nil, nil, fmt.Errorf("unable to unmarshal cluster labels of cluster credentials '%s': %v", clusterCredentials.Clustercredentials_cred_id, err)
+		}
+	}
+
+	clusterAnnotations := map[string]string{}
+	if clusterCredentials.Cluster_annotations != "" {
+		if err := json.Unmarshal([]byte(clusterCredentials.Cluster_annotations), &clusterAnnotations); err != nil {
+			return nil, nil, fmt.Errorf("unable to unmarshal cluster annotations of cluster credentials '%s': %v", clusterCredentials.Clustercredentials_cred_id, err)
+		}
+	}
+
+	labels := map[string]string{}
+	var managedLabelKeys []string
+	for key, value := range clusterLabels {
+		if key == sharedutil.ArgoCDSecretTypeIdentifierKey || key == ArgoCDClusterSecretDatabaseIDLabel {
+			continue
+		}
+		labels[key] = value
+		managedLabelKeys = append(managedLabelKeys, key)
+	}
+	labels[sharedutil.ArgoCDSecretTypeIdentifierKey] = sharedutil.ArgoCDSecretClusterTypeValue
+	labels[ArgoCDClusterSecretDatabaseIDLabel] = managedEnvID
+
+	var annotations map[string]string
+	var managedAnnotationKeys []string
+	for key, value := range clusterAnnotations {
+		if key == ArgoCDClusterSecretManagedLabelsAnnotation || key == ArgoCDClusterSecretManagedAnnotationsAnnotation {
+			continue
+		}
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[key] = value
+		managedAnnotationKeys = append(managedAnnotationKeys, key)
+	}
+
+	for annotation, keys := range map[string][]string{
+		ArgoCDClusterSecretManagedLabelsAnnotation:      managedLabelKeys,
+		ArgoCDClusterSecretManagedAnnotationsAnnotation: managedAnnotationKeys,
+	} {
+		if len(keys) == 0 {
+			continue
+		}
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		sort.Strings(keys)
+		annotations[annotation] = strings.Join(keys, ",")
+	}
+
+	return labels, annotations, nil
+}
+
+// IsArgoCDClusterSecretMetadataEqual returns true if the Argo CD cluster secret has the expected labels and annotations, and
+// none of the cluster labels and annotations that were previously added by GitOps Service, but are no longer expected.
+// Other labels and annotations of the Secret are ignored.
+func IsArgoCDClusterSecretMetadataEqual(secret corev1.Secret, expectedLabels map[string]string, expectedAnnotations map[string]string) bool {
+
+	previousLabelKeys, previousAnnotationKeys := argoCDClusterSecretManagedMetadataKeys(secret)
+
+	return isManagedMetadataEqual(secret.Labels, expectedLabels, previousLabelKeys) &&
+		isManagedMetadataEqual(secret.Annotations, expectedAnnotations, previousAnnotationKeys)
+}
+
+// ApplyArgoCDClusterSecretMetadata sets the expected labels and annotations on the Argo CD cluster secret, and removes the
+// cluster labels and annotations that were previously added by GitOps Service, but are no longer expected. Other labels
+// and annotations of the Secret are kept.
+func ApplyArgoCDClusterSecretMetadata(secret *corev1.Secret, expectedLabels map[string]string, expectedAnnotations map[string]string) {
+
+	previousLabelKeys, previousAnnotationKeys := argoCDClusterSecretManagedMetadataKeys(*secret)
+
+	secret.Labels = applyManagedMetadata(secret.Labels, expectedLabels, previousLabelKeys)
+	secret.Annotations = applyManagedMetadata(secret.Annotations, expectedAnnotations, previousAnnotationKeys)
+}
+
+// argoCDClusterSecretManagedMetadataKeys returns the keys of the labels and annotations of the Argo CD cluster secret that
+// are managed by GitOps Service. The annotations that record these keys are themselves managed.
+func argoCDClusterSecretManagedMetadataKeys(secret corev1.Secret) ([]string, []string) {
+
+	splitKeys := func(value string) []string {
+		if value == "" {
+			return nil
+		}
+		return strings.Split(value, ",")
+	}
+
+	labelKeys := splitKeys(secret.Annotations[ArgoCDClusterSecretManagedLabelsAnnotation])
+
+	annotationKeys := append(splitKeys(secret.Annotations[ArgoCDClusterSecretManagedAnnotationsAnnotation]),
+		ArgoCDClusterSecretManagedLabelsAnnotation, ArgoCDClusterSecretManagedAnnotationsAnnotation)
+
+	return labelKeys, annotationKeys
+}
+
+// isManagedMetadataEqual returns true if 'actual' contains the 'expected' entries, and none of the previously managed keys
+// that are no longer expected.
+func isManagedMetadataEqual(actual map[string]string, expected map[string]string, previouslyManagedKeys []string) bool {
+
+	for key, value := range expected {
+		if actualValue, exists := actual[key]; !exists || actualValue != value {
+			return false
+		}
+	}
+
+	for _, key := range previouslyManagedKeys {
+		if _, isExpected := expected[key]; isExpected {
+			continue
+		}
+		if _, exists := actual[key]; exists {
+			return false
+		}
+	}
+
+	return true
+}
+
+// applyManagedMetadata returns 'actual', with the 'expected' entries set, and the previously managed keys that are no longer
+// expected removed.
+func applyManagedMetadata(actual map[string]string, expected map[string]string, previouslyManagedKeys []string) map[string]string {
+
+	res := map[string]string{}
+	for key, value := range actual {
+		res[key] = value
+	}
+
+	for _, key := range previouslyManagedKeys {
+		if _, isExpected := expected[key]; !isExpected {
+			delete(res, key)
+		}
+	}
+
+	for key, value := range expected {
+		res[key] = value
+	}
+
+	// A nil map is equivalent to an empty map, in a Secret
+	if len(res) == 0 {
+		return nil
+	}
+
+	return res
+}
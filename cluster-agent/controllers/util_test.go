@@ -11,6 +11,7 @@ import (
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/db"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/fauxargocd"
 	"github.com/redhat-appstudio/managed-gitops/backend-shared/util/tests"
+	goyaml "gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -486,6 +487,225 @@ var _ = Describe("Tests for the small number of utility functions in cluster-age
 
 			Expect(result).To(BeEmpty())
 		})
+
+		It("Should consider empty Kustomize lists and maps from the DB entry equal to nil values in Argo CD.", func() {
+
+			appDB, _, appArgo, err := createDummyApplicationData()
+			Expect(err).ToNot(HaveOccurred())
+
+			var ctx context.Context
+			log := log.FromContext(ctx)
+
+			appDB.Spec.Source.Kustomize = &fauxargocd.ApplicationSourceKustomize{
+				NamePrefix: "dev-",
+			}
+			appArgo.Spec.Source.Kustomize = &appv1.ApplicationSourceKustomize{
+				NamePrefix: "dev-",
+			}
+
+			bytes, err := yaml.Marshal(&appDB)
+			Expect(err).ToNot(HaveOccurred())
+
+			result, err := CompareApplication(appArgo, db.Application{Spec_field: string(bytes)}, log)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(BeEmpty())
+
+			By("Kustomize common labels differ between Argo CD and DB, hence it is not in sync.")
+
+			appArgo.Spec.Source.Kustomize.CommonLabels = map[string]string{"env": "dev"}
+			result, err = CompareApplication(appArgo, db.Application{Spec_field: string(bytes)}, log)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).ToNot(BeEmpty())
+		})
+
+		It("Should compare the sources of multi-source applications.", func() {
+
+			appDB, _, appArgo, err := createDummyApplicationData()
+			Expect(err).ToNot(HaveOccurred())
+
+			var ctx context.Context
+			log := log.FromContext(ctx)
+
+			appDB.Spec.Source = fauxargocd.ApplicationSource{}
+			appDB.Spec.Sources = []fauxargocd.ApplicationSource{
+				{
+					RepoURL: "https://github.com/redhat-appstudio/managed-gitops",
+					Ref:     "values",
+				},
+				{
+					RepoURL: "https://charts.example.com",
+					Chart:   "my-chart",
+					Helm: &fauxargocd.ApplicationSourceHelm{
+						ValueFiles: []string{"$values/environments/dev/values.yaml"},
+					},
+				},
+			}
+
+			appArgo.Spec.Source = nil
+			appArgo.Spec.Sources = appv1.ApplicationSources{
+				{
+					RepoURL: "https://github.com/redhat-appstudio/managed-gitops",
+					Ref:     "values",
+				},
+				{
+					RepoURL: "https://charts.example.com",
+					Chart:   "my-chart",
+					Helm: &appv1.ApplicationSourceHelm{
+						ValueFiles: []string{"$values/environments/dev/values.yaml"},
+					},
+				},
+			}
+
+			// The spec field is generated by the backend using gopkg.in/yaml.v2, which omits the empty single source
+			bytes, err := goyaml.Marshal(&appDB)
+			Expect(err).ToNot(HaveOccurred())
+
+			result, err := CompareApplication(appArgo, db.Application{Spec_field: string(bytes)}, log)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(BeEmpty())
+
+			By("The sources differ between Argo CD and DB, hence it is not in sync.")
+
+			appArgo.Spec.Sources[1].TargetRevision = "1.0.0"
+			result, err = CompareApplication(appArgo, db.Application{Spec_field: string(bytes)}, log)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal("spec.sources fields differ"))
+		})
+
+		It("Should compare the ignoreDifferences of applications.", func() {
+
+			appDB, _, appArgo, err := createDummyApplicationData()
+			Expect(err).ToNot(HaveOccurred())
+
+			var ctx context.Context
+			log := log.FromContext(ctx)
+
+			appDB.Spec.IgnoreDifferences = []fauxargocd.ResourceIgnoreDifferences{
+				{
+					Group:                 "apps",
+					Kind:                  "Deployment",
+					JSONPointers:          []string{"/spec/replicas"},
+					JQPathExpressions:     []string{},
+					ManagedFieldsManagers: []string{},
+				},
+			}
+			appArgo.Spec.IgnoreDifferences = []appv1.ResourceIgnoreDifferences{
+				{
+					Group:        "apps",
+					Kind:         "Deployment",
+					JSONPointers: []string{"/spec/replicas"},
+				},
+			}
+
+			// The spec field is generated by the backend using gopkg.in/yaml.v2
+			bytes, err := goyaml.Marshal(&appDB)
+			Expect(err).ToNot(HaveOccurred())
+
+			result, err := CompareApplication(appArgo, db.Application{Spec_field: string(bytes)}, log)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(BeEmpty())
+
+			By("The ignoreDifferences differ between Argo CD and DB, hence it is not in sync.")
+
+			appArgo.Spec.IgnoreDifferences[0].JQPathExpressions = []string{".spec.replicas"}
+			result, err = CompareApplication(appArgo, db.Application{Spec_field: string(bytes)}, log)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal("spec.ignoreDifferences fields differ"))
+		})
 	})
 
+	Context("Testing for GenerateArgoCDClusterSecretMetadata and IsArgoCDClusterSecretMetadataEqual functions.", func() {
+
+		It("should add the cluster labels and annotations of the cluster credentials to the Argo CD cluster secret", func() {
+
+			labels, annotations, err := GenerateArgoCDClusterSecretMetadata("managed-env-id", db.ClusterCredentials{
+				Cluster_labels:      `{"region":"eu"}`,
+				Cluster_annotations: `{"example.com/owner":"team-a"}`,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(labels).To(Equal(map[string]string{
+				"argocd.argoproj.io/secret-type":   "cluster",
+				ArgoCDClusterSecretDatabaseIDLabel: "managed-env-id",
+				"region":                           "eu",
+			}))
+			Expect(annotations).To(Equal(map[string]string{
+				"example.com/owner":                             "team-a",
+				ArgoCDClusterSecretManagedLabelsAnnotation:      "region",
+				ArgoCDClusterSecretManagedAnnotationsAnnotation: "example.com/owner",
+			}))
+
+			secret := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Labels: labels, Annotations: annotations}}
+			Expect(IsArgoCDClusterSecretMetadataEqual(secret, labels, annotations)).To(BeTrue())
+
+			By("verifying that a Secret with a different label is not equal")
+			secret.Labels = map[string]string{"argocd.argoproj.io/secret-type": "cluster", ArgoCDClusterSecretDatabaseIDLabel: "managed-env-id"}
+			Expect(IsArgoCDClusterSecretMetadataEqual(secret, labels, annotations)).To(BeFalse())
+		})
+
+		It("should only compare and update the labels and annotations that are managed by GitOps Service", func() {
+
+			labels, annotations, err := GenerateArgoCDClusterSecretMetadata("managed-env-id", db.ClusterCredentials{
+				Cluster_labels:      `{"region":"eu","tier":"prod"}`,
+				Cluster_annotations: `{"example.com/owner":"team-a"}`,
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			secret := corev1.Secret{}
+			ApplyArgoCDClusterSecretMetadata(&secret, labels, annotations)
+			Expect(IsArgoCDClusterSecretMetadataEqual(secret, labels, annotations)).To(BeTrue())
+
+			By("verifying that labels and annotations added by others are ignored")
+			secret.Labels["other-tool/label"] = "value"
+			secret.Annotations["other-tool/annotation"] = "value"
+			Expect(IsArgoCDClusterSecretMetadataEqual(secret, labels, annotations)).To(BeTrue())
+
+			By("removing a cluster label and the cluster annotations, to ensure they are removed from the Secret")
+			labels, annotations, err = GenerateArgoCDClusterSecretMetadata("managed-env-id", db.ClusterCredentials{
+				Cluster_labels: `{"region":"us"}`,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(IsArgoCDClusterSecretMetadataEqual(secret, labels, annotations)).To(BeFalse())
+
+			ApplyArgoCDClusterSecretMetadata(&secret, labels, annotations)
+			Expect(IsArgoCDClusterSecretMetadataEqual(secret, labels, annotations)).To(BeTrue())
+			Expect(secret.Labels).To(Equal(map[string]string{
+				"argocd.argoproj.io/secret-type":   "cluster",
+				ArgoCDClusterSecretDatabaseIDLabel: "managed-env-id",
+				"region":                           "us",
+				"other-tool/label":                 "value",
+			}))
+			Expect(secret.Annotations).To(Equal(map[string]string{
+				"other-tool/annotation":                    "value",
+				ArgoCDClusterSecretManagedLabelsAnnotation: "region",
+			}))
+
+			By("removing all the cluster labels, to ensure the annotation that records them is also removed")
+			labels, annotations, err = GenerateArgoCDClusterSecretMetadata("managed-env-id", db.ClusterCredentials{})
+			Expect(err).ToNot(HaveOccurred())
+
+			ApplyArgoCDClusterSecretMetadata(&secret, labels, annotations)
+			Expect(secret.Labels).ToNot(HaveKey("region"))
+			Expect(secret.Annotations).To(Equal(map[string]string{"other-tool/annotation": "value"}))
+		})
+
+		It("should not allow the cluster labels to override the labels that identify the Argo CD cluster secret", func() {
+
+			labels, annotations, err := GenerateArgoCDClusterSecretMetadata("managed-env-id", db.ClusterCredentials{
+				Cluster_labels: `{"databaseID":"another-id"}`,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(labels).To(HaveKeyWithValue(ArgoCDClusterSecretDatabaseIDLabel, "managed-env-id"))
+			Expect(annotations).To(BeNil())
+
+			By("verifying that a Secret without annotations is equal to one with empty annotations")
+			secret := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Labels: labels, Annotations: map[string]string{}}}
+			Expect(IsArgoCDClusterSecretMetadataEqual(secret, labels, annotations)).To(BeTrue())
+		})
+
+		It("should return an error if the cluster labels of the cluster credentials are invalid", func() {
+
+			_, _, err := GenerateArgoCDClusterSecretMetadata("managed-env-id", db.ClusterCredentials{Cluster_labels: "not json"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
 })
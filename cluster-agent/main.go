@@ -88,6 +88,11 @@ func main() {
 		go sharedutil.StartProfilers(profilerAddr)
 	}
 
+	if err := db.ValidateColumnEncryptionKey(); err != nil {
+		setupLog.Error(err, "invalid database column encryption key")
+		os.Exit(1)
+	}
+
 	restConfig, err := sharedutil.GetRESTConfig()
 	if err != nil {
 		setupLog.Error(err, "unable to get kubeconfig")
@@ -25,8 +25,24 @@ import (
 // This contents of this file are loosely based on the 'argocd app sync' CLI command:
 // https://github.com/argoproj/argo-cd/blob/0a46d37fc6af9fe0aa963bdd845e3d799aa0320d/cmd/argocd/commands/app.go#L1333
 
+// AppSyncParameters describes how an Argo CD Application is synchronized by AppSync
+type AppSyncParameters struct {
+	// Revision is the revision to sync the Application to, or empty for the target revision of the Application
+	Revision string
+	// Prune deletes resources that are no longer defined in the Application's source
+	Prune bool
+	// DryRun performs the sync without making changes to the cluster
+	DryRun bool
+	// Force deletes and recreates resources that cannot be updated in place
+	Force bool
+	// Strategy is the sync strategy: 'apply', 'hook', or empty for the default ('hook')
+	Strategy string
+	// Resources limits the sync to the given resources, or syncs all resources if empty
+	Resources []argoappv1.SyncOperationResource
+}
+
 // AppSync will trigger a synchronize application on the given Argo CD appliatication, in the given namespace.
-func AppSync(ctx context.Context, appName string, revision string, namespaceName string, k8sClient client.Client,
+func AppSync(ctx context.Context, appName string, syncParams AppSyncParameters, namespaceName string, k8sClient client.Client,
 	credentialsService *CredentialService, skipTLSTest bool) error {
 
 	namespace := &corev1.Namespace{
@@ -45,7 +61,8 @@ func AppSync(ctx context.Context, appName string, revision string, namespaceName
 		return err
 	}
 
-	err = appSync(ctx, acdClient, appName, false, false, revision, false, "", false, false, 0, 0, 0, 0, 0)
+	err = appSync(ctx, acdClient, appName, syncParams.DryRun, false, syncParams.Revision, syncParams.Prune, syncParams.Resources,
+		syncParams.Strategy, syncParams.Force, false, 0, 0, 0, 0, 0)
 	if err != nil {
 		return err
 	}
@@ -55,7 +72,7 @@ func AppSync(ctx context.Context, appName string, revision string, namespaceName
 }
 
 func appSync(ctx context.Context, acdClient argocdclient.Client, appName string, dryRun bool, replace bool, revision string, prune bool,
-	strategy string, force bool, async bool, timeout uint, retryLimit int64, retryBackoffDuration time.Duration,
+	selectedResources []argoappv1.SyncOperationResource, strategy string, force bool, async bool, timeout uint, retryLimit int64, retryBackoffDuration time.Duration,
 	retryBackoffMaxDuration time.Duration, retryBackoffFactor int64) error {
 
 	conn, appIf, err := acdClient.NewApplicationClient()
@@ -90,6 +107,10 @@ func appSync(ctx context.Context, acdClient argocdclient.Client, appName string,
 		SyncOptions: syncOptionsFactory(),
 	}
 
+	for i := range selectedResources {
+		syncReq.Resources = append(syncReq.Resources, &selectedResources[i])
+	}
+
 	switch strategy {
 	case "apply":
 		syncReq.Strategy = &argoappv1.SyncStrategy{Apply: &argoappv1.SyncStrategyApply{}}
@@ -116,7 +137,7 @@ func appSync(ctx context.Context, acdClient argocdclient.Client, appName string,
 	}
 
 	if !async {
-		app, err := waitOnApplicationStatus(ctx, acdClient, appName, timeout, false, false, true, false, []argoappv1.SyncOperationResource{})
+		app, err := waitOnApplicationStatus(ctx, acdClient, appName, timeout, false, false, true, false, selectedResources)
 		if err != nil {
 			return err
 		}
@@ -125,7 +146,7 @@ func appSync(ctx context.Context, acdClient argocdclient.Client, appName string,
 			operationState := app.Status.OperationState
 			if !operationState.Phase.Successful() {
 				return fmt.Errorf("operation has completed with phase: %s and message: %s", operationState.Phase, operationState.Message)
-			} else if /*len(selectedResources) == 0 &&*/ app.Status.Sync.Status != argoappv1.SyncStatusCodeSynced {
+			} else if len(selectedResources) == 0 && app.Status.Sync.Status != argoappv1.SyncStatusCodeSynced {
 				// Only get resources to be pruned if sync was application-wide and final status is not synced
 				pruningRequired := operationState.SyncResult.Resources.PruningRequired()
 				if pruningRequired > 0 {
@@ -138,6 +159,56 @@ func appSync(ctx context.Context, acdClient argocdclient.Client, appName string,
 	return nil
 }
 
+// AppRollback rolls back the Argo CD Application to the deployment with the given ID, from the history of the Application,
+// and waits for the resulting operation to complete. Of the sync parameters, only Prune and DryRun apply to a rollback.
+func AppRollback(ctx context.Context, appName string, historyID int64, syncParams AppSyncParameters, namespaceName string,
+	k8sClient client.Client, credentialsService *CredentialService, skipTLSTest bool) error {
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: namespaceName,
+		},
+	}
+
+	err := k8sClient.Get(ctx, client.ObjectKeyFromObject(namespace), namespace)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve namespace in AppRollback: %s, %v", namespaceName, err)
+	}
+
+	_, acdClient, err := credentialsService.GetArgoCDLoginCredentials(ctx, namespaceName, string(namespace.UID), false, k8sClient)
+	if err != nil {
+		return err
+	}
+
+	conn, appIf, err := acdClient.NewApplicationClient()
+	if err != nil {
+		return fmt.Errorf("unable to retrieve acd client: %v", err)
+	}
+	defer argoio.Close(conn)
+
+	rollbackReq := applicationpkg.ApplicationRollbackRequest{
+		Name:   &appName,
+		Id:     &historyID,
+		Prune:  &syncParams.Prune,
+		DryRun: &syncParams.DryRun,
+	}
+
+	if _, err := appIf.Rollback(ctx, &rollbackReq); err != nil {
+		return fmt.Errorf("unable to roll back application '%s' to history ID %d: %v", appName, historyID, err)
+	}
+
+	app, err := waitOnApplicationStatus(ctx, acdClient, appName, 0, false, false, true, false, []argoappv1.SyncOperationResource{})
+	if err != nil {
+		return err
+	}
+
+	if operationState := app.Status.OperationState; operationState != nil && !operationState.Phase.Successful() {
+		return fmt.Errorf("rollback operation has completed with phase: %s and message: %s", operationState.Phase, operationState.Message)
+	}
+
+	return nil
+}
+
 // ResourceDiff tracks the state of a resource when waiting on an application status.
 type resourceState struct {
 	Group     string
@@ -124,7 +124,7 @@ var _ = Describe("ArgoCD AppSync Command", func() {
 			}
 
 			cs := NewCredentialService(&clientGenerator, true)
-			err = AppSync(context.Background(), appName, "master", "openshift-gitops", k8sClient, cs, true)
+			err = AppSync(context.Background(), appName, AppSyncParameters{Revision: "master"}, "openshift-gitops", k8sClient, cs, true)
 			Expect(err).ToNot(HaveOccurred())
 		})
 	})
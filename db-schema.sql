@@ -45,11 +45,47 @@ CREATE TABLE ClusterCredentials (
 
 	-- A list of namespaces that Argo CD is able to deploy to using these cluster credentials
 	-- - This corresponds to the Argo CD cluster secret field of the same name.
-	namespaces VARCHAR (4096),
+	namespaces VARCHAR (65536),
 
 	-- Whether or not Argo CD is able to deploy cluster-scoped resources using these cluster credentials
 	-- - This corresponds to the Argo CD cluster secret field of the same name.
-	cluster_resources BOOLEAN DEFAULT FALSE
+	cluster_resources BOOLEAN DEFAULT FALSE,
+
+	-- PEM-encoded CA bundle that is used to verify the TLS certificate of the cluster API URL.
+	-- - This corresponds to the 'caData' field of the Argo CD cluster secret config.
+	ca_data VARCHAR (16384),
+
+	-- State 2) PEM-encoded client certificate and key, used to authenticate to the cluster (as an alternative to the bearer token)
+	-- - These correspond to the 'certData'/'keyData' fields of the Argo CD cluster secret config.
+	-- - The key is encrypted: the encrypted value is about 4/3 the length of the key, so keys of up to ~12000 characters fit.
+	cert_data VARCHAR (16384),
+	key_data VARCHAR (16384),
+
+	-- State 2) JSON-encoded exec plugin configuration, used to authenticate to the cluster (as an alternative to the bearer token)
+	-- - This corresponds to the 'execProviderConfig' field of the Argo CD cluster secret config.
+	exec_provider_config VARCHAR (4096),
+
+	-- JSON-encoded RBAC policy rules that were granted to the ServiceAccount created by the GitOps Service (if any)
+	-- - This corresponds to the '.spec.serviceAccountRules' field of the GitOpsDeploymentManagedEnvironment.
+	serviceaccount_rules VARCHAR (16384),
+
+	-- The UID that was used to name the ServiceAccount/ClusterRole/ClusterRoleBinding/Roles/RoleBindings that were installed
+	-- into the managed cluster by the GitOps Service (if any). These resources are removed when the credentials are no longer used.
+	-- - This corresponds to the UID of the GitOpsDeploymentManagedEnvironment that requested the ServiceAccount.
+	serviceaccount_installation_uid VARCHAR (48),
+
+	-- The time at which serviceaccount_bearer_token expires, if it was issued by the Kubernetes TokenRequest API.
+	-- - NULL if the token does not expire (for example, it was read from a ServiceAccount token Secret, or provided by the user).
+	serviceaccount_token_expiration TIMESTAMP,
+
+	-- URL of the HTTP(S) proxy that is used to reach the cluster API URL (if any)
+	-- - This corresponds to the 'proxyUrl' field of the Argo CD cluster secret config.
+	proxy_url VARCHAR (512),
+
+	-- JSON-encoded labels and annotations that are added to the Argo CD cluster secret (if any)
+	-- - These correspond to the '.spec.clusterLabels'/'.spec.clusterAnnotations' fields of the GitOpsDeploymentManagedEnvironment.
+	cluster_labels VARCHAR (16384),
+	cluster_annotations VARCHAR (16384)
 
 );
 
@@ -449,6 +485,23 @@ CREATE TABLE SyncOperation (
 	-- values: Running, Terminated
 	desired_state VARCHAR(16) NOT NULL,	
 
+	-- The ID of the Argo CD Application history entry to roll back to, if the 'rollback' field of the GitOpsDeploymentSyncRun CR is set.
+	-- Null if the SyncOperation is a regular sync.
+	rollback_history_id BIGINT,
+
+	-- The 'prune', 'dryRun' and 'force' fields of the GitOpsDeploymentSyncRun CR
+	prune BOOLEAN DEFAULT FALSE,
+	dry_run BOOLEAN DEFAULT FALSE,
+	force BOOLEAN DEFAULT FALSE,
+
+	-- The 'syncStrategy' field of the GitOpsDeploymentSyncRun CR
+	-- values: apply, hook, or empty for the default (hook)
+	sync_strategy VARCHAR(16),
+
+	-- The 'resources' field of the GitOpsDeploymentSyncRun CR: a comma-separated list of resources, each of
+	-- the form 'GROUP:KIND:NAMESPACE/NAME'. Empty if all the resources of the Application should be synced.
+	resources VARCHAR(4096),
+
 	seq_id serial,
 
 	-- When SyncOperation was created, which allow us to tell how old the resources are
@@ -456,7 +509,7 @@ CREATE TABLE SyncOperation (
 
 );
 
--- RepositoryCredentials represents Git repository credentials (username/password, or an SSH key).
+-- RepositoryCredentials represents Git repository credentials (username/password, an SSH key, or a GitHub App).
 -- This database table will then correspond to an Argo CD repository secret in the namespace of the target Argo CD instance.
 CREATE TABLE RepositoryCredentials (
 
@@ -480,6 +533,42 @@ CREATE TABLE RepositoryCredentials (
 	-- Alternative authentication method using an authorized private SSH key
 	repo_cred_ssh VARCHAR (1024),
 
+	-- Alternative authentication method using a GitHub App: the ID of the GitHub App
+	repo_cred_github_app_id VARCHAR (64),
+
+	-- The ID of the installation of the GitHub App, in the organization/user account that owns the repository
+	repo_cred_github_app_installation_id VARCHAR (64),
+
+	-- The private key of the GitHub App (PEM encoded, encrypted), used to mint installation access tokens
+	-- - The encrypted value is about 4/3 the length of the key, so keys of up to ~6000 characters fit.
+	repo_cred_github_app_private_key VARCHAR (8192),
+
+	-- Optional: the base URL of the GitHub Enterprise API (example: https://ghe.example.com/api/v3). Defaults to GitHub.com.
+	repo_cred_github_app_enterprise_base_url VARCHAR (512),
+
+	-- Whether the credentials are a template, in which case 'repo_cred_url' is a URL prefix, and the credentials are used
+	-- for every repository whose URL begins with it
+	repo_cred_template BOOLEAN DEFAULT FALSE,
+
+	-- The type of the repository: 'git', 'helm' (a Helm chart repository), or 'oci' (an OCI registry). Empty is 'git'.
+	repo_cred_type VARCHAR (16),
+
+	-- Alternative (or additional) authentication method using a TLS client certificate (PEM encoded, encrypted)
+	-- - The encrypted value is about 4/3 the length of the certificate, so certificates of up to ~12000 characters fit.
+	repo_cred_tls_client_cert_data VARCHAR (16384),
+
+	-- The private key of the TLS client certificate (PEM encoded, encrypted, as with 'repo_cred_tls_client_cert_data')
+	repo_cred_tls_client_cert_key VARCHAR (16384),
+
+	-- Optional: the bundle of CA certificates (PEM encoded) that are trusted to sign the certificate of the repository server
+	repo_cred_tls_ca_cert_data VARCHAR (16384),
+
+	-- Whether the certificate of the repository server is verified
+	repo_cred_insecure BOOLEAN DEFAULT FALSE,
+
+	-- Optional: the SSH known hosts entries (in the OpenSSH 'known_hosts' format) of the servers of SSH repository URLs
+	repo_cred_known_hosts VARCHAR (16384),
+
 	-- The name of the Secret resource in the Argo CD Repository, in the GitOps Engine instance
 	repo_cred_secret VARCHAR(48) NOT NULL,
 
@@ -6,6 +6,13 @@ kubectl create namespace gitops 2> /dev/null || true
 if ! kubectl get secret -n gitops gitops-postgresql-staging &>/dev/null; then
 	kubectl create secret generic gitops-postgresql-staging \
 	--namespace=gitops \
-	--from-literal=postgresql-password=$(openssl rand -base64 20)
+	--from-literal=postgresql-password=$(openssl rand -base64 20) \
+	--from-literal=column-encryption-key=$(openssl rand -base64 32)
+elif [ -z "$(kubectl get secret -n gitops gitops-postgresql-staging -o jsonpath='{.data.column-encryption-key}')" ]; then
+	# Secrets created by earlier versions have no column encryption key: the backend and cluster-agent do not start without it.
+	kubectl patch secret gitops-postgresql-staging \
+	--namespace=gitops \
+	--type=merge \
+	-p "{\"stringData\":{\"column-encryption-key\":\"$(openssl rand -base64 32)\"}}"
 fi
 
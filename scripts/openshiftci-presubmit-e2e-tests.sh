@@ -41,6 +41,9 @@ set +x
 # DB Password is required by E2E to connect with DB
 export DB_PASS=$(kubectl get -n gitops secret gitops-postgresql-staging -o jsonpath="{.data.postgresql-password}" | base64 --decode)
 
+# The column encryption key is required by E2E to read encrypted columns from the DB
+export DB_COLUMN_ENCRYPTION_KEY=$(kubectl get -n gitops secret gitops-postgresql-staging -o jsonpath="{.data.column-encryption-key}" | base64 --decode)
+
 # Show commands in logs
 set -x
 
@@ -0,0 +1 @@
+ALTER TABLE SyncOperation DROP COLUMN prune, DROP COLUMN dry_run, DROP COLUMN force, DROP COLUMN sync_strategy, DROP COLUMN resources;
\ No newline at end of file
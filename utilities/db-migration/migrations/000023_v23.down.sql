@@ -0,0 +1 @@
+ALTER TABLE ClusterCredentials DROP COLUMN ca_data;
\ No newline at end of file
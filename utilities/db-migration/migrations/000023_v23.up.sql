@@ -0,0 +1 @@
+ALTER TABLE ClusterCredentials ADD COLUMN ca_data VARCHAR(16384);
\ No newline at end of file
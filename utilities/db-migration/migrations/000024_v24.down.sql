@@ -0,0 +1 @@
+ALTER TABLE ClusterCredentials DROP COLUMN cert_data, DROP COLUMN key_data, DROP COLUMN exec_provider_config;
\ No newline at end of file
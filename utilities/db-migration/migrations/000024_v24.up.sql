@@ -0,0 +1,3 @@
+ALTER TABLE ClusterCredentials ADD COLUMN cert_data VARCHAR(16384);
+ALTER TABLE ClusterCredentials ADD COLUMN key_data VARCHAR(16384);
+ALTER TABLE ClusterCredentials ADD COLUMN exec_provider_config VARCHAR(4096);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE ClusterCredentials DROP COLUMN serviceaccount_rules;
\ No newline at end of file
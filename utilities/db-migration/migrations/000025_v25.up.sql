@@ -0,0 +1 @@
+ALTER TABLE ClusterCredentials ADD COLUMN serviceaccount_rules VARCHAR(16384);
\ No newline at end of file
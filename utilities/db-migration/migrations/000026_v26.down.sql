@@ -0,0 +1 @@
+ALTER TABLE ClusterCredentials DROP COLUMN serviceaccount_installation_uid;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE ClusterCredentials ADD COLUMN serviceaccount_installation_uid VARCHAR(48);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE ClusterCredentials DROP COLUMN serviceaccount_token_expiration;
\ No newline at end of file
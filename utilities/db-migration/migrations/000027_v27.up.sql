@@ -0,0 +1 @@
+ALTER TABLE ClusterCredentials ADD COLUMN serviceaccount_token_expiration TIMESTAMP;
\ No newline at end of file
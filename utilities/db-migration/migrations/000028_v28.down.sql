@@ -0,0 +1 @@
+ALTER TABLE ClusterCredentials ALTER COLUMN namespaces TYPE VARCHAR (4096);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE ClusterCredentials ADD COLUMN proxy_url VARCHAR (512);
\ No newline at end of file
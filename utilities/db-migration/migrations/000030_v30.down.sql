@@ -0,0 +1,2 @@
+ALTER TABLE ClusterCredentials DROP COLUMN IF EXISTS cluster_labels;
+ALTER TABLE ClusterCredentials DROP COLUMN IF EXISTS cluster_annotations;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE ClusterCredentials ADD COLUMN cluster_labels VARCHAR (16384);
+ALTER TABLE ClusterCredentials ADD COLUMN cluster_annotations VARCHAR (16384);
\ No newline at end of file
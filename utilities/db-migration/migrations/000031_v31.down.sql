@@ -0,0 +1,4 @@
+ALTER TABLE RepositoryCredentials DROP COLUMN IF EXISTS repo_cred_github_app_id;
+ALTER TABLE RepositoryCredentials DROP COLUMN IF EXISTS repo_cred_github_app_installation_id;
+ALTER TABLE RepositoryCredentials DROP COLUMN IF EXISTS repo_cred_github_app_private_key;
+ALTER TABLE RepositoryCredentials DROP COLUMN IF EXISTS repo_cred_github_app_enterprise_base_url;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE RepositoryCredentials ADD COLUMN repo_cred_github_app_id VARCHAR (64);
+ALTER TABLE RepositoryCredentials ADD COLUMN repo_cred_github_app_installation_id VARCHAR (64);
+ALTER TABLE RepositoryCredentials ADD COLUMN repo_cred_github_app_private_key VARCHAR (8192);
+ALTER TABLE RepositoryCredentials ADD COLUMN repo_cred_github_app_enterprise_base_url VARCHAR (512);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE RepositoryCredentials DROP COLUMN IF EXISTS repo_cred_template;
\ No newline at end of file
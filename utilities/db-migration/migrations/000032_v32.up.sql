@@ -0,0 +1 @@
+ALTER TABLE RepositoryCredentials ADD COLUMN repo_cred_template BOOLEAN DEFAULT FALSE;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE RepositoryCredentials ADD COLUMN repo_cred_type VARCHAR (16);
\ No newline at end of file
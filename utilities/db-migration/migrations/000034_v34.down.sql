@@ -0,0 +1,4 @@
+ALTER TABLE RepositoryCredentials DROP COLUMN IF EXISTS repo_cred_tls_client_cert_data;
+ALTER TABLE RepositoryCredentials DROP COLUMN IF EXISTS repo_cred_tls_client_cert_key;
+ALTER TABLE RepositoryCredentials DROP COLUMN IF EXISTS repo_cred_tls_ca_cert_data;
+ALTER TABLE RepositoryCredentials DROP COLUMN IF EXISTS repo_cred_insecure;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE RepositoryCredentials ADD COLUMN repo_cred_tls_client_cert_data VARCHAR (16384);
+ALTER TABLE RepositoryCredentials ADD COLUMN repo_cred_tls_client_cert_key VARCHAR (16384);
+ALTER TABLE RepositoryCredentials ADD COLUMN repo_cred_tls_ca_cert_data VARCHAR (16384);
+ALTER TABLE RepositoryCredentials ADD COLUMN repo_cred_insecure BOOLEAN DEFAULT FALSE;
@@ -0,0 +1 @@
+ALTER TABLE RepositoryCredentials DROP COLUMN IF EXISTS repo_cred_known_hosts;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE RepositoryCredentials ADD COLUMN repo_cred_known_hosts VARCHAR (16384);
\ No newline at end of file
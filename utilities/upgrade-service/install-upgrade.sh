@@ -82,7 +82,16 @@ function generate_postgresql_secret {
   if ! kubectl get secret -n gitops gitops-postgresql-staging &>/dev/null; then
     kubectl create secret generic gitops-postgresql-staging \
     --namespace=gitops \
-    --from-literal=postgresql-password=$(openssl rand -base64 20)
+    --from-literal=postgresql-password=$(openssl rand -base64 20) \
+    --from-literal=column-encryption-key=$(openssl rand -base64 32)
+  elif [ -z "$(kubectl get secret -n gitops gitops-postgresql-staging -o jsonpath='{.data.column-encryption-key}')" ]; then
+    # The Secret was created by an earlier version, which did not encrypt database columns: the backend and cluster-agent
+    # do not start without the key.
+    echo "Generating column-encryption-key in existing secret"
+    kubectl patch secret gitops-postgresql-staging \
+    --namespace=gitops \
+    --type=merge \
+    -p "{\"stringData\":{\"column-encryption-key\":\"$(openssl rand -base64 32)\"}}"
   fi
 }
 